@@ -0,0 +1,99 @@
+// cmd/url-shortener/cli.go
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/user"
+
+	"url-shortener/internal/cli"
+	"url-shortener/internal/config"
+	"url-shortener/internal/lib/snapshot"
+	"url-shortener/internal/storage/sqlite"
+)
+
+// runCLI выполняет команду администрирования и возвращает код завершения:
+// 0 - успех, 1 - ошибка выполнения, 2 - неверные аргументы
+func runCLI(cfg *config.Config, args []string) int {
+	if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		fmt.Print(cli.Usage)
+		return 0
+	}
+
+	// Восстановление заменяет файл БД, поэтому выполняется без открытия хранилища
+	if len(args) > 1 && args[0] == "db" && args[1] == "restore" {
+		return runRestore(cfg, args[2:])
+	}
+
+	policy, err := setupAliasPolicy(cfg.Aliases)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "invalid alias policy:", err)
+		return 1
+	}
+
+	storage, err := sqlite.NewStorage(cfg.StoragePath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to open storage:", err)
+		return 1
+	}
+	defer func() { _ = storage.Close() }()
+
+	err = cli.New(storage, policy, os.Stdout, operatorName()).Run(args)
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, cli.ErrUsage):
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprint(os.Stderr, "\n", cli.Usage)
+		return 2
+	default:
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+}
+
+// runRestore восстанавливает БД storage_path из резервной копии.
+// Копия сверяется с контрольной суммой и проверяется (целостность, версия схемы) до замены БД
+func runRestore(cfg *config.Config, args []string) int {
+	fs := flag.NewFlagSet("db restore", flag.ContinueOnError)
+	skipChecksum := fs.Bool("skip-checksum", false, "do not verify the snapshot checksum (for copies made by db backup)")
+
+	if err := fs.Parse(args); err != nil || fs.NArg() != 1 {
+		fmt.Fprint(os.Stderr, "usage: db restore [--skip-checksum] <snapshot>\n")
+		return 2
+	}
+	path := fs.Arg(0)
+
+	previous, err := snapshot.Restore(path, cfg.StoragePath, !*skipChecksum, func(restored string) error {
+		version, err := sqlite.CheckSnapshot(restored)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("snapshot schema version %d (current %d)\n", version, sqlite.SchemaVersion())
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+
+	if previous != "" {
+		fmt.Println("previous database moved to", previous)
+	}
+	fmt.Println("database restored from", path)
+
+	return 0
+}
+
+// operatorName - имя пользователя ОС для журнала аудита
+func operatorName() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+
+	return "unknown"
+}
@@ -4,11 +4,14 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"github.com/go-chi/cors"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
 	"syscall"
 	"time"
 	"url-shortener/internal/lib/jwt"
@@ -19,15 +22,47 @@ import (
 	"github.com/go-chi/chi/v5/middleware"
 
 	"url-shortener/internal/config"
+	"url-shortener/internal/http-server/handlers/account"
+	"url-shortener/internal/http-server/handlers/admin/aliases"
+	"url-shortener/internal/http-server/handlers/admin/audit"
+	"url-shortener/internal/http-server/handlers/admin/backups"
+	"url-shortener/internal/http-server/handlers/admin/domains"
+	"url-shortener/internal/http-server/handlers/admin/threats"
+	"url-shortener/internal/http-server/handlers/keys"
+	"url-shortener/internal/http-server/handlers/url/list"
 	"url-shortener/internal/http-server/handlers/url/redirect"
 	"url-shortener/internal/http-server/handlers/url/remove"
+	"url-shortener/internal/http-server/handlers/url/restore"
+	"url-shortener/internal/http-server/handlers/url/rules"
+	"url-shortener/internal/http-server/handlers/url/stats"
+	"url-shortener/internal/http-server/handlers/url/transfer"
+	"url-shortener/internal/http-server/handlers/url/update"
 
 	"url-shortener/internal/http-server/handlers/url/save"
+	"url-shortener/internal/http-server/handlers/webhooks"
+	"url-shortener/internal/http-server/handlers/workspaces"
+	"url-shortener/internal/http-server/middleware/auth"
+	"url-shortener/internal/http-server/middleware/idempotency"
 	mwLogger "url-shortener/internal/http-server/middleware/logger"
+	"url-shortener/internal/jobs/backup"
+	"url-shortener/internal/jobs/deliver"
+	"url-shortener/internal/jobs/healthcheck"
+	"url-shortener/internal/jobs/preview"
+	"url-shortener/internal/jobs/purge"
+	"url-shortener/internal/jobs/relay"
+	"url-shortener/internal/jobs/screen"
 
 	ssogrpc "url-shortener/internal/clients/sso/grpc"
+	"url-shortener/internal/lib/aliaspolicy"
+	"url-shortener/internal/lib/geoip"
+	"url-shortener/internal/lib/pagemeta"
+	"url-shortener/internal/lib/publisher"
+	"url-shortener/internal/lib/snapshot"
+	"url-shortener/internal/lib/threatlist"
+	"url-shortener/internal/lib/utm"
 	//"url-shortener/internal/lib/logger/handlers/slogpretty"
 	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/storage"
 	"url-shortener/internal/storage/sqlite"
 )
 
@@ -38,11 +73,18 @@ const (
 )
 
 // для запуска (в bash):
-// CONFIG_PATH="./config/local.yaml" go run  "./cmd/url-shortener/main.go"
+// CONFIG_PATH="./config/local.yaml" go run  "./cmd/url-shortener"
 func main() {
 	//region Получаем объект конфига
 	cfg := config.MustLoadFetchFlag() // ...или с использованием параметра командной строки
 	//cfg := config.MustLoad()			// с использованием переменной окружения CONFIG_PATH
+
+	// Команды администрирования: url-shortener --config ./config/local.yaml link list.
+	// Без команды или с командой serve запускается сервер
+	if args := flag.Args(); len(args) > 0 && args[0] != "serve" {
+		os.Exit(runCLI(cfg, args))
+	}
+
 	fmt.Println("Конфигурация загружена успешно")
 	//endregion
 	//region Получаем логгер
@@ -72,12 +114,19 @@ func main() {
 	log.Debug("logger debug mode enabled")
 	//endregion
 	//region Создаем объект клиента gRPC-сервиса SSO
+	ssoOpts, err := setupSSOOptions(cfg.Clients.SSO)
+	if err != nil {
+		log.Error("invalid sso client config", sl.Err(err))
+		os.Exit(1)
+	}
+
 	ssoClient, err := ssogrpc.New(
 		context.Background(),
 		log,
 		cfg.Clients.SSO.Address,
 		cfg.Clients.SSO.Timeout,
 		cfg.Clients.SSO.RetriesCount,
+		ssoOpts...,
 	)
 	if err != nil {
 		log.Error("failed to init sso client", sl.Err(err))
@@ -118,8 +167,93 @@ func main() {
 	}
 
 	log.Info("storage created")
+
+	// Режим сравнения алиасов. При смене режима совпадающие алиасы не дают запустить сервис
+	if err := storage.SetAliasMatch(aliaspolicy.MatchMode(cfg.Aliases.Match)); err != nil {
+		log.Error("failed to set alias match mode", slog.String("match", cfg.Aliases.Match), sl.Err(err))
+		os.Exit(1)
+	}
 	fmt.Println(storage)
 	//endregion
+	if !utm.ValidPolicy(cfg.HTTPServer.QueryPolicy) {
+		log.Error("invalid query policy", slog.String("query_policy", cfg.HTTPServer.QueryPolicy))
+		os.Exit(1)
+	}
+	if cfg.HTTPServer.IdempotencyWindow > 0 && cfg.HTTPServer.IdempotencyStaleAfter <= cfg.HTTPServer.Timeout {
+		log.Error("idempotency_stale_after must be greater than the server timeout",
+			slog.Duration("idempotency_stale_after", cfg.HTTPServer.IdempotencyStaleAfter),
+			slog.Duration("timeout", cfg.HTTPServer.Timeout))
+		os.Exit(1)
+	}
+	//region Загружаем GeoIP-базу для правил редиректа по стране
+	var geo *geoip.DB
+	if cfg.GeoIPPath != "" {
+		geo, err = geoip.Open(cfg.GeoIPPath)
+		if err != nil {
+			log.Error("failed to load geoip database", sl.Err(err))
+			os.Exit(1)
+		}
+		log.Info("geoip database loaded", slog.String("path", cfg.GeoIPPath))
+	}
+	//endregion
+	//region Политика алиасов
+	aliasPolicy, err := setupAliasPolicy(cfg.Aliases)
+	if err != nil {
+		log.Error("invalid alias policy", sl.Err(err))
+		os.Exit(1)
+	}
+	//endregion
+	//region Резервные копии БД (по расписанию и по запросу администратора)
+	snapshots := snapshot.New(storage, cfg.Backup.Dir, cfg.Backup.Keep, cfg.Backup.Compress)
+	//endregion
+	//region Метаданные страниц перехода для предпросмотра ссылок
+	var (
+		previews      save.PreviewFetcher
+		previewWorker *preview.Worker
+	)
+	if cfg.Preview.PollInterval > 0 {
+		fetcher := pagemeta.New(pagemeta.Options{
+			Timeout:      cfg.Preview.Timeout,
+			MaxBytes:     cfg.Preview.MaxBytes,
+			AllowPrivate: cfg.Preview.AllowPrivate,
+			UserAgent:    "url-shortener-preview/1.0",
+		})
+		previewWorker = preview.New(log, storage, fetcher, preview.Options{
+			Concurrency: cfg.Preview.Concurrency,
+			BatchSize:   cfg.Preview.BatchSize,
+		})
+		previews = previewWorker
+	}
+	//endregion
+	//region Список опасных адресов: проверка при создании и изменении ссылок и периодически
+	var (
+		screener   save.ThreatScreener
+		threatFile *threatlist.File
+	)
+	if cfg.Threats.ListPath != "" {
+		threatFile, err = threatlist.Open(cfg.Threats.ListPath)
+		if err != nil {
+			log.Error("failed to load threat list", sl.Err(err))
+			os.Exit(1)
+		}
+		screener = threatFile
+
+		log.Info("threat list loaded", slog.String("path", cfg.Threats.ListPath), slog.Int("entries", threatFile.Len()))
+	}
+	//endregion
+	//region Публикация событий ссылок во внешнюю шину через outbox
+	eventPublisher, outboxEvents, err := setupOutbox(cfg.Outbox)
+	if err != nil {
+		log.Error("invalid outbox config", sl.Err(err))
+		os.Exit(1)
+	}
+	if eventPublisher != nil {
+		defer func() { _ = eventPublisher.Close() }()
+
+		storage.SetOutboxEvents(outboxEvents)
+		log.Info("outbox enabled", slog.String("publisher", cfg.Outbox.Publisher), slog.Any("events", outboxEvents))
+	}
+	//endregion
 
 	//region Создаем http-сервер
 
@@ -133,9 +267,9 @@ func main() {
 		//AllowedOrigins: []string{"https://87.242.85.156:*", "http://87.242.85.156:*"}, // пока что разрешаем все
 		AllowedOrigins: []string{"https://*", "http://*"}, // пока что разрешаем все
 		// AllowOriginFunc:  func(r *http.Request, origin string) bool { return true },
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-API-Key", idempotency.HeaderKey},
+		ExposedHeaders:   []string{"Link", idempotency.HeaderReplayed},
 		AllowCredentials: false,
 		MaxAge:           300, // Maximum value not ignored by any of major browsers
 	}))
@@ -156,30 +290,152 @@ func main() {
 	//--------------------------------------------------------------------------------
 	//router.Post("/", save.New(log, storage))
 
+	// Цепочки аутентификации групп маршрутов (см. auth.groups в конфиге).
+	// Каждая цепочка сохраняет в контекст запроса Principal - от чьего имени выполняется запрос
+	authChains, err := setupAuthChains(cfg, storage, ssoClient)
+	if err != nil {
+		log.Error("failed to setup authentication", sl.Err(err))
+		os.Exit(1)
+	}
+	authGroup := func(group string) func(next http.Handler) http.Handler {
+		return auth.Chain(log, "url-shortener", authChains[group]...)
+	}
+
 	// Все пути этого роутера будут начинаться с префикса `/url`
 	router.Route("/url", func(r chi.Router) {
-		// Подключаем базовую аутентификацияю
-		r.Use(middleware.BasicAuth("url-shortener", map[string]string{
-			// Передаем в middleware креды
-			cfg.HTTPServer.User: cfg.HTTPServer.Password,
-			// Если у вас более одного пользователя,
-			// то можете добавить остальные пары по аналогии.
-		}))
+		// Подключаем аутентификацию (по умолчанию basic auth, JWT-токен или API-ключ).
+		// Права пользователей SSO на ссылки рабочих пространств проверяют сами хендлеры
+		r.Use(authGroup("url"))
+
+		// Для API-ключей дополнительно проверяется область действия ключа
+		read := r.With(auth.RequireScope(auth.ScopeLinksRead))
+		write := r.With(auth.RequireScope(auth.ScopeLinksWrite))
+
+		// Создание ссылок можно безопасно повторять с заголовком Idempotency-Key
+		idempotent := write.With(idempotency.New(log, storage, cfg.HTTPServer.IdempotencyWindow, cfg.HTTPServer.IdempotencyStaleAfter))
 
 		//	r.Post("/", save.New(log, storage))
-		r.Post("/", save.New(log, storage))
+		idempotent.Post("/", save.New(log, storage, aliasPolicy, previews, screener))
+		read.Get("/", list.New(log, storage))                       // список ссылок, ?state=active|scheduled|ended|deleted
+		write.Patch("/{alias}", update.New(log, storage, screener)) // изменение ссылки (URL, окно активности)
+
+		// Правила умного редиректа (платформа, язык, страна)
+		read.Get("/{alias}/rules", rules.NewList(log, storage))
+		write.Put("/{alias}/rules", rules.NewReplace(log, storage, screener))
+		write.Delete("/{alias}/rules", rules.NewClear(log, storage))
+
+		// Выгрузка и загрузка ссылок файлом (csv, ndjson; загрузка - еще и выгрузка bit.ly)
+		read.Get("/export", transfer.NewExport(log, storage))
+		idempotent.Post("/import", transfer.NewImport(log, storage, aliasPolicy, screener))
+
+		// Восстановление удаленной ссылки (до очистки по сроку хранения)
+		write.Post("/{alias}/restore", restore.New(log, storage))
+
+		// Статистика переходов (в т.ч. по вариантам A/B-теста)
+		r.With(auth.RequireScope(auth.ScopeStatsRead)).Get("/{alias}/stats", stats.New(log, storage))
+	})
+
+	// Вход и регистрация через SSO (без аутентификации).
+	// Токен выпускается для приложения auth.jwt.app_id, без него вход недоступен
+	if cfg.Auth.JWT.AppID != 0 {
+		router.Route("/auth", func(r chi.Router) {
+			r.Post("/login", account.NewLogin(log, ssoClient, int32(cfg.Auth.JWT.AppID), account.Cookie{
+				Name:   cfg.Auth.Cookie.Name,
+				Domain: cfg.Auth.Cookie.Domain,
+				Secure: cfg.Auth.Cookie.Secure,
+			}))
+			r.Post("/register", account.NewRegister(log, ssoClient))
+		})
+	} else {
+		log.Warn("auth.jwt.app_id is not set, /auth endpoints are disabled")
+	}
+
+	// API-ключи для машинных клиентов (нужен JWT-токен)
+	router.Route("/keys", func(r chi.Router) {
+		r.Use(authGroup("keys"))
+
+		r.Post("/", keys.NewCreate(log, storage))
+		r.Get("/", keys.NewList(log, storage))
+		r.Delete("/{id}", keys.NewRevoke(log, storage))
+	})
+
+	// Вебхуки: события по ссылкам пользователя и его рабочих пространств
+	// (JWT-токен или API-ключ с областью webhooks:*)
+	router.Route("/webhooks", func(r chi.Router) {
+		r.Use(authGroup("webhooks"))
+
+		read := r.With(auth.RequireScope(auth.ScopeWebhooksRead))
+		write := r.With(auth.RequireScope(auth.ScopeWebhooksWrite))
+
+		write.Post("/", webhooks.NewCreate(log, storage, cfg.Webhooks.AllowPrivate))
+		read.Get("/", webhooks.NewList(log, storage))
+		write.Delete("/{id}", webhooks.NewDelete(log, storage))
+		read.Get("/{id}/deliveries", webhooks.NewDeliveries(log, storage))
+	})
+
+	// Рабочие пространства команд (JWT-токен или API-ключ с областью workspaces:*)
+	router.Route("/workspaces", func(r chi.Router) {
+		r.Use(authGroup("workspaces"))
+
+		read := r.With(auth.RequireScope(auth.ScopeWorkspacesRead))
+		write := r.With(auth.RequireScope(auth.ScopeWorkspacesWrite))
+
+		write.Post("/", workspaces.NewCreate(log, storage))
+		read.Get("/", workspaces.NewList(log, storage))
+		read.Get("/{id}/members", workspaces.NewMembers(log, storage))
+		write.Put("/{id}/members/{uid}", workspaces.NewSetMember(log, storage))
+		write.Delete("/{id}/members/{uid}", workspaces.NewRemoveMember(log, storage))
+	})
+
+	// Администрирование сервиса
+	router.Route("/admin", func(r chi.Router) {
+		// Какой бы ни была цепочка группы в конфиге, эндпоинты доступны только администраторам
+		r.Use(authGroup("admin"), auth.RequireAdmin)
+
+		// Короткие домены: регистрация, подтверждение через DNS TXT, адрес по умолчанию
+		r.Post("/domains", domains.NewRegister(log, storage))
+		r.Get("/domains", domains.NewList(log, storage))
+		r.Post("/domains/{host}/verify", domains.NewVerify(log, storage, net.DefaultResolver))
+		r.Patch("/domains/{host}", domains.NewUpdate(log, storage))
+
+		// Алиасы, зарезервированные для будущего использования
+		r.Post("/aliases", aliases.NewReserve(log, storage, aliasPolicy))
+		r.Get("/aliases", aliases.NewList(log, storage))
+		r.Delete("/aliases/{alias}", aliases.NewRelease(log, storage))
+
+		// Журнал аудита изменений ссылок, format=jsonl - выгрузка
+		r.Get("/audit", audit.New(log, storage))
+
+		// Резервные копии БД
+		r.Post("/backups", backups.NewCreate(log, snapshots))
+		r.Get("/backups", backups.NewList(log, snapshots))
+		r.Post("/backups/{name}/verify", backups.NewVerify(log, snapshots))
+
+		// Ссылки, отключенные проверкой по списку опасных адресов: очередь и решения
+		r.Get("/threats", threats.NewList(log, storage))
+		r.Post("/threats/{alias}", threats.NewReview(log, storage))
 	})
 	log.Debug("Auth info", cfg.User, cfg.Password)
 
 	// Подключаем редирект-хендлер.
 	// Здесь формируем путь для обращения и именуем его параметр — {alias}.
-	// В хендлере можно получить этот параметр по указанному имени
-	router.Get("/{alias}", redirect.New(log, storage))
+	// В хендлере можно получить этот параметр по указанному имени.
+	// Короткий домен определяется по заголовку Host
+	router.Get("/{alias}", redirect.New(log, storage, storage, storage, geo, cfg.HTTPServer.QueryPolicy))
 	// Это очень удобная и гибкая штука. Вы можете формировать и более сложные пути, например:
 	//// router.Get("/v1/{user_id}/uid", redirect.New(log, storage))
 
 	//прикручиваем ремувер
-	router.Delete("/{alias}", remove.New(log, storage))
+	router.With(
+		authGroup("url"),
+		auth.RequireScope(auth.ScopeLinksWrite),
+	).Delete("/{alias}", remove.New(log, storage))
+
+	// Алиасы не должны перекрывать маршруты сервиса
+	if err := aliasPolicy.ReserveRoutes(router); err != nil {
+		log.Error("failed to reserve route prefixes", sl.Err(err))
+		os.Exit(1)
+	}
 	//endregion
 
 	//region ЗАПУСК и ОСТАНОВКА СЕРВЕРА
@@ -196,6 +452,58 @@ func main() {
 		IdleTimeout:  cfg.HTTPServer.IdleTimeout,
 	}
 
+	// Фоновые задачи работают до остановки сервера
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+
+	// Очистка удаленных ссылок по истечении срока хранения
+	go purge.Run(jobsCtx, log, storage, cfg.Retention.DeletedLinks, cfg.Retention.PurgeInterval)
+
+	// Резервное копирование БД по расписанию
+	go backup.Run(jobsCtx, log, snapshots, cfg.Backup.Interval)
+
+	// Доставка событий на вебхуки. Очередь хранится в БД, недоставленное отправится после перезапуска
+	go deliver.New(log, storage, deliver.Options{
+		Timeout:      cfg.Webhooks.Timeout,
+		MaxAttempts:  cfg.Webhooks.MaxAttempts,
+		BackoffBase:  cfg.Webhooks.BackoffBase,
+		BackoffMax:   cfg.Webhooks.BackoffMax,
+		BatchSize:    cfg.Webhooks.BatchSize,
+		AllowPrivate: cfg.Webhooks.AllowPrivate,
+	}).Run(jobsCtx, cfg.Webhooks.PollInterval)
+
+	// Метаданные страниц перехода для новых ссылок и ссылок со смененным адресом
+	if previewWorker != nil {
+		go previewWorker.Run(jobsCtx, cfg.Preview.PollInterval)
+	}
+
+	// Проверка доступности страниц перехода
+	go healthcheck.New(log, storage, healthcheck.NewProber(healthcheck.ProberOptions{
+		Timeout:      cfg.HealthCheck.Timeout,
+		AllowPrivate: cfg.HealthCheck.AllowPrivate,
+		UserAgent:    "url-shortener-healthcheck/1.0",
+	}), healthcheck.Options{
+		Interval:         cfg.HealthCheck.Interval,
+		Concurrency:      cfg.HealthCheck.Concurrency,
+		HostDelay:        cfg.HealthCheck.HostDelay,
+		FailureThreshold: cfg.HealthCheck.FailureThreshold,
+		BatchSize:        cfg.HealthCheck.BatchSize,
+	}).Run(jobsCtx, cfg.HealthCheck.PollInterval)
+
+	// Проверка всех ссылок по списку опасных адресов (список перечитывается при изменении файла)
+	if threatFile != nil {
+		go screen.Run(jobsCtx, log, storage, threatFile, cfg.Threats.ScanInterval, cfg.Threats.BatchSize)
+	}
+
+	// Публикация событий из outbox. Неопубликованное отправится после перезапуска
+	if eventPublisher != nil {
+		go relay.Run(jobsCtx, log, storage, eventPublisher, relay.Options{
+			Interval:  cfg.Outbox.PollInterval,
+			BatchSize: cfg.Outbox.BatchSize,
+			Retention: cfg.Outbox.Retention,
+		})
+	}
+
 	go func() {
 		if err := srv.ListenAndServe(); err != nil {
 			log.Error("failed to start server")
@@ -208,6 +516,8 @@ func main() {
 	<-done
 	log.Info("stopping server")
 
+	stopJobs()
+
 	// TODO: move timeout to config
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -227,6 +537,161 @@ func main() {
 	//endregion
 }
 
+// defaultAuthGroups - цепочки аутентификации групп маршрутов, не заданных в конфиге
+var defaultAuthGroups = map[string][]string{
+	"url":        {"basic", "jwt", "api_key"},
+	"workspaces": {"jwt", "api_key"},
+	"keys":       {"jwt"},
+	"webhooks":   {"jwt", "api_key"},
+	"admin":      {"basic"},
+}
+
+// setupAuthChains собирает цепочки аутентификации для групп маршрутов из конфига.
+// Права администратора владельцев JWT-токенов запрашиваются у SSO (admins)
+func setupAuthChains(cfg *config.Config, keys auth.APIKeyVerifier, admins auth.AdminChecker) (map[string][]auth.Authenticator, error) {
+	verifier, err := setupJWTVerifier(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make(map[string][]string, len(defaultAuthGroups))
+	for group, names := range defaultAuthGroups {
+		groups[group] = names
+	}
+	for group, names := range cfg.Auth.Groups {
+		if _, ok := defaultAuthGroups[group]; !ok {
+			return nil, fmt.Errorf("unknown auth group %q", group)
+		}
+		groups[group] = names
+	}
+
+	available := map[string]auth.Authenticator{
+		"jwt":       auth.NewJWT(verifier).WithCookie(cfg.Auth.Cookie.Name).WithAdminChecker(admins),
+		"api_key":   auth.NewAPIKey(keys),
+		"anonymous": auth.Anonymous{},
+	}
+
+	// Basic auth нужен, только если он есть в какой-нибудь цепочке: без пользователей его не создать
+	for _, names := range groups {
+		if slices.Contains(names, "basic") {
+			basic, err := auth.NewBasic(cfg.Auth.HtpasswdPath, map[string]string{
+				// пользователь из старого формата конфига
+				cfg.HTTPServer.User: cfg.HTTPServer.Password,
+			})
+			if err != nil {
+				return nil, err
+			}
+			available["basic"] = basic
+
+			break
+		}
+	}
+
+	chains := make(map[string][]auth.Authenticator, len(groups))
+	for group, names := range groups {
+		chain, err := auth.Build(names, available)
+		if err != nil {
+			return nil, fmt.Errorf("auth group %q: %w", group, err)
+		}
+		chains[group] = chain
+	}
+
+	return chains, nil
+}
+
+// setupAliasPolicy создает политику алиасов по конфигу
+func setupAliasPolicy(cfg config.Aliases) (*aliaspolicy.Policy, error) {
+	if cfg.MinLength > cfg.MaxLength {
+		return nil, fmt.Errorf("aliases.min_length %d is greater than max_length %d", cfg.MinLength, cfg.MaxLength)
+	}
+
+	policy := aliaspolicy.New(cfg.MinLength, cfg.MaxLength)
+	policy.Reserve(cfg.Reserved...)
+
+	if cfg.ProfanityPath != "" {
+		words, err := aliaspolicy.LoadWords(cfg.ProfanityPath)
+		if err != nil {
+			return nil, err
+		}
+		policy.SetProfanity(words)
+	}
+
+	return policy, nil
+}
+
+// setupOutbox создает публикатор событий и список публикуемых типов событий по конфигу.
+// Публикатор nil - outbox выключен
+func setupOutbox(cfg config.Outbox) (publisher.EventPublisher, []string, error) {
+	if cfg.Publisher == "" || cfg.Publisher == publisher.KindNone {
+		return nil, nil, nil
+	}
+
+	if cfg.PollInterval <= 0 {
+		return nil, nil, fmt.Errorf("outbox.poll_interval must be positive")
+	}
+
+	events := cfg.Events
+	if len(events) == 0 {
+		events = storage.EventTypes
+	}
+	for _, e := range events {
+		if !slices.Contains(storage.EventTypes, e) {
+			return nil, nil, fmt.Errorf("outbox.events: unknown event type %q", e)
+		}
+	}
+
+	pub, err := publisher.Open(cfg.Publisher, cfg.Path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return pub, events, nil
+}
+
+// setupSSOOptions переводит настройки клиента SSO из конфига в опции ssogrpc.New
+func setupSSOOptions(cfg config.Client) ([]ssogrpc.Option, error) {
+	opts := []ssogrpc.Option{
+		ssogrpc.WithKeepalive(cfg.Keepalive, cfg.KeepaliveTimeout),
+		ssogrpc.WithCircuitBreaker(cfg.Breaker.Threshold, cfg.Breaker.Cooldown),
+	}
+
+	if cfg.TLS.Enabled {
+		tlsCfg, err := ssogrpc.LoadTLSConfig(cfg.TLS.CAFile, cfg.TLS.CertFile, cfg.TLS.KeyFile, cfg.TLS.ServerName)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, ssogrpc.WithTLS(tlsCfg))
+	}
+
+	for call, mode := range cfg.Degraded {
+		opts = append(opts, ssogrpc.WithFailMode(call, ssogrpc.FailMode(mode)))
+	}
+
+	return opts, nil
+}
+
+// setupJWTVerifier создает проверку JWT-токенов SSO по секции auth.jwt конфига
+func setupJWTVerifier(cfg *config.Config) (*jwt.Verifier, error) {
+	opts := jwt.Options{
+		Algorithms: cfg.Auth.JWT.Algorithms,
+		Secret:     cfg.AppSecret,
+		Issuer:     cfg.Auth.JWT.Issuer,
+		Audience:   cfg.Auth.JWT.Audience,
+		AppID:      cfg.Auth.JWT.AppID,
+		Leeway:     cfg.Auth.JWT.Leeway,
+	}
+
+	if cfg.Auth.JWT.JWKSPath != "" {
+		keys, err := jwt.LoadJWKS(cfg.Auth.JWT.JWKSPath)
+		if err != nil {
+			return nil, err
+		}
+		opts.Keys = keys
+	}
+
+	return jwt.NewVerifier(opts)
+}
+
 // setupLogger создает логгер в зависимости от окружения с разными параметрами — TextHandler / JSONHandler и уровень LevelDebug / LevelInfo
 func setupLogger(env string) *slog.Logger {
 	var log *slog.Logger
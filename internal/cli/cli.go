@@ -0,0 +1,212 @@
+// internal/cli/cli.go
+
+// Package cli - команды администрирования, которые работают напрямую с хранилищем сервиса,
+// без HTTP-запросов: управление ссылками и API-ключами, статистика, обслуживание БД.
+// Вывод - таблицей (по умолчанию) или JSON (флаг -o json)
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"url-shortener/internal/lib/aliaspolicy"
+	"url-shortener/internal/storage"
+)
+
+// ErrUsage - команда вызвана с неверными аргументами
+var ErrUsage = errors.New("invalid usage")
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// Usage - справка по командам
+const Usage = `Usage: url-shortener [--config path] <command> [flags]
+
+Commands:
+  serve                                     run the HTTP server (default)
+  link create --url URL [--alias A] [--domain D] [--title T] [--workspace ID]
+  link get <alias> [--domain D]
+  link delete <alias> [--domain D]
+  link list [--state S] [--domain D] [--workspace ID]
+  user-keys list <uid>
+  user-keys revoke <uid> <key-id>
+  stats <alias> [--domain D]
+  db vacuum
+  db backup <path>
+  db check
+  db restore [--skip-checksum] <snapshot>  replace the database with a snapshot (stop the server first)
+
+Every command except serve and db restore accepts -o table|json.
+`
+
+// Storage is an interface for storage operations available from the command line.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=Storage
+type Storage interface {
+	SaveLink(link storage.Link, actor storage.Actor) (int64, error)
+	GetLink(domain string, alias string) (storage.Link, error)
+	DeleteURL(domain string, alias string, actor storage.Actor) error
+	ListLinks(filter storage.ListFilter) ([]storage.Link, error)
+	ListAPIKeys(uid int64) ([]storage.APIKey, error)
+	RevokeAPIKey(uid int64, id int64, at time.Time) error
+	Vacuum() error
+	BackupTo(ctx context.Context, path string) error
+	Check() ([]string, error)
+}
+
+// CLI выполняет команды администрирования
+type CLI struct {
+	store  Storage
+	policy *aliaspolicy.Policy
+	out    io.Writer
+	// от чьего имени изменения записываются в журнал аудита
+	actor storage.Actor
+}
+
+// New создает CLI. Изменения ссылок записываются в журнал аудита от имени operator
+func New(store Storage, policy *aliaspolicy.Policy, out io.Writer, operator string) *CLI {
+	return &CLI{
+		store:  store,
+		policy: policy,
+		out:    out,
+		actor:  storage.Actor{Name: "cli:" + operator},
+	}
+}
+
+// Run выполняет команду args (без имени программы).
+// Ошибка ErrUsage означает неверные аргументы, текст ошибки поясняет, что не так
+func (c *CLI) Run(args []string) error {
+	if len(args) == 0 {
+		return usageError("command is required")
+	}
+
+	switch args[0] {
+	case "link":
+		return c.runGroup(args[1:], "link", map[string]func([]string) error{
+			"create": c.linkCreate,
+			"get":    c.linkGet,
+			"delete": c.linkDelete,
+			"list":   c.linkList,
+		})
+	case "user-keys":
+		return c.runGroup(args[1:], "user-keys", map[string]func([]string) error{
+			"list":   c.keysList,
+			"revoke": c.keysRevoke,
+		})
+	case "stats":
+		return c.stats(args[1:])
+	case "db":
+		return c.runGroup(args[1:], "db", map[string]func([]string) error{
+			"vacuum": c.dbVacuum,
+			"backup": c.dbBackup,
+			"check":  c.dbCheck,
+		})
+	default:
+		return usageError("unknown command %q", args[0])
+	}
+}
+
+func (c *CLI) runGroup(args []string, group string, commands map[string]func([]string) error) error {
+	if len(args) == 0 {
+		return usageError("%s: subcommand is required", group)
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		return usageError("%s: unknown subcommand %q", group, args[0])
+	}
+
+	return cmd(args[1:])
+}
+
+func usageError(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrUsage, fmt.Sprintf(format, args...))
+}
+
+// command - разбор флагов одной команды
+type command struct {
+	name   string
+	fs     *flag.FlagSet
+	output string
+}
+
+func newCommand(name string) *command {
+	cmd := &command{
+		name: name,
+		fs:   flag.NewFlagSet(name, flag.ContinueOnError),
+	}
+	cmd.fs.SetOutput(io.Discard)
+	cmd.fs.StringVar(&cmd.output, "o", outputTable, "output format: table or json")
+
+	return cmd
+}
+
+// parse разбирает флаги и возвращает ровно want позиционных аргументов.
+// Флаги можно указывать и до, и после позиционных аргументов
+func (cmd *command) parse(args []string, want int) ([]string, error) {
+	var positional []string
+
+	for {
+		if err := cmd.fs.Parse(args); err != nil {
+			return nil, usageError("%s: %v", cmd.name, err)
+		}
+
+		args = cmd.fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+
+	if cmd.output != outputTable && cmd.output != outputJSON {
+		return nil, usageError("%s: unknown output format %q", cmd.name, cmd.output)
+	}
+	if len(positional) != want {
+		return nil, usageError("%s: expected %d argument(s), got %d", cmd.name, want, len(positional))
+	}
+
+	return positional, nil
+}
+
+// print выводит v в JSON или вызывает table для вывода таблицей
+func (c *CLI) print(cmd *command, v any, table func(w io.Writer)) error {
+	if cmd.output == outputJSON {
+		enc := json.NewEncoder(c.out)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(v)
+	}
+
+	tw := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	table(tw)
+
+	return tw.Flush()
+}
+
+// row выводит ячейки таблицы через табуляцию
+func row(w io.Writer, cells ...any) {
+	s := make([]string, len(cells))
+	for i, cell := range cells {
+		s[i] = fmt.Sprint(cell)
+	}
+
+	fmt.Fprintln(w, strings.Join(s, "\t"))
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+
+	return t.Format(time.RFC3339)
+}
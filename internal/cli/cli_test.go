@@ -0,0 +1,107 @@
+package cli_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/cli"
+	"url-shortener/internal/cli/mocks"
+	"url-shortener/internal/lib/aliaspolicy"
+	"url-shortener/internal/storage"
+)
+
+func TestRun_Usage(t *testing.T) {
+	cases := []struct {
+		name string
+		args []string
+	}{
+		{name: "No command", args: nil},
+		{name: "Unknown command", args: []string{"frob"}},
+		{name: "Unknown subcommand", args: []string{"link", "frob"}},
+		{name: "Missing alias", args: []string{"link", "get"}},
+		{name: "Extra argument", args: []string{"db", "vacuum", "now"}},
+		{name: "Missing url", args: []string{"link", "create", "--alias", "abc"}},
+		{name: "Invalid uid", args: []string{"user-keys", "list", "me"}},
+		{name: "Unknown output", args: []string{"db", "check", "-o", "xml"}},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := cli.New(mocks.NewStorage(t), aliaspolicy.New(0, 0), &bytes.Buffer{}, "tester")
+			require.ErrorIs(t, c.Run(tc.args), cli.ErrUsage)
+		})
+	}
+}
+
+func TestRun_LinkCreate(t *testing.T) {
+	storageMock := mocks.NewStorage(t)
+	storageMock.On("SaveLink", mock.MatchedBy(func(l storage.Link) bool {
+		return l.Domain == "go.example.com" && l.Alias == "promo" && l.URL == "https://example.com"
+	}), storage.Actor{Name: "cli:tester"}).Return(int64(7), nil).Once()
+
+	var out bytes.Buffer
+	c := cli.New(storageMock, aliaspolicy.New(0, 0), &out, "tester")
+
+	// флаги можно указывать после позиционных аргументов и в любом порядке
+	err := c.Run([]string{"link", "create", "--url", "https://example.com", "-o", "json", "--alias", "promo", "--domain", "Go.Example.com"})
+	require.NoError(t, err)
+
+	var link storage.Link
+	require.NoError(t, json.Unmarshal(out.Bytes(), &link))
+	require.Equal(t, int64(7), link.ID)
+	require.Equal(t, "promo", link.Alias)
+}
+
+func TestRun_LinkCreate_PolicyRejected(t *testing.T) {
+	policy := aliaspolicy.New(0, 0)
+	policy.Reserve("admin")
+
+	c := cli.New(mocks.NewStorage(t), policy, &bytes.Buffer{}, "tester")
+
+	err := c.Run([]string{"link", "create", "--url", "https://example.com", "--alias", "admin"})
+	require.ErrorIs(t, err, aliaspolicy.ErrReserved)
+}
+
+func TestRun_LinkGetTable(t *testing.T) {
+	storageMock := mocks.NewStorage(t)
+	storageMock.On("GetLink", "", "abc").
+		Return(storage.Link{ID: 1, Alias: "abc", URL: "https://example.com", Clicks: 5}, nil).Once()
+
+	var out bytes.Buffer
+	c := cli.New(storageMock, aliaspolicy.New(0, 0), &out, "tester")
+
+	require.NoError(t, c.Run([]string{"link", "get", "abc"}))
+	require.Contains(t, out.String(), "url         https://example.com\n")
+	require.Contains(t, out.String(), "clicks      5\n")
+}
+
+func TestRun_LinkDeleteNotFound(t *testing.T) {
+	storageMock := mocks.NewStorage(t)
+	storageMock.On("DeleteURL", "", "abc", mock.Anything).Return(storage.ErrURLNotFound).Once()
+
+	c := cli.New(storageMock, aliaspolicy.New(0, 0), &bytes.Buffer{}, "tester")
+
+	err := c.Run([]string{"link", "delete", "abc"})
+	require.ErrorIs(t, err, storage.ErrURLNotFound)
+	require.False(t, errors.Is(err, cli.ErrUsage))
+}
+
+func TestRun_DBCheck(t *testing.T) {
+	storageMock := mocks.NewStorage(t)
+	storageMock.On("Check").Return([]string{"url_rule row 3 references missing url"}, nil).Once()
+
+	var out bytes.Buffer
+	c := cli.New(storageMock, aliaspolicy.New(0, 0), &out, "tester")
+
+	require.ErrorIs(t, c.Run([]string{"db", "check", "-o", "json"}), cli.ErrCheckFailed)
+	require.JSONEq(t, `{"ok":false,"problems":["url_rule row 3 references missing url"]}`, out.String())
+}
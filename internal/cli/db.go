@@ -0,0 +1,81 @@
+// internal/cli/db.go
+package cli
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+func (c *CLI) dbVacuum(args []string) error {
+	cmd := newCommand("db vacuum")
+	if _, err := cmd.parse(args, 0); err != nil {
+		return err
+	}
+
+	if err := c.store.Vacuum(); err != nil {
+		return err
+	}
+
+	return c.print(cmd, map[string]string{"status": "ok"}, func(w io.Writer) {
+		row(w, "vacuum", "ok")
+	})
+}
+
+func (c *CLI) dbBackup(args []string) error {
+	cmd := newCommand("db backup")
+
+	pos, err := cmd.parse(args, 1)
+	if err != nil {
+		return err
+	}
+
+	if err := c.store.BackupTo(context.Background(), pos[0]); err != nil {
+		return err
+	}
+
+	return c.print(cmd, map[string]string{"backup": pos[0]}, func(w io.Writer) {
+		row(w, "backup", pos[0])
+	})
+}
+
+// ErrCheckFailed - проверка БД нашла проблемы (они выведены в результате команды)
+var ErrCheckFailed = errors.New("database check failed")
+
+func (c *CLI) dbCheck(args []string) error {
+	cmd := newCommand("db check")
+	if _, err := cmd.parse(args, 0); err != nil {
+		return err
+	}
+
+	problems, err := c.store.Check()
+	if err != nil {
+		return err
+	}
+
+	res := struct {
+		OK       bool     `json:"ok"`
+		Problems []string `json:"problems"`
+	}{OK: len(problems) == 0, Problems: problems}
+	if res.Problems == nil {
+		res.Problems = []string{}
+	}
+
+	err = c.print(cmd, res, func(w io.Writer) {
+		if res.OK {
+			row(w, "check", "ok")
+		}
+		for _, p := range problems {
+			row(w, "problem", p)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	if !res.OK {
+		return ErrCheckFailed
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+// internal/cli/keys.go
+package cli
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"url-shortener/internal/storage"
+)
+
+func (c *CLI) keysList(args []string) error {
+	cmd := newCommand("user-keys list")
+
+	pos, err := cmd.parse(args, 1)
+	if err != nil {
+		return err
+	}
+
+	uid, err := parseID(cmd, "uid", pos[0])
+	if err != nil {
+		return err
+	}
+
+	keys, err := c.store.ListAPIKeys(uid)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	return c.print(cmd, keys, func(w io.Writer) {
+		row(w, "ID", "NAME", "PREFIX", "SCOPES", "STATUS", "CREATED", "LAST USED", "EXPIRES")
+		for _, key := range keys {
+			row(w, key.ID, orDash(key.Name), key.Prefix, strings.Join(key.Scopes, ","), keyStatus(key, now),
+				key.CreatedAt.Format(time.RFC3339), formatTime(key.LastUsedAt), formatTime(key.ExpiresAt))
+		}
+	})
+}
+
+func (c *CLI) keysRevoke(args []string) error {
+	cmd := newCommand("user-keys revoke")
+
+	pos, err := cmd.parse(args, 2)
+	if err != nil {
+		return err
+	}
+
+	uid, err := parseID(cmd, "uid", pos[0])
+	if err != nil {
+		return err
+	}
+	id, err := parseID(cmd, "key-id", pos[1])
+	if err != nil {
+		return err
+	}
+
+	if err := c.store.RevokeAPIKey(uid, id, time.Now()); err != nil {
+		return err
+	}
+
+	return c.print(cmd, map[string]int64{"revoked": id}, func(w io.Writer) {
+		row(w, "revoked", id)
+	})
+}
+
+func keyStatus(key storage.APIKey, now time.Time) string {
+	switch {
+	case key.RevokedAt != nil:
+		return "revoked"
+	case !key.ActiveAt(now):
+		return "expired"
+	default:
+		return "active"
+	}
+}
+
+func parseID(cmd *command, name, v string) (int64, error) {
+	id, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, usageError("%s: invalid %s %q", cmd.name, name, v)
+	}
+
+	return id, nil
+}
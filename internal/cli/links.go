@@ -0,0 +1,200 @@
+// internal/cli/links.go
+package cli
+
+import (
+	"errors"
+	"io"
+	"time"
+
+	"url-shortener/internal/lib/hostname"
+	"url-shortener/internal/lib/random"
+	"url-shortener/internal/storage"
+)
+
+// Длина случайного алиаса, как у ссылок, созданных через API
+const aliasLength = 6
+
+// Сколько раз генерировать случайный алиас, если он не прошел политику алиасов
+const aliasAttempts = 10
+
+func (c *CLI) linkCreate(args []string) error {
+	cmd := newCommand("link create")
+	url := cmd.fs.String("url", "", "target URL (required)")
+	alias := cmd.fs.String("alias", "", "alias, random if empty")
+	domain := cmd.fs.String("domain", "", "short domain")
+	title := cmd.fs.String("title", "", "link title")
+	workspace := cmd.fs.Int64("workspace", 0, "workspace ID, 0 - shared link")
+
+	if _, err := cmd.parse(args, 0); err != nil {
+		return err
+	}
+	if *url == "" {
+		return usageError("link create: --url is required")
+	}
+
+	link := storage.Link{
+		Domain:      hostname.Normalize(*domain),
+		WorkspaceID: *workspace,
+		Alias:       *alias,
+		URL:         *url,
+		Title:       *title,
+	}
+
+	if link.Alias == "" {
+		for i := 0; i < aliasAttempts && link.Alias == ""; i++ {
+			if a := random.NewRandomString(aliasLength); c.policy.Check(a) == nil {
+				link.Alias = a
+			}
+		}
+		if link.Alias == "" {
+			return errors.New("no acceptable alias generated")
+		}
+	} else if err := c.policy.Check(link.Alias); err != nil {
+		return err
+	}
+
+	id, err := c.store.SaveLink(link, c.actor)
+	if err != nil {
+		return err
+	}
+	link.ID = id
+
+	return c.printLink(cmd, link)
+}
+
+func (c *CLI) linkGet(args []string) error {
+	cmd := newCommand("link get")
+	domain := cmd.fs.String("domain", "", "short domain")
+
+	pos, err := cmd.parse(args, 1)
+	if err != nil {
+		return err
+	}
+
+	link, err := c.store.GetLink(hostname.Normalize(*domain), pos[0])
+	if err != nil {
+		return err
+	}
+
+	return c.printLink(cmd, link)
+}
+
+func (c *CLI) linkDelete(args []string) error {
+	cmd := newCommand("link delete")
+	domain := cmd.fs.String("domain", "", "short domain")
+
+	pos, err := cmd.parse(args, 1)
+	if err != nil {
+		return err
+	}
+
+	if err := c.store.DeleteURL(hostname.Normalize(*domain), pos[0], c.actor); err != nil {
+		return err
+	}
+
+	return c.print(cmd, map[string]string{"deleted": pos[0]}, func(w io.Writer) {
+		row(w, "deleted", pos[0])
+	})
+}
+
+func (c *CLI) linkList(args []string) error {
+	cmd := newCommand("link list")
+	state := cmd.fs.String("state", "", "active, scheduled, ended or deleted")
+	domain := cmd.fs.String("domain", "", "short domain")
+	workspace := cmd.fs.Int64("workspace", 0, "workspace ID")
+
+	if _, err := cmd.parse(args, 0); err != nil {
+		return err
+	}
+
+	links, err := c.store.ListLinks(storage.ListFilter{
+		State:       *state,
+		Domain:      hostname.Normalize(*domain),
+		WorkspaceID: *workspace,
+	})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	return c.print(cmd, links, func(w io.Writer) {
+		row(w, "ID", "DOMAIN", "ALIAS", "STATE", "CLICKS", "WORKSPACE", "URL")
+		for _, link := range links {
+			row(w, link.ID, orDash(link.Domain), link.Alias, linkState(link, now), link.Clicks, link.WorkspaceID, link.URL)
+		}
+	})
+}
+
+func (c *CLI) stats(args []string) error {
+	cmd := newCommand("stats")
+	domain := cmd.fs.String("domain", "", "short domain")
+
+	pos, err := cmd.parse(args, 1)
+	if err != nil {
+		return err
+	}
+
+	link, err := c.store.GetLink(hostname.Normalize(*domain), pos[0])
+	if err != nil {
+		return err
+	}
+
+	type targetStats struct {
+		URL    string `json:"url"`
+		Weight int    `json:"weight"`
+		Clicks int64  `json:"clicks"`
+	}
+	res := struct {
+		Domain  string        `json:"domain,omitempty"`
+		Alias   string        `json:"alias"`
+		Clicks  int64         `json:"clicks"`
+		Targets []targetStats `json:"targets,omitempty"`
+	}{Domain: link.Domain, Alias: link.Alias, Clicks: link.Clicks}
+
+	for _, t := range link.Targets {
+		res.Targets = append(res.Targets, targetStats{URL: t.URL, Weight: t.Weight, Clicks: t.Clicks})
+	}
+
+	return c.print(cmd, res, func(w io.Writer) {
+		row(w, "TARGET", "WEIGHT", "CLICKS")
+		row(w, "(total)", "-", res.Clicks)
+		for _, t := range res.Targets {
+			row(w, t.URL, t.Weight, t.Clicks)
+		}
+	})
+}
+
+func (c *CLI) printLink(cmd *command, link storage.Link) error {
+	return c.print(cmd, link, func(w io.Writer) {
+		row(w, "id", link.ID)
+		row(w, "domain", orDash(link.Domain))
+		row(w, "alias", link.Alias)
+		row(w, "url", link.URL)
+		row(w, "title", orDash(link.Title))
+		row(w, "state", linkState(link, time.Now()))
+		row(w, "workspace", link.WorkspaceID)
+		row(w, "owner_uid", link.OwnerUID)
+		row(w, "not_before", formatTime(link.NotBefore))
+		row(w, "not_after", formatTime(link.NotAfter))
+		row(w, "clicks", link.Clicks)
+		row(w, "rules", len(link.Rules))
+		row(w, "targets", len(link.Targets))
+	})
+}
+
+func linkState(link storage.Link, now time.Time) string {
+	if link.DeletedAt != nil {
+		return storage.StateDeleted
+	}
+
+	return link.StateAt(now)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+
+	return s
+}
@@ -0,0 +1,214 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	storage "url-shortener/internal/storage"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// Storage is an autogenerated mock type for the Storage type
+type Storage struct {
+	mock.Mock
+}
+
+// BackupTo provides a mock function with given fields: ctx, path
+func (_m *Storage) BackupTo(ctx context.Context, path string) error {
+	ret := _m.Called(ctx, path)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, path)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Check provides a mock function with given fields:
+func (_m *Storage) Check() ([]string, error) {
+	ret := _m.Called()
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]string, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []string); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteURL provides a mock function with given fields: domain, alias, actor
+func (_m *Storage) DeleteURL(domain string, alias string, actor storage.Actor) error {
+	ret := _m.Called(domain, alias, actor)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, storage.Actor) error); ok {
+		r0 = rf(domain, alias, actor)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetLink provides a mock function with given fields: domain, alias
+func (_m *Storage) GetLink(domain string, alias string) (storage.Link, error) {
+	ret := _m.Called(domain, alias)
+
+	var r0 storage.Link
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (storage.Link, error)); ok {
+		return rf(domain, alias)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) storage.Link); ok {
+		r0 = rf(domain, alias)
+	} else {
+		r0 = ret.Get(0).(storage.Link)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(domain, alias)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListAPIKeys provides a mock function with given fields: uid
+func (_m *Storage) ListAPIKeys(uid int64) ([]storage.APIKey, error) {
+	ret := _m.Called(uid)
+
+	var r0 []storage.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64) ([]storage.APIKey, error)); ok {
+		return rf(uid)
+	}
+	if rf, ok := ret.Get(0).(func(int64) []storage.APIKey); ok {
+		r0 = rf(uid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]storage.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(uid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListLinks provides a mock function with given fields: filter
+func (_m *Storage) ListLinks(filter storage.ListFilter) ([]storage.Link, error) {
+	ret := _m.Called(filter)
+
+	var r0 []storage.Link
+	var r1 error
+	if rf, ok := ret.Get(0).(func(storage.ListFilter) ([]storage.Link, error)); ok {
+		return rf(filter)
+	}
+	if rf, ok := ret.Get(0).(func(storage.ListFilter) []storage.Link); ok {
+		r0 = rf(filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]storage.Link)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(storage.ListFilter) error); ok {
+		r1 = rf(filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RevokeAPIKey provides a mock function with given fields: uid, id, at
+func (_m *Storage) RevokeAPIKey(uid int64, id int64, at time.Time) error {
+	ret := _m.Called(uid, id, at)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64, int64, time.Time) error); ok {
+		r0 = rf(uid, id, at)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveLink provides a mock function with given fields: link, actor
+func (_m *Storage) SaveLink(link storage.Link, actor storage.Actor) (int64, error) {
+	ret := _m.Called(link, actor)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(storage.Link, storage.Actor) (int64, error)); ok {
+		return rf(link, actor)
+	}
+	if rf, ok := ret.Get(0).(func(storage.Link, storage.Actor) int64); ok {
+		r0 = rf(link, actor)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(storage.Link, storage.Actor) error); ok {
+		r1 = rf(link, actor)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Vacuum provides a mock function with given fields:
+func (_m *Storage) Vacuum() error {
+	ret := _m.Called()
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewStorage interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewStorage creates a new instance of Storage. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewStorage(t mockConstructorTestingTNewStorage) *Storage {
+	mock := &Storage{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -2,34 +2,72 @@ package grpc
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"time"
 
 	ssov1 "github.com/Alexxtn105/protos/gen/go/sso"
+	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors"
 	grpclog "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/logging"
 	grpcretry "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/retry"
+	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/selector"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"url-shortener/internal/lib/breaker"
+	"url-shortener/internal/lib/logger/sl"
 )
 
+// ErrUnavailable - SSO недоступен: автомат размыкается после серии сбоев,
+// и вызовы сразу получают эту ошибку, не дожидаясь таймаута.
+// Это ошибка gRPC с кодом Unavailable, поэтому status.Code для нее работает как для ответа сервера
+var ErrUnavailable = status.Error(codes.Unavailable, "sso is unavailable: circuit breaker is open")
+
 type Client struct {
 	api ssov1.AuthClient
 	log *slog.Logger
+
+	cc       *grpc.ClientConn
+	breaker  *breaker.Breaker
+	degraded map[string]FailMode
+	stop     context.CancelFunc // останавливает наблюдение за соединением
 }
 
-// New конструктор клиента для SSO/Auth
+// New конструктор клиента для SSO/Auth.
+// По умолчанию соединение без TLS, без keepalive и с автоматом, который не размыкается (см. Option)
 func New(
 	ctx context.Context, //
 	log *slog.Logger,
 	addr string,
 	timeout time.Duration,
 	retriesCount int,
+	opts ...Option,
 ) (*Client, error) {
 	const op = "grpc.New"
 
+	o := options{creds: insecure.NewCredentials(), degraded: map[string]FailMode{}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	// Fail-open возможен только там, где у ответа есть безопасное значение по умолчанию
+	for call, mode := range o.degraded {
+		if call != CallIsAdmin && call != CallLogin && call != CallRegister {
+			return nil, fmt.Errorf("%s: unknown call %q in degraded mode policy", op, call)
+		}
+		if mode != FailOpen && mode != FailClosed {
+			return nil, fmt.Errorf("%s: unknown degraded mode %q for %s", op, mode, call)
+		}
+		if mode == FailOpen && call != CallIsAdmin {
+			return nil, fmt.Errorf("%s: %s can only fail closed", op, call)
+		}
+	}
+
 	// опции для ретраев
 	retryOpts := []grpcretry.CallOption{
 		grpcretry.WithCodes(codes.NotFound, codes.Aborted, codes.DeadlineExceeded), // указываем, какие коды нужно ретраить
@@ -41,17 +79,34 @@ func New(
 	logOpts := []grpclog.Option{
 		grpclog.WithLogOnEvents(grpclog.PayloadReceived, grpclog.PayloadSent),
 	}
+	// В запросах и ответах Login и Register - пароль и JWT, у них логируется только итог вызова
+	secretLogOpts := []grpclog.Option{
+		grpclog.WithLogOnEvents(grpclog.FinishCall),
+	}
 
-	// Создаём соединение с gRPC-сервером SSO для клиента
-	// по-хорошему здесь нужно создавать защищенное соединение, здесь будет insecure
-	cc, err := grpc.DialContext(ctx, addr,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	dialOpts := []grpc.DialOption{
+		// TLS (или mTLS) включается опцией WithTLS, иначе соединение insecure
+		grpc.WithTransportCredentials(o.creds),
 		grpc.WithChainUnaryInterceptor( //обертка для двух следующих интерсепторов (создаем цепочку интерцепторов, чтобы все интерцепторы вызывались по очереди)
-			// этот интерцептор будет тело каждого запроса и ответа,
-			grpclog.UnaryClientInterceptor(InterceptorLogger(log), logOpts...),
+			// этот интерцептор будет логировать тело каждого запроса и ответа, кроме вызовов с секретами,
+			selector.UnaryClientInterceptor(
+				grpclog.UnaryClientInterceptor(InterceptorLogger(log), logOpts...),
+				selector.MatchFunc(func(_ context.Context, c interceptors.CallMeta) bool { return !hasSecrets(c) }),
+			),
+			selector.UnaryClientInterceptor(
+				grpclog.UnaryClientInterceptor(InterceptorLogger(log), secretLogOpts...),
+				selector.MatchFunc(func(_ context.Context, c interceptors.CallMeta) bool { return hasSecrets(c) }),
+			),
 			// этот интерцептор будет делать ретраи в случае неудачных запросов
 			grpcretry.UnaryClientInterceptor(retryOpts...),
-		))
+		),
+	}
+	if o.keepalive != nil {
+		dialOpts = append(dialOpts, grpc.WithKeepaliveParams(*o.keepalive))
+	}
+
+	// Создаём соединение с gRPC-сервером SSO для клиента
+	cc, err := grpc.DialContext(ctx, addr, dialOpts...)
 
 	if err != nil {
 
@@ -65,12 +120,92 @@ func New(
 	// на отдельные сервисы: Auth и в будущем Permissions, UserInfo и т.п.
 	grpcClient := ssov1.NewAuthClient(cc)
 
-	return &Client{
-		api: grpcClient,
-	}, nil
+	c := &Client{
+		api:      grpcClient,
+		log:      log.With(slog.String("component", "sso_client"), slog.String("address", addr)),
+		cc:       cc,
+		breaker:  breaker.New(o.breakerThreshold, o.breakerCooldown),
+		degraded: o.degraded,
+	}
+
+	c.breaker.OnStateChange(func(from, to breaker.State) {
+		c.log.Warn("sso circuit breaker state changed", slog.String("from", from.String()), slog.String("to", to.String()))
+	})
+
+	// Соединение устанавливается в фоне; следим за его состоянием, чтобы сбои SSO были видны в логах
+	watchCtx, stop := context.WithCancel(context.Background())
+	c.stop = stop
+	cc.Connect()
+	go c.watchState(watchCtx)
+
+	return c, nil
 
 }
 
+// Close закрывает соединение с SSO
+func (c *Client) Close() error {
+	c.stop()
+	return c.cc.Close()
+}
+
+// State - текущее состояние соединения с SSO
+func (c *Client) State() connectivity.State {
+	return c.cc.GetState()
+}
+
+// Ready сообщает, установлено ли соединение с SSO и замкнут ли автомат
+func (c *Client) Ready() bool {
+	return c.cc.GetState() == connectivity.Ready && c.breaker.State() == breaker.Closed
+}
+
+// watchState логирует смену состояний соединения до отмены ctx
+func (c *Client) watchState(ctx context.Context) {
+	state := c.cc.GetState()
+	for c.cc.WaitForStateChange(ctx, state) {
+		next := c.cc.GetState()
+
+		switch next {
+		case connectivity.TransientFailure, connectivity.Shutdown:
+			c.log.Warn("sso connection state changed", slog.String("from", state.String()), slog.String("to", next.String()))
+		default:
+			c.log.Info("sso connection state changed", slog.String("from", state.String()), slog.String("to", next.String()))
+		}
+
+		state = next
+	}
+}
+
+// call выполняет вызов SSO через автомат. Сбоем SSO считаются только ошибки доступности,
+// а не, например, неверный пароль
+func (c *Client) call(f func() error) error {
+	err := c.breaker.Do(f, isFailure)
+	if errors.Is(err, breaker.ErrOpen) {
+		return ErrUnavailable
+	}
+
+	return err
+}
+
+// isFailure - признак того, что SSO недоступен или неисправен
+func isFailure(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Internal, codes.Unknown:
+		return true
+	default:
+		return false
+	}
+}
+
+// failOpen сообщает, что при недоступности SSO вызов должен вернуть значение по умолчанию, а не ошибку
+func (c *Client) failOpen(call string, err error) bool {
+	return c.degraded[call] == FailOpen && (errors.Is(err, ErrUnavailable) || isFailure(err))
+}
+
+// hasSecrets сообщает, что в запросе или ответе вызова есть пароль или токен
+func hasSecrets(c interceptors.CallMeta) bool {
+	return c.Service == "auth.Auth" && (c.Method == "Login" || c.Method == "Register")
+}
+
 // InterceptorLogger адаптирует логгер slog к интерцептор-логгеру
 // Достаточно просто скопировать этот код вместо импорта.
 // Копипаст из gRPC middleware, для логирования запросов и ответов.
@@ -88,11 +223,21 @@ func (c *Client) IsAdmin(
 	const op = "grpc.IsAdmin"
 
 	// основная часть
-	resp, err := c.api.IsAdmin(ctx, &ssov1.IsAdminRequest{
-		UserId: userID,
+	var resp *ssov1.IsAdminResponse
+	err := c.call(func() (err error) {
+		resp, err = c.api.IsAdmin(ctx, &ssov1.IsAdminRequest{
+			UserId: userID,
+		})
+		return err
 	})
 
 	if err != nil {
+		// В режиме fail-open запрос продолжается так, как будто пользователь не администратор
+		if c.failOpen(CallIsAdmin, err) {
+			c.log.Warn("sso is unavailable, treating user as non-admin", slog.Int64("uid", userID), sl.Err(err))
+			return false, nil
+		}
+
 		return false, fmt.Errorf("%s: %w", op, err)
 	}
 
@@ -104,10 +249,14 @@ func (c *Client) Login(ctx context.Context, email string, password string, app_I
 	const op = "grpc.Login"
 
 	// основная часть
-	resp, err := c.api.Login(ctx, &ssov1.LoginRequest{
-		Email:    email,
-		Password: password,
-		AppId:    app_Id,
+	var resp *ssov1.LoginResponse
+	err := c.call(func() (err error) {
+		resp, err = c.api.Login(ctx, &ssov1.LoginRequest{
+			Email:    email,
+			Password: password,
+			AppId:    app_Id,
+		})
+		return err
 	})
 
 	if err != nil {
@@ -122,9 +271,13 @@ func (c *Client) Register(ctx context.Context, email string, password string) (i
 	const op = "grpc.Register"
 
 	// основная часть
-	resp, err := c.api.Register(ctx, &ssov1.RegisterRequest{
-		Email:    email,
-		Password: password,
+	var resp *ssov1.RegisterResponse
+	err := c.call(func() (err error) {
+		resp, err = c.api.Register(ctx, &ssov1.RegisterRequest{
+			Email:    email,
+			Password: password,
+		})
+		return err
 	})
 
 	if err != nil {
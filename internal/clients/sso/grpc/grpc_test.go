@@ -0,0 +1,273 @@
+package grpc_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"log/slog"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	ssov1 "github.com/Alexxtn105/protos/gen/go/sso"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+
+	ssogrpc "url-shortener/internal/clients/sso/grpc"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+)
+
+// fakeSSO отвечает на IsAdmin ошибкой isAdminErr (если задана) и считает вызовы
+type fakeSSO struct {
+	ssov1.UnimplementedAuthServer
+
+	isAdminErr error
+	calls      atomic.Int32
+}
+
+func (s *fakeSSO) IsAdmin(_ context.Context, req *ssov1.IsAdminRequest) (*ssov1.IsAdminResponse, error) {
+	s.calls.Add(1)
+	if s.isAdminErr != nil {
+		return nil, s.isAdminErr
+	}
+
+	return &ssov1.IsAdminResponse{IsAdmin: req.GetUserId() == 1}, nil
+}
+
+func (s *fakeSSO) Login(context.Context, *ssov1.LoginRequest) (*ssov1.LoginResponse, error) {
+	s.calls.Add(1)
+	return nil, status.Error(codes.InvalidArgument, "invalid email or password")
+}
+
+// serve запускает fakeSSO на локальном порту и возвращает его адрес
+func serve(t *testing.T, sso *fakeSSO, opts ...grpc.ServerOption) string {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv := grpc.NewServer(opts...)
+	ssov1.RegisterAuthServer(srv, sso)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	return lis.Addr().String()
+}
+
+func newClient(t *testing.T, addr string, opts ...ssogrpc.Option) *ssogrpc.Client {
+	t.Helper()
+
+	client, err := ssogrpc.New(context.Background(), slogdiscard.NewDiscardLogger(), addr, time.Second, 1, opts...)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	return client
+}
+
+func TestClient_CircuitBreaker(t *testing.T) {
+	sso := &fakeSSO{isAdminErr: status.Error(codes.Unavailable, "database is down")}
+	client := newClient(t, serve(t, sso), ssogrpc.WithCircuitBreaker(2, time.Hour))
+
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		_, err := client.IsAdmin(ctx, 1)
+		require.Equal(t, codes.Unavailable, status.Code(err))
+	}
+	require.EqualValues(t, 2, sso.calls.Load())
+
+	// Автомат разомкнут: SSO больше не вызывается
+	_, err := client.IsAdmin(ctx, 1)
+	require.ErrorIs(t, err, ssogrpc.ErrUnavailable)
+	require.Equal(t, codes.Unavailable, status.Code(err))
+	require.EqualValues(t, 2, sso.calls.Load())
+	require.False(t, client.Ready())
+}
+
+func TestClient_BusinessErrorsDoNotOpenBreaker(t *testing.T) {
+	sso := &fakeSSO{}
+	client := newClient(t, serve(t, sso), ssogrpc.WithCircuitBreaker(1, time.Hour))
+
+	for i := 0; i < 3; i++ {
+		_, err := client.Login(context.Background(), "user@example.com", "wrong", 1)
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	}
+	require.EqualValues(t, 3, sso.calls.Load())
+
+	isAdmin, err := client.IsAdmin(context.Background(), 1)
+	require.NoError(t, err)
+	require.True(t, isAdmin)
+	require.True(t, client.Ready())
+}
+
+func TestClient_SecretsAreNotLogged(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	client, err := ssogrpc.New(context.Background(), log, serve(t, &fakeSSO{}), time.Second, 1)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	_, err = client.Login(context.Background(), "user@example.com", "top-secret-password", 1)
+	require.Error(t, err)
+	require.Contains(t, buf.String(), "Login")
+	require.NotContains(t, buf.String(), "top-secret-password")
+
+	// У остальных вызовов тело запроса по-прежнему логируется
+	buf.Reset()
+	_, err = client.IsAdmin(context.Background(), 42)
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), "42")
+}
+
+func TestClient_FailMode(t *testing.T) {
+	sso := &fakeSSO{isAdminErr: status.Error(codes.Unavailable, "database is down")}
+	addr := serve(t, sso)
+
+	// fail-open: пользователь считается не администратором, ошибки нет
+	open := newClient(t, addr, ssogrpc.WithFailMode(ssogrpc.CallIsAdmin, ssogrpc.FailOpen))
+	isAdmin, err := open.IsAdmin(context.Background(), 1)
+	require.NoError(t, err)
+	require.False(t, isAdmin)
+
+	// fail-closed (по умолчанию): ошибка
+	closed := newClient(t, addr)
+	_, err = closed.IsAdmin(context.Background(), 1)
+	require.Error(t, err)
+
+	// Ошибки, не связанные с доступностью SSO, fail-open не скрывает
+	sso.isAdminErr = status.Error(codes.PermissionDenied, "denied")
+	_, err = open.IsAdmin(context.Background(), 1)
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestNew_InvalidFailMode(t *testing.T) {
+	_, err := ssogrpc.New(context.Background(), slogdiscard.NewDiscardLogger(), "127.0.0.1:1", time.Second, 1,
+		ssogrpc.WithFailMode(ssogrpc.CallLogin, ssogrpc.FailOpen))
+	require.Error(t, err)
+
+	_, err = ssogrpc.New(context.Background(), slogdiscard.NewDiscardLogger(), "127.0.0.1:1", time.Second, 1,
+		ssogrpc.WithFailMode("is_root", ssogrpc.FailClosed))
+	require.Error(t, err)
+}
+
+// certs - CA и подписанные им сертификаты сервера и клиента во временных файлах
+type certs struct {
+	ca                    *x509.CertPool
+	caFile                string
+	serverCert            tls.Certificate
+	clientCert, clientKey string
+}
+
+func issueCerts(t *testing.T) certs {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	caCert, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+
+	issue := func(serial int64, usage x509.ExtKeyUsage) (certPEM, keyPEM []byte) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		tmpl := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: "sso"},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+			IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, tmpl, caCert, &key.PublicKey, caKey)
+		require.NoError(t, err)
+
+		keyDER, err := x509.MarshalECPrivateKey(key)
+		require.NoError(t, err)
+
+		return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+			pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	}
+
+	write := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, data, 0o600))
+		return path
+	}
+
+	serverCertPEM, serverKeyPEM := issue(2, x509.ExtKeyUsageServerAuth)
+	serverCert, err := tls.X509KeyPair(serverCertPEM, serverKeyPEM)
+	require.NoError(t, err)
+
+	clientCertPEM, clientKeyPEM := issue(3, x509.ExtKeyUsageClientAuth)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(caCert)
+
+	return certs{
+		ca:         pool,
+		caFile:     write("ca.pem", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})),
+		serverCert: serverCert,
+		clientCert: write("client.pem", clientCertPEM),
+		clientKey:  write("client-key.pem", clientKeyPEM),
+	}
+}
+
+func TestClient_MutualTLS(t *testing.T) {
+	c := issueCerts(t)
+
+	addr := serve(t, &fakeSSO{}, grpc.Creds(credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{c.serverCert},
+		ClientCAs:    c.ca,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	})))
+
+	// С сертификатом клиента
+	tlsCfg, err := ssogrpc.LoadTLSConfig(c.caFile, c.clientCert, c.clientKey, "")
+	require.NoError(t, err)
+
+	client := newClient(t, addr, ssogrpc.WithTLS(tlsCfg))
+	isAdmin, err := client.IsAdmin(context.Background(), 1)
+	require.NoError(t, err)
+	require.True(t, isAdmin)
+
+	// Без сертификата клиента сервер не принимает соединение
+	tlsCfg, err = ssogrpc.LoadTLSConfig(c.caFile, "", "", "")
+	require.NoError(t, err)
+
+	client = newClient(t, addr, ssogrpc.WithTLS(tlsCfg))
+	_, err = client.IsAdmin(context.Background(), 1)
+	require.Error(t, err)
+
+	// Сертификат без ключа
+	_, err = ssogrpc.LoadTLSConfig(c.caFile, c.clientCert, "", "")
+	require.Error(t, err)
+}
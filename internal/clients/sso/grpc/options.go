@@ -0,0 +1,122 @@
+package grpc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
+)
+
+// FailMode - поведение вызова, когда SSO недоступен
+type FailMode string
+
+const (
+	// FailClosed - вызов возвращает ошибку (по умолчанию)
+	FailClosed FailMode = "fail_closed"
+	// FailOpen - вызов возвращает безопасное значение по умолчанию, и запрос продолжается
+	FailOpen FailMode = "fail_open"
+)
+
+// Вызовы SSO, для которых задается FailMode
+const (
+	CallIsAdmin  = "is_admin"
+	CallLogin    = "login"
+	CallRegister = "register"
+)
+
+// Option - дополнительная настройка клиента SSO
+type Option func(*options)
+
+type options struct {
+	creds            credentials.TransportCredentials
+	keepalive        *keepalive.ClientParameters
+	breakerThreshold int
+	breakerCooldown  time.Duration
+	degraded         map[string]FailMode
+}
+
+// WithTLS включает TLS. Если в cfg есть сертификат клиента - это mTLS (см. LoadTLSConfig)
+func WithTLS(cfg *tls.Config) Option {
+	return func(o *options) {
+		o.creds = credentials.NewTLS(cfg)
+	}
+}
+
+// WithKeepalive включает пинги соединения: без них обрыв соединения может
+// обнаружиться только по таймауту очередного запроса.
+// interval не должен быть меньше разрешенного сервером (в grpc-go по умолчанию 5 минут),
+// иначе сервер закроет соединение с ошибкой too_many_pings
+func WithKeepalive(interval, timeout time.Duration) Option {
+	return func(o *options) {
+		if interval <= 0 {
+			return
+		}
+
+		o.keepalive = &keepalive.ClientParameters{
+			Time:                interval,
+			Timeout:             timeout,
+			PermitWithoutStream: true,
+		}
+	}
+}
+
+// WithCircuitBreaker размыкает автомат после threshold сбоев SSO подряд:
+// следующие cooldown вызовы сразу получают ErrUnavailable
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	return func(o *options) {
+		o.breakerThreshold = threshold
+		o.breakerCooldown = cooldown
+	}
+}
+
+// WithFailMode задает поведение вызова call (CallIsAdmin, ...) при недоступности SSO.
+// FailOpen допустим только для CallIsAdmin: пользователь считается не администратором
+func WithFailMode(call string, mode FailMode) Option {
+	return func(o *options) {
+		o.degraded[call] = mode
+	}
+}
+
+// LoadTLSConfig собирает настройки TLS для соединения с SSO.
+// caFile - сертификат CA сервера (пусто - системные корневые сертификаты),
+// certFile и keyFile - сертификат клиента для mTLS (задаются вместе или не задаются),
+// serverName - имя сервера в сертификате, если оно отличается от адреса
+func LoadTLSConfig(caFile, certFile, keyFile, serverName string) (*tls.Config, error) {
+	const op = "grpc.LoadTLSConfig"
+
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: serverName,
+	}
+
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("%s: no certificates in %s", op, caFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if (certFile == "") != (keyFile == "") {
+		return nil, fmt.Errorf("%s: client certificate and key must be set together", op)
+	}
+
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
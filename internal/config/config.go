@@ -24,21 +24,182 @@ type Config struct {
 	HTTPServer  `yaml:"http_server"`
 	Clients     ClientConfig `yaml:"clients"`
 	AppSecret   string       `yaml:"app_secret" env-required:"true" env:"APP_SECRET"` // секретный ключ, с помощью которого приложение будет проверять JWT-токены
+	GeoIPPath   string       `yaml:"geoip_path" env:"GEOIP_PATH"`                     // CSV-база "сеть,страна" для правил редиректа по стране. Пусто - правила по стране не срабатывают
+	Auth        Auth         `yaml:"auth"`
+	Retention   Retention    `yaml:"retention"`
+	Aliases     Aliases      `yaml:"aliases"`
+	Backup      Backup       `yaml:"backup"`
+	Webhooks    Webhooks     `yaml:"webhooks"`
+	Outbox      Outbox       `yaml:"outbox"`
+	Preview     Preview      `yaml:"preview"`
+	HealthCheck HealthCheck  `yaml:"health_check"`
+	Threats     Threats      `yaml:"threats"`
+}
+
+// Threats - проверка адресов перехода по локальному списку опасных адресов (см. lib/threatlist)
+type Threats struct {
+	// Файл списка: обновление Safe Browsing (JSON) или текстовый список. Пусто - проверка выключена
+	ListPath string `yaml:"list_path" env:"THREAT_LIST_PATH" env-default:""`
+	// Как часто перечитывать список (если файл изменился) и проверять все ссылки. 0 - только при создании
+	ScanInterval time.Duration `yaml:"scan_interval" env-default:"1h"`
+	BatchSize    int           `yaml:"batch_size" env-default:"500"`
+}
+
+// HealthCheck - периодическая проверка доступности страниц перехода
+type HealthCheck struct {
+	// Как часто искать ссылки, которые пора проверить. 0 - проверка выключена
+	PollInterval time.Duration `yaml:"poll_interval" env-default:"1m"`
+	Interval     time.Duration `yaml:"interval" env-default:"24h"`   // как часто проверять каждую ссылку
+	Timeout      time.Duration `yaml:"timeout" env-default:"10s"`    // на запрос страницы, включая редиректы
+	Concurrency  int           `yaml:"concurrency" env-default:"8"`  // сколько страниц проверять одновременно
+	HostDelay    time.Duration `yaml:"host_delay" env-default:"1s"`  // пауза между запросами к одному хосту
+	BatchSize    int           `yaml:"batch_size" env-default:"100"` // сколько ссылок выбирать за раз
+	// После скольких неудачных проверок подряд ссылка считается сломанной (событие link.broken)
+	FailureThreshold int `yaml:"failure_threshold" env-default:"2"`
+	// Разрешить адреса внутренних сетей (защита от SSRF выключается). Только для закрытых инсталляций
+	AllowPrivate bool `yaml:"allow_private" env-default:"false"`
+}
+
+// Preview - получение метаданных страниц перехода (заголовок, описание, картинка, иконка)
+type Preview struct {
+	// Как часто проверять очередь. Новые ссылки обрабатываются сразу. 0 - метаданные не запрашиваются
+	PollInterval time.Duration `yaml:"poll_interval" env-default:"1m"`
+	Timeout      time.Duration `yaml:"timeout" env-default:"5s"`       // на запрос страницы, включая редиректы
+	MaxBytes     int64         `yaml:"max_bytes" env-default:"524288"` // сколько байт страницы читать
+	Concurrency  int           `yaml:"concurrency" env-default:"4"`    // сколько страниц запрашивать одновременно
+	BatchSize    int           `yaml:"batch_size" env-default:"20"`
+	// Разрешить адреса внутренних сетей (защита от SSRF выключается). Только для закрытых инсталляций
+	AllowPrivate bool `yaml:"allow_private" env-default:"false"`
+}
+
+// Outbox - публикация событий ссылок во внешнюю шину сообщений
+type Outbox struct {
+	Publisher string   `yaml:"publisher" env-default:"none"` // none (выключено), stdout или file
+	Path      string   `yaml:"path" env-default:"./storage/events.ndjson"`
+	Events    []string `yaml:"events"` // типы событий для публикации, пусто - все
+	// Как часто публиковать накопившиеся события и сколько выбирать за раз
+	PollInterval time.Duration `yaml:"poll_interval" env-default:"1s"`
+	BatchSize    int           `yaml:"batch_size" env-default:"100"`
+	Retention    time.Duration `yaml:"retention" env-default:"168h"` // сколько хранить опубликованные события, 0 - бессрочно
+}
+
+// Webhooks - доставка событий на вебхуки пользователей
+type Webhooks struct {
+	PollInterval time.Duration `yaml:"poll_interval" env-default:"5s"` // как часто проверять очередь, 0 - доставка выключена
+	Timeout      time.Duration `yaml:"timeout" env-default:"10s"`      // таймаут запроса к получателю
+	MaxAttempts  int           `yaml:"max_attempts" env-default:"10"`  // попыток до отметки доставки неудачной
+	// Задержка перед повторной попыткой: backoff_base, затем вдвое больше каждый раз, но не более backoff_max
+	BackoffBase time.Duration `yaml:"backoff_base" env-default:"30s"`
+	BackoffMax  time.Duration `yaml:"backoff_max" env-default:"6h"`
+	BatchSize   int           `yaml:"batch_size" env-default:"100"`
+	// Разрешить адреса внутренних сетей (защита от SSRF выключается). Только для закрытых инсталляций
+	AllowPrivate bool `yaml:"allow_private" env-default:"false"`
+}
+
+// Backup - резервные копии БД
+type Backup struct {
+	Dir      string        `yaml:"dir" env-default:"./storage/backups"`
+	Interval time.Duration `yaml:"interval" env-default:"24h"`  // как часто делать копию по расписанию, 0 - только по запросу
+	Keep     int           `yaml:"keep" env-default:"7"`        // сколько последних копий хранить, 0 - все
+	Compress bool          `yaml:"compress" env-default:"true"` // сжимать копии gzip
+}
+
+// Aliases - политика алиасов коротких ссылок
+type Aliases struct {
+	MinLength int `yaml:"min_length" env-default:"3"`
+	MaxLength int `yaml:"max_length" env-default:"64"`
+	// Сравнение алиасов при сохранении и редиректе: exact, case_insensitive или confusable (O/0, l/I/1).
+	// При смене режима существующие ссылки проверяются на совпадения, с ними сервис не запустится
+	Match string `yaml:"match" env-default:"exact"`
+	// Зарезервированные слова в дополнение к встроенному списку и первым сегментам маршрутов сервиса
+	Reserved []string `yaml:"reserved"`
+	// Файл со списком нецензурных слов (по одному на строку). Пусто - фильтр выключен
+	ProfanityPath string `yaml:"profanity_path" env:"ALIASES_PROFANITY_PATH"`
+}
+
+// Retention - хранение удаленных ссылок
+type Retention struct {
+	// Сколько хранить удаленную ссылку до окончательной очистки. Пока ссылка хранится, ее можно восстановить,
+	// а алиас остается занятым. 0 - хранить бессрочно
+	DeletedLinks  time.Duration `yaml:"deleted_links" env-default:"720h"`
+	PurgeInterval time.Duration `yaml:"purge_interval" env-default:"1h"` // как часто запускать очистку
+}
+
+// Auth - настройки аутентификации
+type Auth struct {
+	// htpasswd-файл с bcrypt-хешами паролей для basic auth (htpasswd -B). Пусто - только http_server.user/password
+	HtpasswdPath string `yaml:"htpasswd_path" env:"AUTH_HTPASSWD_PATH"`
+	// Цепочки способов аутентификации по группам маршрутов: url, workspaces, keys, admin.
+	// Способы: basic, jwt, api_key, anonymous. Для незаданной группы используется цепочка по умолчанию
+	Groups map[string][]string `yaml:"groups"`
+	JWT    JWT                 `yaml:"jwt"`
+	Cookie Cookie              `yaml:"cookie"`
+}
+
+// Cookie - HttpOnly cookie с JWT-токеном, которую выставляет POST /auth/login.
+// Токен из нее принимается так же, как из заголовка Authorization
+type Cookie struct {
+	Name   string `yaml:"name"` // пусто - cookie не используется, токен только в теле ответа
+	Domain string `yaml:"domain"`
+	Secure bool   `yaml:"secure" env-default:"true"` // только HTTPS. Для локальной разработки по HTTP - false
+}
+
+// JWT - проверка JWT-токенов SSO
+type JWT struct {
+	// Разрешенные алгоритмы подписи: HS256/HS384/HS512 (секрет app_secret), RS256/RS384/RS512, EdDSA (ключи из jwks_path).
+	// Пусто - только HS256
+	Algorithms []string `yaml:"algorithms"`
+	// Файл JSON Web Key Set с открытыми ключами SSO. Ключ выбирается по kid токена, файл перечитывается при ротации
+	JWKSPath string `yaml:"jwks_path" env:"AUTH_JWKS_PATH"`
+	// Ожидаемые iss, aud и app_id токена. Пусто (0) - не проверяются
+	Issuer   string `yaml:"issuer"`
+	Audience string `yaml:"audience"`
+	AppID    int    `yaml:"app_id"`
+	// Допустимое расхождение часов с сервером SSO
+	Leeway time.Duration `yaml:"leeway" env-default:"30s"`
 }
 
 type HTTPServer struct {
 	Address     string        `yaml:"address" env-default:"localhost:8080"`
 	Timeout     time.Duration `yaml:"timeout" env-default:"4s"`
 	IdleTimeout time.Duration `yaml:"idle_timeout" env-default:"60s"`
-	User        string        `yaml:"user" env-required:"true"`
-	Password    string        `yaml:"password" env-required:"true" env:"HTTP_SERVER_PASSWORD"`
+	User        string        `yaml:"user"` // пользователь basic auth с паролем в открытом виде. Устарело: лучше auth.htpasswd_path
+	Password    string        `yaml:"password" env:"HTTP_SERVER_PASSWORD"`
+	QueryPolicy string        `yaml:"query_policy" env-default:"drop"` // перенос параметров запроса при редиректе: drop, merge или override
+	// Сколько хранить ответы на запросы создания ссылок с Idempotency-Key. 0 - заголовок игнорируется
+	IdempotencyWindow time.Duration `yaml:"idempotency_window" env-default:"24h"`
+	// Через сколько незавершенный запрос с Idempotency-Key считается прерванным и ключ можно занять заново.
+	// Должно быть больше timeout: обработчик продолжает работать и после истечения таймаута записи ответа
+	IdempotencyStaleAfter time.Duration `yaml:"idempotency_stale_after" env-default:"10m"`
 }
 
 type Client struct {
 	Address      string        `yaml:"address"`
 	Timeout      time.Duration `yaml:"timeout"`
 	RetriesCount int           `yaml:"retriesCount"`
-	//Insecure bool `yaml:"insecure"`  //может пригодиться в будущем. Можно запускать приложение в двух режимах
+	TLS          ClientTLS     `yaml:"tls"`
+	// Интервал пингов соединения и время ожидания ответа на пинг. 0 - пинги выключены
+	Keepalive        time.Duration `yaml:"keepalive"`
+	KeepaliveTimeout time.Duration `yaml:"keepalive_timeout" env-default:"20s"`
+	Breaker          Breaker       `yaml:"breaker"`
+	// Поведение вызовов при недоступности сервиса: вызов (is_admin, login, register) -> fail_open или fail_closed.
+	// fail_open допустим только для is_admin: пользователь считается не администратором
+	Degraded map[string]string `yaml:"degraded"`
+}
+
+// ClientTLS - TLS соединения с сервисом. Если задан сертификат клиента - mTLS
+type ClientTLS struct {
+	Enabled    bool   `yaml:"enabled"`
+	CAFile     string `yaml:"ca_file"` // пусто - системные корневые сертификаты
+	CertFile   string `yaml:"cert_file"`
+	KeyFile    string `yaml:"key_file"`
+	ServerName string `yaml:"server_name"`
+}
+
+// Breaker - автомат, который после серии сбоев сервиса сразу отклоняет вызовы, не дожидаясь таймаута
+type Breaker struct {
+	Threshold int           `yaml:"threshold" env-default:"5"` // сбоев подряд до размыкания. 0 - автомат выключен
+	Cooldown  time.Duration `yaml:"cooldown" env-default:"10s"`
 }
 
 type ClientConfig struct {
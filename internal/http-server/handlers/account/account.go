@@ -0,0 +1,210 @@
+// internal/http-server/handlers/account/account.go
+
+// Вход и регистрация пользователей: ресурс /auth.
+// Хендлеры только проксируют запросы в SSO, сами учетные записи сервис не хранит
+package account
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/sl"
+)
+
+// структура запроса входа и регистрации
+type Request struct {
+	Email    string `json:"email" validate:"required,email,max=254"`
+	Password string `json:"password" validate:"required,max=72"`
+}
+
+// структура ответа на вход
+type LoginResponse struct {
+	resp.Response
+	Token string `json:"token,omitempty"`
+}
+
+// структура ответа на регистрацию
+type RegisterResponse struct {
+	resp.Response
+	UserID int64 `json:"user_id,omitempty"`
+}
+
+// Cookie - параметры cookie с JWT-токеном, которую выставляет вход.
+// Пустое имя - cookie не выставляется, токен возвращается только в теле ответа
+type Cookie struct {
+	Name   string
+	Domain string
+	Secure bool
+}
+
+// SSOClient is an interface for SSO login and registration (см. ssogrpc.Client).
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=SSOClient
+type SSOClient interface {
+	Login(ctx context.Context, email string, password string, appID int32) (string, error)
+	Register(ctx context.Context, email string, password string) (int64, error)
+}
+
+// NewLogin Конструктор обработчика POST /auth/login.
+// appID - приложение, для которого SSO выпускает токен
+func NewLogin(log *slog.Logger, sso SSOClient, appID int32, cookie Cookie) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.account.NewLogin"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		req, ok := decodeRequest(w, r, log)
+		if !ok {
+			return
+		}
+
+		token, err := sso.Login(r.Context(), req.Email, req.Password, appID)
+		if err != nil {
+			code, msg := ssoError(err, true)
+			if code >= http.StatusInternalServerError {
+				log.Error("failed to login", sl.Err(err))
+			} else {
+				log.Info("login rejected", sl.Err(err))
+			}
+
+			render.Status(r, code)
+			render.JSON(w, r, resp.Error(msg))
+
+			return
+		}
+
+		log.Info("user logged in")
+
+		if cookie.Name != "" {
+			// Сессионная cookie: срок действия задает сам токен.
+			// HttpOnly - скрипты страницы не смогут ее прочитать, SameSite=Strict - защита от CSRF
+			http.SetCookie(w, &http.Cookie{
+				Name:     cookie.Name,
+				Value:    token,
+				Path:     "/",
+				Domain:   cookie.Domain,
+				Secure:   cookie.Secure,
+				HttpOnly: true,
+				SameSite: http.SameSiteStrictMode,
+			})
+		}
+
+		render.JSON(w, r, LoginResponse{
+			Response: resp.OK(),
+			Token:    token,
+		})
+	}
+}
+
+// NewRegister Конструктор обработчика POST /auth/register
+func NewRegister(log *slog.Logger, sso SSOClient) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.account.NewRegister"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		req, ok := decodeRequest(w, r, log)
+		if !ok {
+			return
+		}
+
+		uid, err := sso.Register(r.Context(), req.Email, req.Password)
+		if err != nil {
+			code, msg := ssoError(err, false)
+			if code >= http.StatusInternalServerError {
+				log.Error("failed to register user", sl.Err(err))
+			} else {
+				log.Info("registration rejected", sl.Err(err))
+			}
+
+			render.Status(r, code)
+			render.JSON(w, r, resp.Error(msg))
+
+			return
+		}
+
+		log.Info("user registered", slog.Int64("uid", uid))
+
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, RegisterResponse{
+			Response: resp.OK(),
+			UserID:   uid,
+		})
+	}
+}
+
+// decodeRequest разбирает и валидирует тело запроса. Пароль в лог не попадает
+func decodeRequest(w http.ResponseWriter, r *http.Request, log *slog.Logger) (Request, bool) {
+	var req Request
+
+	err := render.DecodeJSON(r.Body, &req)
+	if errors.Is(err, io.EOF) {
+		log.Error("request body is empty")
+
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, resp.Error("empty request"))
+
+		return Request{}, false
+	}
+	if err != nil {
+		log.Error("failed to decode request body", sl.Err(err))
+
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, resp.Error("failed to decode request"))
+
+		return Request{}, false
+	}
+
+	if err := validator.New().Struct(req); err != nil {
+		log.Info("invalid request", slog.String("email", req.Email), sl.Err(err))
+
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, resp.Error(err.Error()))
+
+		return Request{}, false
+	}
+
+	return req, true
+}
+
+// ssoError переводит код ошибки gRPC от SSO в HTTP-статус и сообщение для клиента.
+// Текст ошибки SSO клиенту не отдается: он может содержать внутренние подробности
+func ssoError(err error, login bool) (int, string) {
+	switch status.Code(err) {
+	case codes.InvalidArgument, codes.NotFound, codes.Unauthenticated:
+		// SSO не различает "нет такого пользователя" и "неверный пароль" - и мы тоже
+		if login {
+			return http.StatusUnauthorized, "invalid email or password"
+		}
+
+		return http.StatusBadRequest, "invalid email or password"
+	case codes.AlreadyExists:
+		return http.StatusConflict, "user already exists"
+	case codes.PermissionDenied:
+		return http.StatusForbidden, "access denied"
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests, "too many requests"
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable, "auth service unavailable"
+	case codes.DeadlineExceeded, codes.Canceled:
+		return http.StatusGatewayTimeout, "auth service timeout"
+	default:
+		return http.StatusBadGateway, "auth service error"
+	}
+}
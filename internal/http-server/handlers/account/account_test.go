@@ -0,0 +1,207 @@
+package account_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	ssov1 "github.com/Alexxtn105/protos/gen/go/sso"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	ssogrpc "url-shortener/internal/clients/sso/grpc"
+	"url-shortener/internal/http-server/handlers/account"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+)
+
+// fakeSSO - SSO в памяти с одним приложением (app_id 1)
+type fakeSSO struct {
+	ssov1.UnimplementedAuthServer
+
+	mu    sync.Mutex
+	users map[string]string // email -> пароль
+	down  bool              // имитация сбоя SSO
+}
+
+func (s *fakeSSO) Register(_ context.Context, req *ssov1.RegisterRequest) (*ssov1.RegisterResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.down {
+		return nil, status.Error(codes.Internal, "database is down")
+	}
+	if _, ok := s.users[req.GetEmail()]; ok {
+		return nil, status.Error(codes.AlreadyExists, "user already exists")
+	}
+
+	s.users[req.GetEmail()] = req.GetPassword()
+
+	return &ssov1.RegisterResponse{UserId: int64(len(s.users))}, nil
+}
+
+func (s *fakeSSO) Login(_ context.Context, req *ssov1.LoginRequest) (*ssov1.LoginResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if req.GetAppId() != 1 {
+		return nil, status.Error(codes.InvalidArgument, "invalid app_id")
+	}
+	if pass, ok := s.users[req.GetEmail()]; !ok || pass != req.GetPassword() {
+		return nil, status.Error(codes.InvalidArgument, "invalid email or password")
+	}
+
+	return &ssov1.LoginResponse{Token: "token-for-" + req.GetEmail()}, nil
+}
+
+// startSSO запускает fakeSSO на локальном порту и возвращает настоящий клиент SSO к нему
+func startSSO(t *testing.T, sso *fakeSSO) *ssogrpc.Client {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv := grpc.NewServer()
+	ssov1.RegisterAuthServer(srv, sso)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	client, err := ssogrpc.New(context.Background(), slogdiscard.NewDiscardLogger(), lis.Addr().String(), time.Second, 1)
+	require.NoError(t, err)
+
+	return client
+}
+
+func post(t *testing.T, h http.Handler, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(body)))
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	return rr
+}
+
+func TestRegisterAndLogin(t *testing.T) {
+	sso := &fakeSSO{users: map[string]string{}}
+	client := startSSO(t, sso)
+	log := slogdiscard.NewDiscardLogger()
+
+	register := account.NewRegister(log, client)
+	login := account.NewLogin(log, client, 1, account.Cookie{Name: "token", Secure: true})
+
+	// Регистрация
+	rr := post(t, register, `{"email": "user@example.com", "password": "secret"}`)
+	require.Equal(t, http.StatusCreated, rr.Code)
+
+	var regResp account.RegisterResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &regResp))
+	require.Equal(t, int64(1), regResp.UserID)
+
+	// Повторная регистрация
+	rr = post(t, register, `{"email": "user@example.com", "password": "secret"}`)
+	require.Equal(t, http.StatusConflict, rr.Code)
+
+	// Вход: токен в теле ответа и в HttpOnly cookie
+	rr = post(t, login, `{"email": "user@example.com", "password": "secret"}`)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var loginResp account.LoginResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &loginResp))
+	require.Equal(t, "token-for-user@example.com", loginResp.Token)
+
+	cookies := rr.Result().Cookies()
+	require.Len(t, cookies, 1)
+	require.Equal(t, "token", cookies[0].Name)
+	require.Equal(t, loginResp.Token, cookies[0].Value)
+	require.True(t, cookies[0].HttpOnly)
+	require.True(t, cookies[0].Secure)
+
+	// Неверный пароль
+	rr = post(t, login, `{"email": "user@example.com", "password": "wrong"}`)
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+	require.Empty(t, rr.Result().Cookies())
+}
+
+func TestErrors(t *testing.T) {
+	sso := &fakeSSO{users: map[string]string{}, down: true}
+	client := startSSO(t, sso)
+	log := slogdiscard.NewDiscardLogger()
+
+	cases := []struct {
+		name    string
+		handler http.Handler
+		body    string
+		code    int
+		error   string
+	}{
+		{
+			name:    "Empty body",
+			handler: account.NewLogin(log, client, 1, account.Cookie{}),
+			body:    "",
+			code:    http.StatusBadRequest,
+			error:   "empty request",
+		},
+		{
+			name:    "Invalid email",
+			handler: account.NewRegister(log, client),
+			body:    `{"email": "not-an-email", "password": "secret"}`,
+			code:    http.StatusBadRequest,
+		},
+		{
+			name:    "SSO internal error",
+			handler: account.NewRegister(log, client),
+			body:    `{"email": "user@example.com", "password": "secret"}`,
+			code:    http.StatusBadGateway,
+			error:   "auth service error",
+		},
+		{
+			name:    "Wrong app",
+			handler: account.NewLogin(log, client, 2, account.Cookie{}),
+			body:    `{"email": "user@example.com", "password": "secret"}`,
+			code:    http.StatusUnauthorized,
+			error:   "invalid email or password",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			rr := post(t, tc.handler, tc.body)
+			require.Equal(t, tc.code, rr.Code)
+
+			var body struct {
+				Status string `json:"status"`
+				Error  string `json:"error"`
+			}
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+			require.Equal(t, "Error", body.Status)
+			if tc.error != "" {
+				require.Equal(t, tc.error, body.Error)
+			}
+		})
+	}
+}
+
+func TestSSOUnavailable(t *testing.T) {
+	// Адрес, на котором никто не слушает
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := lis.Addr().String()
+	require.NoError(t, lis.Close())
+
+	client, err := ssogrpc.New(context.Background(), slogdiscard.NewDiscardLogger(), addr, time.Second, 1)
+	require.NoError(t, err)
+
+	rr := post(t, account.NewLogin(slogdiscard.NewDiscardLogger(), client, 1, account.Cookie{}),
+		`{"email": "user@example.com", "password": "secret"}`)
+	require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+}
@@ -0,0 +1,77 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// SSOClient is an autogenerated mock type for the SSOClient type
+type SSOClient struct {
+	mock.Mock
+}
+
+// Login provides a mock function with given fields: ctx, email, password, appID
+func (_m *SSOClient) Login(ctx context.Context, email string, password string, appID int32) (string, error) {
+	ret := _m.Called(ctx, email, password, appID)
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int32) (string, error)); ok {
+		return rf(ctx, email, password, appID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int32) string); ok {
+		r0 = rf(ctx, email, password, appID)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, int32) error); ok {
+		r1 = rf(ctx, email, password, appID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Register provides a mock function with given fields: ctx, email, password
+func (_m *SSOClient) Register(ctx context.Context, email string, password string) (int64, error) {
+	ret := _m.Called(ctx, email, password)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (int64, error)); ok {
+		return rf(ctx, email, password)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) int64); ok {
+		r0 = rf(ctx, email, password)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, email, password)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewSSOClient interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewSSOClient creates a new instance of SSOClient. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewSSOClient(t mockConstructorTestingTNewSSOClient) *SSOClient {
+	mock := &SSOClient{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,215 @@
+// internal/http-server/handlers/admin/aliases/aliases.go
+
+// Резервирование алиасов администраторами: подресурс /admin/aliases.
+// Ссылку с зарезервированным алиасом может создать только администратор
+package aliases
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+
+	"url-shortener/internal/http-server/middleware/auth"
+	"url-shortener/internal/lib/aliaspolicy"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/hostname"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/storage"
+)
+
+// структура запроса на резервирование алиаса
+type ReserveRequest struct {
+	Alias  string `json:"alias"`
+	Domain string `json:"domain,omitempty"` // пусто - основной домен
+	Note   string `json:"note,omitempty"`
+}
+
+// структура ответа
+type Response struct {
+	resp.Response
+	Alias *storage.ReservedAlias `json:"alias,omitempty"`
+}
+
+// структура ответа со списком алиасов
+type ListResponse struct {
+	resp.Response
+	Aliases []storage.ReservedAlias `json:"aliases"`
+}
+
+// AliasReserver is an interface for reserving aliases.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=AliasReserver
+type AliasReserver interface {
+	ReserveAlias(reserved storage.ReservedAlias) error
+}
+
+// AliasLister is an interface for listing reserved aliases.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=AliasLister
+type AliasLister interface {
+	ListReservedAliases() ([]storage.ReservedAlias, error)
+}
+
+// AliasReleaser is an interface for releasing reserved aliases.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=AliasReleaser
+type AliasReleaser interface {
+	ReleaseAlias(domain string, alias string) error
+}
+
+// NewReserve Конструктор обработчика POST /admin/aliases.
+// Алиас должен удовлетворять политике алиасов и не быть занят ссылкой
+func NewReserve(log *slog.Logger, aliasReserver AliasReserver, policy *aliaspolicy.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.admin.aliases.NewReserve"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req ReserveRequest
+
+		err := render.DecodeJSON(r.Body, &req)
+		if errors.Is(err, io.EOF) {
+			log.Error("request body is empty")
+
+			render.JSON(w, r, resp.Error("empty request"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed to decode request"))
+
+			return
+		}
+
+		log.Info("request body decoded", slog.Any("req", req))
+
+		if err := policy.Check(req.Alias); err != nil {
+			log.Info("alias rejected by policy", slog.String("alias", req.Alias), sl.Err(err))
+
+			render.JSON(w, r, resp.Error(err.Error()))
+
+			return
+		}
+
+		reserved := storage.ReservedAlias{
+			Domain:     hostname.Normalize(req.Domain),
+			Alias:      req.Alias,
+			Note:       req.Note,
+			ReservedBy: reservedBy(r),
+		}
+
+		err = aliasReserver.ReserveAlias(reserved)
+		if errors.Is(err, storage.ErrURLExists) {
+			log.Info("alias is in use", slog.String("alias", req.Alias))
+
+			render.JSON(w, r, resp.Error("alias is already in use"))
+
+			return
+		}
+		if errors.Is(err, storage.ErrAliasReserved) {
+			log.Info("alias already reserved", slog.String("alias", req.Alias))
+
+			render.JSON(w, r, resp.Error("alias is already reserved"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to reserve alias", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("internal error"))
+
+			return
+		}
+
+		log.Info("alias reserved", slog.String("alias", req.Alias), slog.String("domain", reserved.Domain))
+
+		render.JSON(w, r, Response{
+			Response: resp.OK(),
+			Alias:    &reserved,
+		})
+	}
+}
+
+// NewList Конструктор обработчика GET /admin/aliases
+func NewList(log *slog.Logger, aliasLister AliasLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.admin.aliases.NewList"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		aliases, err := aliasLister.ListReservedAliases()
+		if err != nil {
+			log.Error("failed to list reserved aliases", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("internal error"))
+
+			return
+		}
+
+		render.JSON(w, r, ListResponse{
+			Response: resp.OK(),
+			Aliases:  aliases,
+		})
+	}
+}
+
+// NewRelease Конструктор обработчика DELETE /admin/aliases/{alias}.
+// Алиас на дополнительном коротком домене адресуется GET-параметром domain
+func NewRelease(log *slog.Logger, aliasReleaser AliasReleaser) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.admin.aliases.NewRelease"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		alias := chi.URLParam(r, "alias")
+		domain := hostname.Normalize(r.URL.Query().Get("domain"))
+
+		err := aliasReleaser.ReleaseAlias(domain, alias)
+		if errors.Is(err, storage.ErrReservationNotFound) {
+			log.Info("reservation not found", slog.String("alias", alias))
+
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error("not found"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to release alias", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("internal error"))
+
+			return
+		}
+
+		log.Info("alias released", slog.String("alias", alias), slog.String("domain", domain))
+
+		render.JSON(w, r, resp.OK())
+	}
+}
+
+// reservedBy - кто резервирует алиас: имя пользователя или, если его нет, UID
+func reservedBy(r *http.Request) string {
+	actor := auth.ActorFromRequest(r)
+	if actor.Name != "" || actor.UID == 0 {
+		return actor.Name
+	}
+
+	return strconv.FormatInt(actor.UID, 10)
+}
@@ -0,0 +1,55 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	storage "url-shortener/internal/storage"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// AliasLister is an autogenerated mock type for the AliasLister type
+type AliasLister struct {
+	mock.Mock
+}
+
+// ListReservedAliases provides a mock function with given fields:
+func (_m *AliasLister) ListReservedAliases() ([]storage.ReservedAlias, error) {
+	ret := _m.Called()
+
+	var r0 []storage.ReservedAlias
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]storage.ReservedAlias, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []storage.ReservedAlias); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]storage.ReservedAlias)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewAliasLister interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewAliasLister creates a new instance of AliasLister. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewAliasLister(t mockConstructorTestingTNewAliasLister) *AliasLister {
+	mock := &AliasLister{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
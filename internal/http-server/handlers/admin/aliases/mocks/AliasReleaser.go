@@ -0,0 +1,39 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import mock "github.com/stretchr/testify/mock"
+
+// AliasReleaser is an autogenerated mock type for the AliasReleaser type
+type AliasReleaser struct {
+	mock.Mock
+}
+
+// ReleaseAlias provides a mock function with given fields: domain, alias
+func (_m *AliasReleaser) ReleaseAlias(domain string, alias string) error {
+	ret := _m.Called(domain, alias)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(domain, alias)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewAliasReleaser interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewAliasReleaser creates a new instance of AliasReleaser. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewAliasReleaser(t mockConstructorTestingTNewAliasReleaser) *AliasReleaser {
+	mock := &AliasReleaser{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
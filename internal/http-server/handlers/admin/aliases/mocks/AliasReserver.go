@@ -0,0 +1,43 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	storage "url-shortener/internal/storage"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// AliasReserver is an autogenerated mock type for the AliasReserver type
+type AliasReserver struct {
+	mock.Mock
+}
+
+// ReserveAlias provides a mock function with given fields: reserved
+func (_m *AliasReserver) ReserveAlias(reserved storage.ReservedAlias) error {
+	ret := _m.Called(reserved)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(storage.ReservedAlias) error); ok {
+		r0 = rf(reserved)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewAliasReserver interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewAliasReserver creates a new instance of AliasReserver. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewAliasReserver(t mockConstructorTestingTNewAliasReserver) *AliasReserver {
+	mock := &AliasReserver{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,172 @@
+// internal/http-server/handlers/admin/audit/audit.go
+
+// Журнал аудита изменений ссылок: GET /admin/audit
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/hostname"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/lib/timeparse"
+	"url-shortener/internal/storage"
+)
+
+const (
+	defaultLimit = 100
+	maxLimit     = 1000
+
+	// формат выгрузки: одна запись JSON на строку
+	formatJSONL = "jsonl"
+)
+
+// структура ответа
+type Response struct {
+	resp.Response
+	Entries []storage.AuditEntry `json:"entries"`
+}
+
+// AuditLister is an interface for reading the audit log.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=AuditLister
+type AuditLister interface {
+	ListAudit(filter storage.AuditFilter) ([]storage.AuditEntry, error)
+	ExportAudit(filter storage.AuditFilter, fn func(storage.AuditEntry) error) error
+}
+
+// New Конструктор обработчика GET /admin/audit.
+// Записи отдаются от новых к старым. Необязательные GET-параметры:
+// domain, alias, action, actor_uid - фильтры по полям записи,
+// since и until - интервал времени (как в timeparse.Parse, часовой пояс - параметр timezone),
+// before_id - записи старше указанной (для постраничного просмотра),
+// limit - размер страницы (по умолчанию 100, не больше 1000).
+// С format=jsonl журнал выгружается целиком (limit по умолчанию не действует)
+// потоком в формате JSON Lines
+func New(log *slog.Logger, auditLister AuditLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.admin.audit.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		query := r.URL.Query()
+		export := query.Get("format") == formatJSONL
+
+		filter, err := parseFilter(query.Get, export)
+		if err != nil {
+			log.Info("invalid audit filter", sl.Err(err))
+
+			render.JSON(w, r, resp.Error(err.Error()))
+
+			return
+		}
+
+		if export {
+			exportJSONL(log, w, auditLister, filter)
+
+			return
+		}
+
+		entries, err := auditLister.ListAudit(filter)
+		if err != nil {
+			log.Error("failed to list audit log", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("internal error"))
+
+			return
+		}
+
+		log.Info("audit log listed", slog.Int("count", len(entries)))
+
+		render.JSON(w, r, Response{
+			Response: resp.OK(),
+			Entries:  entries,
+		})
+	}
+}
+
+// exportJSONL пишет записи в ответ по мере чтения из хранилища.
+// Ошибку после начала выгрузки клиенту уже не сообщить - только в лог,
+// выгрузка при этом обрывается
+func exportJSONL(log *slog.Logger, w http.ResponseWriter, auditLister AuditLister, filter storage.AuditFilter) {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", `attachment; filename="audit.jsonl"`)
+
+	enc := json.NewEncoder(w)
+	count := 0
+
+	err := auditLister.ExportAudit(filter, func(entry storage.AuditEntry) error {
+		count++
+		return enc.Encode(entry)
+	})
+	if err != nil {
+		log.Error("failed to export audit log", slog.Int("written", count), sl.Err(err))
+
+		return
+	}
+
+	log.Info("audit log exported", slog.Int("count", count))
+}
+
+// parseFilter разбирает GET-параметры фильтра. Текст ошибок предназначен для пользователя
+func parseFilter(get func(string) string, export bool) (storage.AuditFilter, error) {
+	filter := storage.AuditFilter{
+		Domain: hostname.Normalize(get("domain")),
+		Alias:  get("alias"),
+		Action: get("action"),
+	}
+
+	var err error
+
+	if filter.ActorUID, err = parseID(get, "actor_uid"); err != nil {
+		return storage.AuditFilter{}, err
+	}
+	if filter.BeforeID, err = parseID(get, "before_id"); err != nil {
+		return storage.AuditFilter{}, err
+	}
+
+	if filter.Since, err = timeparse.ParseOptional(get("since"), get("timezone")); err != nil {
+		return storage.AuditFilter{}, err
+	}
+	if filter.Until, err = timeparse.ParseOptional(get("until"), get("timezone")); err != nil {
+		return storage.AuditFilter{}, err
+	}
+
+	if !export {
+		filter.Limit = defaultLimit
+	}
+	if v := get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 || limit > maxLimit {
+			return storage.AuditFilter{}, errors.New("invalid limit")
+		}
+		filter.Limit = limit
+	}
+
+	return filter, nil
+}
+
+func parseID(get func(string) string, name string) (int64, error) {
+	v := get(name)
+	if v == "" {
+		return 0, nil
+	}
+
+	id, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid %s", name)
+	}
+
+	return id, nil
+}
@@ -0,0 +1,128 @@
+package audit_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/handlers/admin/audit"
+	"url-shortener/internal/http-server/handlers/admin/audit/mocks"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/storage"
+)
+
+func TestAuditHandler_List(t *testing.T) {
+	cases := []struct {
+		name      string
+		query     string
+		filter    storage.AuditFilter // ожидаемый фильтр, если запрос корректен
+		respError string
+	}{
+		{
+			name:   "Defaults",
+			filter: storage.AuditFilter{Limit: 100},
+		},
+		{
+			name:  "Filters",
+			query: "?domain=Go.Example.com&alias=abc&action=link.delete&actor_uid=7&before_id=50&limit=10&since=2024-05-01&timezone=Europe/Moscow",
+			filter: storage.AuditFilter{
+				Domain:   "go.example.com",
+				Alias:    "abc",
+				Action:   storage.AuditLinkDelete,
+				ActorUID: 7,
+				BeforeID: 50,
+				Limit:    10,
+				Since:    ptr(time.Date(2024, 4, 30, 21, 0, 0, 0, time.UTC)),
+			},
+		},
+		{
+			name:      "Limit too large",
+			query:     "?limit=5000",
+			respError: "invalid limit",
+		},
+		{
+			name:      "Invalid actor",
+			query:     "?actor_uid=x",
+			respError: "invalid actor_uid",
+		},
+		{
+			name:      "Invalid since",
+			query:     "?since=yesterday",
+			respError: "invalid time format: yesterday",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			listerMock := mocks.NewAuditLister(t)
+			if tc.respError == "" {
+				listerMock.On("ListAudit", mock.MatchedBy(func(f storage.AuditFilter) bool {
+					return sameFilter(f, tc.filter)
+				})).Return([]storage.AuditEntry{{ID: 1, Action: storage.AuditLinkCreate}}, nil).Once()
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/admin/audit"+tc.query, nil)
+			rr := httptest.NewRecorder()
+			audit.New(slogdiscard.NewDiscardLogger(), listerMock).ServeHTTP(rr, req)
+
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			var resp audit.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, tc.respError, resp.Error)
+			if tc.respError == "" {
+				require.Len(t, resp.Entries, 1)
+			}
+		})
+	}
+}
+
+func TestAuditHandler_ExportJSONL(t *testing.T) {
+	listerMock := mocks.NewAuditLister(t)
+	listerMock.On("ExportAudit", storage.AuditFilter{Alias: "abc"}, mock.Anything).
+		Run(func(args mock.Arguments) {
+			fn := args.Get(1).(func(storage.AuditEntry) error)
+			for _, id := range []int64{3, 2, 1} {
+				require.NoError(t, fn(storage.AuditEntry{ID: id, Alias: "abc"}))
+			}
+		}).
+		Return(nil).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/audit?alias=abc&format=jsonl", nil)
+	rr := httptest.NewRecorder()
+	audit.New(slogdiscard.NewDiscardLogger(), listerMock).ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "application/x-ndjson", rr.Header().Get("Content-Type"))
+
+	var ids []int64
+	scanner := bufio.NewScanner(rr.Body)
+	for scanner.Scan() {
+		var entry storage.AuditEntry
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		ids = append(ids, entry.ID)
+	}
+	require.Equal(t, []int64{3, 2, 1}, ids)
+}
+
+func sameFilter(a, b storage.AuditFilter) bool {
+	sameTime := func(x, y *time.Time) bool {
+		return x == nil && y == nil || x != nil && y != nil && x.Equal(*y)
+	}
+
+	return a.Domain == b.Domain && a.Alias == b.Alias && a.Action == b.Action &&
+		a.ActorUID == b.ActorUID && a.BeforeID == b.BeforeID && a.Limit == b.Limit &&
+		sameTime(a.Since, b.Since) && sameTime(a.Until, b.Until)
+}
+
+func ptr(t time.Time) *time.Time { return &t }
@@ -0,0 +1,69 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	storage "url-shortener/internal/storage"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// AuditLister is an autogenerated mock type for the AuditLister type
+type AuditLister struct {
+	mock.Mock
+}
+
+// ExportAudit provides a mock function with given fields: filter, fn
+func (_m *AuditLister) ExportAudit(filter storage.AuditFilter, fn func(storage.AuditEntry) error) error {
+	ret := _m.Called(filter, fn)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(storage.AuditFilter, func(storage.AuditEntry) error) error); ok {
+		r0 = rf(filter, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ListAudit provides a mock function with given fields: filter
+func (_m *AuditLister) ListAudit(filter storage.AuditFilter) ([]storage.AuditEntry, error) {
+	ret := _m.Called(filter)
+
+	var r0 []storage.AuditEntry
+	var r1 error
+	if rf, ok := ret.Get(0).(func(storage.AuditFilter) ([]storage.AuditEntry, error)); ok {
+		return rf(filter)
+	}
+	if rf, ok := ret.Get(0).(func(storage.AuditFilter) []storage.AuditEntry); ok {
+		r0 = rf(filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]storage.AuditEntry)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(storage.AuditFilter) error); ok {
+		r1 = rf(filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewAuditLister interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewAuditLister creates a new instance of AuditLister. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewAuditLister(t mockConstructorTestingTNewAuditLister) *AuditLister {
+	mock := &AuditLister{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
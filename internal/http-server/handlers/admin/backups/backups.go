@@ -0,0 +1,158 @@
+// internal/http-server/handlers/admin/backups/backups.go
+
+// Резервные копии БД: подресурс /admin/backups.
+// Копия создается без остановки сервиса, восстановление - командой db restore при остановленном сервисе
+package backups
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/lib/snapshot"
+)
+
+// структура ответа
+type Response struct {
+	resp.Response
+	Snapshot *snapshot.Snapshot `json:"snapshot,omitempty"`
+}
+
+// структура ответа со списком копий
+type ListResponse struct {
+	resp.Response
+	Snapshots []snapshot.Snapshot `json:"snapshots"`
+}
+
+// SnapshotCreator is an interface for creating database snapshots.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=SnapshotCreator
+type SnapshotCreator interface {
+	Create(ctx context.Context) (snapshot.Snapshot, error)
+}
+
+// SnapshotLister is an interface for listing database snapshots.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=SnapshotLister
+type SnapshotLister interface {
+	List() ([]snapshot.Snapshot, error)
+}
+
+// SnapshotVerifier is an interface for verifying database snapshots.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=SnapshotVerifier
+type SnapshotVerifier interface {
+	Verify(name string) (snapshot.Snapshot, error)
+}
+
+// NewCreate Конструктор обработчика POST /admin/backups - создать копию сейчас.
+// Лишние старые копии удаляются ротацией
+func NewCreate(log *slog.Logger, creator SnapshotCreator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.admin.backups.NewCreate"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		s, err := creator.Create(r.Context())
+		if errors.Is(err, snapshot.ErrExists) {
+			log.Info("snapshot already exists", sl.Err(err))
+
+			render.Status(r, http.StatusConflict)
+			render.JSON(w, r, resp.Error("snapshot for this second already exists, retry later"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to create snapshot", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed to create snapshot"))
+
+			return
+		}
+
+		log.Info("snapshot created", slog.String("name", s.Name), slog.Int64("size", s.Size))
+
+		render.JSON(w, r, Response{Response: resp.OK(), Snapshot: &s})
+	}
+}
+
+// NewList Конструктор обработчика GET /admin/backups - копии от новых к старым
+func NewList(log *slog.Logger, lister SnapshotLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.admin.backups.NewList"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		snapshots, err := lister.List()
+		if err != nil {
+			log.Error("failed to list snapshots", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("internal error"))
+
+			return
+		}
+
+		render.JSON(w, r, ListResponse{Response: resp.OK(), Snapshots: snapshots})
+	}
+}
+
+// NewVerify Конструктор обработчика POST /admin/backups/{name}/verify - сверить копию с ее контрольной суммой.
+// Неизвестная копия - 404
+func NewVerify(log *slog.Logger, verifier SnapshotVerifier) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.admin.backups.NewVerify"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		name := chi.URLParam(r, "name")
+
+		s, err := verifier.Verify(name)
+		switch {
+		case errors.Is(err, snapshot.ErrNotFound):
+			log.Info("snapshot not found", slog.String("name", name))
+
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error("not found"))
+
+			return
+		case errors.Is(err, snapshot.ErrChecksumMismatch):
+			log.Warn("snapshot is corrupted", slog.String("name", name), sl.Err(err))
+
+			render.JSON(w, r, resp.Error(snapshot.ErrChecksumMismatch.Error()))
+
+			return
+		case errors.Is(err, snapshot.ErrNoChecksum):
+			log.Warn("snapshot has no checksum", slog.String("name", name))
+
+			render.JSON(w, r, resp.Error(snapshot.ErrNoChecksum.Error()))
+
+			return
+		case err != nil:
+			log.Error("failed to verify snapshot", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("internal error"))
+
+			return
+		}
+
+		log.Info("snapshot verified", slog.String("name", name))
+
+		render.JSON(w, r, Response{Response: resp.OK(), Snapshot: &s})
+	}
+}
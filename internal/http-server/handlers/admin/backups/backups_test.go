@@ -0,0 +1,93 @@
+package backups_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/handlers/admin/backups"
+	"url-shortener/internal/http-server/handlers/admin/backups/mocks"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/lib/snapshot"
+)
+
+const snapshotName = "snapshot-20240501T100000Z.db.gz"
+
+func TestVerifyHandler(t *testing.T) {
+	cases := []struct {
+		name      string
+		mockError error
+		code      int
+		respError string
+	}{
+		{
+			name: "Success",
+			code: http.StatusOK,
+		},
+		{
+			name:      "Not found",
+			mockError: fmt.Errorf("lib.snapshot.Verify: %w", snapshot.ErrNotFound),
+			code:      http.StatusNotFound,
+			respError: "not found",
+		},
+		{
+			name:      "Corrupted",
+			mockError: fmt.Errorf("lib.snapshot.Verify: %w: expected a, got b", snapshot.ErrChecksumMismatch),
+			code:      http.StatusOK,
+			respError: "snapshot checksum mismatch",
+		},
+		{
+			name:      "Storage error",
+			mockError: errors.New("permission denied"),
+			code:      http.StatusOK,
+			respError: "internal error",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			verifierMock := mocks.NewSnapshotVerifier(t)
+			verifierMock.On("Verify", snapshotName).
+				Return(snapshot.Snapshot{Name: snapshotName}, tc.mockError).Once()
+
+			router := chi.NewRouter()
+			router.Post("/admin/backups/{name}/verify", backups.NewVerify(slogdiscard.NewDiscardLogger(), verifierMock))
+
+			req := httptest.NewRequest(http.MethodPost, "/admin/backups/"+snapshotName+"/verify", nil)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.code, rr.Code)
+
+			var body backups.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+			require.Equal(t, tc.respError, body.Error)
+		})
+	}
+}
+
+func TestCreateHandler(t *testing.T) {
+	creatorMock := mocks.NewSnapshotCreator(t)
+	creatorMock.On("Create", mock.Anything).
+		Return(snapshot.Snapshot{Name: snapshotName, Compressed: true}, nil).Once()
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/backups", nil)
+	rr := httptest.NewRecorder()
+	backups.NewCreate(slogdiscard.NewDiscardLogger(), creatorMock).ServeHTTP(rr, req)
+
+	var body backups.Response
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	require.Empty(t, body.Error)
+	require.Equal(t, snapshotName, body.Snapshot.Name)
+}
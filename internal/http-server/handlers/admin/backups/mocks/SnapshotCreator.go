@@ -0,0 +1,54 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	snapshot "url-shortener/internal/lib/snapshot"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// SnapshotCreator is an autogenerated mock type for the SnapshotCreator type
+type SnapshotCreator struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: ctx
+func (_m *SnapshotCreator) Create(ctx context.Context) (snapshot.Snapshot, error) {
+	ret := _m.Called(ctx)
+
+	var r0 snapshot.Snapshot
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (snapshot.Snapshot, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) snapshot.Snapshot); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(snapshot.Snapshot)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewSnapshotCreator interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewSnapshotCreator creates a new instance of SnapshotCreator. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewSnapshotCreator(t mockConstructorTestingTNewSnapshotCreator) *SnapshotCreator {
+	mock := &SnapshotCreator{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
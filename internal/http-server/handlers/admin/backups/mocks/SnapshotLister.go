@@ -0,0 +1,55 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	snapshot "url-shortener/internal/lib/snapshot"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// SnapshotLister is an autogenerated mock type for the SnapshotLister type
+type SnapshotLister struct {
+	mock.Mock
+}
+
+// List provides a mock function with given fields:
+func (_m *SnapshotLister) List() ([]snapshot.Snapshot, error) {
+	ret := _m.Called()
+
+	var r0 []snapshot.Snapshot
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]snapshot.Snapshot, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []snapshot.Snapshot); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]snapshot.Snapshot)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewSnapshotLister interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewSnapshotLister creates a new instance of SnapshotLister. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewSnapshotLister(t mockConstructorTestingTNewSnapshotLister) *SnapshotLister {
+	mock := &SnapshotLister{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
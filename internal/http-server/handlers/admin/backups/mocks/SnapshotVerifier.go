@@ -0,0 +1,53 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	snapshot "url-shortener/internal/lib/snapshot"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// SnapshotVerifier is an autogenerated mock type for the SnapshotVerifier type
+type SnapshotVerifier struct {
+	mock.Mock
+}
+
+// Verify provides a mock function with given fields: name
+func (_m *SnapshotVerifier) Verify(name string) (snapshot.Snapshot, error) {
+	ret := _m.Called(name)
+
+	var r0 snapshot.Snapshot
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (snapshot.Snapshot, error)); ok {
+		return rf(name)
+	}
+	if rf, ok := ret.Get(0).(func(string) snapshot.Snapshot); ok {
+		r0 = rf(name)
+	} else {
+		r0 = ret.Get(0).(snapshot.Snapshot)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewSnapshotVerifier interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewSnapshotVerifier creates a new instance of SnapshotVerifier. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewSnapshotVerifier(t mockConstructorTestingTNewSnapshotVerifier) *SnapshotVerifier {
+	mock := &SnapshotVerifier{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,381 @@
+// internal/http-server/handlers/admin/domains/domains.go
+
+// Администрирование коротких доменов: подресурс /admin/domains.
+// Домен начинает обслуживаться только после подтверждения владения через DNS TXT-запись
+package domains
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/hostname"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/lib/random"
+	"url-shortener/internal/storage"
+)
+
+const (
+	// Имя TXT-записи: _url-shortener.<домен>
+	verifyRecordPrefix = "_url-shortener."
+	// Значение TXT-записи: url-shortener-verification=<токен>
+	verifyValuePrefix = "url-shortener-verification="
+
+	verifyTokenSize = 16
+	verifyTimeout   = 5 * time.Second
+)
+
+// структура запроса на регистрацию домена
+type RegisterRequest struct {
+	Host       string `json:"host" validate:"required,fqdn"`
+	DefaultURL string `json:"default_url,omitempty" validate:"omitempty,url"`
+}
+
+// структура запроса на изменение домена
+type UpdateRequest struct {
+	DefaultURL string `json:"default_url" validate:"omitempty,url"`
+}
+
+// Verification - что нужно разместить в DNS для подтверждения домена
+type Verification struct {
+	Record string `json:"record"` // имя TXT-записи
+	Value  string `json:"value"`  // значение TXT-записи
+}
+
+// структура ответа
+type Response struct {
+	resp.Response
+	Domain       *storage.Domain `json:"domain,omitempty"`
+	Verification *Verification   `json:"verification,omitempty"`
+}
+
+// структура ответа со списком доменов
+type ListResponse struct {
+	resp.Response
+	Domains []storage.Domain `json:"domains"`
+}
+
+// DomainSaver is an interface for registering short domains.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=DomainSaver
+type DomainSaver interface {
+	SaveDomain(domain storage.Domain) (int64, error)
+	GetDomain(host string) (storage.Domain, error)
+}
+
+// DomainLister is an interface for listing short domains.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=DomainLister
+type DomainLister interface {
+	ListDomains() ([]storage.Domain, error)
+}
+
+// DomainVerifier is an interface for marking short domains as verified.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=DomainVerifier
+type DomainVerifier interface {
+	GetDomain(host string) (storage.Domain, error)
+	MarkDomainVerified(host string, at time.Time) error
+}
+
+// DomainUpdater is an interface for changing short domain settings.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=DomainUpdater
+type DomainUpdater interface {
+	SetDomainDefaultURL(host string, defaultURL string) error
+	GetDomain(host string) (storage.Domain, error)
+}
+
+// TXTResolver is an interface for DNS TXT lookups. net.DefaultResolver satisfies it.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=TXTResolver
+type TXTResolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// NewRegister Конструктор обработчика POST /admin/domains.
+// Домен сохраняется неподтвержденным, в ответе - TXT-запись для подтверждения
+func NewRegister(log *slog.Logger, domainSaver DomainSaver) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.admin.domains.NewRegister"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req RegisterRequest
+
+		err := render.DecodeJSON(r.Body, &req)
+		if errors.Is(err, io.EOF) {
+			log.Error("request body is empty")
+
+			render.JSON(w, r, resp.Error("empty request"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed to decode request"))
+
+			return
+		}
+
+		log.Info("request body decoded", slog.Any("req", req))
+
+		req.Host = hostname.Normalize(req.Host)
+
+		if err := validator.New().Struct(req); err != nil {
+			log.Error("invalid request", sl.Err(err))
+
+			render.JSON(w, r, resp.Error(err.Error()))
+
+			return
+		}
+
+		token, err := random.NewSecureToken(verifyTokenSize)
+		if err != nil {
+			log.Error("failed to generate verify token", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed to add domain"))
+
+			return
+		}
+
+		_, err = domainSaver.SaveDomain(storage.Domain{
+			Host:        req.Host,
+			VerifyToken: token,
+			DefaultURL:  req.DefaultURL,
+		})
+		if errors.Is(err, storage.ErrDomainExists) {
+			log.Info("domain already exists", slog.String("host", req.Host))
+
+			render.JSON(w, r, resp.Error("domain already exists"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to add domain", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed to add domain"))
+
+			return
+		}
+
+		log.Info("domain added", slog.String("host", req.Host))
+
+		domain, err := domainSaver.GetDomain(req.Host)
+		if err != nil {
+			log.Error("failed to get domain", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("internal error"))
+
+			return
+		}
+
+		responseOK(w, r, domain)
+	}
+}
+
+// NewList Конструктор обработчика GET /admin/domains
+func NewList(log *slog.Logger, domainLister DomainLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.admin.domains.NewList"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		domains, err := domainLister.ListDomains()
+		if err != nil {
+			log.Error("failed to list domains", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("internal error"))
+
+			return
+		}
+
+		render.JSON(w, r, ListResponse{
+			Response: resp.OK(),
+			Domains:  domains,
+		})
+	}
+}
+
+// NewVerify Конструктор обработчика POST /admin/domains/{host}/verify.
+// Ищет в DNS TXT-запись с токеном домена и, если она есть, отмечает домен подтвержденным
+func NewVerify(log *slog.Logger, domainVerifier DomainVerifier, resolver TXTResolver) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.admin.domains.NewVerify"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		host := hostname.Normalize(chi.URLParam(r, "host"))
+
+		domain, err := domainVerifier.GetDomain(host)
+		if errors.Is(err, storage.ErrDomainNotFound) {
+			log.Info("domain not found", slog.String("host", host))
+
+			render.JSON(w, r, resp.Error("not found"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to get domain", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("internal error"))
+
+			return
+		}
+
+		if domain.Verified() {
+			responseOK(w, r, domain)
+
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), verifyTimeout)
+		defer cancel()
+
+		records, err := resolver.LookupTXT(ctx, verifyRecordPrefix+host)
+		if err != nil {
+			log.Info("failed to lookup txt record", slog.String("host", host), sl.Err(err))
+		}
+
+		if !hasToken(records, domain.VerifyToken) {
+			log.Info("verification record not found", slog.String("host", host))
+
+			render.JSON(w, r, resp.Error("verification record not found"))
+
+			return
+		}
+
+		now := time.Now().UTC()
+		if err := domainVerifier.MarkDomainVerified(host, now); err != nil {
+			log.Error("failed to mark domain verified", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("internal error"))
+
+			return
+		}
+
+		log.Info("domain verified", slog.String("host", host))
+
+		domain.VerifiedAt = &now
+
+		responseOK(w, r, domain)
+	}
+}
+
+// NewUpdate Конструктор обработчика PATCH /admin/domains/{host}.
+// Пустой default_url отключает редирект неизвестных алиасов
+func NewUpdate(log *slog.Logger, domainUpdater DomainUpdater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.admin.domains.NewUpdate"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		host := hostname.Normalize(chi.URLParam(r, "host"))
+
+		var req UpdateRequest
+
+		err := render.DecodeJSON(r.Body, &req)
+		if errors.Is(err, io.EOF) {
+			log.Error("request body is empty")
+
+			render.JSON(w, r, resp.Error("empty request"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed to decode request"))
+
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			log.Error("invalid request", sl.Err(err))
+
+			render.JSON(w, r, resp.Error(err.Error()))
+
+			return
+		}
+
+		err = domainUpdater.SetDomainDefaultURL(host, req.DefaultURL)
+		if errors.Is(err, storage.ErrDomainNotFound) {
+			log.Info("domain not found", slog.String("host", host))
+
+			render.JSON(w, r, resp.Error("not found"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to update domain", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed to update domain"))
+
+			return
+		}
+
+		log.Info("domain updated", slog.String("host", host))
+
+		domain, err := domainUpdater.GetDomain(host)
+		if err != nil {
+			log.Error("failed to get domain", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("internal error"))
+
+			return
+		}
+
+		responseOK(w, r, domain)
+	}
+}
+
+// hasToken - есть ли среди TXT-записей запись подтверждения с нужным токеном
+func hasToken(records []string, token string) bool {
+	for _, record := range records {
+		if strings.TrimSpace(record) == verifyValuePrefix+token {
+			return true
+		}
+	}
+
+	return false
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request, domain storage.Domain) {
+	response := Response{
+		Response: resp.OK(),
+		Domain:   &domain,
+	}
+
+	// Пока домен не подтвержден, подсказываем, какую запись нужно добавить в DNS
+	if !domain.Verified() {
+		response.Verification = &Verification{
+			Record: verifyRecordPrefix + domain.Host,
+			Value:  verifyValuePrefix + domain.VerifyToken,
+		}
+	}
+
+	render.JSON(w, r, response)
+}
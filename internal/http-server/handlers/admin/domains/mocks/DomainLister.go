@@ -0,0 +1,55 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	storage "url-shortener/internal/storage"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// DomainLister is an autogenerated mock type for the DomainLister type
+type DomainLister struct {
+	mock.Mock
+}
+
+// ListDomains provides a mock function with given fields:
+func (_m *DomainLister) ListDomains() ([]storage.Domain, error) {
+	ret := _m.Called()
+
+	var r0 []storage.Domain
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]storage.Domain, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []storage.Domain); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]storage.Domain)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewDomainLister interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewDomainLister creates a new instance of DomainLister. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewDomainLister(t mockConstructorTestingTNewDomainLister) *DomainLister {
+	mock := &DomainLister{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,77 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	storage "url-shortener/internal/storage"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// DomainSaver is an autogenerated mock type for the DomainSaver type
+type DomainSaver struct {
+	mock.Mock
+}
+
+// GetDomain provides a mock function with given fields: host
+func (_m *DomainSaver) GetDomain(host string) (storage.Domain, error) {
+	ret := _m.Called(host)
+
+	var r0 storage.Domain
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (storage.Domain, error)); ok {
+		return rf(host)
+	}
+	if rf, ok := ret.Get(0).(func(string) storage.Domain); ok {
+		r0 = rf(host)
+	} else {
+		r0 = ret.Get(0).(storage.Domain)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(host)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveDomain provides a mock function with given fields: domain
+func (_m *DomainSaver) SaveDomain(domain storage.Domain) (int64, error) {
+	ret := _m.Called(domain)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(storage.Domain) (int64, error)); ok {
+		return rf(domain)
+	}
+	if rf, ok := ret.Get(0).(func(storage.Domain) int64); ok {
+		r0 = rf(domain)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(storage.Domain) error); ok {
+		r1 = rf(domain)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewDomainSaver interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewDomainSaver creates a new instance of DomainSaver. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewDomainSaver(t mockConstructorTestingTNewDomainSaver) *DomainSaver {
+	mock := &DomainSaver{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,67 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	storage "url-shortener/internal/storage"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// DomainUpdater is an autogenerated mock type for the DomainUpdater type
+type DomainUpdater struct {
+	mock.Mock
+}
+
+// GetDomain provides a mock function with given fields: host
+func (_m *DomainUpdater) GetDomain(host string) (storage.Domain, error) {
+	ret := _m.Called(host)
+
+	var r0 storage.Domain
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (storage.Domain, error)); ok {
+		return rf(host)
+	}
+	if rf, ok := ret.Get(0).(func(string) storage.Domain); ok {
+		r0 = rf(host)
+	} else {
+		r0 = ret.Get(0).(storage.Domain)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(host)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetDomainDefaultURL provides a mock function with given fields: host, defaultURL
+func (_m *DomainUpdater) SetDomainDefaultURL(host string, defaultURL string) error {
+	ret := _m.Called(host, defaultURL)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(host, defaultURL)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewDomainUpdater interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewDomainUpdater creates a new instance of DomainUpdater. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewDomainUpdater(t mockConstructorTestingTNewDomainUpdater) *DomainUpdater {
+	mock := &DomainUpdater{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,69 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	storage "url-shortener/internal/storage"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// DomainVerifier is an autogenerated mock type for the DomainVerifier type
+type DomainVerifier struct {
+	mock.Mock
+}
+
+// GetDomain provides a mock function with given fields: host
+func (_m *DomainVerifier) GetDomain(host string) (storage.Domain, error) {
+	ret := _m.Called(host)
+
+	var r0 storage.Domain
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (storage.Domain, error)); ok {
+		return rf(host)
+	}
+	if rf, ok := ret.Get(0).(func(string) storage.Domain); ok {
+		r0 = rf(host)
+	} else {
+		r0 = ret.Get(0).(storage.Domain)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(host)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MarkDomainVerified provides a mock function with given fields: host, at
+func (_m *DomainVerifier) MarkDomainVerified(host string, at time.Time) error {
+	ret := _m.Called(host, at)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, time.Time) error); ok {
+		r0 = rf(host, at)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewDomainVerifier interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewDomainVerifier creates a new instance of DomainVerifier. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewDomainVerifier(t mockConstructorTestingTNewDomainVerifier) *DomainVerifier {
+	mock := &DomainVerifier{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,55 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// TXTResolver is an autogenerated mock type for the TXTResolver type
+type TXTResolver struct {
+	mock.Mock
+}
+
+// LookupTXT provides a mock function with given fields: ctx, name
+func (_m *TXTResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	ret := _m.Called(ctx, name)
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]string, error)); ok {
+		return rf(ctx, name)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []string); ok {
+		r0 = rf(ctx, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewTXTResolver interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewTXTResolver creates a new instance of TXTResolver. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewTXTResolver(t mockConstructorTestingTNewTXTResolver) *TXTResolver {
+	mock := &TXTResolver{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,55 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	storage "url-shortener/internal/storage"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ThreatLister is an autogenerated mock type for the ThreatLister type
+type ThreatLister struct {
+	mock.Mock
+}
+
+// ListLinks provides a mock function with given fields: filter
+func (_m *ThreatLister) ListLinks(filter storage.ListFilter) ([]storage.Link, error) {
+	ret := _m.Called(filter)
+
+	var r0 []storage.Link
+	var r1 error
+	if rf, ok := ret.Get(0).(func(storage.ListFilter) ([]storage.Link, error)); ok {
+		return rf(filter)
+	}
+	if rf, ok := ret.Get(0).(func(storage.ListFilter) []storage.Link); ok {
+		r0 = rf(filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]storage.Link)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(storage.ListFilter) error); ok {
+		r1 = rf(filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewThreatLister interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewThreatLister creates a new instance of ThreatLister. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewThreatLister(t mockConstructorTestingTNewThreatLister) *ThreatLister {
+	mock := &ThreatLister{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,53 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	storage "url-shortener/internal/storage"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ThreatReviewer is an autogenerated mock type for the ThreatReviewer type
+type ThreatReviewer struct {
+	mock.Mock
+}
+
+// ReviewThreat provides a mock function with given fields: domain, alias, status, actor
+func (_m *ThreatReviewer) ReviewThreat(domain string, alias string, status string, actor storage.Actor) (storage.Link, error) {
+	ret := _m.Called(domain, alias, status, actor)
+
+	var r0 storage.Link
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string, storage.Actor) (storage.Link, error)); ok {
+		return rf(domain, alias, status, actor)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string, storage.Actor) storage.Link); ok {
+		r0 = rf(domain, alias, status, actor)
+	} else {
+		r0 = ret.Get(0).(storage.Link)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string, storage.Actor) error); ok {
+		r1 = rf(domain, alias, status, actor)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewThreatReviewer interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewThreatReviewer creates a new instance of ThreatReviewer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewThreatReviewer(t mockConstructorTestingTNewThreatReviewer) *ThreatReviewer {
+	mock := &ThreatReviewer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,174 @@
+// internal/http-server/handlers/admin/threats/threats.go
+
+// Очередь ссылок, отключенных проверкой по списку опасных адресов: подресурс /admin/threats.
+// Администратор подтверждает угрозу или признает срабатывание ложным, после чего ссылка снова работает
+package threats
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+
+	"url-shortener/internal/http-server/middleware/auth"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/hostname"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/storage"
+)
+
+// структура запроса на решение по ссылке
+type ReviewRequest struct {
+	// confirmed - угроза подтверждена, ссылка остается отключенной;
+	// cleared - ложное срабатывание, ссылка снова работает
+	Status string `json:"status" validate:"required,oneof=confirmed cleared"`
+}
+
+// структура ответа на решение
+type ReviewResponse struct {
+	resp.Response
+	Link *storage.Link `json:"link,omitempty"`
+}
+
+// структура ответа со списком ссылок
+type ListResponse struct {
+	resp.Response
+	Links []storage.Link `json:"links"`
+}
+
+// ThreatLister is an interface for listing links flagged by threat screening.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=ThreatLister
+type ThreatLister interface {
+	ListLinks(filter storage.ListFilter) ([]storage.Link, error)
+}
+
+// ThreatReviewer is an interface for reviewing links flagged by threat screening.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=ThreatReviewer
+type ThreatReviewer interface {
+	ReviewThreat(domain string, alias string, status string, actor storage.Actor) (storage.Link, error)
+}
+
+// NewList Конструктор обработчика GET /admin/threats.
+// По умолчанию - очередь ссылок, ожидающих решения (flagged); GET-параметр status
+// (flagged, confirmed или cleared) выбирает ссылки с другим статусом
+func NewList(log *slog.Logger, threatLister ThreatLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.admin.threats.NewList"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		status := r.URL.Query().Get("status")
+		switch status {
+		case "":
+			status = storage.ThreatFlagged
+		case storage.ThreatFlagged, storage.ThreatConfirmed, storage.ThreatCleared:
+		default:
+			log.Info("invalid status filter", slog.String("status", status))
+
+			render.JSON(w, r, resp.Error("invalid status, expected one of: flagged, confirmed, cleared"))
+
+			return
+		}
+
+		links, err := threatLister.ListLinks(storage.ListFilter{Threat: status})
+		if err != nil {
+			log.Error("failed to list flagged links", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("internal error"))
+
+			return
+		}
+
+		render.JSON(w, r, ListResponse{
+			Response: resp.OK(),
+			Links:    links,
+		})
+	}
+}
+
+// NewReview Конструктор обработчика POST /admin/threats/{alias}.
+// Ссылка на дополнительном коротком домене адресуется GET-параметром domain
+func NewReview(log *slog.Logger, threatReviewer ThreatReviewer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.admin.threats.NewReview"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req ReviewRequest
+
+		err := render.DecodeJSON(r.Body, &req)
+		if errors.Is(err, io.EOF) {
+			log.Error("request body is empty")
+
+			render.JSON(w, r, resp.Error("empty request"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed to decode request"))
+
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			log.Info("invalid request", sl.Err(err))
+
+			render.JSON(w, r, resp.Error(err.(validator.ValidationErrors).Error()))
+
+			return
+		}
+
+		alias := chi.URLParam(r, "alias")
+		domain := hostname.Normalize(r.URL.Query().Get("domain"))
+
+		link, err := threatReviewer.ReviewThreat(domain, alias, req.Status, auth.ActorFromRequest(r))
+		if errors.Is(err, storage.ErrURLNotFound) {
+			log.Info("link not found", slog.String("alias", alias))
+
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error("not found"))
+
+			return
+		}
+		if errors.Is(err, storage.ErrNotFlagged) {
+			log.Info("link is not flagged", slog.String("alias", alias))
+
+			render.JSON(w, r, resp.Error("link is not flagged"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to review link", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("internal error"))
+
+			return
+		}
+
+		log.Info("flagged link reviewed",
+			slog.String("alias", alias),
+			slog.String("domain", domain),
+			slog.String("status", req.Status),
+		)
+
+		render.JSON(w, r, ReviewResponse{
+			Response: resp.OK(),
+			Link:     &link,
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package threats_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/handlers/admin/threats"
+	"url-shortener/internal/http-server/handlers/admin/threats/mocks"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/storage"
+)
+
+func TestReviewHandler(t *testing.T) {
+	cases := []struct {
+		name      string
+		body      string
+		mock      bool // дойдет ли запрос до хранилища
+		mockError error
+		code      int
+		respError string
+	}{
+		{
+			name: "Cleared",
+			body: `{"status": "cleared"}`,
+			mock: true,
+			code: http.StatusOK,
+		},
+		{
+			name:      "Unknown status",
+			body:      `{"status": "ignored"}`,
+			code:      http.StatusOK,
+			respError: "Key: 'ReviewRequest.Status' Error:Field validation for 'Status' failed on the 'oneof' tag",
+		},
+		{
+			name:      "Not found",
+			body:      `{"status": "confirmed"}`,
+			mock:      true,
+			mockError: storage.ErrURLNotFound,
+			code:      http.StatusNotFound,
+			respError: "not found",
+		},
+		{
+			name:      "Not flagged",
+			body:      `{"status": "cleared"}`,
+			mock:      true,
+			mockError: storage.ErrNotFlagged,
+			code:      http.StatusOK,
+			respError: "link is not flagged",
+		},
+		{
+			name:      "Storage error",
+			body:      `{"status": "cleared"}`,
+			mock:      true,
+			mockError: errors.New("disk I/O error"),
+			code:      http.StatusOK,
+			respError: "internal error",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			reviewerMock := mocks.NewThreatReviewer(t)
+			if tc.mock {
+				var req threats.ReviewRequest
+				require.NoError(t, json.Unmarshal([]byte(tc.body), &req))
+
+				reviewerMock.On("ReviewThreat", "go.example.com", "promo", req.Status, mock.Anything).
+					Return(storage.Link{Alias: "promo", Threat: &storage.LinkThreat{Status: req.Status}}, tc.mockError).
+					Once()
+			}
+
+			router := chi.NewRouter()
+			router.Post("/admin/threats/{alias}", threats.NewReview(slogdiscard.NewDiscardLogger(), reviewerMock))
+
+			req := httptest.NewRequest(http.MethodPost, "/admin/threats/promo?domain=Go.Example.com", strings.NewReader(tc.body))
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.code, rr.Code)
+
+			var resp threats.ReviewResponse
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+			require.Equal(t, tc.respError, resp.Error)
+			if tc.respError == "" {
+				require.Equal(t, storage.ThreatCleared, resp.Link.Threat.Status)
+			}
+		})
+	}
+}
+
+func TestListHandler(t *testing.T) {
+	listerMock := mocks.NewThreatLister(t)
+	listerMock.On("ListLinks", storage.ListFilter{Threat: storage.ThreatFlagged}).
+		Return([]storage.Link{{Alias: "promo"}}, nil).Once()
+
+	handler := threats.NewList(slogdiscard.NewDiscardLogger(), listerMock)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/admin/threats", nil))
+
+	var resp threats.ListResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Empty(t, resp.Error)
+	require.Len(t, resp.Links, 1)
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/admin/threats?status=unknown", nil))
+
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Equal(t, "invalid status, expected one of: flagged, confirmed, cleared", resp.Error)
+}
@@ -0,0 +1,265 @@
+// internal/http-server/handlers/keys/keys.go
+
+// API-ключи пользователя: ресурс /keys.
+// Управлять ключами можно только с JWT-токеном: ключ не может выпускать другие ключи
+package keys
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+
+	"url-shortener/internal/http-server/middleware/auth"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/apikey"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/lib/timeparse"
+	"url-shortener/internal/storage"
+)
+
+// структура запроса на создание ключа
+type CreateRequest struct {
+	Name   string   `json:"name" validate:"required,max=100"`
+	Scopes []string `json:"scopes" validate:"required,min=1,dive,oneof=links:read links:write stats:read workspaces:read workspaces:write webhooks:read webhooks:write"`
+	// Срок действия ключа (формат как у not_before ссылки). Пусто - бессрочный
+	ExpiresAt string `json:"expires_at,omitempty"`
+	Timezone  string `json:"timezone,omitempty"`
+}
+
+// структура ответа с ключом
+type Response struct {
+	resp.Response
+	// Сам ключ. Возвращается только при создании, повторно получить его нельзя
+	Key    string          `json:"key,omitempty"`
+	APIKey *storage.APIKey `json:"api_key,omitempty"`
+}
+
+// структура ответа со списком ключей
+type ListResponse struct {
+	resp.Response
+	APIKeys []storage.APIKey `json:"api_keys"`
+}
+
+// KeySaver is an interface for storing new API keys.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=KeySaver
+type KeySaver interface {
+	SaveAPIKey(key storage.APIKey, keyHash string) (int64, error)
+}
+
+// KeyLister is an interface for listing user API keys.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=KeyLister
+type KeyLister interface {
+	ListAPIKeys(uid int64) ([]storage.APIKey, error)
+}
+
+// KeyRevoker is an interface for revoking API keys.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=KeyRevoker
+type KeyRevoker interface {
+	RevokeAPIKey(uid int64, id int64, at time.Time) error
+}
+
+// NewCreate Конструктор обработчика POST /keys
+func NewCreate(log *slog.Logger, keySaver KeySaver) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.keys.NewCreate"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		uid, ok := userFromContext(w, r, log)
+		if !ok {
+			return
+		}
+
+		var req CreateRequest
+
+		err := render.DecodeJSON(r.Body, &req)
+		if errors.Is(err, io.EOF) {
+			log.Error("request body is empty")
+
+			render.JSON(w, r, resp.Error("empty request"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed to decode request"))
+
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			log.Error("invalid request", sl.Err(err))
+
+			render.JSON(w, r, resp.Error(err.Error()))
+
+			return
+		}
+
+		expiresAt, err := timeparse.ParseOptional(req.ExpiresAt, req.Timezone)
+		if err != nil {
+			log.Error("invalid expiration time", sl.Err(err))
+
+			render.JSON(w, r, resp.Error(err.Error()))
+
+			return
+		}
+
+		now := time.Now().UTC().Truncate(time.Second)
+		if expiresAt != nil && !expiresAt.After(now) {
+			render.JSON(w, r, resp.Error("expires_at must be in the future"))
+
+			return
+		}
+
+		key, display, hash, err := apikey.Generate()
+		if err != nil {
+			log.Error("failed to generate api key", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed to create api key"))
+
+			return
+		}
+
+		stored := storage.APIKey{
+			UID:       uid,
+			Name:      req.Name,
+			Prefix:    display,
+			Scopes:    req.Scopes,
+			CreatedAt: now,
+			ExpiresAt: expiresAt,
+		}
+
+		stored.ID, err = keySaver.SaveAPIKey(stored, hash)
+		if err != nil {
+			log.Error("failed to save api key", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed to create api key"))
+
+			return
+		}
+
+		// сам ключ в лог не пишем
+		log.Info("api key created", slog.Int64("id", stored.ID), slog.Int64("uid", uid))
+
+		render.JSON(w, r, Response{
+			Response: resp.OK(),
+			Key:      key,
+			APIKey:   &stored,
+		})
+	}
+}
+
+// NewList Конструктор обработчика GET /keys - ключи текущего пользователя
+func NewList(log *slog.Logger, keyLister KeyLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.keys.NewList"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		uid, ok := userFromContext(w, r, log)
+		if !ok {
+			return
+		}
+
+		keys, err := keyLister.ListAPIKeys(uid)
+		if err != nil {
+			log.Error("failed to list api keys", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("internal error"))
+
+			return
+		}
+
+		render.JSON(w, r, ListResponse{
+			Response: resp.OK(),
+			APIKeys:  keys,
+		})
+	}
+}
+
+// NewRevoke Конструктор обработчика DELETE /keys/{id}. Отозванный ключ остается в списке
+func NewRevoke(log *slog.Logger, keyRevoker KeyRevoker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.keys.NewRevoke"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		uid, ok := userFromContext(w, r, log)
+		if !ok {
+			return
+		}
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			render.JSON(w, r, resp.Error("not found"))
+
+			return
+		}
+
+		err = keyRevoker.RevokeAPIKey(uid, id, time.Now())
+		if errors.Is(err, storage.ErrAPIKeyNotFound) {
+			log.Info("api key not found", slog.Int64("id", id))
+
+			render.JSON(w, r, resp.Error("not found"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to revoke api key", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("internal error"))
+
+			return
+		}
+
+		log.Info("api key revoked", slog.Int64("id", id), slog.Int64("uid", uid))
+
+		render.JSON(w, r, resp.OK())
+	}
+}
+
+// userFromContext - UID пользователя, авторизованного JWT-токеном.
+// Если запрос не авторизован или авторизован API-ключом, отвечает клиенту сам
+func userFromContext(w http.ResponseWriter, r *http.Request, log *slog.Logger) (int64, bool) {
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		log.Info("unauthorized")
+
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, resp.Error(auth.ErrUnauthorized.Error()))
+
+		return 0, false
+	}
+
+	if principal.KeyID != 0 {
+		log.Info("api keys cannot be managed with an api key", slog.Int64("key_id", principal.KeyID))
+
+		render.Status(r, http.StatusForbidden)
+		render.JSON(w, r, resp.Error("api keys can only be managed with a user token"))
+
+		return 0, false
+	}
+
+	return principal.UID, true
+}
@@ -0,0 +1,55 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	storage "url-shortener/internal/storage"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// KeyLister is an autogenerated mock type for the KeyLister type
+type KeyLister struct {
+	mock.Mock
+}
+
+// ListAPIKeys provides a mock function with given fields: uid
+func (_m *KeyLister) ListAPIKeys(uid int64) ([]storage.APIKey, error) {
+	ret := _m.Called(uid)
+
+	var r0 []storage.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64) ([]storage.APIKey, error)); ok {
+		return rf(uid)
+	}
+	if rf, ok := ret.Get(0).(func(int64) []storage.APIKey); ok {
+		r0 = rf(uid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]storage.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(uid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewKeyLister interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewKeyLister creates a new instance of KeyLister. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewKeyLister(t mockConstructorTestingTNewKeyLister) *KeyLister {
+	mock := &KeyLister{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,43 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	time "time"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// KeyRevoker is an autogenerated mock type for the KeyRevoker type
+type KeyRevoker struct {
+	mock.Mock
+}
+
+// RevokeAPIKey provides a mock function with given fields: uid, id, at
+func (_m *KeyRevoker) RevokeAPIKey(uid int64, id int64, at time.Time) error {
+	ret := _m.Called(uid, id, at)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64, int64, time.Time) error); ok {
+		r0 = rf(uid, id, at)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewKeyRevoker interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewKeyRevoker creates a new instance of KeyRevoker. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewKeyRevoker(t mockConstructorTestingTNewKeyRevoker) *KeyRevoker {
+	mock := &KeyRevoker{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,53 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	storage "url-shortener/internal/storage"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// KeySaver is an autogenerated mock type for the KeySaver type
+type KeySaver struct {
+	mock.Mock
+}
+
+// SaveAPIKey provides a mock function with given fields: key, keyHash
+func (_m *KeySaver) SaveAPIKey(key storage.APIKey, keyHash string) (int64, error) {
+	ret := _m.Called(key, keyHash)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(storage.APIKey, string) (int64, error)); ok {
+		return rf(key, keyHash)
+	}
+	if rf, ok := ret.Get(0).(func(storage.APIKey, string) int64); ok {
+		r0 = rf(key, keyHash)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(storage.APIKey, string) error); ok {
+		r1 = rf(key, keyHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewKeySaver interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewKeySaver creates a new instance of KeySaver. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewKeySaver(t mockConstructorTestingTNewKeySaver) *KeySaver {
+	mock := &KeySaver{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
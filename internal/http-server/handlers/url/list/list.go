@@ -0,0 +1,124 @@
+// internal/http-server/handlers/url/list/list.go
+
+package list
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+
+	"url-shortener/internal/http-server/middleware/auth"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/hostname"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/storage"
+)
+
+// структура ответа
+type Response struct {
+	resp.Response
+	Links []storage.Link `json:"links"`
+}
+
+// URLLister is an interface for listing saved links.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=URLLister
+type URLLister interface {
+	ListLinks(filter storage.ListFilter) ([]storage.Link, error)
+	GetMember(workspaceID int64, uid int64) (storage.Member, error)
+}
+
+// New Конструктор обработчика списка ссылок.
+// Необязательный GET-параметр state фильтрует ссылки по окну активности:
+// active, scheduled или ended (deleted - удаленные ссылки, которые еще можно восстановить), параметр domain - по короткому домену,
+// параметр workspace_id - по рабочему пространству, параметр health - по доступности страницы перехода
+// (ok, failing или broken - отчет о сломанных ссылках).
+// Пользователь, авторизованный JWT-токеном, видит только общие ссылки и ссылки своих пространств
+func New(log *slog.Logger, urlLister URLLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.list.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		filter := storage.ListFilter{
+			State:  r.URL.Query().Get("state"),
+			Domain: hostname.Normalize(r.URL.Query().Get("domain")),
+			Health: r.URL.Query().Get("health"),
+		}
+
+		if v := r.URL.Query().Get("workspace_id"); v != "" {
+			id, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || id < 0 {
+				log.Info("invalid workspace filter", slog.String("workspace_id", v))
+
+				render.JSON(w, r, resp.Error("invalid workspace_id"))
+
+				return
+			}
+			filter.WorkspaceID = id
+		}
+
+		switch filter.State {
+		case "", storage.StateActive, storage.StateScheduled, storage.StateEnded, storage.StateDeleted:
+		default:
+			log.Info("invalid state filter", slog.String("state", filter.State))
+
+			render.JSON(w, r, resp.Error("invalid state, expected one of: active, scheduled, ended, deleted"))
+
+			return
+		}
+
+		switch filter.Health {
+		case "", storage.HealthOK, storage.HealthFailing, storage.HealthBroken:
+		default:
+			log.Info("invalid health filter", slog.String("health", filter.Health))
+
+			render.JSON(w, r, resp.Error("invalid health, expected one of: ok, failing, broken"))
+
+			return
+		}
+
+		if err := auth.Authorize(r.Context(), urlLister, filter.WorkspaceID, storage.RoleViewer); err != nil {
+			if status := auth.AccessStatus(err); status != 0 {
+				log.Info("access denied", slog.Int64("workspace_id", filter.WorkspaceID), sl.Err(err))
+
+				render.Status(r, status)
+				render.JSON(w, r, resp.Error(err.Error()))
+
+				return
+			}
+
+			log.Error("failed to check access", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("internal error"))
+
+			return
+		}
+
+		if uid, ok := auth.UIDFromContext(r.Context()); ok && !auth.IsAdminFromContext(r.Context()) {
+			filter.MemberUID = uid
+		}
+
+		links, err := urlLister.ListLinks(filter)
+		if err != nil {
+			log.Error("failed to list links", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("internal error"))
+
+			return
+		}
+
+		log.Info("links listed", slog.Int("count", len(links)))
+
+		render.JSON(w, r, Response{
+			Response: resp.OK(),
+			Links:    links,
+		})
+	}
+}
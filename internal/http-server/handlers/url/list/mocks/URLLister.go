@@ -0,0 +1,79 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	storage "url-shortener/internal/storage"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// URLLister is an autogenerated mock type for the URLLister type
+type URLLister struct {
+	mock.Mock
+}
+
+// GetMember provides a mock function with given fields: workspaceID, uid
+func (_m *URLLister) GetMember(workspaceID int64, uid int64) (storage.Member, error) {
+	ret := _m.Called(workspaceID, uid)
+
+	var r0 storage.Member
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64, int64) (storage.Member, error)); ok {
+		return rf(workspaceID, uid)
+	}
+	if rf, ok := ret.Get(0).(func(int64, int64) storage.Member); ok {
+		r0 = rf(workspaceID, uid)
+	} else {
+		r0 = ret.Get(0).(storage.Member)
+	}
+
+	if rf, ok := ret.Get(1).(func(int64, int64) error); ok {
+		r1 = rf(workspaceID, uid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListLinks provides a mock function with given fields: filter
+func (_m *URLLister) ListLinks(filter storage.ListFilter) ([]storage.Link, error) {
+	ret := _m.Called(filter)
+
+	var r0 []storage.Link
+	var r1 error
+	if rf, ok := ret.Get(0).(func(storage.ListFilter) ([]storage.Link, error)); ok {
+		return rf(filter)
+	}
+	if rf, ok := ret.Get(0).(func(storage.ListFilter) []storage.Link); ok {
+		r0 = rf(filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]storage.Link)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(storage.ListFilter) error); ok {
+		r1 = rf(filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewURLLister interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewURLLister creates a new instance of URLLister. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewURLLister(t mockConstructorTestingTNewURLLister) *URLLister {
+	mock := &URLLister{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
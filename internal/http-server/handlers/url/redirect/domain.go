@@ -0,0 +1,37 @@
+// internal/http-server/handlers/url/redirect/domain.go
+
+package redirect
+
+import (
+	"errors"
+	"fmt"
+
+	"url-shortener/internal/lib/hostname"
+	"url-shortener/internal/storage"
+)
+
+// DomainGetter is an interface for getting short domain by host.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=DomainGetter
+type DomainGetter interface {
+	GetDomain(host string) (storage.Domain, error)
+}
+
+// resolveDomain определяет короткий домен по заголовку Host.
+// Незарегистрированные и неподтвержденные домены обслуживаются как основной домен
+// (пустой storage.Domain)
+func resolveDomain(domainGetter DomainGetter, host string) (storage.Domain, error) {
+	domain, err := domainGetter.GetDomain(hostname.Normalize(host))
+	if errors.Is(err, storage.ErrDomainNotFound) {
+		return storage.Domain{}, nil
+	}
+	if err != nil {
+		return storage.Domain{}, fmt.Errorf("resolve domain: %w", err)
+	}
+
+	if !domain.Verified() {
+		return storage.Domain{}, nil
+	}
+
+	return domain, nil
+}
@@ -0,0 +1,39 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import mock "github.com/stretchr/testify/mock"
+
+// ClickRecorder is an autogenerated mock type for the ClickRecorder type
+type ClickRecorder struct {
+	mock.Mock
+}
+
+// RecordClick provides a mock function with given fields: linkID, targetID
+func (_m *ClickRecorder) RecordClick(linkID int64, targetID int64) error {
+	ret := _m.Called(linkID, targetID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64, int64) error); ok {
+		r0 = rf(linkID, targetID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewClickRecorder interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewClickRecorder creates a new instance of ClickRecorder. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewClickRecorder(t mockConstructorTestingTNewClickRecorder) *ClickRecorder {
+	mock := &ClickRecorder{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
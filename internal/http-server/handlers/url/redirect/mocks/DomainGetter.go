@@ -0,0 +1,53 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	mock "github.com/stretchr/testify/mock"
+
+	storage "url-shortener/internal/storage"
+)
+
+// DomainGetter is an autogenerated mock type for the DomainGetter type
+type DomainGetter struct {
+	mock.Mock
+}
+
+// GetDomain provides a mock function with given fields: host
+func (_m *DomainGetter) GetDomain(host string) (storage.Domain, error) {
+	ret := _m.Called(host)
+
+	var r0 storage.Domain
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (storage.Domain, error)); ok {
+		return rf(host)
+	}
+	if rf, ok := ret.Get(0).(func(string) storage.Domain); ok {
+		r0 = rf(host)
+	} else {
+		r0 = ret.Get(0).(storage.Domain)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(host)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewDomainGetter interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewDomainGetter creates a new instance of DomainGetter. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewDomainGetter(t mockConstructorTestingTNewDomainGetter) *DomainGetter {
+	mock := &DomainGetter{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -6,13 +6,20 @@ import (
 	"errors"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
 
+	"url-shortener/internal/http-server/templates"
 	resp "url-shortener/internal/lib/api/response"
 	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/lib/threatlist"
+	"url-shortener/internal/lib/urlsafety"
+	"url-shortener/internal/lib/utm"
 	"url-shortener/internal/storage"
 )
 
@@ -20,10 +27,28 @@ import (
 //
 //go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=URLGetter
 type URLGetter interface {
-	GetURL(alias string) (string, error)
+	GetLink(domain string, alias string) (storage.Link, error)
 }
 
-func New(log *slog.Logger, urlGetter URLGetter) http.HandlerFunc {
+// ClickRecorder is an interface for counting link clicks.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=ClickRecorder
+type ClickRecorder interface {
+	RecordClick(linkID int64, targetID int64) error
+}
+
+// New Конструктор обработчика редиректа.
+// Ссылка ищется по паре (домен из заголовка Host, алиас).
+// geo используется для правил по стране и может быть nil.
+// defaultQueryPolicy применяется к ссылкам, у которых не задана своя политика переноса параметров
+func New(
+	log *slog.Logger,
+	urlGetter URLGetter,
+	clickRecorder ClickRecorder,
+	domainGetter DomainGetter,
+	geo CountryResolver,
+	defaultQueryPolicy string,
+) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.redirect.New"
 
@@ -36,6 +61,10 @@ func New(log *slog.Logger, urlGetter URLGetter) http.HandlerFunc {
 		// получать GET-параметры по их именам.
 		// Имена определяются при добавлении хэндлера в роутер, это будет ниже.
 		alias := chi.URLParam(r, "alias")
+
+		// "+" в конце алиаса (как в bit.ly) - показать страницу предпросмотра вместо перехода
+		alias, preview := strings.CutSuffix(alias, "+")
+
 		if alias == "" {
 			log.Info("alias is empty")
 
@@ -44,9 +73,28 @@ func New(log *slog.Logger, urlGetter URLGetter) http.HandlerFunc {
 			return
 		}
 
-		// Находим URL по алиасу в БД
-		resURL, err := urlGetter.GetURL(alias)
+		// Определяем, к какому короткому домену пришел запрос
+		domain, err := resolveDomain(domainGetter, r.Host)
+		if err != nil {
+			log.Error("failed to resolve domain", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("internal error"))
+
+			return
+		}
+
+		// Находим URL по домену и алиасу в БД
+		link, err := urlGetter.GetLink(domain.Host, alias)
 		if errors.Is(err, storage.ErrURLNotFound) {
+			// У домена может быть адрес по умолчанию для неизвестных алиасов
+			if domain.DefaultURL != "" {
+				log.Info("url not found, redirect to domain default", "alias", alias, "domain", domain.Host)
+
+				http.Redirect(w, r, domain.DefaultURL, http.StatusFound)
+
+				return
+			}
+
 			// Не нашли URL, сообщаем об этом клиенту
 			log.Info("url not found", "alias", alias)
 
@@ -63,16 +111,176 @@ func New(log *slog.Logger, urlGetter URLGetter) http.HandlerFunc {
 			return
 		}
 
+		// Ссылка на адрес из списка опасных адресов отключена до решения администратора.
+		// Переход не выполняется и не считается
+		if link.Threat.Blocked() {
+			log.Warn("link is disabled by threat screening",
+				slog.String("alias", alias),
+				slog.String("threat", link.Threat.Type),
+			)
+
+			if err := renderBlocked(w, link); err != nil {
+				log.Error("failed to render blocked page", sl.Err(err))
+
+				render.JSON(w, r, resp.Error("internal error"))
+			}
+
+			return
+		}
+
+		// Проверяем окно активности ссылки
+		resURL := link.URL
+		targetID := int64(0) // выбранный вариант A/B-теста
+		switch link.StateAt(time.Now()) {
+		case storage.StateScheduled:
+			// Ссылка еще не запущена - ведем себя так, как будто ее нет
+			log.Info("link is not active yet", slog.String("alias", alias))
+
+			render.JSON(w, r, resp.Error("not found"))
+
+			return
+		case storage.StateActive:
+			// Умный редирект: первое подходящее правило задает адрес перехода,
+			// иначе трафик распределяется между вариантами A/B-теста (если они есть)
+			if rule, ok := matchRule(link.Rules, clientFromRequest(r, geo)); ok {
+				resURL = rule.TargetURL
+			} else if len(link.Targets) > 0 {
+				target := chooseTarget(w, r, link)
+				resURL, targetID = target.URL, target.ID
+			}
+		case storage.StateEnded:
+			if link.FallbackURL == "" {
+				log.Info("link has ended", slog.String("alias", alias))
+
+				render.JSON(w, r, resp.Error("link expired"))
+
+				return
+			}
+
+			resURL = link.FallbackURL
+		}
+
+		// Переносим параметры запроса короткой ссылки и добавляем UTM-метки
+		policy := link.QueryPolicy
+		if policy == "" {
+			policy = defaultQueryPolicy
+		}
+
+		resURL, err = utm.Apply(resURL, utm.Tags{
+			Source:   link.UTMSource,
+			Medium:   link.UTMMedium,
+			Campaign: link.UTMCampaign,
+		}, r.URL.Query(), policy)
+		if err != nil {
+			log.Error("failed to build redirect url", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("internal error"))
+
+			return
+		}
+
 		log.Info("got url", slog.String("url", resURL))
 
+		// Предпросмотр по "+" - это не переход, его не считаем
+		if !preview {
+			// Ошибка подсчета не должна мешать переходу
+			if err := clickRecorder.RecordClick(link.ID, targetID); err != nil {
+				log.Error("failed to record click", sl.Err(err))
+			}
+		}
+
+		if preview || link.Interstitial {
+			if err := renderPreview(w, link, resURL); err != nil {
+				log.Error("failed to render preview page", sl.Err(err))
+
+				render.JSON(w, r, resp.Error("internal error"))
+			}
+
+			return
+		}
+
 		// Делаем редирект на найденный URL
-		http.Redirect(w, r, resURL, http.StatusFound)
-		// В последней строчке делаем редирект со статусом http.StatusFound — код HTTP 302. Он обычно используется для временных перенаправлений, а не постоянных, за которые отвечает 301.
-		// Наш сервис может перенаправлять на разные URL в зависимости от ситуации
-		// (мы ведь можем удалить или изменить сохраненный URL),
-		// поэтому есть смысл использовать именно http.StatusFound.
-		// Это важно для систем кэширования и поисковых машин —
-		// они обычно кэшируют редиректы с кодом 301, то есть считают их постоянными.
-		// Нам такое поведение не нужно.
+		http.Redirect(w, r, resURL, redirectCode(link))
+	}
+}
+
+// redirectCode возвращает HTTP-код редиректа для ссылки.
+// По умолчанию используется http.StatusFound — код HTTP 302. Он обычно используется для временных перенаправлений, а не постоянных, за которые отвечает 301.
+// Наш сервис может перенаправлять на разные URL в зависимости от ситуации
+// (мы ведь можем удалить или изменить сохраненный URL),
+// поэтому есть смысл использовать именно http.StatusFound.
+// Это важно для систем кэширования и поисковых машин —
+// они обычно кэшируют редиректы с кодом 301, то есть считают их постоянными.
+// Если такое поведение нужно (ссылка никогда не изменится), код можно задать для ссылки явно
+func redirectCode(link storage.Link) int {
+	switch link.RedirectCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return link.RedirectCode
+	default:
+		return http.StatusFound
 	}
 }
+
+// previewPage - данные для шаблона preview.html
+type previewPage struct {
+	Alias     string
+	Title     string
+	Host      string
+	TargetURL string
+	Warnings  []string
+}
+
+// blockedPage - данные для шаблона blocked.html
+type blockedPage struct {
+	Alias  string
+	Host   string
+	Threat string
+}
+
+// renderBlocked отдает страницу-предупреждение об отключенной ссылке. Адрес перехода не раскрывается,
+// только хост, чтобы по странице нельзя было перейти на опасный адрес
+func renderBlocked(w http.ResponseWriter, link storage.Link) error {
+	host := ""
+	if u, err := url.Parse(link.URL); err == nil {
+		host = u.Hostname()
+	}
+	if host == "" {
+		host = "a site"
+	}
+
+	return templates.Render(w, http.StatusForbidden, "blocked.html", blockedPage{
+		Alias:  link.Alias,
+		Host:   host,
+		Threat: threatDescription(link.Threat.Type),
+	})
+}
+
+// threatDescription - тип угрозы Safe Browsing для пользователя
+func threatDescription(threatType string) string {
+	switch threatType {
+	case threatlist.Malware:
+		return "distributing malware"
+	case threatlist.SocialEngineering:
+		return "a deceptive (phishing) site"
+	case threatlist.UnwantedSoftware:
+		return "distributing unwanted software"
+	default:
+		return "harmful"
+	}
+}
+
+// renderPreview отдает страницу "вы переходите на ..." с адресом перехода и предупреждениями
+func renderPreview(w http.ResponseWriter, link storage.Link, targetURL string) error {
+	host := targetURL
+	if u, err := url.Parse(targetURL); err == nil && u.Host != "" {
+		host = u.Hostname()
+	}
+
+	return templates.Render(w, http.StatusOK, "preview.html", previewPage{
+		Alias:     link.Alias,
+		Title:     link.Title,
+		Host:      host,
+		TargetURL: targetURL,
+		Warnings:  urlsafety.Warnings(targetURL),
+	})
+}
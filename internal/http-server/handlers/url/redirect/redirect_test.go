@@ -0,0 +1,470 @@
+package redirect_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/handlers/url/redirect"
+	"url-shortener/internal/http-server/handlers/url/redirect/mocks"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/storage"
+)
+
+func TestRedirectHandler_ActivationWindow(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	cases := []struct {
+		name     string
+		link     storage.Link
+		code     int    // ожидаемый код ответа
+		location string // куда должен вести редирект
+	}{
+		{
+			name:     "No window",
+			link:     storage.Link{URL: "https://example.com"},
+			code:     http.StatusFound,
+			location: "https://example.com",
+		},
+		{
+			name:     "Inside window",
+			link:     storage.Link{URL: "https://example.com", NotBefore: &past, NotAfter: &future},
+			code:     http.StatusFound,
+			location: "https://example.com",
+		},
+		{
+			name: "Scheduled",
+			link: storage.Link{URL: "https://example.com", NotBefore: &future, FallbackURL: "https://fallback.com"},
+			code: http.StatusOK, // ответ с ошибкой "not found"
+		},
+		{
+			name:     "Ended with fallback",
+			link:     storage.Link{URL: "https://example.com", NotAfter: &past, FallbackURL: "https://fallback.com"},
+			code:     http.StatusFound,
+			location: "https://fallback.com",
+		},
+		{
+			name: "Ended without fallback",
+			link: storage.Link{URL: "https://example.com", NotAfter: &past},
+			code: http.StatusOK, // ответ с ошибкой "link expired"
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			urlGetterMock := mocks.NewURLGetter(t)
+			urlGetterMock.On("GetLink", "", "promo").Return(tc.link, nil).Once()
+
+			// переход засчитывается только при успешном редиректе
+			clickRecorderMock := mocks.NewClickRecorder(t)
+			if tc.code == http.StatusFound {
+				clickRecorderMock.On("RecordClick", tc.link.ID, int64(0)).Return(nil).Once()
+			}
+
+			router := chi.NewRouter()
+			router.Get("/{alias}", redirect.New(slogdiscard.NewDiscardLogger(), urlGetterMock, clickRecorderMock, noDomains(t), nil, ""))
+
+			req := httptest.NewRequest(http.MethodGet, "/promo", nil)
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.code, rr.Code)
+			require.Equal(t, tc.location, rr.Header().Get("Location"))
+		})
+	}
+}
+
+// countryByIP - фейковая GeoIP-база для тестов
+type countryByIP map[string]string
+
+func (c countryByIP) Country(ip netip.Addr) string {
+	return c[ip.String()]
+}
+
+func TestRedirectHandler_Rules(t *testing.T) {
+	link := storage.Link{
+		URL: "https://example.com",
+		Rules: []storage.Rule{
+			{Platform: "ios", TargetURL: "https://apps.apple.com/app"},
+			{Platform: "android", Country: "DE", TargetURL: "https://play.google.com/de"},
+			{Language: "de", TargetURL: "https://example.com/de"},
+		},
+	}
+
+	geo := countryByIP{"10.0.0.1": "DE", "10.0.0.2": "FR"}
+
+	const (
+		iPhone  = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X)"
+		android = "Mozilla/5.0 (Linux; Android 14; Pixel 8)"
+		desktop = "Mozilla/5.0 (X11; Linux x86_64)"
+	)
+
+	cases := []struct {
+		name     string
+		ua       string
+		lang     string
+		ip       string
+		location string
+	}{
+		{name: "iOS", ua: iPhone, ip: "10.0.0.2", location: "https://apps.apple.com/app"},
+		{name: "Android in DE", ua: android, ip: "10.0.0.1", location: "https://play.google.com/de"},
+		{name: "Android in FR", ua: android, ip: "10.0.0.2", location: "https://example.com"},
+		{name: "German language", ua: desktop, lang: "en;q=0.5, de-AT", ip: "10.0.0.2", location: "https://example.com/de"},
+		{name: "No match", ua: desktop, lang: "en-US,de;q=0.8", ip: "10.0.0.1", location: "https://example.com"},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			urlGetterMock := mocks.NewURLGetter(t)
+			urlGetterMock.On("GetLink", "", "promo").Return(link, nil).Once()
+
+			clickRecorderMock := mocks.NewClickRecorder(t)
+			clickRecorderMock.On("RecordClick", link.ID, int64(0)).Return(nil).Once()
+
+			router := chi.NewRouter()
+			router.Get("/{alias}", redirect.New(slogdiscard.NewDiscardLogger(), urlGetterMock, clickRecorderMock, noDomains(t), geo, ""))
+
+			req := httptest.NewRequest(http.MethodGet, "/promo", nil)
+			req.RemoteAddr = tc.ip + ":12345"
+			req.Header.Set("User-Agent", tc.ua)
+			req.Header.Set("Accept-Language", tc.lang)
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, req)
+
+			require.Equal(t, http.StatusFound, rr.Code)
+			require.Equal(t, tc.location, rr.Header().Get("Location"))
+		})
+	}
+}
+
+func TestRedirectHandler_Split(t *testing.T) {
+	targets := []storage.Target{
+		{ID: 11, URL: "https://example.com/a", Weight: 1},
+		{ID: 12, URL: "https://example.com/b", Weight: 3},
+	}
+
+	t.Run("Cookie", func(t *testing.T) {
+		link := storage.Link{ID: 7, URL: "https://example.com", Targets: targets, Sticky: storage.StickyCookie}
+
+		urlGetterMock := mocks.NewURLGetter(t)
+		urlGetterMock.On("GetLink", "", "ab").Return(link, nil).Twice()
+
+		clickRecorderMock := mocks.NewClickRecorder(t)
+		clickRecorderMock.On("RecordClick", int64(7), int64(12)).Return(nil).Twice()
+
+		router := chi.NewRouter()
+		router.Get("/{alias}", redirect.New(slogdiscard.NewDiscardLogger(), urlGetterMock, clickRecorderMock, noDomains(t), nil, ""))
+
+		// клиент с cookie варианта B всегда попадает на B
+		for i := 0; i < 2; i++ {
+			req := httptest.NewRequest(http.MethodGet, "/ab", nil)
+			req.AddCookie(&http.Cookie{Name: "ab_7", Value: "12"})
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, req)
+
+			require.Equal(t, http.StatusFound, rr.Code)
+			require.Equal(t, "https://example.com/b", rr.Header().Get("Location"))
+		}
+	})
+
+	t.Run("New cookie", func(t *testing.T) {
+		link := storage.Link{ID: 7, URL: "https://example.com", Targets: targets, Sticky: storage.StickyCookie}
+
+		urlGetterMock := mocks.NewURLGetter(t)
+		urlGetterMock.On("GetLink", "", "ab").Return(link, nil).Once()
+
+		clickRecorderMock := mocks.NewClickRecorder(t)
+		clickRecorderMock.On("RecordClick", int64(7), mock.AnythingOfType("int64")).Return(nil).Once()
+
+		router := chi.NewRouter()
+		router.Get("/{alias}", redirect.New(slogdiscard.NewDiscardLogger(), urlGetterMock, clickRecorderMock, noDomains(t), nil, ""))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/ab", nil))
+
+		require.Equal(t, http.StatusFound, rr.Code)
+
+		cookies := rr.Result().Cookies()
+		require.Len(t, cookies, 1)
+		require.Equal(t, "ab_7", cookies[0].Name)
+
+		// cookie указывает на тот же вариант, на который был выполнен переход
+		expected := map[string]string{"11": "https://example.com/a", "12": "https://example.com/b"}
+		require.Equal(t, expected[cookies[0].Value], rr.Header().Get("Location"))
+	})
+
+	t.Run("Hash", func(t *testing.T) {
+		link := storage.Link{ID: 8, URL: "https://example.com", Targets: targets, Sticky: storage.StickyHash}
+
+		urlGetterMock := mocks.NewURLGetter(t)
+		urlGetterMock.On("GetLink", "", "ab").Return(link, nil)
+
+		clickRecorderMock := mocks.NewClickRecorder(t)
+		clickRecorderMock.On("RecordClick", int64(8), mock.AnythingOfType("int64")).Return(nil)
+
+		router := chi.NewRouter()
+		router.Get("/{alias}", redirect.New(slogdiscard.NewDiscardLogger(), urlGetterMock, clickRecorderMock, noDomains(t), nil, ""))
+
+		// один и тот же клиент всегда получает один и тот же вариант
+		var first string
+		for i := 0; i < 5; i++ {
+			req := httptest.NewRequest(http.MethodGet, "/ab", nil)
+			req.RemoteAddr = "10.1.2.3:4444"
+			req.Header.Set("User-Agent", "test-agent")
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, req)
+
+			require.Equal(t, http.StatusFound, rr.Code)
+			if i == 0 {
+				first = rr.Header().Get("Location")
+			}
+			require.Equal(t, first, rr.Header().Get("Location"))
+		}
+	})
+}
+
+func TestRedirectHandler_CodeAndPreview(t *testing.T) {
+	cases := []struct {
+		name    string
+		path    string
+		link    storage.Link
+		code    int
+		counted bool   // должен ли переход быть засчитан
+		body    string // фрагмент страницы предпросмотра
+	}{
+		{
+			name:    "Default code",
+			path:    "/promo",
+			link:    storage.Link{Alias: "promo", URL: "https://example.com"},
+			code:    http.StatusFound,
+			counted: true,
+		},
+		{
+			name:    "Permanent redirect",
+			path:    "/promo",
+			link:    storage.Link{Alias: "promo", URL: "https://example.com", RedirectCode: http.StatusPermanentRedirect},
+			code:    http.StatusPermanentRedirect,
+			counted: true,
+		},
+		{
+			name: "Preview by plus",
+			path: "/promo+",
+			link: storage.Link{Alias: "promo", URL: "http://example.com/setup.exe", Title: "Installer"},
+			code: http.StatusOK,
+			body: "the link downloads an executable file",
+		},
+		{
+			name:    "Interstitial",
+			path:    "/promo",
+			link:    storage.Link{Alias: "promo", URL: "https://example.com", Interstitial: true, Title: "Spring sale"},
+			code:    http.StatusOK,
+			counted: true,
+			body:    "Spring sale",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			urlGetterMock := mocks.NewURLGetter(t)
+			urlGetterMock.On("GetLink", "", "promo").Return(tc.link, nil).Once()
+
+			clickRecorderMock := mocks.NewClickRecorder(t)
+			if tc.counted {
+				clickRecorderMock.On("RecordClick", tc.link.ID, int64(0)).Return(nil).Once()
+			}
+
+			router := chi.NewRouter()
+			router.Get("/{alias}", redirect.New(slogdiscard.NewDiscardLogger(), urlGetterMock, clickRecorderMock, noDomains(t), nil, ""))
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			require.Equal(t, tc.code, rr.Code)
+			if tc.body != "" {
+				require.Contains(t, rr.Header().Get("Content-Type"), "text/html")
+				require.Contains(t, rr.Body.String(), tc.body)
+				require.Contains(t, rr.Body.String(), tc.link.URL)
+			}
+		})
+	}
+}
+
+// noDomains - дополнительных коротких доменов нет, все запросы идут к основному домену
+func noDomains(t *testing.T) *mocks.DomainGetter {
+	domainGetterMock := mocks.NewDomainGetter(t)
+	domainGetterMock.On("GetDomain", mock.AnythingOfType("string")).
+		Return(storage.Domain{}, storage.ErrDomainNotFound).Maybe()
+
+	return domainGetterMock
+}
+
+func TestRedirectHandler_Domains(t *testing.T) {
+	verifiedAt := time.Now().Add(-time.Hour)
+
+	cases := []struct {
+		name     string
+		domain   storage.Domain // домен из заголовка Host, пустой Host - домен не зарегистрирован
+		found    bool           // есть ли алиас в пространстве имен домена
+		code     int            // ожидаемый код ответа
+		location string         // куда должен вести редирект
+	}{
+		{
+			name:     "Verified domain",
+			domain:   storage.Domain{Host: "go.example.com", VerifiedAt: &verifiedAt},
+			found:    true,
+			code:     http.StatusFound,
+			location: "https://example.com",
+		},
+		{
+			name:     "Unknown alias with default url",
+			domain:   storage.Domain{Host: "go.example.com", VerifiedAt: &verifiedAt, DefaultURL: "https://home.example.com"},
+			code:     http.StatusFound,
+			location: "https://home.example.com",
+		},
+		{
+			name:   "Unknown alias without default url",
+			domain: storage.Domain{Host: "go.example.com", VerifiedAt: &verifiedAt},
+			code:   http.StatusOK, // ответ с ошибкой "not found"
+		},
+		{
+			// неподтвержденный домен обслуживается как основной
+			name:     "Unverified domain",
+			domain:   storage.Domain{Host: "go.example.com", DefaultURL: "https://home.example.com"},
+			found:    true,
+			code:     http.StatusFound,
+			location: "https://example.com",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			domainGetterMock := mocks.NewDomainGetter(t)
+			domainGetterMock.On("GetDomain", "go.example.com").Return(tc.domain, nil).Once()
+
+			namespace := tc.domain.Host
+			if !tc.domain.Verified() {
+				namespace = ""
+			}
+
+			link := storage.Link{ID: 1, Domain: namespace, Alias: "promo", URL: "https://example.com"}
+
+			urlGetterMock := mocks.NewURLGetter(t)
+			if tc.found {
+				urlGetterMock.On("GetLink", namespace, "promo").Return(link, nil).Once()
+			} else {
+				urlGetterMock.On("GetLink", namespace, "promo").Return(storage.Link{}, storage.ErrURLNotFound).Once()
+			}
+
+			clickRecorderMock := mocks.NewClickRecorder(t)
+			if tc.found {
+				clickRecorderMock.On("RecordClick", link.ID, int64(0)).Return(nil).Once()
+			}
+
+			router := chi.NewRouter()
+			router.Get("/{alias}", redirect.New(slogdiscard.NewDiscardLogger(), urlGetterMock, clickRecorderMock, domainGetterMock, nil, ""))
+
+			req := httptest.NewRequest(http.MethodGet, "/promo", nil)
+			req.Host = "Go.Example.com:8082"
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.code, rr.Code)
+			require.Equal(t, tc.location, rr.Header().Get("Location"))
+		})
+	}
+}
+
+func TestRedirectHandler_Threat(t *testing.T) {
+	cases := []struct {
+		name   string
+		path   string
+		threat *storage.LinkThreat
+		code   int
+	}{
+		{
+			name:   "Flagged",
+			path:   "/promo",
+			threat: &storage.LinkThreat{Status: storage.ThreatFlagged, Type: "SOCIAL_ENGINEERING"},
+			code:   http.StatusForbidden,
+		},
+		{
+			name:   "Flagged preview",
+			path:   "/promo+",
+			threat: &storage.LinkThreat{Status: storage.ThreatFlagged, Type: "SOCIAL_ENGINEERING"},
+			code:   http.StatusForbidden,
+		},
+		{
+			name:   "Confirmed",
+			path:   "/promo",
+			threat: &storage.LinkThreat{Status: storage.ThreatConfirmed, Type: "MALWARE"},
+			code:   http.StatusForbidden,
+		},
+		{
+			name:   "Cleared by admin",
+			path:   "/promo",
+			threat: &storage.LinkThreat{Status: storage.ThreatCleared, Type: "MALWARE"},
+			code:   http.StatusFound,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			link := storage.Link{ID: 7, Alias: "promo", URL: "https://login.phish.example/account?id=1", Threat: tc.threat}
+
+			urlGetterMock := mocks.NewURLGetter(t)
+			urlGetterMock.On("GetLink", "", "promo").Return(link, nil).Once()
+
+			// переход по отключенной ссылке не выполняется и не считается
+			clickRecorderMock := mocks.NewClickRecorder(t)
+			if tc.code == http.StatusFound {
+				clickRecorderMock.On("RecordClick", link.ID, int64(0)).Return(nil).Once()
+			}
+
+			router := chi.NewRouter()
+			router.Get("/{alias}", redirect.New(slogdiscard.NewDiscardLogger(), urlGetterMock, clickRecorderMock, noDomains(t), nil, ""))
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			require.Equal(t, tc.code, rr.Code)
+			if tc.code == http.StatusForbidden {
+				require.Empty(t, rr.Header().Get("Location"))
+				require.Contains(t, rr.Body.String(), "This link has been disabled")
+				require.Contains(t, rr.Body.String(), "login.phish.example")
+				require.NotContains(t, rr.Body.String(), link.URL)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+// internal/http-server/handlers/url/redirect/rules.go
+
+package redirect
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"url-shortener/internal/lib/useragent"
+	"url-shortener/internal/storage"
+)
+
+// CountryResolver определяет страну клиента по IP-адресу
+type CountryResolver interface {
+	Country(ip netip.Addr) string
+}
+
+// client - параметры клиента, по которым проверяются правила
+type client struct {
+	platform string
+	language string // основной язык клиента в нижнем регистре, например "de-at"
+	country  string
+}
+
+func clientFromRequest(r *http.Request, geo CountryResolver) client {
+	c := client{
+		platform: useragent.Platform(r.UserAgent()),
+		language: primaryLanguage(r.Header.Get("Accept-Language")),
+	}
+
+	if geo != nil {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+		if ip, err := netip.ParseAddr(host); err == nil {
+			c.country = geo.Country(ip)
+		}
+	}
+
+	return c
+}
+
+// matchRule возвращает первое правило, которому соответствует клиент
+func matchRule(rules []storage.Rule, c client) (storage.Rule, bool) {
+	for _, rule := range rules {
+		if rule.Platform != "" && rule.Platform != c.platform {
+			continue
+		}
+		if rule.Country != "" && !strings.EqualFold(rule.Country, c.country) {
+			continue
+		}
+		if rule.Language != "" && !languageMatches(rule.Language, c.language) {
+			continue
+		}
+
+		return rule, true
+	}
+
+	return storage.Rule{}, false
+}
+
+// languageMatches: тег правила "de" подходит для "de" и "de-at", а "pt-br" - только для "pt-br"
+func languageMatches(ruleLang, clientLang string) bool {
+	ruleLang = strings.ToLower(ruleLang)
+
+	return clientLang == ruleLang || strings.HasPrefix(clientLang, ruleLang+"-")
+}
+
+// primaryLanguage возвращает язык с наибольшим весом из заголовка Accept-Language
+func primaryLanguage(header string) string {
+	var (
+		best  string
+		bestQ = 0.0
+	)
+
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+
+		// при равных весах побеждает тег, указанный раньше
+		if q > bestQ {
+			best, bestQ = tag, q
+		}
+	}
+
+	return best
+}
@@ -0,0 +1,101 @@
+// internal/http-server/handlers/url/redirect/split.go
+
+package redirect
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+
+	"url-shortener/internal/storage"
+)
+
+// Сколько живет cookie с выбранным вариантом A/B-теста
+const stickyCookieMaxAge = 30 * 24 * 60 * 60 // 30 дней, в секундах
+
+// chooseTarget выбирает вариант A/B-теста с учетом способа закрепления за клиентом
+func chooseTarget(w http.ResponseWriter, r *http.Request, link storage.Link) storage.Target {
+	switch link.Sticky {
+	case storage.StickyCookie:
+		name := stickyCookieName(link.ID)
+
+		// клиент уже участвовал в тесте - отдаем тот же вариант, если он еще существует
+		if c, err := r.Cookie(name); err == nil {
+			if id, err := strconv.ParseInt(c.Value, 10, 64); err == nil {
+				for _, target := range link.Targets {
+					if target.ID == id {
+						return target
+					}
+				}
+			}
+		}
+
+		target := pickTarget(link.Targets, rand.Uint64())
+
+		http.SetCookie(w, &http.Cookie{
+			Name:     name,
+			Value:    strconv.FormatInt(target.ID, 10),
+			Path:     "/",
+			MaxAge:   stickyCookieMaxAge,
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+
+		return target
+	case storage.StickyHash:
+		return pickTarget(link.Targets, clientHash(r, link.ID))
+	default:
+		return pickTarget(link.Targets, rand.Uint64())
+	}
+}
+
+// pickTarget выбирает вариант пропорционально весам. n - случайное число или хеш клиента
+func pickTarget(targets []storage.Target, n uint64) storage.Target {
+	var total uint64
+	for _, target := range targets {
+		if target.Weight > 0 {
+			total += uint64(target.Weight)
+		}
+	}
+
+	if total == 0 {
+		return targets[0]
+	}
+
+	n %= total
+	for _, target := range targets {
+		if target.Weight <= 0 {
+			continue
+		}
+		if n < uint64(target.Weight) {
+			return target
+		}
+		n -= uint64(target.Weight)
+	}
+
+	return targets[len(targets)-1]
+}
+
+// clientHash - устойчивый ключ клиента: один и тот же IP и User-Agent
+// для одной ссылки всегда попадают в один вариант
+func clientHash(r *http.Request, linkID int64) uint64 {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(strconv.FormatInt(linkID, 10)))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(host))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(r.UserAgent()))
+
+	return h.Sum64()
+}
+
+func stickyCookieName(linkID int64) string {
+	return "ab_" + strconv.FormatInt(linkID, 10)
+}
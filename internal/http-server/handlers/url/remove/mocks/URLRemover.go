@@ -2,20 +2,24 @@
 
 package mocks
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	mock "github.com/stretchr/testify/mock"
+
+	storage "url-shortener/internal/storage"
+)
 
 // URLRemover is an autogenerated mock type for the URLRemover type
 type URLRemover struct {
 	mock.Mock
 }
 
-// DeleteURL provides a mock function with given fields: alias
-func (_m *URLRemover) DeleteURL(alias string) error {
-	ret := _m.Called(alias)
+// DeleteURL provides a mock function with given fields: domain, alias, actor
+func (_m *URLRemover) DeleteURL(domain string, alias string, actor storage.Actor) error {
+	ret := _m.Called(domain, alias, actor)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string) error); ok {
-		r0 = rf(alias)
+	if rf, ok := ret.Get(0).(func(string, string, storage.Actor) error); ok {
+		r0 = rf(domain, alias, actor)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -23,6 +27,54 @@ func (_m *URLRemover) DeleteURL(alias string) error {
 	return r0
 }
 
+// GetLink provides a mock function with given fields: domain, alias
+func (_m *URLRemover) GetLink(domain string, alias string) (storage.Link, error) {
+	ret := _m.Called(domain, alias)
+
+	var r0 storage.Link
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (storage.Link, error)); ok {
+		return rf(domain, alias)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) storage.Link); ok {
+		r0 = rf(domain, alias)
+	} else {
+		r0 = ret.Get(0).(storage.Link)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(domain, alias)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetMember provides a mock function with given fields: workspaceID, uid
+func (_m *URLRemover) GetMember(workspaceID int64, uid int64) (storage.Member, error) {
+	ret := _m.Called(workspaceID, uid)
+
+	var r0 storage.Member
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64, int64) (storage.Member, error)); ok {
+		return rf(workspaceID, uid)
+	}
+	if rf, ok := ret.Get(0).(func(int64, int64) storage.Member); ok {
+		r0 = rf(workspaceID, uid)
+	} else {
+		r0 = ret.Get(0).(storage.Member)
+	}
+
+	if rf, ok := ret.Get(1).(func(int64, int64) error); ok {
+		r1 = rf(workspaceID, uid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 type mockConstructorTestingTNewURLRemover interface {
 	mock.TestingT
 	Cleanup(func())
@@ -6,7 +6,9 @@ import (
 	"errors"
 	"log/slog"
 	"net/http"
+	"url-shortener/internal/http-server/middleware/auth"
 	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/hostname"
 	"url-shortener/internal/lib/logger/sl"
 	"url-shortener/internal/storage"
 
@@ -19,9 +21,15 @@ import (
 //
 //go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=URLRemover
 type URLRemover interface {
-	DeleteURL(alias string) error
+	GetLink(domain string, alias string) (storage.Link, error)
+	DeleteURL(domain string, alias string, actor storage.Actor) error
+	GetMember(workspaceID int64, uid int64) (storage.Member, error)
 }
 
+// New Конструктор обработчика удаления ссылки.
+// Ссылка на дополнительном коротком домене адресуется GET-параметром domain.
+// Удаление мягкое: до очистки по сроку хранения ссылку можно восстановить (см. restore.New).
+// Неизвестный алиас - 404
 func New(log *slog.Logger, urlRemover URLRemover) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.remove.New"
@@ -41,23 +49,56 @@ func New(log *slog.Logger, urlRemover URLRemover) http.HandlerFunc {
 			return
 		}
 
-		// Находим URL по алиасу в БД
-		err := urlRemover.DeleteURL(alias)
+		domain := hostname.Normalize(r.URL.Query().Get("domain"))
+
+		// Находим URL по алиасу в БД, чтобы проверить права на рабочее пространство ссылки
+		link, err := urlRemover.GetLink(domain, alias)
+		if errors.Is(err, storage.ErrURLNotFound) {
+			log.Info("url not found", "alias", alias)
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error("not found"))
+			return
+		}
+		if err != nil {
+			log.Error("failed to get url", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("internal error"))
+			return
+		}
+
+		// Удалять ссылки рабочего пространства могут только его редакторы и владельцы, личные ссылки - только их автор
+		if err := auth.AuthorizeLink(r.Context(), urlRemover, link, storage.RoleEditor); err != nil {
+			if status := auth.AccessStatus(err); status != 0 {
+				log.Info("access denied", slog.Int64("workspace_id", link.WorkspaceID), sl.Err(err))
+				render.Status(r, status)
+				render.JSON(w, r, resp.Error(err.Error()))
+				return
+			}
+			log.Error("failed to check access", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("internal error"))
+			return
+		}
+
+		err = urlRemover.DeleteURL(domain, alias, auth.ActorFromRequest(r))
 		if errors.Is(err, storage.ErrURLNotFound) {
-			// Не нашли URL, сообщаем об этом клиенту
+			// Не нашли URL (например, его уже удалил параллельный запрос), сообщаем об этом клиенту
 			log.Info("url not found", "alias", alias)
+			render.Status(r, http.StatusNotFound)
 			render.JSON(w, r, resp.Error("not found"))
 			return
 		}
 		if err != nil {
 			// Не удалось осуществить поиск
 			log.Error("failed to delete url", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
 			render.JSON(w, r, resp.Error("internal error"))
 			return
 		}
 
-		log.Info("delete url by alias", slog.String("alias", alias))
+		log.Info("url deleted", slog.String("alias", alias))
 
+		render.JSON(w, r, resp.OK())
 	}
 
 }
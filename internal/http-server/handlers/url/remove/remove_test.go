@@ -0,0 +1,108 @@
+package remove_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/handlers/url/remove"
+	"url-shortener/internal/http-server/handlers/url/remove/mocks"
+	"url-shortener/internal/http-server/middleware/auth"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/storage"
+)
+
+func TestRemoveHandler(t *testing.T) {
+	cases := []struct {
+		name      string
+		principal auth.Principal // от чьего имени выполняется запрос
+		getErr    error
+		code      int
+		respError string
+		deleteRun bool // ожидается вызов DeleteURL
+		deleteErr error
+	}{
+		{
+			name:      "Author",
+			principal: auth.Principal{UID: 42, Method: auth.MethodJWT},
+			code:      http.StatusOK,
+			deleteRun: true,
+		},
+		{
+			name:      "Another user",
+			principal: auth.Principal{UID: 7, Method: auth.MethodJWT},
+			code:      http.StatusForbidden,
+			respError: auth.ErrForbidden.Error(),
+		},
+		{
+			name:      "Another user's API key",
+			principal: auth.Principal{UID: 7, KeyID: 3, Method: auth.MethodAPIKey},
+			code:      http.StatusForbidden,
+			respError: auth.ErrForbidden.Error(),
+		},
+		{
+			name:      "Admin",
+			principal: auth.Principal{UID: 7, Method: auth.MethodJWT, IsAdmin: true},
+			code:      http.StatusOK,
+			deleteRun: true,
+		},
+		{
+			name:      "Deleted concurrently",
+			principal: auth.Principal{UID: 42, Method: auth.MethodJWT},
+			code:      http.StatusNotFound,
+			respError: "not found",
+			deleteRun: true,
+			deleteErr: storage.ErrURLNotFound,
+		},
+		{
+			name:      "GetLink Error",
+			principal: auth.Principal{UID: 42, Method: auth.MethodJWT},
+			getErr:    errors.New("unexpected error"),
+			code:      http.StatusInternalServerError,
+			respError: "internal error",
+		},
+		{
+			name:      "DeleteURL Error",
+			principal: auth.Principal{UID: 42, Method: auth.MethodJWT},
+			code:      http.StatusInternalServerError,
+			respError: "internal error",
+			deleteRun: true,
+			deleteErr: errors.New("unexpected error"),
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			removerMock := mocks.NewURLRemover(t)
+			removerMock.On("GetLink", "", "abc").Return(storage.Link{Alias: "abc", OwnerUID: 42}, tc.getErr).Once()
+			if tc.deleteRun {
+				removerMock.On("DeleteURL", "", "abc", mock.Anything).Return(tc.deleteErr).Once()
+			}
+
+			router := chi.NewRouter()
+			router.Delete("/url/{alias}", remove.New(slogdiscard.NewDiscardLogger(), removerMock))
+
+			req := httptest.NewRequest(http.MethodDelete, "/url/abc", nil)
+			req = req.WithContext(auth.WithPrincipal(req.Context(), tc.principal))
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.code, rr.Code)
+
+			var body resp.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+			require.Equal(t, tc.respError, body.Error)
+		})
+	}
+}
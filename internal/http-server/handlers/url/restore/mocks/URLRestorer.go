@@ -0,0 +1,91 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	mock "github.com/stretchr/testify/mock"
+
+	storage "url-shortener/internal/storage"
+)
+
+// URLRestorer is an autogenerated mock type for the URLRestorer type
+type URLRestorer struct {
+	mock.Mock
+}
+
+// GetDeletedLink provides a mock function with given fields: domain, alias
+func (_m *URLRestorer) GetDeletedLink(domain string, alias string) (storage.Link, error) {
+	ret := _m.Called(domain, alias)
+
+	var r0 storage.Link
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (storage.Link, error)); ok {
+		return rf(domain, alias)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) storage.Link); ok {
+		r0 = rf(domain, alias)
+	} else {
+		r0 = ret.Get(0).(storage.Link)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(domain, alias)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetMember provides a mock function with given fields: workspaceID, uid
+func (_m *URLRestorer) GetMember(workspaceID int64, uid int64) (storage.Member, error) {
+	ret := _m.Called(workspaceID, uid)
+
+	var r0 storage.Member
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64, int64) (storage.Member, error)); ok {
+		return rf(workspaceID, uid)
+	}
+	if rf, ok := ret.Get(0).(func(int64, int64) storage.Member); ok {
+		r0 = rf(workspaceID, uid)
+	} else {
+		r0 = ret.Get(0).(storage.Member)
+	}
+
+	if rf, ok := ret.Get(1).(func(int64, int64) error); ok {
+		r1 = rf(workspaceID, uid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RestoreURL provides a mock function with given fields: domain, alias, actor
+func (_m *URLRestorer) RestoreURL(domain string, alias string, actor storage.Actor) error {
+	ret := _m.Called(domain, alias, actor)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, storage.Actor) error); ok {
+		r0 = rf(domain, alias, actor)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewURLRestorer interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewURLRestorer creates a new instance of URLRestorer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewURLRestorer(t mockConstructorTestingTNewURLRestorer) *URLRestorer {
+	mock := &URLRestorer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
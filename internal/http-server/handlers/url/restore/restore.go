@@ -0,0 +1,106 @@
+// internal/http-server/handlers/url/restore/restore.go
+
+package restore
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+
+	"url-shortener/internal/http-server/middleware/auth"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/hostname"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/storage"
+)
+
+// URLRestorer is an interface for restoring deleted links.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=URLRestorer
+type URLRestorer interface {
+	GetDeletedLink(domain string, alias string) (storage.Link, error)
+	RestoreURL(domain string, alias string, actor storage.Actor) error
+	GetMember(workspaceID int64, uid int64) (storage.Member, error)
+}
+
+// New Конструктор обработчика POST /url/{alias}/restore - восстановление удаленной ссылки.
+// Ссылка на дополнительном коротком домене адресуется GET-параметром domain.
+// Если удаленной ссылки нет (не удалялась или уже очищена по сроку хранения) - 404
+func New(log *slog.Logger, urlRestorer URLRestorer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.restore.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		alias := chi.URLParam(r, "alias")
+		domain := hostname.Normalize(r.URL.Query().Get("domain"))
+
+		// Находим удаленную ссылку, чтобы проверить права на ее рабочее пространство
+		link, err := urlRestorer.GetDeletedLink(domain, alias)
+		if errors.Is(err, storage.ErrURLNotFound) {
+			log.Info("deleted url not found", slog.String("alias", alias))
+
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error("not found"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to get url", sl.Err(err))
+
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("internal error"))
+
+			return
+		}
+
+		// Восстанавливать ссылки, как и удалять, могут редакторы и владельцы рабочего пространства или автор личной ссылки
+		if err := auth.AuthorizeLink(r.Context(), urlRestorer, link, storage.RoleEditor); err != nil {
+			if status := auth.AccessStatus(err); status != 0 {
+				log.Info("access denied", slog.Int64("workspace_id", link.WorkspaceID), sl.Err(err))
+
+				render.Status(r, status)
+				render.JSON(w, r, resp.Error(err.Error()))
+
+				return
+			}
+
+			log.Error("failed to check access", sl.Err(err))
+
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("internal error"))
+
+			return
+		}
+
+		err = urlRestorer.RestoreURL(domain, alias, auth.ActorFromRequest(r))
+		if errors.Is(err, storage.ErrURLNotFound) {
+			// Ссылку успели восстановить или очистить параллельно
+			log.Info("deleted url not found", slog.String("alias", alias))
+
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error("not found"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to restore url", sl.Err(err))
+
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("internal error"))
+
+			return
+		}
+
+		log.Info("url restored", slog.String("alias", alias))
+
+		render.JSON(w, r, resp.OK())
+	}
+}
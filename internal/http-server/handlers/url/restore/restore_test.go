@@ -0,0 +1,105 @@
+package restore_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/handlers/url/restore"
+	"url-shortener/internal/http-server/handlers/url/restore/mocks"
+	"url-shortener/internal/http-server/middleware/auth"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/storage"
+)
+
+func TestRestoreHandler(t *testing.T) {
+	cases := []struct {
+		name       string
+		getError   error // ошибка поиска удаленной ссылки
+		mockError  error // ошибка восстановления
+		code       int
+		respError  string
+		restoreRun bool  // ожидается вызов RestoreURL
+		ownerUID   int64 // автор ссылки; 0 - пользователь запроса (42)
+	}{
+		{
+			name:       "Success",
+			code:       http.StatusOK,
+			restoreRun: true,
+		},
+		{
+			name:      "Not deleted or purged",
+			getError:  storage.ErrURLNotFound,
+			code:      http.StatusNotFound,
+			respError: "not found",
+		},
+		{
+			name:      "Another user's link",
+			ownerUID:  7,
+			code:      http.StatusForbidden,
+			respError: auth.ErrForbidden.Error(),
+		},
+		{
+			name:       "Restored concurrently",
+			mockError:  storage.ErrURLNotFound,
+			code:       http.StatusNotFound,
+			respError:  "not found",
+			restoreRun: true,
+		},
+		{
+			name:      "GetDeletedLink Error",
+			getError:  errors.New("unexpected error"),
+			code:      http.StatusInternalServerError,
+			respError: "internal error",
+		},
+		{
+			name:       "RestoreURL Error",
+			mockError:  errors.New("unexpected error"),
+			code:       http.StatusInternalServerError,
+			respError:  "internal error",
+			restoreRun: true,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ownerUID := tc.ownerUID
+			if ownerUID == 0 {
+				ownerUID = 42
+			}
+
+			restorerMock := mocks.NewURLRestorer(t)
+			restorerMock.On("GetDeletedLink", "go.example.com", "abc").
+				Return(storage.Link{Alias: "abc", OwnerUID: ownerUID}, tc.getError).Once()
+			if tc.restoreRun {
+				restorerMock.On("RestoreURL", "go.example.com", "abc", mock.Anything).
+					Return(tc.mockError).Once()
+			}
+
+			router := chi.NewRouter()
+			router.Post("/url/{alias}/restore", restore.New(slogdiscard.NewDiscardLogger(), restorerMock))
+
+			req := httptest.NewRequest(http.MethodPost, "/url/abc/restore?domain=Go.Example.com", nil)
+			req = req.WithContext(auth.WithUID(req.Context(), 42))
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.code, rr.Code)
+
+			var body resp.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+			require.Equal(t, tc.respError, body.Error)
+		})
+	}
+}
@@ -0,0 +1,55 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import mock "github.com/stretchr/testify/mock"
+
+// ThreatScreener is an autogenerated mock type for the ThreatScreener type
+type ThreatScreener struct {
+	mock.Mock
+}
+
+// Match provides a mock function with given fields: urls
+func (_m *ThreatScreener) Match(urls ...string) (string, bool) {
+	_va := make([]interface{}, len(urls))
+	for _i := range urls {
+		_va[_i] = urls[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 string
+	var r1 bool
+	if rf, ok := ret.Get(0).(func(...string) (string, bool)); ok {
+		return rf(urls...)
+	}
+	if rf, ok := ret.Get(0).(func(...string) string); ok {
+		r0 = rf(urls...)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(...string) bool); ok {
+		r1 = rf(urls...)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewThreatScreener interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewThreatScreener creates a new instance of ThreatScreener. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewThreatScreener(t mockConstructorTestingTNewThreatScreener) *ThreatScreener {
+	mock := &ThreatScreener{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
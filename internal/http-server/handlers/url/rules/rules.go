@@ -0,0 +1,304 @@
+// internal/http-server/handlers/url/rules/rules.go
+
+// Управление правилами умного редиректа: подресурс /url/{alias}/rules.
+// Ссылка на дополнительном коротком домене адресуется GET-параметром domain
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+
+	"url-shortener/internal/http-server/middleware/auth"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/hostname"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/lib/threatlist"
+	"url-shortener/internal/storage"
+)
+
+// RuleRequest - одно правило в запросе
+type RuleRequest struct {
+	Platform  string `json:"platform,omitempty" validate:"omitempty,oneof=ios android desktop"`
+	Language  string `json:"language,omitempty" validate:"omitempty,bcp47_language_tag"`
+	Country   string `json:"country,omitempty" validate:"omitempty,len=2,alpha"`
+	TargetURL string `json:"target_url" validate:"required,url"`
+}
+
+// структура запроса на замену правил. Порядок правил важен.
+// Количество правил ограничено: они проверяются на каждом редиректе
+type Request struct {
+	Rules []RuleRequest `json:"rules" validate:"max=50,dive"`
+}
+
+// структура ответа
+type Response struct {
+	resp.Response
+	Rules []storage.Rule `json:"rules"`
+}
+
+// RulesGetter is an interface for getting redirect rules by alias.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=RulesGetter
+type RulesGetter interface {
+	GetLink(domain string, alias string) (storage.Link, error)
+	GetMember(workspaceID int64, uid int64) (storage.Member, error)
+}
+
+// RulesSetter is an interface for replacing redirect rules by alias.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=RulesSetter
+type RulesSetter interface {
+	GetLink(domain string, alias string) (storage.Link, error)
+	SetRules(domain string, alias string, rules []storage.Rule, actor storage.Actor) error
+	GetMember(workspaceID int64, uid int64) (storage.Member, error)
+}
+
+// ThreatScreener is an interface for checking target URLs against the threat list.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=ThreatScreener
+type ThreatScreener interface {
+	Match(urls ...string) (string, bool)
+}
+
+// NewList Конструктор обработчика GET /url/{alias}/rules
+func NewList(log *slog.Logger, rulesGetter RulesGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.rules.NewList"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		alias := chi.URLParam(r, "alias")
+
+		// Правила загружаются вместе со ссылкой
+		link, err := rulesGetter.GetLink(domainParam(r), alias)
+		if errors.Is(err, storage.ErrURLNotFound) {
+			log.Info("url not found", slog.String("alias", alias))
+
+			render.JSON(w, r, resp.Error("not found"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to get rules", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("internal error"))
+
+			return
+		}
+
+		// Правила ссылок рабочего пространства видят все его участники, личных ссылок - только их автор
+		if !authorize(w, r, log, rulesGetter, link, storage.RoleViewer) {
+			return
+		}
+
+		responseOK(w, r, link.Rules)
+	}
+}
+
+// NewReplace Конструктор обработчика PUT /url/{alias}/rules - полная замена набора правил.
+// Новые адреса перехода правил проверяются по списку опасных адресов screener (nil - не проверяются):
+// набор с адресом из списка не сохраняется
+func NewReplace(log *slog.Logger, rulesSetter RulesSetter, screener ThreatScreener) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.rules.NewReplace"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		alias := chi.URLParam(r, "alias")
+
+		var req Request
+
+		err := render.DecodeJSON(r.Body, &req)
+		if errors.Is(err, io.EOF) {
+			log.Error("request body is empty")
+
+			render.JSON(w, r, resp.Error("empty request"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed to decode request"))
+
+			return
+		}
+
+		log.Info("request body decoded", slog.Any("req", req))
+
+		if err := validator.New().Struct(req); err != nil {
+			log.Error("invalid request", sl.Err(err))
+
+			render.JSON(w, r, resp.Error(err.Error()))
+
+			return
+		}
+
+		rules := make([]storage.Rule, 0, len(req.Rules))
+		for _, rule := range req.Rules {
+			rules = append(rules, storage.Rule{
+				Platform:  rule.Platform,
+				Language:  strings.ToLower(rule.Language),
+				Country:   strings.ToUpper(rule.Country),
+				TargetURL: rule.TargetURL,
+			})
+		}
+
+		setRules(w, r, log, rulesSetter, screener, alias, rules)
+	}
+}
+
+// NewClear Конструктор обработчика DELETE /url/{alias}/rules - удаление всех правил
+func NewClear(log *slog.Logger, rulesSetter RulesSetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.rules.NewClear"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		setRules(w, r, log, rulesSetter, nil, chi.URLParam(r, "alias"), []storage.Rule{})
+	}
+}
+
+func setRules(
+	w http.ResponseWriter,
+	r *http.Request,
+	log *slog.Logger,
+	rulesSetter RulesSetter,
+	screener ThreatScreener,
+	alias string,
+	rules []storage.Rule,
+) {
+	link, err := rulesSetter.GetLink(domainParam(r), alias)
+	if errors.Is(err, storage.ErrURLNotFound) {
+		log.Info("url not found", slog.String("alias", alias))
+
+		render.JSON(w, r, resp.Error("not found"))
+
+		return
+	}
+	if err != nil {
+		log.Error("failed to get url", sl.Err(err))
+
+		render.JSON(w, r, resp.Error("internal error"))
+
+		return
+	}
+
+	// Менять правила ссылок рабочего пространства могут только его редакторы и владельцы, личных ссылок - только их автор
+	if !authorize(w, r, log, rulesSetter, link, storage.RoleEditor) {
+		return
+	}
+
+	// Проверяются только новые адреса: решение администратора по текущим адресам остается в силе
+	if urls := newTargets(link.Rules, rules); screener != nil && len(urls) > 0 {
+		if threatType, ok := screener.Match(urls...); ok {
+			log.Warn("rule target is in the threat list", slog.String("alias", alias), slog.String("threat", threatType))
+
+			render.JSON(w, r, resp.Error(fmt.Sprintf("%s: %s", threatlist.ErrListed, threatType)))
+
+			return
+		}
+	}
+
+	err = rulesSetter.SetRules(link.Domain, alias, rules, auth.ActorFromRequest(r))
+	if errors.Is(err, storage.ErrURLNotFound) {
+		log.Info("url not found", slog.String("alias", alias))
+
+		render.JSON(w, r, resp.Error("not found"))
+
+		return
+	}
+	if err != nil {
+		log.Error("failed to set rules", sl.Err(err))
+
+		render.JSON(w, r, resp.Error("failed to set rules"))
+
+		return
+	}
+
+	log.Info("rules updated", slog.String("alias", alias), slog.Int("count", len(rules)))
+
+	responseOK(w, r, rules)
+}
+
+// authorize проверяет права на ссылку (см. auth.AuthorizeLink).
+// Если прав нет, отвечает клиенту сам и возвращает false
+func authorize(
+	w http.ResponseWriter,
+	r *http.Request,
+	log *slog.Logger,
+	members auth.MemberGetter,
+	link storage.Link,
+	minRole string,
+) bool {
+	err := auth.AuthorizeLink(r.Context(), members, link, minRole)
+	if err == nil {
+		return true
+	}
+
+	if status := auth.AccessStatus(err); status != 0 {
+		log.Info("access denied", slog.Int64("workspace_id", link.WorkspaceID), sl.Err(err))
+
+		render.Status(r, status)
+		render.JSON(w, r, resp.Error(err.Error()))
+
+		return false
+	}
+
+	log.Error("failed to check access", sl.Err(err))
+
+	render.JSON(w, r, resp.Error("internal error"))
+
+	return false
+}
+
+// newTargets - адреса перехода правил rules, которых нет среди правил current
+func newTargets(current, rules []storage.Rule) []string {
+	known := make(map[string]bool, len(current))
+	for _, rule := range current {
+		known[rule.TargetURL] = true
+	}
+
+	var urls []string
+	for _, rule := range rules {
+		if !known[rule.TargetURL] {
+			urls = append(urls, rule.TargetURL)
+		}
+	}
+
+	return urls
+}
+
+// domainParam - короткий домен ссылки из GET-параметра domain
+func domainParam(r *http.Request) string {
+	return hostname.Normalize(r.URL.Query().Get("domain"))
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request, rules []storage.Rule) {
+	if rules == nil {
+		rules = []storage.Rule{}
+	}
+
+	render.JSON(w, r, Response{
+		Response: resp.OK(),
+		Rules:    rules,
+	})
+}
@@ -0,0 +1,307 @@
+package rules_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/handlers/url/rules"
+	"url-shortener/internal/http-server/handlers/url/rules/mocks"
+	"url-shortener/internal/http-server/middleware/auth"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/lib/threatlist"
+	"url-shortener/internal/storage"
+)
+
+// threatList - список опасных адресов: адрес -> тип угрозы
+type threatList map[string]string
+
+func (l threatList) Match(urls ...string) (string, bool) {
+	for _, u := range urls {
+		if threat, ok := l[u]; ok {
+			return threat, true
+		}
+	}
+	return "", false
+}
+
+var listed = threatList{"https://evil.example/login": threatlist.SocialEngineering}
+
+var savedRules = []storage.Rule{
+	{Platform: "ios", TargetURL: "https://apps.apple.com/app/1"},
+	{Country: "DE", Language: "de", TargetURL: "https://example.de"},
+}
+
+func TestListHandler(t *testing.T) {
+	cases := []struct {
+		name      string
+		link      storage.Link
+		getError  error
+		member    *storage.Member // участник рабочего пространства; nil - не участник
+		code      int
+		respError string
+		rules     []storage.Rule
+	}{
+		{
+			name:  "Success",
+			link:  storage.Link{Alias: "abc", OwnerUID: 42, Rules: savedRules},
+			code:  http.StatusOK,
+			rules: savedRules,
+		},
+		{
+			name:  "No rules",
+			link:  storage.Link{Alias: "abc", OwnerUID: 42},
+			code:  http.StatusOK,
+			rules: []storage.Rule{},
+		},
+		{
+			name:      "Another user's link",
+			link:      storage.Link{Alias: "abc", OwnerUID: 7, Rules: savedRules},
+			code:      http.StatusForbidden,
+			respError: auth.ErrForbidden.Error(),
+		},
+		{
+			name:      "Not found",
+			getError:  storage.ErrURLNotFound,
+			code:      http.StatusOK,
+			respError: "not found",
+		},
+		{
+			name:      "Storage error",
+			getError:  errors.New("unexpected error"),
+			code:      http.StatusOK,
+			respError: "internal error",
+		},
+		{
+			name:   "Workspace viewer",
+			link:   storage.Link{Alias: "abc", WorkspaceID: 7, Rules: savedRules},
+			member: &storage.Member{WorkspaceID: 7, UID: 42, Role: storage.RoleViewer},
+			code:   http.StatusOK,
+			rules:  savedRules,
+		},
+		{
+			name:      "Not a workspace member",
+			link:      storage.Link{Alias: "abc", WorkspaceID: 7, Rules: savedRules},
+			code:      http.StatusForbidden,
+			respError: auth.ErrForbidden.Error(),
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			getterMock := mocks.NewRulesGetter(t)
+			getterMock.On("GetLink", "go.example.com", "abc").Return(tc.link, tc.getError).Once()
+			if tc.link.WorkspaceID != 0 {
+				if tc.member != nil {
+					getterMock.On("GetMember", tc.link.WorkspaceID, int64(42)).Return(*tc.member, nil).Once()
+				} else {
+					getterMock.On("GetMember", tc.link.WorkspaceID, int64(42)).Return(storage.Member{}, storage.ErrMemberNotFound).Once()
+				}
+			}
+
+			router := chi.NewRouter()
+			router.Get("/url/{alias}/rules", rules.NewList(slogdiscard.NewDiscardLogger(), getterMock))
+
+			req := httptest.NewRequest(http.MethodGet, "/url/abc/rules?domain=Go.Example.com", nil)
+			req = req.WithContext(auth.WithUID(req.Context(), 42))
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.code, rr.Code)
+
+			var body rules.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+			require.Equal(t, tc.respError, body.Error)
+			require.Equal(t, tc.rules, body.Rules)
+		})
+	}
+}
+
+func TestReplaceHandler(t *testing.T) {
+	cases := []struct {
+		name      string
+		body      string
+		setError  error
+		ownerUID  int64          // автор ссылки; 0 - пользователь запроса (42)
+		current   []storage.Rule // текущие правила ссылки
+		code      int
+		respError string
+		rules     []storage.Rule // правила, которые должны быть сохранены; nil - SetRules не вызывается
+	}{
+		{
+			name: "Success",
+			body: `{"rules": [{"platform": "android", "target_url": "https://play.google.com/store/apps/1"},` +
+				`{"language": "DE-at", "country": "at", "target_url": "https://example.at"}]}`,
+			code: http.StatusOK,
+			// язык и страна приводятся к каноническому регистру
+			rules: []storage.Rule{
+				{Platform: "android", TargetURL: "https://play.google.com/store/apps/1"},
+				{Language: "de-at", Country: "AT", TargetURL: "https://example.at"},
+			},
+		},
+		{
+			name:  "Empty rules",
+			body:  `{"rules": []}`,
+			code:  http.StatusOK,
+			rules: []storage.Rule{},
+		},
+		{
+			name:      "Empty body",
+			code:      http.StatusOK,
+			respError: "empty request",
+		},
+		{
+			name:      "Unknown platform",
+			body:      `{"rules": [{"platform": "symbian", "target_url": "https://example.com"}]}`,
+			code:      http.StatusOK,
+			respError: "Key: 'Request.Rules[0].Platform' Error:Field validation for 'Platform' failed on the 'oneof' tag",
+		},
+		{
+			name:      "Invalid country",
+			body:      `{"rules": [{"country": "RUS", "target_url": "https://example.com"}]}`,
+			code:      http.StatusOK,
+			respError: "Key: 'Request.Rules[0].Country' Error:Field validation for 'Country' failed on the 'len' tag",
+		},
+		{
+			name:      "Missing target",
+			body:      `{"rules": [{"platform": "ios"}]}`,
+			code:      http.StatusOK,
+			respError: "Key: 'Request.Rules[0].TargetURL' Error:Field validation for 'TargetURL' failed on the 'required' tag",
+		},
+		{
+			name:      "Listed target",
+			body:      `{"rules": [{"platform": "ios", "target_url": "https://evil.example/login"}]}`,
+			ownerUID:  42,
+			code:      http.StatusOK,
+			respError: "target url is in the threat list: SOCIAL_ENGINEERING",
+		},
+		{
+			// Решение администратора по текущему адресу ("ложное срабатывание") остается в силе
+			name:    "Listed target already in rules",
+			body:    `{"rules": [{"platform": "ios", "target_url": "https://evil.example/login"}]}`,
+			current: []storage.Rule{{Platform: "android", TargetURL: "https://evil.example/login"}},
+			code:    http.StatusOK,
+			rules:   []storage.Rule{{Platform: "ios", TargetURL: "https://evil.example/login"}},
+		},
+		{
+			name:      "Another user's link",
+			body:      `{"rules": []}`,
+			ownerUID:  7,
+			code:      http.StatusForbidden,
+			respError: auth.ErrForbidden.Error(),
+		},
+		{
+			name:      "Deleted concurrently",
+			body:      `{"rules": []}`,
+			setError:  storage.ErrURLNotFound,
+			code:      http.StatusOK,
+			respError: "not found",
+			rules:     []storage.Rule{},
+		},
+		{
+			name:      "Storage error",
+			body:      `{"rules": []}`,
+			setError:  errors.New("unexpected error"),
+			code:      http.StatusOK,
+			respError: "failed to set rules",
+			rules:     []storage.Rule{},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ownerUID := tc.ownerUID
+			if ownerUID == 0 {
+				ownerUID = 42
+			}
+
+			setterMock := mocks.NewRulesSetter(t)
+			if tc.rules != nil || tc.ownerUID != 0 {
+				setterMock.On("GetLink", "go.example.com", "abc").
+					Return(storage.Link{Domain: "go.example.com", Alias: "abc", OwnerUID: ownerUID, Rules: tc.current}, nil).Once()
+			}
+			if tc.rules != nil {
+				setterMock.On("SetRules", "go.example.com", "abc", tc.rules, mock.Anything).
+					Return(tc.setError).Once()
+			}
+
+			router := chi.NewRouter()
+			router.Put("/url/{alias}/rules", rules.NewReplace(slogdiscard.NewDiscardLogger(), setterMock, listed))
+
+			req := httptest.NewRequest(http.MethodPut, "/url/abc/rules?domain=go.example.com", strings.NewReader(tc.body))
+			req = req.WithContext(auth.WithUID(req.Context(), 42))
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.code, rr.Code)
+
+			var body rules.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+			require.Equal(t, tc.respError, body.Error)
+			if tc.respError == "" {
+				require.Equal(t, tc.rules, body.Rules)
+			}
+		})
+	}
+}
+
+func TestClearHandler(t *testing.T) {
+	cases := []struct {
+		name      string
+		role      string // роль пользователя в рабочем пространстве ссылки
+		code      int
+		respError string
+	}{
+		{name: "Editor", role: storage.RoleEditor, code: http.StatusOK},
+		{name: "Viewer", role: storage.RoleViewer, code: http.StatusForbidden, respError: auth.ErrForbidden.Error()},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			setterMock := mocks.NewRulesSetter(t)
+			setterMock.On("GetLink", "", "abc").
+				Return(storage.Link{Alias: "abc", WorkspaceID: 7, Rules: savedRules}, nil).Once()
+			setterMock.On("GetMember", int64(7), int64(42)).
+				Return(storage.Member{WorkspaceID: 7, UID: 42, Role: tc.role}, nil).Once()
+			if tc.respError == "" {
+				setterMock.On("SetRules", "", "abc", []storage.Rule{}, mock.Anything).Return(nil).Once()
+			}
+
+			router := chi.NewRouter()
+			router.Delete("/url/{alias}/rules", rules.NewClear(slogdiscard.NewDiscardLogger(), setterMock))
+
+			req := httptest.NewRequest(http.MethodDelete, "/url/abc/rules", nil)
+			req = req.WithContext(auth.WithUID(req.Context(), 42))
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.code, rr.Code)
+
+			var body rules.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+			require.Equal(t, tc.respError, body.Error)
+			if tc.respError == "" {
+				require.Equal(t, []storage.Rule{}, body.Rules)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import mock "github.com/stretchr/testify/mock"
+
+// PreviewFetcher is an autogenerated mock type for the PreviewFetcher type
+type PreviewFetcher struct {
+	mock.Mock
+}
+
+// Enqueue provides a mock function with given fields: linkID
+func (_m *PreviewFetcher) Enqueue(linkID int64) {
+	_m.Called(linkID)
+}
+
+type mockConstructorTestingTNewPreviewFetcher interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewPreviewFetcher creates a new instance of PreviewFetcher. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewPreviewFetcher(t mockConstructorTestingTNewPreviewFetcher) *PreviewFetcher {
+	mock := &PreviewFetcher{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
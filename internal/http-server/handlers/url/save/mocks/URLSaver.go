@@ -2,30 +2,106 @@
 
 package mocks
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	mock "github.com/stretchr/testify/mock"
+
+	storage "url-shortener/internal/storage"
+)
 
 // URLSaver is an autogenerated mock type for the URLSaver type
 type URLSaver struct {
 	mock.Mock
 }
 
-// SaveURL provides a mock function with given fields: URL, alias
-func (_m *URLSaver) SaveURL(URL string, alias string) (int64, error) {
-	ret := _m.Called(URL, alias)
+// GetDomain provides a mock function with given fields: host
+func (_m *URLSaver) GetDomain(host string) (storage.Domain, error) {
+	ret := _m.Called(host)
+
+	var r0 storage.Domain
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (storage.Domain, error)); ok {
+		return rf(host)
+	}
+	if rf, ok := ret.Get(0).(func(string) storage.Domain); ok {
+		r0 = rf(host)
+	} else {
+		r0 = ret.Get(0).(storage.Domain)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(host)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetMember provides a mock function with given fields: workspaceID, uid
+func (_m *URLSaver) GetMember(workspaceID int64, uid int64) (storage.Member, error) {
+	ret := _m.Called(workspaceID, uid)
+
+	var r0 storage.Member
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64, int64) (storage.Member, error)); ok {
+		return rf(workspaceID, uid)
+	}
+	if rf, ok := ret.Get(0).(func(int64, int64) storage.Member); ok {
+		r0 = rf(workspaceID, uid)
+	} else {
+		r0 = ret.Get(0).(storage.Member)
+	}
+
+	if rf, ok := ret.Get(1).(func(int64, int64) error); ok {
+		r1 = rf(workspaceID, uid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IsAliasReserved provides a mock function with given fields: domain, alias
+func (_m *URLSaver) IsAliasReserved(domain string, alias string) (bool, error) {
+	ret := _m.Called(domain, alias)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (bool, error)); ok {
+		return rf(domain, alias)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) bool); ok {
+		r0 = rf(domain, alias)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(domain, alias)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveLink provides a mock function with given fields: link, actor
+func (_m *URLSaver) SaveLink(link storage.Link, actor storage.Actor) (int64, error) {
+	ret := _m.Called(link, actor)
 
 	var r0 int64
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, string) (int64, error)); ok {
-		return rf(URL, alias)
+	if rf, ok := ret.Get(0).(func(storage.Link, storage.Actor) (int64, error)); ok {
+		return rf(link, actor)
 	}
-	if rf, ok := ret.Get(0).(func(string, string) int64); ok {
-		r0 = rf(URL, alias)
+	if rf, ok := ret.Get(0).(func(storage.Link, storage.Actor) int64); ok {
+		r0 = rf(link, actor)
 	} else {
 		r0 = ret.Get(0).(int64)
 	}
 
-	if rf, ok := ret.Get(1).(func(string, string) error); ok {
-		r1 = rf(URL, alias)
+	if rf, ok := ret.Get(1).(func(storage.Link, storage.Actor) error); ok {
+		r1 = rf(link, actor)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"io"
 	"net/http"
+	"time"
 
 	"log/slog"
 
@@ -12,9 +13,13 @@ import (
 	"github.com/go-chi/render"
 	"github.com/go-playground/validator/v10"
 
+	"url-shortener/internal/http-server/middleware/auth"
+	"url-shortener/internal/lib/aliaspolicy"
 	resp "url-shortener/internal/lib/api/response" // для краткости даем короткий алиас пакету
+	"url-shortener/internal/lib/hostname"
 	"url-shortener/internal/lib/logger/sl"
 	"url-shortener/internal/lib/random"
+	"url-shortener/internal/lib/timeparse"
 	"url-shortener/internal/storage"
 )
 
@@ -22,30 +27,101 @@ import (
 type Request struct {
 	URL   string `json:"url" validate:"required,url"` // эта строчка для валидации, об этом будет ниже.
 	Alias string `json:"alias,omitempty"`
+	// Короткий домен (должен быть зарегистрирован и подтвержден). Пусто - основной домен
+	Domain string `json:"domain,omitempty"`
+	// Рабочее пространство ссылки (нужна роль editor или owner). 0 - общая ссылка
+	WorkspaceID int64 `json:"workspace_id,omitempty" validate:"min=0"`
+
+	// Окно активности ссылки: RFC 3339 со смещением ("2024-05-01T10:00:00+03:00")
+	// либо локальное время ("2024-05-01T10:00"), которое трактуется в часовом поясе Timezone
+	NotBefore   string `json:"not_before,omitempty"`
+	NotAfter    string `json:"not_after,omitempty"`
+	Timezone    string `json:"timezone,omitempty"` // имя IANA, например "Europe/Moscow". По умолчанию UTC
+	FallbackURL string `json:"fallback_url,omitempty" validate:"omitempty,url"`
+
+	// Варианты A/B-теста: трафик распределяется между ними пропорционально весам.
+	// Sticky - как закрепить вариант за клиентом: "cookie", "hash" или пусто (без закрепления)
+	Targets []TargetRequest `json:"targets,omitempty" validate:"max=20,dive"`
+	Sticky  string          `json:"sticky,omitempty" validate:"omitempty,oneof=cookie hash"`
+
+	// UTM-метки, которые добавляются к адресу перехода, если в нем нет своих
+	UTMSource   string `json:"utm_source,omitempty" validate:"max=200"`
+	UTMMedium   string `json:"utm_medium,omitempty" validate:"max=200"`
+	UTMCampaign string `json:"utm_campaign,omitempty" validate:"max=200"`
+	// Перенос параметров запроса короткой ссылки: drop, merge или override.
+	// Пусто - политика по умолчанию из конфига
+	QueryPolicy string `json:"query_policy,omitempty" validate:"omitempty,oneof=drop merge override"`
+
+	// HTTP-код редиректа: 301, 302, 307 или 308 (по умолчанию 302)
+	RedirectCode int `json:"redirect_code,omitempty" validate:"omitempty,oneof=301 302 307 308"`
+	// Показывать промежуточную страницу "вы переходите на ..." вместо мгновенного редиректа
+	Interstitial bool   `json:"interstitial,omitempty"`
+	Title        string `json:"title,omitempty" validate:"max=300"`
+}
+
+// TargetRequest - вариант A/B-теста в запросе
+type TargetRequest struct {
+	URL    string `json:"url" validate:"required,url"`
+	Weight int    `json:"weight" validate:"min=1,max=10000"`
 }
 
 // структура ответа
 type Response struct {
 	resp.Response
-	Alias string `json:"alias,omitempty"`
+	Alias  string `json:"alias,omitempty"`
+	Domain string `json:"domain,omitempty"`
+	// Тип угрозы, если адрес перехода найден в списке опасных адресов:
+	// ссылка сохранена отключенной и ждет решения администратора
+	Threat string `json:"threat,omitempty"`
 }
 
 // TODO: move to config when needed
 const aliasLength = 6
 
+// Сколько раз генерировать случайный алиас, если он не прошел политику алиасов
+const aliasAttempts = 10
+
 // интерфейс сохранения полученной URL-строки
 //
 //go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=URLSaver
 type URLSaver interface {
-	SaveURL(URL, alias string) (int64, error)
+	SaveLink(link storage.Link, actor storage.Actor) (int64, error)
+	GetDomain(host string) (storage.Domain, error)
+	GetMember(workspaceID int64, uid int64) (storage.Member, error)
+	IsAliasReserved(domain string, alias string) (bool, error)
+}
+
+// PreviewFetcher is an interface for requesting target page metadata of a new link.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=PreviewFetcher
+type PreviewFetcher interface {
+	Enqueue(linkID int64)
+}
+
+// ThreatScreener is an interface for checking target URLs against the threat list.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=ThreatScreener
+type ThreatScreener interface {
+	Match(urls ...string) (string, bool)
 }
 
 // Тесты:
 // Mockery generation fo SaveURL:
 // ./internal/http-server/handlers/url/save/save.go
 
-// New Конструктор обработчика запросов
-func New(log *slog.Logger, urlSaver URLSaver) http.HandlerFunc {
+// New Конструктор обработчика запросов.
+// Алиас проверяется политикой алиасов policy; алиасы, зарезервированные
+// администраторами, доступны только администраторам.
+// Метаданные страницы новой ссылки запрашиваются в фоне через previews (nil - не запрашиваются).
+// Адреса перехода проверяются по списку опасных адресов screener (nil - не проверяются):
+// ссылка на опасный адрес сохраняется отключенной и попадает в очередь на проверку администратором
+func New(
+	log *slog.Logger,
+	urlSaver URLSaver,
+	policy *aliaspolicy.Policy,
+	previews PreviewFetcher,
+	screener ThreatScreener,
+) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.url.save.New"
 
@@ -103,15 +179,128 @@ func New(log *slog.Logger, urlSaver URLSaver) http.HandlerFunc {
 			return
 		}
 
+		// Разбираем окно активности с учетом часового пояса
+		notBefore, notAfter, err := timeparse.ParseWindow(req.NotBefore, req.NotAfter, req.Timezone)
+		if err != nil {
+			log.Error("invalid activation window", sl.Err(err))
+
+			render.JSON(w, r, resp.Error(err.Error()))
+
+			return
+		}
+
+		// Создавать ссылки в рабочем пространстве могут только его редакторы и владельцы
+		if err := auth.Authorize(r.Context(), urlSaver, req.WorkspaceID, storage.RoleEditor); err != nil {
+			if status := auth.AccessStatus(err); status != 0 {
+				log.Info("access denied", slog.Int64("workspace_id", req.WorkspaceID), sl.Err(err))
+
+				render.Status(r, status)
+				render.JSON(w, r, resp.Error(err.Error()))
+
+				return
+			}
+
+			log.Error("failed to check access", sl.Err(err))
+
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("internal error"))
+
+			return
+		}
+
+		// Ссылку можно создать только на подтвержденном домене
+		domain := hostname.Normalize(req.Domain)
+		if domain != "" {
+			d, err := urlSaver.GetDomain(domain)
+			if errors.Is(err, storage.ErrDomainNotFound) || err == nil && !d.Verified() {
+				log.Info("unknown or unverified domain", slog.String("domain", domain))
+
+				render.JSON(w, r, resp.Error("unknown or unverified domain"))
+
+				return
+			}
+			if err != nil {
+				log.Error("failed to get domain", sl.Err(err))
+
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.Error("failed to add url"))
+
+				return
+			}
+		}
+
 		// Alias проверяем вручную. Если он пустой — генерируем случайный:
 		alias := req.Alias
 		if alias == "" {
-			// используем собственный генератор случайных строк
-			alias = random.NewRandomString(aliasLength)
+			alias, err = randomAlias(urlSaver, policy, domain)
+			if err != nil {
+				log.Error("failed to generate alias", sl.Err(err))
+
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.Error("failed to add url"))
+
+				return
+			}
+		} else {
+			if err := policy.Check(alias); err != nil {
+				log.Info("alias rejected by policy", slog.String("alias", alias), sl.Err(err))
+
+				render.JSON(w, r, resp.Error(err.Error()))
+
+				return
+			}
+
+			reserved, err := urlSaver.IsAliasReserved(domain, alias)
+			if err != nil {
+				log.Error("failed to check alias reservation", sl.Err(err))
+
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.Error("failed to add url"))
+
+				return
+			}
+			if reserved && !auth.IsAdminFromContext(r.Context()) {
+				log.Info("alias is reserved by admin", slog.String("alias", alias))
+
+				render.JSON(w, r, resp.Error(aliaspolicy.ErrReserved.Error()))
+
+				return
+			}
+		}
+
+		// Автор ссылки (0, если запрос авторизован по basic auth)
+		ownerUID, _ := auth.UIDFromContext(r.Context())
+
+		// Проверяем все адреса перехода по списку опасных адресов
+		threat := screen(screener, req)
+		if threat != nil {
+			log.Warn("target is in the threat list, link will be disabled",
+				slog.String("url", req.URL),
+				slog.String("threat", threat.Type),
+			)
 		}
 
 		// Осталось только сохранить URL и Alias,
-		id, err := urlSaver.SaveURL(req.URL, alias)
+		id, err := urlSaver.SaveLink(storage.Link{
+			Domain:       domain,
+			WorkspaceID:  req.WorkspaceID,
+			OwnerUID:     ownerUID,
+			Alias:        alias,
+			URL:          req.URL,
+			NotBefore:    notBefore,
+			NotAfter:     notAfter,
+			FallbackURL:  req.FallbackURL,
+			Targets:      targets(req.Targets),
+			Sticky:       req.Sticky,
+			UTMSource:    req.UTMSource,
+			UTMMedium:    req.UTMMedium,
+			UTMCampaign:  req.UTMCampaign,
+			QueryPolicy:  req.QueryPolicy,
+			RedirectCode: req.RedirectCode,
+			Interstitial: req.Interstitial,
+			Title:        req.Title,
+			Threat:       threat,
+		}, auth.ActorFromRequest(r))
 		if errors.Is(err, storage.ErrURLExists) {
 			// отдельно обрабатываем ситуацию, когда запись с таким alias уже существует
 			log.Info("url already exists", slog.String("url", req.URL))
@@ -124,6 +313,7 @@ func New(log *slog.Logger, urlSaver URLSaver) http.HandlerFunc {
 		if err != nil {
 			log.Error("failed to add url", sl.Err(err))
 
+			render.Status(r, http.StatusInternalServerError)
 			render.JSON(w, r, resp.Error("failed to add url"))
 
 			return
@@ -131,14 +321,76 @@ func New(log *slog.Logger, urlSaver URLSaver) http.HandlerFunc {
 
 		log.Info("url added", slog.Int64("id", id))
 
+		if previews != nil {
+			previews.Enqueue(id)
+		}
+
 		// а после — вернуть ответ с сообщением об успехе.
-		responseOK(w, r, alias)
+		res := Response{
+			Response: resp.OK(),
+			Alias:    alias,
+			Domain:   domain,
+		}
+		if threat != nil {
+			res.Threat = threat.Type
+		}
+
+		render.JSON(w, r, res)
+	}
+}
+
+// screen проверяет адреса перехода из запроса (основной, запасной, варианты A/B-теста)
+// по списку опасных адресов. nil - угроз не найдено
+func screen(screener ThreatScreener, req Request) *storage.LinkThreat {
+	if screener == nil {
+		return nil
+	}
+
+	urls := []string{req.URL}
+	if req.FallbackURL != "" {
+		urls = append(urls, req.FallbackURL)
+	}
+	for _, t := range req.Targets {
+		urls = append(urls, t.URL)
 	}
+
+	threatType, ok := screener.Match(urls...)
+	if !ok {
+		return nil
+	}
+
+	now := time.Now()
+
+	return &storage.LinkThreat{Status: storage.ThreatFlagged, Type: threatType, FlaggedAt: &now}
 }
 
-func responseOK(w http.ResponseWriter, r *http.Request, alias string) {
-	render.JSON(w, r, Response{
-		Response: resp.OK(),
-		Alias:    alias,
-	})
+// randomAlias генерирует случайный алиас, который проходит политику алиасов
+// и не зарезервирован администратором
+func randomAlias(urlSaver URLSaver, policy *aliaspolicy.Policy, domain string) (string, error) {
+	for i := 0; i < aliasAttempts; i++ {
+		// используем собственный генератор случайных строк
+		alias := random.NewRandomString(aliasLength)
+		if policy.Check(alias) != nil {
+			continue
+		}
+
+		reserved, err := urlSaver.IsAliasReserved(domain, alias)
+		if err != nil {
+			return "", err
+		}
+		if !reserved {
+			return alias, nil
+		}
+	}
+
+	return "", errors.New("no acceptable alias generated")
+}
+
+func targets(reqTargets []TargetRequest) []storage.Target {
+	res := make([]storage.Target, 0, len(reqTargets))
+	for _, t := range reqTargets {
+		res = append(res, storage.Target{URL: t.URL, Weight: t.Weight})
+	}
+
+	return res
 }
@@ -4,17 +4,19 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"url-shortener/internal/http-server/handlers/url/save"
 	"url-shortener/internal/http-server/handlers/url/save/mocks"
+	"url-shortener/internal/lib/aliaspolicy"
 	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/storage"
 )
 
 func TestSaveHandler(t *testing.T) {
@@ -22,8 +24,16 @@ func TestSaveHandler(t *testing.T) {
 		name      string // Имя теста
 		alias     string // Отправляемый alias
 		url       string // Отправляемый URL
+		notBefore string // Начало окна активности
+		notAfter  string // Конец окна активности
+		timezone  string // Часовой пояс для окна активности
+		domain    string // Короткий домен
+		verified  bool   // Подтвержден ли домен
+		reserved  bool   // Зарезервирован ли алиас администратором
+		threat    string // Тип угрозы, если адрес есть в списке опасных адресов
 		respError string // Какую ошибку мы должны получить?
 		mockError error  // Ошибку, которую вернёт мок
+		code      int    // Ожидаемый код ответа, если не 200
 	}{
 		{
 			name:  "Success",
@@ -49,12 +59,82 @@ func TestSaveHandler(t *testing.T) {
 			alias:     "some_alias",
 			respError: "field URL is not a valid URL",
 		},
+		{
+			name:      "Activation window",
+			alias:     "campaign",
+			url:       "https://google.com",
+			notBefore: "2030-01-01T10:00",
+			notAfter:  "2030-02-01T10:00:00+03:00",
+			timezone:  "Europe/Moscow",
+		},
+		{
+			name:      "Invalid window",
+			alias:     "campaign",
+			url:       "https://google.com",
+			notBefore: "2030-02-01",
+			notAfter:  "2030-01-01",
+			respError: "not_after must be later than not_before",
+		},
+		{
+			name:      "Unknown timezone",
+			alias:     "campaign",
+			url:       "https://google.com",
+			notBefore: "2030-01-01T10:00",
+			timezone:  "Mars/Olympus",
+			respError: "unknown timezone: Mars/Olympus",
+		},
+		{
+			name:     "Verified domain",
+			alias:    "promo",
+			url:      "https://google.com",
+			domain:   "Go.Example.com",
+			verified: true,
+		},
+		{
+			name:      "Unverified domain",
+			alias:     "promo",
+			url:       "https://google.com",
+			domain:    "go.example.com",
+			respError: "unknown or unverified domain",
+		},
+		{
+			name:      "Reserved word",
+			alias:     "admin",
+			url:       "https://google.com",
+			respError: "alias is reserved",
+		},
+		{
+			name:      "Reserved by admin",
+			alias:     "launch",
+			url:       "https://google.com",
+			reserved:  true,
+			respError: "alias is reserved",
+		},
+		{
+			name:      "Invalid alias",
+			alias:     "bad/alias",
+			url:       "https://google.com",
+			respError: "invalid alias: allowed characters are a-z, A-Z, 0-9, '-' and '_'",
+		},
+		{
+			name:      "Profane alias",
+			alias:     "darn1t",
+			url:       "https://google.com",
+			respError: "alias contains a disallowed word",
+		},
+		{
+			name:   "Threat",
+			alias:  "download",
+			url:    "http://evil.example/setup.exe",
+			threat: "MALWARE",
+		},
 		{
 			name:      "SaveURL Error",
 			alias:     "test_alias",
 			url:       "https://google.com",
 			respError: "failed to add url",
 			mockError: errors.New("unexpected error"),
+			code:      http.StatusInternalServerError,
 		},
 	}
 
@@ -66,22 +146,56 @@ func TestSaveHandler(t *testing.T) {
 			// Создаем объект мока стораджа
 			urlSaverMock := mocks.NewURLSaver(t)
 
+			if tc.domain != "" {
+				domain := storage.Domain{Host: "go.example.com"}
+				if tc.verified {
+					verifiedAt := time.Now()
+					domain.VerifiedAt = &verifiedAt
+				}
+				urlSaverMock.On("GetDomain", "go.example.com").Return(domain, nil).Once()
+			}
+
+			urlSaverMock.On("IsAliasReserved", mock.Anything, mock.Anything).Return(tc.reserved, nil).Maybe()
+
 			// Если ожидается успешный ответ, значит к моку точно будет вызов
 			// Либо даже если в ответе ожидаем ошибку,
 			// но мок должен ответить с ошибкой, к нему тоже будет запрос:
 			if tc.respError == "" || tc.mockError != nil {
 				// Сообщаем моку, какой к нему будет запрос, и что надо вернуть
-				urlSaverMock.On("SaveURL", tc.url, mock.AnythingOfType("string")).
+				urlSaverMock.On("SaveLink", mock.MatchedBy(func(link storage.Link) bool {
+					return link.URL == tc.url && link.Alias != "" && (tc.domain == "" || link.Domain == "go.example.com") &&
+						(tc.threat == "" && link.Threat == nil || link.Threat.Blocked() && link.Threat.Type == tc.threat)
+				}), mock.Anything).
 					Return(int64(1), tc.mockError).
 					Once() // Запрос будет ровно один
 			}
 			// Создаем наш хэндлер
-			handler := save.New(slogdiscard.NewDiscardLogger(), urlSaverMock)
+			policy := aliaspolicy.New(0, 0)
+			policy.SetProfanity([]string{"darn"})
+			// Метаданные страницы запрашиваются только для сохраненной ссылки
+			previewMock := mocks.NewPreviewFetcher(t)
+			if tc.respError == "" {
+				previewMock.On("Enqueue", int64(1)).Once()
+			}
 
-			input := fmt.Sprintf(`{"url": "%s", "alias": "%s"}`, tc.url, tc.alias)
+			// Адреса проверяются по списку опасных адресов только у запросов, прошедших проверки
+			screenerMock := mocks.NewThreatScreener(t)
+			screenerMock.On("Match", tc.url).Return(tc.threat, tc.threat != "").Maybe()
+
+			handler := save.New(slogdiscard.NewDiscardLogger(), urlSaverMock, policy, previewMock, screenerMock)
+
+			input, err := json.Marshal(save.Request{
+				URL:       tc.url,
+				Alias:     tc.alias,
+				NotBefore: tc.notBefore,
+				NotAfter:  tc.notAfter,
+				Timezone:  tc.timezone,
+				Domain:    tc.domain,
+			})
+			require.NoError(t, err)
 
 			// Формируем тело запроса
-			req, err := http.NewRequest(http.MethodPost, "/save", bytes.NewReader([]byte(input)))
+			req, err := http.NewRequest(http.MethodPost, "/save", bytes.NewReader(input))
 			require.NoError(t, err)
 
 			// Создаем ResponseRecorder для записи ответа хэндлера
@@ -91,7 +205,11 @@ func TestSaveHandler(t *testing.T) {
 			handler.ServeHTTP(rr, req)
 
 			// Проверяем, что статус ответа корректный
-			require.Equal(t, rr.Code, http.StatusOK)
+			code := http.StatusOK
+			if tc.code != 0 {
+				code = tc.code
+			}
+			require.Equal(t, code, rr.Code)
 
 			body := rr.Body.String()
 
@@ -102,6 +220,7 @@ func TestSaveHandler(t *testing.T) {
 
 			// Проверяем наличие требуемой ошибки в ответе
 			require.Equal(t, tc.respError, resp.Error)
+			require.Equal(t, tc.threat, resp.Threat)
 
 			// Другие проверки
 		})
@@ -0,0 +1,111 @@
+// internal/http-server/handlers/url/stats/stats.go
+
+package stats
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+
+	"url-shortener/internal/http-server/middleware/auth"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/hostname"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/storage"
+)
+
+// Variant - статистика варианта A/B-теста
+type Variant struct {
+	storage.Target
+	Share float64 `json:"share"` // доля переходов варианта среди всех переходов на варианты
+}
+
+// структура ответа
+type Response struct {
+	resp.Response
+	Alias    string    `json:"alias,omitempty"`
+	Clicks   int64     `json:"clicks"`
+	Variants []Variant `json:"variants,omitempty"`
+}
+
+// StatsGetter is an interface for getting link with its counters.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=StatsGetter
+type StatsGetter interface {
+	GetLink(domain string, alias string) (storage.Link, error)
+	GetMember(workspaceID int64, uid int64) (storage.Member, error)
+}
+
+// New Конструктор обработчика статистики переходов по ссылке.
+// Ссылка на дополнительном коротком домене адресуется GET-параметром domain
+func New(log *slog.Logger, statsGetter StatsGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.stats.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		alias := chi.URLParam(r, "alias")
+
+		link, err := statsGetter.GetLink(hostname.Normalize(r.URL.Query().Get("domain")), alias)
+		if errors.Is(err, storage.ErrURLNotFound) {
+			log.Info("url not found", slog.String("alias", alias))
+
+			render.JSON(w, r, resp.Error("not found"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to get url", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("internal error"))
+
+			return
+		}
+
+		// Статистику ссылок рабочего пространства видят все его участники, личных ссылок - только их автор
+		if err := auth.AuthorizeLink(r.Context(), statsGetter, link, storage.RoleViewer); err != nil {
+			if status := auth.AccessStatus(err); status != 0 {
+				log.Info("access denied", slog.Int64("workspace_id", link.WorkspaceID), sl.Err(err))
+
+				render.Status(r, status)
+				render.JSON(w, r, resp.Error(err.Error()))
+
+				return
+			}
+
+			log.Error("failed to check access", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("internal error"))
+
+			return
+		}
+
+		var variantClicks int64
+		for _, target := range link.Targets {
+			variantClicks += target.Clicks
+		}
+
+		variants := make([]Variant, 0, len(link.Targets))
+		for _, target := range link.Targets {
+			v := Variant{Target: target}
+			if variantClicks > 0 {
+				v.Share = float64(target.Clicks) / float64(variantClicks)
+			}
+			variants = append(variants, v)
+		}
+
+		render.JSON(w, r, Response{
+			Response: resp.OK(),
+			Alias:    link.Alias,
+			Clicks:   link.Clicks,
+			Variants: variants,
+		})
+	}
+}
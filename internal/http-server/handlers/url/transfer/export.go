@@ -0,0 +1,150 @@
+// internal/http-server/handlers/url/transfer/export.go
+
+// Package transfer - выгрузка ссылок в файл и загрузка ссылок из файла (см. пакет linkfile)
+package transfer
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+
+	"url-shortener/internal/http-server/middleware/auth"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/hostname"
+	"url-shortener/internal/lib/linkfile"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/storage"
+)
+
+// LinkExporter is an interface for exporting saved links.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=LinkExporter
+type LinkExporter interface {
+	ExportLinks(filter storage.ListFilter, fn func(storage.Link) error) error
+	GetMember(workspaceID int64, uid int64) (storage.Member, error)
+}
+
+// NewExport Конструктор обработчика выгрузки ссылок.
+// GET-параметр format - csv (по умолчанию) или ndjson. Фильтры state, domain и workspace_id -
+// как у списка ссылок; пользователь (JWT-токен или API-ключ) выгружает только свои личные ссылки
+// и ссылки своих пространств, все ссылки - только администратор. Ссылки выгружаются вместе с правилами, вариантами A/B-теста и счетчиками переходов
+func NewExport(log *slog.Logger, linkExporter LinkExporter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.transfer.NewExport"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = linkfile.FormatCSV
+		}
+		if format != linkfile.FormatCSV && format != linkfile.FormatNDJSON {
+			log.Info("invalid export format", slog.String("format", format))
+
+			render.JSON(w, r, resp.Error("invalid format, expected one of: csv, ndjson"))
+
+			return
+		}
+
+		filter := storage.ListFilter{
+			State:  r.URL.Query().Get("state"),
+			Domain: hostname.Normalize(r.URL.Query().Get("domain")),
+		}
+
+		switch filter.State {
+		case "", storage.StateActive, storage.StateScheduled, storage.StateEnded:
+		default:
+			log.Info("invalid state filter", slog.String("state", filter.State))
+
+			render.JSON(w, r, resp.Error("invalid state, expected one of: active, scheduled, ended"))
+
+			return
+		}
+
+		if v := r.URL.Query().Get("workspace_id"); v != "" {
+			id, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || id < 0 {
+				log.Info("invalid workspace filter", slog.String("workspace_id", v))
+
+				render.JSON(w, r, resp.Error("invalid workspace_id"))
+
+				return
+			}
+			filter.WorkspaceID = id
+		}
+
+		if !authorize(log, w, r, linkExporter, filter.WorkspaceID, storage.RoleViewer) {
+			return
+		}
+
+		if !auth.IsAdminFromContext(r.Context()) {
+			uid, ok := auth.UIDFromContext(r.Context())
+			if !ok {
+				log.Info("export without user")
+
+				render.Status(r, http.StatusUnauthorized)
+				render.JSON(w, r, resp.Error(auth.ErrUnauthorized.Error()))
+
+				return
+			}
+			filter.MemberUID = uid
+		}
+
+		w.Header().Set("Content-Type", linkfile.ContentType(format))
+		w.Header().Set("Content-Disposition", `attachment; filename="links.`+format+`"`)
+
+		// Ошибку после начала выгрузки клиенту уже не сообщить - только в лог
+		writer, err := linkfile.NewWriter(format, w)
+		if err != nil {
+			log.Error("failed to create writer", sl.Err(err))
+
+			return
+		}
+
+		count := 0
+		err = linkExporter.ExportLinks(filter, func(link storage.Link) error {
+			count++
+			return writer.Write(linkfile.FromLink(link))
+		})
+		if err == nil {
+			err = writer.Flush()
+		}
+		if err != nil {
+			log.Error("failed to export links", slog.Int("written", count), sl.Err(err))
+
+			return
+		}
+
+		log.Info("links exported", slog.Int("count", count), slog.String("format", format))
+	}
+}
+
+// authorize проверяет роль пользователя в рабочем пространстве и при отказе сам пишет ответ
+func authorize(log *slog.Logger, w http.ResponseWriter, r *http.Request, members auth.MemberGetter, workspaceID int64, minRole string) bool {
+	err := auth.Authorize(r.Context(), members, workspaceID, minRole)
+	if err == nil {
+		return true
+	}
+
+	if status := auth.AccessStatus(err); status != 0 {
+		log.Info("access denied", slog.Int64("workspace_id", workspaceID), sl.Err(err))
+
+		render.Status(r, status)
+		render.JSON(w, r, resp.Error(err.Error()))
+
+		return false
+	}
+
+	log.Error("failed to check access", sl.Err(err))
+
+	render.Status(r, http.StatusInternalServerError)
+	render.JSON(w, r, resp.Error("internal error"))
+
+	return false
+}
@@ -0,0 +1,622 @@
+// internal/http-server/handlers/url/transfer/import.go
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+
+	"url-shortener/internal/http-server/middleware/auth"
+	"url-shortener/internal/lib/aliaspolicy"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/hostname"
+	"url-shortener/internal/lib/linkfile"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/lib/threatlist"
+	"url-shortener/internal/storage"
+)
+
+const (
+	// Максимальный размер файла импорта
+	maxImportSize = 10 << 20
+	// Максимальное число ссылок в одном файле
+	maxImportRows = 10000
+	// Сколько суффиксов перебирать при переименовании (alias-2 ... alias-99)
+	maxRenameSuffix = 99
+)
+
+// Стратегии разрешения конфликтов алиасов
+const (
+	ConflictSkip      = "skip"
+	ConflictOverwrite = "overwrite"
+	ConflictRename    = "rename"
+)
+
+// Итог обработки строки файла
+const (
+	StatusCreated = "created"
+	StatusUpdated = "updated"
+	StatusRenamed = "renamed"
+	StatusSkipped = "skipped"
+	StatusFailed  = "error"
+)
+
+// RowResult - итог обработки одной ссылки из файла
+type RowResult struct {
+	Row      int    `json:"row"` // номер записи в файле, с 1 (без строки заголовка)
+	Domain   string `json:"domain,omitempty"`
+	Alias    string `json:"alias,omitempty"`
+	Status   string `json:"status"`
+	NewAlias string `json:"new_alias,omitempty"` // для renamed
+	Error    string `json:"error,omitempty"`
+}
+
+// Summary - число строк файла по итогам обработки
+type Summary struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Renamed int `json:"renamed"`
+	Skipped int `json:"skipped"`
+	Failed  int `json:"failed"`
+}
+
+// ImportResponse - отчет об импорте
+type ImportResponse struct {
+	resp.Response
+	DryRun  bool        `json:"dry_run"`
+	Summary Summary     `json:"summary"`
+	Rows    []RowResult `json:"rows"`
+}
+
+// LinkImporter is an interface for importing links.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=LinkImporter
+type LinkImporter interface {
+	GetLink(domain string, alias string) (storage.Link, error)
+	GetDeletedLink(domain string, alias string) (storage.Link, error)
+	SaveLink(link storage.Link, actor storage.Actor) (int64, error)
+	ReplaceLink(link storage.Link, actor storage.Actor) error
+	GetDomain(host string) (storage.Domain, error)
+	GetMember(workspaceID int64, uid int64) (storage.Member, error)
+	IsAliasReserved(domain string, alias string) (bool, error)
+}
+
+// ThreatScreener is an interface for checking target URLs against the threat list.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=ThreatScreener
+type ThreatScreener interface {
+	Match(urls ...string) (string, bool)
+}
+
+// importRecord - проверки ссылки из файла, те же, что при создании ссылки (см. save.Request)
+type importRecord struct {
+	Alias        string         `validate:"required"`
+	URL          string         `validate:"required,url"`
+	FallbackURL  string         `validate:"omitempty,url"`
+	Targets      []importTarget `validate:"max=20,dive"`
+	Rules        []importRule   `validate:"max=50,dive"`
+	Sticky       string         `validate:"omitempty,oneof=cookie hash"`
+	UTMSource    string         `validate:"max=200"`
+	UTMMedium    string         `validate:"max=200"`
+	UTMCampaign  string         `validate:"max=200"`
+	QueryPolicy  string         `validate:"omitempty,oneof=drop merge override"`
+	RedirectCode int            `validate:"omitempty,oneof=301 302 307 308"`
+	Title        string         `validate:"max=300"`
+}
+
+type importTarget struct {
+	URL    string `validate:"required,url"`
+	Weight int    `validate:"min=1,max=10000"`
+}
+
+type importRule struct {
+	Platform  string `validate:"omitempty,oneof=ios android desktop"`
+	Language  string `validate:"omitempty,bcp47_language_tag"`
+	Country   string `validate:"omitempty,len=2,alpha"`
+	TargetURL string `validate:"required,url"`
+}
+
+// NewImport Конструктор обработчика загрузки ссылок из файла.
+// Тело запроса - файл импорта (не больше 10 МБ и 10000 ссылок). GET-параметры:
+//   - format - csv (по умолчанию), ndjson или bitly,
+//   - on_conflict - что делать, если алиас занят: skip (по умолчанию) - пропустить ссылку,
+//     overwrite - заменить существующую ссылку, rename - сохранить под алиасом с суффиксом (alias-2, alias-3, ...),
+//   - dry_run=true - только проверить файл и вернуть отчет, ничего не сохраняя,
+//   - domain - короткий домен для ссылок, у которых он не указан,
+//   - workspace_id - рабочее пространство новых ссылок (нужна роль editor или owner).
+//
+// Каждая ссылка проходит те же проверки, что и при создании через API, а ее адреса перехода -
+// проверку по списку опасных адресов screener (nil - не проверяются): ссылка с адресом из списка
+// не импортируется. Ошибка в одной ссылке не прерывает импорт. В ответе - итог по каждой ссылке и сводка
+func NewImport(log *slog.Logger, linkImporter LinkImporter, policy *aliaspolicy.Policy, screener ThreatScreener) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.transfer.NewImport"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		query := r.URL.Query()
+
+		format := query.Get("format")
+		if format == "" {
+			format = linkfile.FormatCSV
+		}
+
+		onConflict := query.Get("on_conflict")
+		switch onConflict {
+		case "":
+			onConflict = ConflictSkip
+		case ConflictSkip, ConflictOverwrite, ConflictRename:
+		default:
+			log.Info("invalid conflict strategy", slog.String("on_conflict", onConflict))
+
+			render.JSON(w, r, resp.Error("invalid on_conflict, expected one of: skip, overwrite, rename"))
+
+			return
+		}
+
+		dryRun := false
+		if v := query.Get("dry_run"); v != "" {
+			var err error
+			if dryRun, err = strconv.ParseBool(v); err != nil {
+				log.Info("invalid dry_run", slog.String("dry_run", v))
+
+				render.JSON(w, r, resp.Error("invalid dry_run"))
+
+				return
+			}
+		}
+
+		var workspaceID int64
+		if v := query.Get("workspace_id"); v != "" {
+			id, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || id < 0 {
+				log.Info("invalid workspace", slog.String("workspace_id", v))
+
+				render.JSON(w, r, resp.Error("invalid workspace_id"))
+
+				return
+			}
+			workspaceID = id
+		}
+
+		// Создавать ссылки в рабочем пространстве могут только его редакторы и владельцы
+		if !authorize(log, w, r, linkImporter, workspaceID, storage.RoleEditor) {
+			return
+		}
+
+		reader, err := linkfile.NewReader(format, http.MaxBytesReader(w, r.Body, maxImportSize))
+		if err != nil {
+			log.Info("failed to open import file", sl.Err(err))
+
+			render.JSON(w, r, resp.Error(err.Error()))
+
+			return
+		}
+
+		ownerUID, _ := auth.UIDFromContext(r.Context())
+
+		imp := &importer{
+			log:           log,
+			links:         linkImporter,
+			policy:        policy,
+			screener:      screener,
+			ctx:           r.Context(),
+			actor:         auth.ActorFromRequest(r),
+			isAdmin:       auth.IsAdminFromContext(r.Context()),
+			dryRun:        dryRun,
+			onConflict:    onConflict,
+			defaultDomain: hostname.Normalize(query.Get("domain")),
+			workspaceID:   workspaceID,
+			ownerUID:      ownerUID,
+			domains:       make(map[string]error),
+			planned:       make(map[string]bool),
+		}
+
+		res := ImportResponse{
+			Response: resp.OK(),
+			DryRun:   dryRun,
+			Rows:     make([]RowResult, 0),
+		}
+
+		for row := 1; ; row++ {
+			rec, err := reader.Read()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			if row > maxImportRows {
+				log.Info("import file has too many rows")
+
+				res.Response = resp.Error(fmt.Sprintf("too many rows, at most %d are imported", maxImportRows))
+
+				break
+			}
+
+			var result RowResult
+			switch {
+			case errors.Is(err, linkfile.ErrInvalidRecord):
+				result = RowResult{Status: StatusFailed, Error: err.Error()}
+			case err != nil:
+				// Файл дальше не прочитать (обрезан или слишком большой)
+				log.Info("failed to read import file", slog.Int("row", row), sl.Err(err))
+
+				res.Response = resp.Error("failed to read file: " + err.Error())
+			default:
+				result = imp.importRecord(rec)
+			}
+			if res.Status == resp.StatusError {
+				break
+			}
+
+			result.Row = row
+			res.Rows = append(res.Rows, result)
+			res.Summary.add(result.Status)
+		}
+
+		log.Info("links imported",
+			slog.Bool("dry_run", dryRun),
+			slog.String("format", format),
+			slog.Any("summary", res.Summary),
+		)
+
+		// Отчет с ошибкой сервера отдаем с кодом 500: такой ответ не запоминается
+		// по Idempotency-Key, и повтор запроса заново импортирует не сохраненные ссылки
+		if imp.internalErr {
+			render.Status(r, http.StatusInternalServerError)
+		}
+
+		render.JSON(w, r, res)
+	}
+}
+
+func (s *Summary) add(status string) {
+	switch status {
+	case StatusCreated:
+		s.Created++
+	case StatusUpdated:
+		s.Updated++
+	case StatusRenamed:
+		s.Renamed++
+	case StatusSkipped:
+		s.Skipped++
+	default:
+		s.Failed++
+	}
+}
+
+// importer - состояние одного импорта
+type importer struct {
+	log      *slog.Logger
+	links    LinkImporter
+	policy   *aliaspolicy.Policy
+	screener ThreatScreener
+
+	ctx           context.Context
+	actor         storage.Actor
+	isAdmin       bool
+	dryRun        bool
+	onConflict    string
+	defaultDomain string
+	workspaceID   int64
+	ownerUID      int64
+
+	// результат проверки доменов, чтобы не запрашивать один домен на каждую ссылку
+	domains map[string]error
+	// алиасы, которые создал бы пробный импорт: домен + "/" + алиас
+	planned map[string]bool
+	// хотя бы одна ссылка не импортирована из-за ошибки сервера
+	internalErr bool
+}
+
+// errInternal - текст ошибки строки, подробности которой пишутся только в лог
+var errInternal = errors.New("internal error")
+
+// importRecord проверяет и сохраняет одну ссылку
+func (imp *importer) importRecord(rec linkfile.Record) RowResult {
+	rec.Domain = hostname.Normalize(rec.Domain)
+	if rec.Domain == "" {
+		rec.Domain = imp.defaultDomain
+	}
+
+	result := RowResult{Domain: rec.Domain, Alias: rec.Alias}
+
+	fail := func(err error) RowResult {
+		if errors.Is(err, errInternal) {
+			imp.internalErr = true
+		}
+		result.Status = StatusFailed
+		result.Error = err.Error()
+		return result
+	}
+
+	if err := validator.New().Struct(validationRecord(rec)); err != nil {
+		return fail(err)
+	}
+	if err := imp.checkAlias(rec.Domain, rec.Alias); err != nil {
+		return fail(err)
+	}
+	if err := imp.checkDomain(rec.Domain); err != nil {
+		return fail(err)
+	}
+
+	link := rec.Link()
+	if err := imp.screen(link); err != nil {
+		return fail(err)
+	}
+
+	link.WorkspaceID = imp.workspaceID
+	link.OwnerUID = imp.ownerUID
+
+	existing, deleted, err := imp.lookup(link.Domain, link.Alias)
+	if err != nil {
+		return fail(err)
+	}
+
+	if existing == nil {
+		created, err := imp.save(link)
+		if err != nil {
+			return fail(err)
+		}
+		if created {
+			result.Status = StatusCreated
+			return result
+		}
+
+		// Алиас заняли между проверкой и сохранением
+		if existing, deleted, err = imp.lookup(link.Domain, link.Alias); err != nil {
+			return fail(err)
+		}
+	}
+
+	switch imp.onConflict {
+	case ConflictOverwrite:
+		if deleted {
+			return fail(errors.New("alias belongs to a deleted link, restore it first"))
+		}
+		if err := imp.overwrite(link, existing); err != nil {
+			return fail(err)
+		}
+		result.Status = StatusUpdated
+	case ConflictRename:
+		alias, err := imp.rename(link)
+		if err != nil {
+			return fail(err)
+		}
+		result.Status = StatusRenamed
+		result.NewAlias = alias
+	default:
+		result.Status = StatusSkipped
+	}
+
+	return result
+}
+
+// screen проверяет адреса перехода ссылки по списку опасных адресов
+func (imp *importer) screen(link storage.Link) error {
+	if imp.screener == nil {
+		return nil
+	}
+
+	urls := []string{link.URL}
+	if link.FallbackURL != "" {
+		urls = append(urls, link.FallbackURL)
+	}
+	for _, t := range link.Targets {
+		urls = append(urls, t.URL)
+	}
+	for _, rule := range link.Rules {
+		urls = append(urls, rule.TargetURL)
+	}
+
+	if threatType, ok := imp.screener.Match(urls...); ok {
+		imp.log.Warn("target is in the threat list", slog.String("alias", link.Alias), slog.String("threat", threatType))
+
+		return fmt.Errorf("%w: %s", threatlist.ErrListed, threatType)
+	}
+
+	return nil
+}
+
+// checkAlias проверяет алиас политикой алиасов и резервированием администраторами
+func (imp *importer) checkAlias(domain, alias string) error {
+	if err := imp.policy.Check(alias); err != nil {
+		return err
+	}
+
+	if imp.isAdmin {
+		return nil
+	}
+
+	reserved, err := imp.links.IsAliasReserved(domain, alias)
+	if err != nil {
+		imp.log.Error("failed to check alias reservation", sl.Err(err))
+
+		return errInternal
+	}
+	if reserved {
+		return aliaspolicy.ErrReserved
+	}
+
+	return nil
+}
+
+// checkDomain проверяет, что домен зарегистрирован и подтвержден
+func (imp *importer) checkDomain(domain string) error {
+	if domain == "" {
+		return nil
+	}
+
+	if err, ok := imp.domains[domain]; ok {
+		return err
+	}
+
+	d, err := imp.links.GetDomain(domain)
+	switch {
+	case errors.Is(err, storage.ErrDomainNotFound) || err == nil && !d.Verified():
+		err = errors.New("unknown or unverified domain")
+	case err != nil:
+		imp.log.Error("failed to get domain", slog.String("domain", domain), sl.Err(err))
+
+		err = errInternal
+	}
+
+	imp.domains[domain] = err
+
+	return err
+}
+
+// lookup ищет ссылку с алиасом, в том числе удаленную (ее алиас занят до очистки)
+// и запланированную пробным импортом (тогда existing - пустая ссылка)
+func (imp *importer) lookup(domain, alias string) (existing *storage.Link, deleted bool, err error) {
+	if imp.planned[domain+"/"+alias] {
+		return &storage.Link{Domain: domain, Alias: alias, WorkspaceID: imp.workspaceID, OwnerUID: imp.ownerUID}, false, nil
+	}
+
+	link, err := imp.links.GetLink(domain, alias)
+	if err == nil {
+		return &link, false, nil
+	}
+	if !errors.Is(err, storage.ErrURLNotFound) {
+		imp.log.Error("failed to get link", sl.Err(err))
+
+		return nil, false, errInternal
+	}
+
+	link, err = imp.links.GetDeletedLink(domain, alias)
+	if err == nil {
+		return &link, true, nil
+	}
+	if !errors.Is(err, storage.ErrURLNotFound) {
+		imp.log.Error("failed to get deleted link", sl.Err(err))
+
+		return nil, false, errInternal
+	}
+
+	return nil, false, nil
+}
+
+// save сохраняет новую ссылку. false - алиас уже занят
+func (imp *importer) save(link storage.Link) (bool, error) {
+	if imp.dryRun {
+		imp.planned[link.Domain+"/"+link.Alias] = true
+
+		return true, nil
+	}
+
+	_, err := imp.links.SaveLink(link, imp.actor)
+	if errors.Is(err, storage.ErrURLExists) {
+		return false, nil
+	}
+	if err != nil {
+		imp.log.Error("failed to save link", sl.Err(err))
+
+		return false, errInternal
+	}
+
+	return true, nil
+}
+
+// errOwnerConflict - алиас занят личной ссылкой другого пользователя
+var errOwnerConflict = errors.New("alias is taken by another user's link")
+
+// overwrite заменяет существующую ссылку. Нужна роль editor в пространстве этой ссылки,
+// а личную ссылку может заменить только ее автор
+func (imp *importer) overwrite(link storage.Link, existing *storage.Link) error {
+	if err := auth.AuthorizeLink(imp.ctx, imp.links, *existing, storage.RoleEditor); err != nil {
+		if existing.WorkspaceID == 0 && errors.Is(err, auth.ErrForbidden) {
+			return errOwnerConflict
+		}
+		if auth.AccessStatus(err) != 0 {
+			return err
+		}
+
+		imp.log.Error("failed to check access", sl.Err(err))
+
+		return errInternal
+	}
+
+	if imp.dryRun {
+		return nil
+	}
+
+	err := imp.links.ReplaceLink(link, imp.actor)
+	if errors.Is(err, storage.ErrURLNotFound) {
+		return errors.New("link was deleted during import")
+	}
+	if err != nil {
+		imp.log.Error("failed to replace link", sl.Err(err))
+
+		return errInternal
+	}
+
+	return nil
+}
+
+// rename сохраняет ссылку под первым свободным алиасом вида alias-N
+func (imp *importer) rename(link storage.Link) (string, error) {
+	base := link.Alias
+
+	for i := 2; i <= maxRenameSuffix; i++ {
+		link.Alias = fmt.Sprintf("%s-%d", base, i)
+
+		if err := imp.checkAlias(link.Domain, link.Alias); err != nil {
+			if errors.Is(err, errInternal) {
+				return "", err
+			}
+			continue
+		}
+
+		existing, _, err := imp.lookup(link.Domain, link.Alias)
+		if err != nil {
+			return "", err
+		}
+		if existing != nil {
+			continue
+		}
+
+		created, err := imp.save(link)
+		if err != nil {
+			return "", err
+		}
+		if created {
+			return link.Alias, nil
+		}
+	}
+
+	return "", errors.New("no free alias to rename to")
+}
+
+func validationRecord(rec linkfile.Record) importRecord {
+	v := importRecord{
+		Alias:        rec.Alias,
+		URL:          rec.URL,
+		FallbackURL:  rec.FallbackURL,
+		Sticky:       rec.Sticky,
+		UTMSource:    rec.UTMSource,
+		UTMMedium:    rec.UTMMedium,
+		UTMCampaign:  rec.UTMCampaign,
+		QueryPolicy:  rec.QueryPolicy,
+		RedirectCode: rec.RedirectCode,
+		Title:        rec.Title,
+	}
+
+	for _, t := range rec.Targets {
+		v.Targets = append(v.Targets, importTarget{URL: t.URL, Weight: t.Weight})
+	}
+	for _, rule := range rec.Rules {
+		v.Rules = append(v.Rules, importRule(rule))
+	}
+
+	return v
+}
@@ -0,0 +1,276 @@
+package transfer_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/handlers/url/transfer"
+	"url-shortener/internal/http-server/handlers/url/transfer/mocks"
+	"url-shortener/internal/http-server/middleware/auth"
+	"url-shortener/internal/lib/aliaspolicy"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/lib/threatlist"
+	"url-shortener/internal/storage"
+)
+
+const importFile = "alias,url,title\n" +
+	"fresh,https://example.com/fresh,Fresh\n" +
+	"taken,https://example.com/taken,\n" +
+	"gone,https://example.com/gone,\n" +
+	"bad,not-a-url,\n" +
+	"fresh,https://example.com/again,\n"
+
+func TestImportHandler(t *testing.T) {
+	cases := []struct {
+		name       string
+		query      string
+		statuses   []string
+		newAliases []string
+		rowErrors  map[int]string // ожидаемые ошибки строк по номеру строки
+		summary    transfer.Summary
+		setup      func(m *mocks.LinkImporter)
+	}{
+		{
+			name:     "Skip",
+			query:    "",
+			statuses: []string{"created", "skipped", "skipped", "error", "skipped"},
+			summary:  transfer.Summary{Created: 1, Skipped: 3, Failed: 1},
+			setup: func(m *mocks.LinkImporter) {
+				m.On("SaveLink", mock.MatchedBy(func(l storage.Link) bool {
+					return l.Alias == "fresh" && l.Title == "Fresh"
+				}), mock.Anything).Return(int64(1), nil).Once()
+				// Вторая строка с алиасом fresh находит уже созданную ссылку
+				m.On("GetLink", "", "fresh").Return(storage.Link{}, storage.ErrURLNotFound).Once()
+				m.On("GetLink", "", "fresh").Return(storage.Link{Alias: "fresh"}, nil).Once()
+			},
+		},
+		{
+			name:     "Dry run overwrite",
+			query:    "?dry_run=true&on_conflict=overwrite",
+			statuses: []string{"created", "updated", "error", "error", "updated"},
+			summary:  transfer.Summary{Created: 1, Updated: 2, Failed: 2},
+		},
+		{
+			name:      "Overwrite another user's link",
+			query:     "?dry_run=true&on_conflict=overwrite",
+			statuses:  []string{"created", "error", "error", "error", "updated"},
+			rowErrors: map[int]string{2: "alias is taken by another user's link"},
+			summary:   transfer.Summary{Created: 1, Updated: 1, Failed: 3},
+			setup: func(m *mocks.LinkImporter) {
+				m.On("GetLink", "", "taken").Return(storage.Link{Alias: "taken", OwnerUID: 7}, nil).Once()
+			},
+		},
+		{
+			name:       "Rename",
+			query:      "?on_conflict=rename",
+			statuses:   []string{"created", "renamed", "renamed", "error", "renamed"},
+			newAliases: []string{"", "taken-2", "gone-2", "", "fresh-3"},
+			summary:    transfer.Summary{Created: 1, Renamed: 3, Failed: 1},
+			setup: func(m *mocks.LinkImporter) {
+				m.On("SaveLink", mock.Anything, mock.Anything).Return(int64(1), nil)
+				m.On("GetLink", "", "fresh").Return(storage.Link{}, storage.ErrURLNotFound).Once()
+				m.On("GetLink", "", "fresh").Return(storage.Link{Alias: "fresh"}, nil).Once()
+				m.On("GetLink", "", "fresh-2").Return(storage.Link{Alias: "fresh-2"}, nil).Once()
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			importerMock := mocks.NewLinkImporter(t)
+			if tc.setup != nil {
+				tc.setup(importerMock)
+			}
+			importerMock.On("IsAliasReserved", "", mock.Anything).Return(false, nil).Maybe()
+			importerMock.On("GetLink", "", "taken").Return(storage.Link{Alias: "taken", OwnerUID: 42}, nil).Maybe()
+			importerMock.On("GetLink", "", mock.Anything).Return(storage.Link{}, storage.ErrURLNotFound).Maybe()
+			importerMock.On("GetDeletedLink", "", "gone").Return(storage.Link{Alias: "gone", OwnerUID: 42}, nil).Maybe()
+			importerMock.On("GetDeletedLink", "", mock.Anything).Return(storage.Link{}, storage.ErrURLNotFound).Maybe()
+
+			handler := transfer.NewImport(slogdiscard.NewDiscardLogger(), importerMock, aliaspolicy.New(0, 0), nil)
+
+			req := httptest.NewRequest(http.MethodPost, "/url/import"+tc.query, strings.NewReader(importFile))
+			req = req.WithContext(auth.WithUID(req.Context(), 42))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			var body transfer.ImportResponse
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+			require.Empty(t, body.Error)
+			require.Equal(t, tc.summary, body.Summary)
+			require.Len(t, body.Rows, len(tc.statuses))
+
+			for i, row := range body.Rows {
+				require.Equal(t, i+1, row.Row)
+				require.Equal(t, tc.statuses[i], row.Status, "row %d: %s", row.Row, row.Error)
+				if msg, ok := tc.rowErrors[row.Row]; ok {
+					require.Equal(t, msg, row.Error)
+				}
+				if tc.newAliases != nil {
+					require.Equal(t, tc.newAliases[i], row.NewAlias)
+				}
+			}
+		})
+	}
+}
+
+// threatList - список опасных адресов: адрес -> тип угрозы
+type threatList map[string]string
+
+func (l threatList) Match(urls ...string) (string, bool) {
+	for _, u := range urls {
+		if threat, ok := l[u]; ok {
+			return threat, true
+		}
+	}
+	return "", false
+}
+
+func TestImportHandler_ThreatList(t *testing.T) {
+	listed := threatList{
+		"https://evil.example/":      threatlist.Malware,
+		"https://evil.example/rule":  threatlist.SocialEngineering,
+		"https://evil.example/split": threatlist.UnwantedSoftware,
+	}
+
+	file := `{"alias":"clean","url":"https://example.com"}` + "\n" +
+		`{"alias":"main","url":"https://evil.example/"}` + "\n" +
+		`{"alias":"rule","url":"https://example.com","rules":[{"platform":"ios","target_url":"https://evil.example/rule"}]}` + "\n" +
+		`{"alias":"split","url":"https://example.com","targets":[{"url":"https://evil.example/split","weight":1}]}` + "\n"
+
+	importerMock := mocks.NewLinkImporter(t)
+	importerMock.On("IsAliasReserved", "", mock.Anything).Return(false, nil)
+	importerMock.On("GetLink", "", "clean").Return(storage.Link{}, storage.ErrURLNotFound).Once()
+	importerMock.On("GetDeletedLink", "", "clean").Return(storage.Link{}, storage.ErrURLNotFound).Once()
+
+	handler := transfer.NewImport(slogdiscard.NewDiscardLogger(), importerMock, aliaspolicy.New(0, 0), listed)
+
+	req := httptest.NewRequest(http.MethodPost, "/url/import?format=ndjson&dry_run=true", strings.NewReader(file))
+	req = req.WithContext(auth.WithUID(req.Context(), 42))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var body transfer.ImportResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	require.Equal(t, transfer.Summary{Created: 1, Failed: 3}, body.Summary)
+	require.Len(t, body.Rows, 4)
+	require.Equal(t, "target url is in the threat list: MALWARE", body.Rows[1].Error)
+	require.Equal(t, "target url is in the threat list: SOCIAL_ENGINEERING", body.Rows[2].Error)
+	require.Equal(t, "target url is in the threat list: UNWANTED_SOFTWARE", body.Rows[3].Error)
+}
+
+func TestImportHandler_StorageError(t *testing.T) {
+	importerMock := mocks.NewLinkImporter(t)
+	importerMock.On("IsAliasReserved", "", "fresh").Return(false, errors.New("storage is down")).Once()
+
+	handler := transfer.NewImport(slogdiscard.NewDiscardLogger(), importerMock, aliaspolicy.New(0, 0), nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/url/import", strings.NewReader("alias,url\nfresh,https://example.com/fresh\n"))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	// Ответ с ошибкой сервера не должен запоминаться по Idempotency-Key
+	require.Equal(t, http.StatusInternalServerError, rr.Code)
+
+	var body transfer.ImportResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	require.Equal(t, transfer.Summary{Failed: 1}, body.Summary)
+	require.Len(t, body.Rows, 1)
+	require.Equal(t, "internal error", body.Rows[0].Error)
+}
+
+func TestImportHandler_InvalidRequest(t *testing.T) {
+	cases := []struct {
+		name      string
+		query     string
+		body      string
+		respError string
+	}{
+		{
+			name:      "Unknown conflict strategy",
+			query:     "?on_conflict=merge",
+			respError: "invalid on_conflict, expected one of: skip, overwrite, rename",
+		},
+		{
+			name:      "Unknown format",
+			query:     "?format=xml",
+			respError: "unknown format: xml",
+		},
+		{
+			name:      "No url column",
+			body:      "alias,title\n",
+			respError: "csv header has no url column",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := transfer.NewImport(slogdiscard.NewDiscardLogger(), mocks.NewLinkImporter(t), aliaspolicy.New(0, 0), nil)
+
+			req := httptest.NewRequest(http.MethodPost, "/url/import"+tc.query, strings.NewReader(tc.body))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			var body transfer.ImportResponse
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+			require.Equal(t, tc.respError, body.Error)
+		})
+	}
+}
+
+func TestExportHandler(t *testing.T) {
+	exporterMock := mocks.NewLinkExporter(t)
+	exporterMock.On("ExportLinks", storage.ListFilter{Domain: "go.example.com"}, mock.Anything).
+		Return(func(_ storage.ListFilter, fn func(storage.Link) error) error {
+			return fn(storage.Link{Domain: "go.example.com", Alias: "abc", URL: "https://example.com", Clicks: 7})
+		}).Once()
+
+	handler := transfer.NewExport(slogdiscard.NewDiscardLogger(), exporterMock)
+
+	req := httptest.NewRequest(http.MethodGet, "/url/export?format=ndjson&domain=Go.Example.com", nil)
+	req = req.WithContext(auth.WithPrincipal(req.Context(), auth.Principal{Method: auth.MethodBasic, Name: "admin", IsAdmin: true}))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, "application/x-ndjson", rr.Header().Get("Content-Type"))
+	require.JSONEq(t, `{"domain":"go.example.com","alias":"abc","url":"https://example.com","clicks":7}`, rr.Body.String())
+}
+
+func TestExportHandler_UserScope(t *testing.T) {
+	// Пользователь выгружает только свои личные ссылки и ссылки своих пространств
+	exporterMock := mocks.NewLinkExporter(t)
+	exporterMock.On("ExportLinks", storage.ListFilter{MemberUID: 42}, mock.Anything).Return(nil).Once()
+
+	handler := transfer.NewExport(slogdiscard.NewDiscardLogger(), exporterMock)
+
+	req := httptest.NewRequest(http.MethodGet, "/url/export", nil)
+	req = req.WithContext(auth.WithUID(req.Context(), 42))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	// Без пользователя выгрузить можно только администратору
+	req = httptest.NewRequest(http.MethodGet, "/url/export", nil)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+}
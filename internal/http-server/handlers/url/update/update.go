@@ -0,0 +1,282 @@
+// internal/http-server/handlers/url/update/update.go
+
+package update
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+
+	"url-shortener/internal/http-server/middleware/auth"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/hostname"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/lib/threatlist"
+	"url-shortener/internal/lib/timeparse"
+	"url-shortener/internal/lib/utm"
+	"url-shortener/internal/storage"
+)
+
+// структура запроса.
+// Поля, которых нет в запросе (nil), не изменяются.
+// Пустая строка в not_before, not_after или fallback_url снимает соответствующее ограничение,
+// в UTM-метках - удаляет метку, в query_policy - возвращает политику по умолчанию.
+// redirect_code = 0 возвращает код редиректа по умолчанию
+type Request struct {
+	URL         *string `json:"url,omitempty" validate:"omitempty,url"`
+	NotBefore   *string `json:"not_before,omitempty"`
+	NotAfter    *string `json:"not_after,omitempty"`
+	Timezone    string  `json:"timezone,omitempty"`
+	FallbackURL *string `json:"fallback_url,omitempty"`
+
+	UTMSource   *string `json:"utm_source,omitempty" validate:"omitempty,max=200"`
+	UTMMedium   *string `json:"utm_medium,omitempty" validate:"omitempty,max=200"`
+	UTMCampaign *string `json:"utm_campaign,omitempty" validate:"omitempty,max=200"`
+	QueryPolicy *string `json:"query_policy,omitempty"`
+
+	RedirectCode *int    `json:"redirect_code,omitempty" validate:"omitempty,oneof=0 301 302 307 308"`
+	Interstitial *bool   `json:"interstitial,omitempty"`
+	Title        *string `json:"title,omitempty" validate:"omitempty,max=300"`
+}
+
+// структура ответа
+type Response struct {
+	resp.Response
+	Link *storage.Link `json:"link,omitempty"`
+}
+
+// URLUpdater is an interface for updating link by alias.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=URLUpdater
+type URLUpdater interface {
+	GetLink(domain string, alias string) (storage.Link, error)
+	UpdateLink(link storage.Link, actor storage.Actor) error
+	GetMember(workspaceID int64, uid int64) (storage.Member, error)
+}
+
+// ThreatScreener is an interface for checking target URLs against the threat list.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=ThreatScreener
+type ThreatScreener interface {
+	Match(urls ...string) (string, bool)
+}
+
+// New Конструктор обработчика изменения ссылки.
+// Ссылка на дополнительном коротком домене адресуется GET-параметром domain.
+// Новые адреса перехода проверяются по списку опасных адресов screener (nil - не проверяются):
+// адрес из списка не сохраняется
+func New(log *slog.Logger, urlUpdater URLUpdater, screener ThreatScreener) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.url.update.New"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		alias := chi.URLParam(r, "alias")
+		if alias == "" {
+			log.Info("alias is empty")
+
+			render.JSON(w, r, resp.Error("not found"))
+
+			return
+		}
+
+		var req Request
+
+		err := render.DecodeJSON(r.Body, &req)
+		if errors.Is(err, io.EOF) {
+			log.Error("request body is empty")
+
+			render.JSON(w, r, resp.Error("empty request"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed to decode request"))
+
+			return
+		}
+
+		log.Info("request body decoded", slog.Any("req", req))
+
+		if err := validator.New().Struct(req); err != nil {
+			log.Error("invalid request", sl.Err(err))
+
+			render.JSON(w, r, resp.Error(err.Error()))
+
+			return
+		}
+
+		// пустой fallback_url допустим - он снимает запасную ссылку
+		if req.FallbackURL != nil && *req.FallbackURL != "" {
+			if err := validator.New().Var(*req.FallbackURL, "url"); err != nil {
+				log.Error("invalid fallback url", sl.Err(err))
+
+				render.JSON(w, r, resp.Error("fallback_url is not a valid url"))
+
+				return
+			}
+		}
+
+		link, err := urlUpdater.GetLink(hostname.Normalize(r.URL.Query().Get("domain")), alias)
+		if errors.Is(err, storage.ErrURLNotFound) {
+			log.Info("url not found", slog.String("alias", alias))
+
+			render.JSON(w, r, resp.Error("not found"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to get url", sl.Err(err))
+
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("internal error"))
+
+			return
+		}
+
+		// Изменять ссылки рабочего пространства могут только его редакторы и владельцы, личные ссылки - только их автор
+		if err := auth.AuthorizeLink(r.Context(), urlUpdater, link, storage.RoleEditor); err != nil {
+			if status := auth.AccessStatus(err); status != 0 {
+				log.Info("access denied", slog.Int64("workspace_id", link.WorkspaceID), sl.Err(err))
+
+				render.Status(r, status)
+				render.JSON(w, r, resp.Error(err.Error()))
+
+				return
+			}
+
+			log.Error("failed to check access", sl.Err(err))
+
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("internal error"))
+
+			return
+		}
+
+		// Проверяются только измененные адреса: решение администратора по текущим адресам остается в силе
+		if urls := changedURLs(link, req); screener != nil && len(urls) > 0 {
+			if threatType, ok := screener.Match(urls...); ok {
+				log.Warn("target is in the threat list", slog.String("alias", alias), slog.String("threat", threatType))
+
+				render.JSON(w, r, resp.Error(fmt.Sprintf("%s: %s", threatlist.ErrListed, threatType)))
+
+				return
+			}
+		}
+
+		if err := applyRequest(&link, req); err != nil {
+			log.Error("invalid request", sl.Err(err))
+
+			render.JSON(w, r, resp.Error(err.Error()))
+
+			return
+		}
+
+		err = urlUpdater.UpdateLink(link, auth.ActorFromRequest(r))
+		if errors.Is(err, storage.ErrURLNotFound) {
+			log.Info("url not found", slog.String("alias", alias))
+
+			render.JSON(w, r, resp.Error("not found"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to update url", sl.Err(err))
+
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, resp.Error("failed to update url"))
+
+			return
+		}
+
+		log.Info("url updated", slog.String("alias", alias))
+
+		render.JSON(w, r, Response{
+			Response: resp.OK(),
+			Link:     &link,
+		})
+	}
+}
+
+// changedURLs - адреса перехода из запроса, которые отличаются от текущих адресов ссылки
+func changedURLs(link storage.Link, req Request) []string {
+	var urls []string
+	if req.URL != nil && *req.URL != link.URL {
+		urls = append(urls, *req.URL)
+	}
+	if req.FallbackURL != nil && *req.FallbackURL != "" && *req.FallbackURL != link.FallbackURL {
+		urls = append(urls, *req.FallbackURL)
+	}
+
+	return urls
+}
+
+var errInvalidQueryPolicy = errors.New("query_policy must be one of: drop, merge, override")
+
+// applyRequest переносит заданные в запросе поля в ссылку
+func applyRequest(link *storage.Link, req Request) error {
+	if req.URL != nil {
+		link.URL = *req.URL
+	}
+	if req.FallbackURL != nil {
+		link.FallbackURL = *req.FallbackURL
+	}
+	if req.UTMSource != nil {
+		link.UTMSource = *req.UTMSource
+	}
+	if req.UTMMedium != nil {
+		link.UTMMedium = *req.UTMMedium
+	}
+	if req.UTMCampaign != nil {
+		link.UTMCampaign = *req.UTMCampaign
+	}
+	if req.QueryPolicy != nil {
+		// пустая политика допустима - она возвращает политику по умолчанию
+		if !utm.ValidPolicy(*req.QueryPolicy) {
+			return errInvalidQueryPolicy
+		}
+		link.QueryPolicy = *req.QueryPolicy
+	}
+
+	if req.RedirectCode != nil {
+		link.RedirectCode = *req.RedirectCode
+	}
+	if req.Interstitial != nil {
+		link.Interstitial = *req.Interstitial
+	}
+	if req.Title != nil {
+		link.Title = *req.Title
+	}
+
+	var err error
+
+	if req.NotBefore != nil {
+		if link.NotBefore, err = timeparse.ParseOptional(*req.NotBefore, req.Timezone); err != nil {
+			return err
+		}
+	}
+	if req.NotAfter != nil {
+		if link.NotAfter, err = timeparse.ParseOptional(*req.NotAfter, req.Timezone); err != nil {
+			return err
+		}
+	}
+
+	// Проверяем итоговое окно, т.к. изменена могла быть только одна граница
+	if link.NotBefore != nil && link.NotAfter != nil && !link.NotAfter.After(*link.NotBefore) {
+		return timeparse.ErrInvalidWindow
+	}
+
+	return nil
+}
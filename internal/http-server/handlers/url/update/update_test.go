@@ -0,0 +1,189 @@
+package update_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/handlers/url/update"
+	"url-shortener/internal/http-server/handlers/url/update/mocks"
+	"url-shortener/internal/http-server/middleware/auth"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/lib/threatlist"
+	"url-shortener/internal/storage"
+)
+
+func TestUpdateHandler(t *testing.T) {
+	cases := []struct {
+		name      string
+		link      storage.Link
+		getErr    error
+		member    *storage.Member // участник рабочего пространства ссылки; nil - не участник
+		code      int
+		respError string
+		updateRun bool // ожидается вызов UpdateLink
+		updateErr error
+	}{
+		{
+			name:      "Author",
+			link:      storage.Link{Alias: "abc", OwnerUID: 42, URL: "https://example.com"},
+			code:      http.StatusOK,
+			updateRun: true,
+		},
+		{
+			name:      "Another user's link",
+			link:      storage.Link{Alias: "abc", OwnerUID: 7, URL: "https://example.com"},
+			code:      http.StatusForbidden,
+			respError: auth.ErrForbidden.Error(),
+		},
+		{
+			name:      "Link without author",
+			link:      storage.Link{Alias: "abc", URL: "https://example.com"},
+			code:      http.StatusForbidden,
+			respError: auth.ErrForbidden.Error(),
+		},
+		{
+			name:      "Workspace editor",
+			link:      storage.Link{Alias: "abc", OwnerUID: 7, WorkspaceID: 5, URL: "https://example.com"},
+			member:    &storage.Member{WorkspaceID: 5, UID: 42, Role: storage.RoleEditor},
+			code:      http.StatusOK,
+			updateRun: true,
+		},
+		{
+			name:      "Workspace viewer",
+			link:      storage.Link{Alias: "abc", OwnerUID: 7, WorkspaceID: 5, URL: "https://example.com"},
+			member:    &storage.Member{WorkspaceID: 5, UID: 42, Role: storage.RoleViewer},
+			code:      http.StatusForbidden,
+			respError: auth.ErrForbidden.Error(),
+		},
+		{
+			name:      "GetLink Error",
+			getErr:    errors.New("unexpected error"),
+			code:      http.StatusInternalServerError,
+			respError: "internal error",
+		},
+		{
+			name:      "UpdateLink Error",
+			link:      storage.Link{Alias: "abc", OwnerUID: 42, URL: "https://example.com"},
+			code:      http.StatusInternalServerError,
+			respError: "failed to update url",
+			updateRun: true,
+			updateErr: errors.New("unexpected error"),
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			updaterMock := mocks.NewURLUpdater(t)
+			updaterMock.On("GetLink", "", "abc").Return(tc.link, tc.getErr).Once()
+			if tc.member != nil {
+				updaterMock.On("GetMember", tc.link.WorkspaceID, int64(42)).Return(*tc.member, nil).Once()
+			}
+			if tc.updateRun {
+				updaterMock.On("UpdateLink", mock.MatchedBy(func(l storage.Link) bool {
+					return l.URL == "https://example.com/new"
+				}), mock.Anything).Return(tc.updateErr).Once()
+			}
+
+			router := chi.NewRouter()
+			router.Patch("/url/{alias}", update.New(slogdiscard.NewDiscardLogger(), updaterMock, nil))
+
+			req := httptest.NewRequest(http.MethodPatch, "/url/abc", strings.NewReader(`{"url": "https://example.com/new"}`))
+			req = req.WithContext(auth.WithUID(req.Context(), 42))
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.code, rr.Code)
+
+			var body update.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+			require.Equal(t, tc.respError, body.Error)
+		})
+	}
+}
+
+// threatList - список опасных адресов: адрес -> тип угрозы
+type threatList map[string]string
+
+func (l threatList) Match(urls ...string) (string, bool) {
+	for _, u := range urls {
+		if threat, ok := l[u]; ok {
+			return threat, true
+		}
+	}
+	return "", false
+}
+
+func TestUpdateHandler_ThreatList(t *testing.T) {
+	const evil = "https://evil.example/login"
+	listed := threatList{evil: threatlist.Malware}
+
+	cases := []struct {
+		name      string
+		link      storage.Link
+		body      string
+		respError string
+	}{
+		{
+			name:      "Listed url",
+			link:      storage.Link{Alias: "abc", OwnerUID: 42, URL: "https://example.com"},
+			body:      `{"url": "` + evil + `"}`,
+			respError: "target url is in the threat list: MALWARE",
+		},
+		{
+			name:      "Listed fallback url",
+			link:      storage.Link{Alias: "abc", OwnerUID: 42, URL: "https://example.com"},
+			body:      `{"fallback_url": "` + evil + `"}`,
+			respError: "target url is in the threat list: MALWARE",
+		},
+		{
+			// Решение администратора по текущему адресу ("ложное срабатывание") остается в силе
+			name: "Unchanged listed url",
+			link: storage.Link{Alias: "abc", OwnerUID: 42, URL: evil,
+				Threat: &storage.LinkThreat{Status: storage.ThreatCleared, Type: threatlist.Malware}},
+			body: `{"url": "` + evil + `", "title": "Login"}`,
+		},
+		{
+			name: "Clean url",
+			link: storage.Link{Alias: "abc", OwnerUID: 42, URL: "https://example.com"},
+			body: `{"url": "https://example.com/new"}`,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			updaterMock := mocks.NewURLUpdater(t)
+			updaterMock.On("GetLink", "", "abc").Return(tc.link, nil).Once()
+			if tc.respError == "" {
+				updaterMock.On("UpdateLink", mock.Anything, mock.Anything).Return(nil).Once()
+			}
+
+			router := chi.NewRouter()
+			router.Patch("/url/{alias}", update.New(slogdiscard.NewDiscardLogger(), updaterMock, listed))
+
+			req := httptest.NewRequest(http.MethodPatch, "/url/abc", strings.NewReader(tc.body))
+			req = req.WithContext(auth.WithUID(req.Context(), 42))
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			var body update.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+			require.Equal(t, tc.respError, body.Error)
+		})
+	}
+}
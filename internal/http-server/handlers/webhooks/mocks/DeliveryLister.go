@@ -0,0 +1,55 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	storage "url-shortener/internal/storage"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// DeliveryLister is an autogenerated mock type for the DeliveryLister type
+type DeliveryLister struct {
+	mock.Mock
+}
+
+// ListDeliveries provides a mock function with given fields: uid, webhookID, limit
+func (_m *DeliveryLister) ListDeliveries(uid int64, webhookID int64, limit int) ([]storage.WebhookDelivery, error) {
+	ret := _m.Called(uid, webhookID, limit)
+
+	var r0 []storage.WebhookDelivery
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64, int64, int) ([]storage.WebhookDelivery, error)); ok {
+		return rf(uid, webhookID, limit)
+	}
+	if rf, ok := ret.Get(0).(func(int64, int64, int) []storage.WebhookDelivery); ok {
+		r0 = rf(uid, webhookID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]storage.WebhookDelivery)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64, int64, int) error); ok {
+		r1 = rf(uid, webhookID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewDeliveryLister interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewDeliveryLister creates a new instance of DeliveryLister. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewDeliveryLister(t mockConstructorTestingTNewDeliveryLister) *DeliveryLister {
+	mock := &DeliveryLister{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
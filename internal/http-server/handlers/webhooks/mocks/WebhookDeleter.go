@@ -0,0 +1,39 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import mock "github.com/stretchr/testify/mock"
+
+// WebhookDeleter is an autogenerated mock type for the WebhookDeleter type
+type WebhookDeleter struct {
+	mock.Mock
+}
+
+// DeleteWebhook provides a mock function with given fields: uid, id
+func (_m *WebhookDeleter) DeleteWebhook(uid int64, id int64) error {
+	ret := _m.Called(uid, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64, int64) error); ok {
+		r0 = rf(uid, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewWebhookDeleter interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewWebhookDeleter creates a new instance of WebhookDeleter. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewWebhookDeleter(t mockConstructorTestingTNewWebhookDeleter) *WebhookDeleter {
+	mock := &WebhookDeleter{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,55 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	storage "url-shortener/internal/storage"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// WebhookLister is an autogenerated mock type for the WebhookLister type
+type WebhookLister struct {
+	mock.Mock
+}
+
+// ListWebhooks provides a mock function with given fields: uid
+func (_m *WebhookLister) ListWebhooks(uid int64) ([]storage.Webhook, error) {
+	ret := _m.Called(uid)
+
+	var r0 []storage.Webhook
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64) ([]storage.Webhook, error)); ok {
+		return rf(uid)
+	}
+	if rf, ok := ret.Get(0).(func(int64) []storage.Webhook); ok {
+		r0 = rf(uid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]storage.Webhook)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(uid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewWebhookLister interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewWebhookLister creates a new instance of WebhookLister. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewWebhookLister(t mockConstructorTestingTNewWebhookLister) *WebhookLister {
+	mock := &WebhookLister{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
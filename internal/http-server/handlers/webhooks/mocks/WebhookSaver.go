@@ -0,0 +1,77 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	storage "url-shortener/internal/storage"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// WebhookSaver is an autogenerated mock type for the WebhookSaver type
+type WebhookSaver struct {
+	mock.Mock
+}
+
+// GetMember provides a mock function with given fields: workspaceID, uid
+func (_m *WebhookSaver) GetMember(workspaceID int64, uid int64) (storage.Member, error) {
+	ret := _m.Called(workspaceID, uid)
+
+	var r0 storage.Member
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64, int64) (storage.Member, error)); ok {
+		return rf(workspaceID, uid)
+	}
+	if rf, ok := ret.Get(0).(func(int64, int64) storage.Member); ok {
+		r0 = rf(workspaceID, uid)
+	} else {
+		r0 = ret.Get(0).(storage.Member)
+	}
+
+	if rf, ok := ret.Get(1).(func(int64, int64) error); ok {
+		r1 = rf(workspaceID, uid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveWebhook provides a mock function with given fields: hook
+func (_m *WebhookSaver) SaveWebhook(hook storage.Webhook) (int64, error) {
+	ret := _m.Called(hook)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(storage.Webhook) (int64, error)); ok {
+		return rf(hook)
+	}
+	if rf, ok := ret.Get(0).(func(storage.Webhook) int64); ok {
+		r0 = rf(hook)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(storage.Webhook) error); ok {
+		r1 = rf(hook)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewWebhookSaver interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewWebhookSaver creates a new instance of WebhookSaver. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewWebhookSaver(t mockConstructorTestingTNewWebhookSaver) *WebhookSaver {
+	mock := &WebhookSaver{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
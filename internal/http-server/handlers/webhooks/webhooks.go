@@ -0,0 +1,393 @@
+// internal/http-server/handlers/webhooks/webhooks.go
+
+// Вебхуки пользователя: ресурс /webhooks.
+// Вебхук получает события по ссылкам владельца (workspace_id = 0) или рабочего пространства
+package webhooks
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+
+	"url-shortener/internal/http-server/middleware/auth"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/lib/safedial"
+	"url-shortener/internal/lib/signature"
+	"url-shortener/internal/storage"
+)
+
+const (
+	defaultDeliveriesLimit = 50
+	maxDeliveriesLimit     = 500
+)
+
+// структура запроса на создание вебхука
+type CreateRequest struct {
+	URL string `json:"url" validate:"required,http_url,max=2048"`
+	// Типы событий. Пусто - все события
+	Events      []string `json:"events,omitempty" validate:"omitempty,dive,oneof=link.created link.updated link.deleted link.restored link.clicked link.broken link.recovered"`
+	WorkspaceID int64    `json:"workspace_id,omitempty" validate:"gte=0"`
+}
+
+// структура ответа с вебхуком
+type Response struct {
+	resp.Response
+	// Секрет подписи. Возвращается только при создании, повторно получить его нельзя
+	Secret  string           `json:"secret,omitempty"`
+	Webhook *storage.Webhook `json:"webhook,omitempty"`
+}
+
+// структура ответа со списком вебхуков
+type ListResponse struct {
+	resp.Response
+	Webhooks []storage.Webhook `json:"webhooks"`
+}
+
+// структура ответа с журналом доставок
+type DeliveriesResponse struct {
+	resp.Response
+	Deliveries []storage.WebhookDelivery `json:"deliveries"`
+}
+
+// WebhookSaver is an interface for registering webhooks.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=WebhookSaver
+type WebhookSaver interface {
+	SaveWebhook(hook storage.Webhook) (int64, error)
+	GetMember(workspaceID int64, uid int64) (storage.Member, error)
+}
+
+// WebhookLister is an interface for listing user webhooks.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=WebhookLister
+type WebhookLister interface {
+	ListWebhooks(uid int64) ([]storage.Webhook, error)
+}
+
+// WebhookDeleter is an interface for deleting webhooks.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=WebhookDeleter
+type WebhookDeleter interface {
+	DeleteWebhook(uid int64, id int64) error
+}
+
+// DeliveryLister is an interface for reading the webhook delivery log.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=DeliveryLister
+type DeliveryLister interface {
+	ListDeliveries(uid int64, webhookID int64, limit int) ([]storage.WebhookDelivery, error)
+}
+
+// NewCreate Конструктор обработчика POST /webhooks.
+// Адреса внутренних сетей, заданные IP-адресом или именем localhost, отклоняются сразу
+// (allowPrivate - разрешить, только для закрытых инсталляций). Имена, которые разрешаются
+// во внутренние адреса, отсекаются при доставке
+func NewCreate(log *slog.Logger, webhookSaver WebhookSaver, allowPrivate bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.webhooks.NewCreate"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		uid, ok := userFromContext(w, r, log)
+		if !ok {
+			return
+		}
+
+		var req CreateRequest
+
+		err := render.DecodeJSON(r.Body, &req)
+		if errors.Is(err, io.EOF) {
+			log.Error("request body is empty")
+
+			render.JSON(w, r, resp.Error("empty request"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed to decode request"))
+
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			log.Error("invalid request", sl.Err(err))
+
+			render.JSON(w, r, resp.Error(err.Error()))
+
+			return
+		}
+
+		if !allowPrivate && privateHost(req.URL) {
+			log.Info("webhook url points to a private address", slog.String("url", req.URL))
+
+			render.JSON(w, r, resp.Error("webhook url must point to a public address"))
+
+			return
+		}
+
+		// события по ссылкам пространства доступны любому его участнику
+		if err := auth.Authorize(r.Context(), webhookSaver, req.WorkspaceID, storage.RoleViewer); err != nil {
+			if status := auth.AccessStatus(err); status != 0 {
+				log.Info("access denied", slog.Int64("workspace_id", req.WorkspaceID), sl.Err(err))
+
+				render.Status(r, status)
+				render.JSON(w, r, resp.Error(err.Error()))
+
+				return
+			}
+
+			log.Error("failed to check access", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("internal error"))
+
+			return
+		}
+
+		secret, err := signature.NewSecret()
+		if err != nil {
+			log.Error("failed to generate webhook secret", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed to create webhook"))
+
+			return
+		}
+
+		events := req.Events
+		if len(events) == 0 {
+			events = storage.EventTypes
+		}
+
+		hook := storage.Webhook{
+			UID:         uid,
+			WorkspaceID: req.WorkspaceID,
+			URL:         req.URL,
+			Events:      dedup(events),
+			Secret:      secret,
+			CreatedAt:   time.Now().UTC().Truncate(time.Second),
+		}
+
+		hook.ID, err = webhookSaver.SaveWebhook(hook)
+		if err != nil {
+			log.Error("failed to save webhook", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed to create webhook"))
+
+			return
+		}
+
+		// секрет в лог не пишем
+		log.Info("webhook created", slog.Int64("id", hook.ID), slog.Int64("uid", uid))
+
+		render.JSON(w, r, Response{
+			Response: resp.OK(),
+			Secret:   secret,
+			Webhook:  &hook,
+		})
+	}
+}
+
+// NewList Конструктор обработчика GET /webhooks - вебхуки текущего пользователя
+func NewList(log *slog.Logger, webhookLister WebhookLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.webhooks.NewList"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		uid, ok := userFromContext(w, r, log)
+		if !ok {
+			return
+		}
+
+		hooks, err := webhookLister.ListWebhooks(uid)
+		if err != nil {
+			log.Error("failed to list webhooks", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("internal error"))
+
+			return
+		}
+
+		render.JSON(w, r, ListResponse{
+			Response: resp.OK(),
+			Webhooks: hooks,
+		})
+	}
+}
+
+// NewDelete Конструктор обработчика DELETE /webhooks/{id}. Недоставленные события удаляются вместе с вебхуком
+func NewDelete(log *slog.Logger, webhookDeleter WebhookDeleter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.webhooks.NewDelete"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		uid, ok := userFromContext(w, r, log)
+		if !ok {
+			return
+		}
+
+		id, ok := webhookID(w, r)
+		if !ok {
+			return
+		}
+
+		err := webhookDeleter.DeleteWebhook(uid, id)
+		if errors.Is(err, storage.ErrWebhookNotFound) {
+			log.Info("webhook not found", slog.Int64("id", id))
+
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error("not found"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to delete webhook", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("internal error"))
+
+			return
+		}
+
+		log.Info("webhook deleted", slog.Int64("id", id), slog.Int64("uid", uid))
+
+		render.JSON(w, r, resp.OK())
+	}
+}
+
+// NewDeliveries Конструктор обработчика GET /webhooks/{id}/deliveries - журнал доставок, новые первыми
+func NewDeliveries(log *slog.Logger, deliveryLister DeliveryLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.webhooks.NewDeliveries"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		uid, ok := userFromContext(w, r, log)
+		if !ok {
+			return
+		}
+
+		id, ok := webhookID(w, r)
+		if !ok {
+			return
+		}
+
+		limit := defaultDeliveriesLimit
+		if raw := r.URL.Query().Get("limit"); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n < 1 || n > maxDeliveriesLimit {
+				render.JSON(w, r, resp.Error("limit must be between 1 and "+strconv.Itoa(maxDeliveriesLimit)))
+
+				return
+			}
+			limit = n
+		}
+
+		deliveries, err := deliveryLister.ListDeliveries(uid, id, limit)
+		if errors.Is(err, storage.ErrWebhookNotFound) {
+			log.Info("webhook not found", slog.Int64("id", id))
+
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, resp.Error("not found"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to list deliveries", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("internal error"))
+
+			return
+		}
+
+		render.JSON(w, r, DeliveriesResponse{
+			Response:   resp.OK(),
+			Deliveries: deliveries,
+		})
+	}
+}
+
+// userFromContext - UID текущего пользователя. Вебхук принадлежит пользователю,
+// поэтому анонимные запросы и запросы без пользователя отклоняются
+func userFromContext(w http.ResponseWriter, r *http.Request, log *slog.Logger) (int64, bool) {
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok || principal.UID == 0 {
+		log.Info("unauthorized")
+
+		render.Status(r, http.StatusUnauthorized)
+		render.JSON(w, r, resp.Error(auth.ErrUnauthorized.Error()))
+
+		return 0, false
+	}
+
+	return principal.UID, true
+}
+
+// webhookID - идентификатор вебхука из пути. Некорректный идентификатор - 404
+func webhookID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, resp.Error("not found"))
+
+		return 0, false
+	}
+
+	return id, true
+}
+
+// privateHost - хост адреса - IP-адрес внутренней сети или localhost
+func privateHost(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return true
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+
+	ip, err := netip.ParseAddr(host)
+
+	return err == nil && !safedial.IsPublic(ip)
+}
+
+// dedup убирает повторы, сохраняя порядок
+func dedup(events []string) []string {
+	seen := make(map[string]bool, len(events))
+	out := make([]string, 0, len(events))
+	for _, e := range events {
+		if !seen[e] {
+			seen[e] = true
+			out = append(out, e)
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,227 @@
+package webhooks_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/handlers/webhooks"
+	"url-shortener/internal/http-server/handlers/webhooks/mocks"
+	"url-shortener/internal/http-server/middleware/auth"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/storage"
+)
+
+func TestCreateHandler(t *testing.T) {
+	cases := []struct {
+		name      string
+		uid       int64 // пользователь из JWT-токена, 0 - не авторизован
+		request   webhooks.CreateRequest
+		role      string   // роль в пространстве 10, пусто - не участник
+		events    []string // ожидаемые события сохраненного вебхука
+		code      int
+		respError string
+	}{
+		{
+			name:    "All events by default",
+			uid:     1,
+			request: webhooks.CreateRequest{URL: "https://crm.example.com/hook"},
+			events:  storage.EventTypes,
+			code:    http.StatusOK,
+		},
+		{
+			name: "Workspace webhook",
+			uid:  1,
+			request: webhooks.CreateRequest{
+				URL:         "https://crm.example.com/hook",
+				Events:      []string{storage.EventLinkClicked, storage.EventLinkClicked},
+				WorkspaceID: 10,
+			},
+			role:   storage.RoleViewer,
+			events: []string{storage.EventLinkClicked},
+			code:   http.StatusOK,
+		},
+		{
+			name:      "Not a member",
+			uid:       1,
+			request:   webhooks.CreateRequest{URL: "https://crm.example.com/hook", WorkspaceID: 10},
+			code:      http.StatusForbidden,
+			respError: auth.ErrForbidden.Error(),
+		},
+		{
+			name:      "Unknown event",
+			uid:       1,
+			request:   webhooks.CreateRequest{URL: "https://crm.example.com/hook", Events: []string{"link.exploded"}},
+			code:      http.StatusOK,
+			respError: "Field validation for 'Events[0]' failed on the 'oneof' tag",
+		},
+		{
+			name:      "Not an HTTP URL",
+			uid:       1,
+			request:   webhooks.CreateRequest{URL: "ftp://crm.example.com/hook"},
+			code:      http.StatusOK,
+			respError: "Field validation for 'URL' failed on the 'http_url' tag",
+		},
+		{
+			name:      "Loopback address",
+			uid:       1,
+			request:   webhooks.CreateRequest{URL: "http://127.0.0.1:8082/hook"},
+			code:      http.StatusOK,
+			respError: "webhook url must point to a public address",
+		},
+		{
+			name:      "Cloud metadata address",
+			uid:       1,
+			request:   webhooks.CreateRequest{URL: "http://169.254.169.254/latest/meta-data/"},
+			code:      http.StatusOK,
+			respError: "webhook url must point to a public address",
+		},
+		{
+			name:      "Localhost",
+			uid:       1,
+			request:   webhooks.CreateRequest{URL: "http://LocalHost./hook"},
+			code:      http.StatusOK,
+			respError: "webhook url must point to a public address",
+		},
+		{
+			name:      "Unauthorized",
+			request:   webhooks.CreateRequest{URL: "https://crm.example.com/hook"},
+			code:      http.StatusUnauthorized,
+			respError: auth.ErrUnauthorized.Error(),
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			saverMock := mocks.NewWebhookSaver(t)
+			if tc.request.WorkspaceID != 0 {
+				if tc.role != "" {
+					saverMock.On("GetMember", tc.request.WorkspaceID, tc.uid).
+						Return(storage.Member{WorkspaceID: tc.request.WorkspaceID, UID: tc.uid, Role: tc.role}, nil).Once()
+				} else {
+					saverMock.On("GetMember", tc.request.WorkspaceID, tc.uid).
+						Return(storage.Member{}, storage.ErrMemberNotFound).Once()
+				}
+			}
+			if tc.respError == "" {
+				saverMock.On("SaveWebhook", mock.MatchedBy(func(hook storage.Webhook) bool {
+					return hook.UID == tc.uid &&
+						hook.WorkspaceID == tc.request.WorkspaceID &&
+						hook.URL == tc.request.URL &&
+						strings.HasPrefix(hook.Secret, "whsec_") &&
+						fmt.Sprint(hook.Events) == fmt.Sprint(tc.events)
+				})).Return(int64(5), nil).Once()
+			}
+
+			body, err := json.Marshal(tc.request)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "/webhooks", bytes.NewReader(body))
+			if tc.uid != 0 {
+				req = req.WithContext(auth.WithUID(req.Context(), tc.uid))
+			}
+			rr := httptest.NewRecorder()
+
+			webhooks.NewCreate(slogdiscard.NewDiscardLogger(), saverMock, false).ServeHTTP(rr, req)
+
+			require.Equal(t, tc.code, rr.Code)
+
+			var response webhooks.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+			require.Contains(t, response.Error, tc.respError)
+
+			if tc.respError == "" {
+				require.True(t, strings.HasPrefix(response.Secret, "whsec_"))
+				require.Equal(t, int64(5), response.Webhook.ID)
+				// секрет отдается только отдельным полем
+				require.NotContains(t, rr.Body.String(), `"Secret"`)
+			}
+		})
+	}
+}
+
+func TestDeliveriesHandler(t *testing.T) {
+	cases := []struct {
+		name      string
+		path      string
+		limit     int // ожидаемый лимит, 0 - хранилище не вызывается
+		mockError error
+		code      int
+		respError string
+	}{
+		{
+			name:  "Default limit",
+			path:  "/webhooks/5/deliveries",
+			limit: 50,
+			code:  http.StatusOK,
+		},
+		{
+			name:  "Custom limit",
+			path:  "/webhooks/5/deliveries?limit=3",
+			limit: 3,
+			code:  http.StatusOK,
+		},
+		{
+			name:      "Limit too large",
+			path:      "/webhooks/5/deliveries?limit=501",
+			code:      http.StatusOK,
+			respError: "limit must be between 1 and 500",
+		},
+		{
+			name:      "Foreign webhook",
+			path:      "/webhooks/5/deliveries",
+			limit:     50,
+			mockError: fmt.Errorf("storage.sqlite.GetWebhook: %w", storage.ErrWebhookNotFound),
+			code:      http.StatusNotFound,
+			respError: "not found",
+		},
+		{
+			name:      "Invalid id",
+			path:      "/webhooks/abc/deliveries",
+			code:      http.StatusNotFound,
+			respError: "not found",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			listerMock := mocks.NewDeliveryLister(t)
+			if tc.limit != 0 {
+				listerMock.On("ListDeliveries", int64(1), int64(5), tc.limit).
+					Return([]storage.WebhookDelivery{}, tc.mockError).Once()
+			}
+
+			router := chi.NewRouter()
+			router.Get("/webhooks/{id}/deliveries", webhooks.NewDeliveries(slogdiscard.NewDiscardLogger(), listerMock))
+
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			req = req.WithContext(auth.WithUID(req.Context(), 1))
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.code, rr.Code)
+
+			var response resp.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+			require.Equal(t, tc.respError, response.Error)
+		})
+	}
+}
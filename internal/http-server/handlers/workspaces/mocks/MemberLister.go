@@ -0,0 +1,79 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	storage "url-shortener/internal/storage"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MemberLister is an autogenerated mock type for the MemberLister type
+type MemberLister struct {
+	mock.Mock
+}
+
+// GetMember provides a mock function with given fields: workspaceID, uid
+func (_m *MemberLister) GetMember(workspaceID int64, uid int64) (storage.Member, error) {
+	ret := _m.Called(workspaceID, uid)
+
+	var r0 storage.Member
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64, int64) (storage.Member, error)); ok {
+		return rf(workspaceID, uid)
+	}
+	if rf, ok := ret.Get(0).(func(int64, int64) storage.Member); ok {
+		r0 = rf(workspaceID, uid)
+	} else {
+		r0 = ret.Get(0).(storage.Member)
+	}
+
+	if rf, ok := ret.Get(1).(func(int64, int64) error); ok {
+		r1 = rf(workspaceID, uid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListMembers provides a mock function with given fields: workspaceID
+func (_m *MemberLister) ListMembers(workspaceID int64) ([]storage.Member, error) {
+	ret := _m.Called(workspaceID)
+
+	var r0 []storage.Member
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64) ([]storage.Member, error)); ok {
+		return rf(workspaceID)
+	}
+	if rf, ok := ret.Get(0).(func(int64) []storage.Member); ok {
+		r0 = rf(workspaceID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]storage.Member)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(workspaceID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewMemberLister interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewMemberLister creates a new instance of MemberLister. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewMemberLister(t mockConstructorTestingTNewMemberLister) *MemberLister {
+	mock := &MemberLister{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,67 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	storage "url-shortener/internal/storage"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MemberSetter is an autogenerated mock type for the MemberSetter type
+type MemberSetter struct {
+	mock.Mock
+}
+
+// GetMember provides a mock function with given fields: workspaceID, uid
+func (_m *MemberSetter) GetMember(workspaceID int64, uid int64) (storage.Member, error) {
+	ret := _m.Called(workspaceID, uid)
+
+	var r0 storage.Member
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64, int64) (storage.Member, error)); ok {
+		return rf(workspaceID, uid)
+	}
+	if rf, ok := ret.Get(0).(func(int64, int64) storage.Member); ok {
+		r0 = rf(workspaceID, uid)
+	} else {
+		r0 = ret.Get(0).(storage.Member)
+	}
+
+	if rf, ok := ret.Get(1).(func(int64, int64) error); ok {
+		r1 = rf(workspaceID, uid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetMember provides a mock function with given fields: member
+func (_m *MemberSetter) SetMember(member storage.Member) error {
+	ret := _m.Called(member)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(storage.Member) error); ok {
+		r0 = rf(member)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewMemberSetter interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewMemberSetter creates a new instance of MemberSetter. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewMemberSetter(t mockConstructorTestingTNewMemberSetter) *MemberSetter {
+	mock := &MemberSetter{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,53 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	storage "url-shortener/internal/storage"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// WorkspaceCreator is an autogenerated mock type for the WorkspaceCreator type
+type WorkspaceCreator struct {
+	mock.Mock
+}
+
+// CreateWorkspace provides a mock function with given fields: name, ownerUID
+func (_m *WorkspaceCreator) CreateWorkspace(name string, ownerUID int64) (storage.Workspace, error) {
+	ret := _m.Called(name, ownerUID)
+
+	var r0 storage.Workspace
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, int64) (storage.Workspace, error)); ok {
+		return rf(name, ownerUID)
+	}
+	if rf, ok := ret.Get(0).(func(string, int64) storage.Workspace); ok {
+		r0 = rf(name, ownerUID)
+	} else {
+		r0 = ret.Get(0).(storage.Workspace)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, int64) error); ok {
+		r1 = rf(name, ownerUID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewWorkspaceCreator interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewWorkspaceCreator creates a new instance of WorkspaceCreator. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewWorkspaceCreator(t mockConstructorTestingTNewWorkspaceCreator) *WorkspaceCreator {
+	mock := &WorkspaceCreator{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,55 @@
+// Code generated by mockery v2.28.2. DO NOT EDIT.
+
+package mocks
+
+import (
+	storage "url-shortener/internal/storage"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// WorkspaceLister is an autogenerated mock type for the WorkspaceLister type
+type WorkspaceLister struct {
+	mock.Mock
+}
+
+// ListWorkspaces provides a mock function with given fields: uid
+func (_m *WorkspaceLister) ListWorkspaces(uid int64) ([]storage.Workspace, error) {
+	ret := _m.Called(uid)
+
+	var r0 []storage.Workspace
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64) ([]storage.Workspace, error)); ok {
+		return rf(uid)
+	}
+	if rf, ok := ret.Get(0).(func(int64) []storage.Workspace); ok {
+		r0 = rf(uid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]storage.Workspace)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(uid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewWorkspaceLister interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewWorkspaceLister creates a new instance of WorkspaceLister. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewWorkspaceLister(t mockConstructorTestingTNewWorkspaceLister) *WorkspaceLister {
+	mock := &WorkspaceLister{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,401 @@
+// internal/http-server/handlers/workspaces/workspaces.go
+
+// Рабочие пространства команд и управление их участниками: ресурс /workspaces.
+// Пользователь определяется по JWT-токену (см. auth.UIDFromContext)
+package workspaces
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+
+	"url-shortener/internal/http-server/middleware/auth"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/storage"
+)
+
+// структура запроса на создание рабочего пространства
+type CreateRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+// структура запроса на добавление участника или изменение его роли
+type MemberRequest struct {
+	Role string `json:"role" validate:"required,oneof=owner editor viewer"`
+}
+
+// структура ответа с одним рабочим пространством
+type Response struct {
+	resp.Response
+	Workspace *storage.Workspace `json:"workspace,omitempty"`
+}
+
+// структура ответа со списком рабочих пространств
+type ListResponse struct {
+	resp.Response
+	Workspaces []storage.Workspace `json:"workspaces"`
+}
+
+// структура ответа со списком участников
+type MembersResponse struct {
+	resp.Response
+	Members []storage.Member `json:"members"`
+}
+
+// WorkspaceCreator is an interface for creating workspaces.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=WorkspaceCreator
+type WorkspaceCreator interface {
+	CreateWorkspace(name string, ownerUID int64) (storage.Workspace, error)
+}
+
+// WorkspaceLister is an interface for listing user workspaces.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=WorkspaceLister
+type WorkspaceLister interface {
+	ListWorkspaces(uid int64) ([]storage.Workspace, error)
+}
+
+// MemberLister is an interface for listing workspace members.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=MemberLister
+type MemberLister interface {
+	ListMembers(workspaceID int64) ([]storage.Member, error)
+	GetMember(workspaceID int64, uid int64) (storage.Member, error)
+}
+
+// MemberSetter is an interface for adding workspace members and changing their roles.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=MemberSetter
+type MemberSetter interface {
+	SetMember(member storage.Member) error
+	GetMember(workspaceID int64, uid int64) (storage.Member, error)
+}
+
+// MemberRemover is an interface for removing workspace members.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=MemberRemover
+type MemberRemover interface {
+	RemoveMember(workspaceID int64, uid int64) error
+	GetMember(workspaceID int64, uid int64) (storage.Member, error)
+}
+
+// NewCreate Конструктор обработчика POST /workspaces. Создатель становится владельцем
+func NewCreate(log *slog.Logger, creator WorkspaceCreator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.workspaces.NewCreate"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		uid, ok := auth.UIDFromContext(r.Context())
+		if !ok {
+			accessDenied(w, r, log, auth.ErrUnauthorized)
+
+			return
+		}
+
+		var req CreateRequest
+
+		err := render.DecodeJSON(r.Body, &req)
+		if errors.Is(err, io.EOF) {
+			log.Error("request body is empty")
+
+			render.JSON(w, r, resp.Error("empty request"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed to decode request"))
+
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			log.Error("invalid request", sl.Err(err))
+
+			render.JSON(w, r, resp.Error(err.Error()))
+
+			return
+		}
+
+		workspace, err := creator.CreateWorkspace(req.Name, uid)
+		if err != nil {
+			log.Error("failed to create workspace", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed to create workspace"))
+
+			return
+		}
+
+		log.Info("workspace created", slog.Int64("id", workspace.ID), slog.Int64("owner", uid))
+
+		render.JSON(w, r, Response{
+			Response:  resp.OK(),
+			Workspace: &workspace,
+		})
+	}
+}
+
+// NewList Конструктор обработчика GET /workspaces - пространства текущего пользователя
+func NewList(log *slog.Logger, lister WorkspaceLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.workspaces.NewList"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		uid, ok := auth.UIDFromContext(r.Context())
+		if !ok {
+			accessDenied(w, r, log, auth.ErrUnauthorized)
+
+			return
+		}
+
+		workspaces, err := lister.ListWorkspaces(uid)
+		if err != nil {
+			log.Error("failed to list workspaces", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("internal error"))
+
+			return
+		}
+
+		render.JSON(w, r, ListResponse{
+			Response:   resp.OK(),
+			Workspaces: workspaces,
+		})
+	}
+}
+
+// NewMembers Конструктор обработчика GET /workspaces/{id}/members. Доступен всем участникам
+func NewMembers(log *slog.Logger, lister MemberLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.workspaces.NewMembers"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		workspaceID, ok := idParam(r, "id")
+		if !ok {
+			render.JSON(w, r, resp.Error("not found"))
+
+			return
+		}
+
+		if err := auth.Authorize(r.Context(), lister, workspaceID, storage.RoleViewer); err != nil {
+			accessDenied(w, r, log, err)
+
+			return
+		}
+
+		members, err := lister.ListMembers(workspaceID)
+		if err != nil {
+			log.Error("failed to list members", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("internal error"))
+
+			return
+		}
+
+		render.JSON(w, r, MembersResponse{
+			Response: resp.OK(),
+			Members:  members,
+		})
+	}
+}
+
+// NewSetMember Конструктор обработчика PUT /workspaces/{id}/members/{uid}.
+// Добавляет участника или меняет его роль. Доступен только владельцам
+func NewSetMember(log *slog.Logger, setter MemberSetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.workspaces.NewSetMember"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		workspaceID, ok := idParam(r, "id")
+		if !ok {
+			render.JSON(w, r, resp.Error("not found"))
+
+			return
+		}
+		memberUID, ok := idParam(r, "uid")
+		if !ok {
+			render.JSON(w, r, resp.Error("invalid uid"))
+
+			return
+		}
+
+		if err := auth.Authorize(r.Context(), setter, workspaceID, storage.RoleOwner); err != nil {
+			accessDenied(w, r, log, err)
+
+			return
+		}
+
+		var req MemberRequest
+
+		err := render.DecodeJSON(r.Body, &req)
+		if errors.Is(err, io.EOF) {
+			log.Error("request body is empty")
+
+			render.JSON(w, r, resp.Error("empty request"))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed to decode request"))
+
+			return
+		}
+
+		if err := validator.New().Struct(req); err != nil {
+			log.Error("invalid request", sl.Err(err))
+
+			render.JSON(w, r, resp.Error(err.Error()))
+
+			return
+		}
+
+		err = setter.SetMember(storage.Member{WorkspaceID: workspaceID, UID: memberUID, Role: req.Role})
+		if errors.Is(err, storage.ErrWorkspaceNotFound) {
+			log.Info("workspace not found", slog.Int64("workspace_id", workspaceID))
+
+			render.JSON(w, r, resp.Error("not found"))
+
+			return
+		}
+		if errors.Is(err, storage.ErrLastOwner) {
+			log.Info("cannot demote last owner", slog.Int64("workspace_id", workspaceID))
+
+			render.JSON(w, r, resp.Error(err.Error()))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to set member", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed to set member"))
+
+			return
+		}
+
+		log.Info("member set",
+			slog.Int64("workspace_id", workspaceID),
+			slog.Int64("uid", memberUID),
+			slog.String("role", req.Role),
+		)
+
+		render.JSON(w, r, resp.OK())
+	}
+}
+
+// NewRemoveMember Конструктор обработчика DELETE /workspaces/{id}/members/{uid}.
+// Владелец может исключить любого участника, остальные - только покинуть пространство сами
+func NewRemoveMember(log *slog.Logger, remover MemberRemover) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.workspaces.NewRemoveMember"
+
+		log := log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		workspaceID, ok := idParam(r, "id")
+		if !ok {
+			render.JSON(w, r, resp.Error("not found"))
+
+			return
+		}
+		memberUID, ok := idParam(r, "uid")
+		if !ok {
+			render.JSON(w, r, resp.Error("invalid uid"))
+
+			return
+		}
+
+		minRole := storage.RoleOwner
+		if uid, ok := auth.UIDFromContext(r.Context()); ok && uid == memberUID {
+			minRole = storage.RoleViewer
+		}
+
+		if err := auth.Authorize(r.Context(), remover, workspaceID, minRole); err != nil {
+			accessDenied(w, r, log, err)
+
+			return
+		}
+
+		err := remover.RemoveMember(workspaceID, memberUID)
+		if errors.Is(err, storage.ErrMemberNotFound) {
+			log.Info("member not found", slog.Int64("workspace_id", workspaceID), slog.Int64("uid", memberUID))
+
+			render.JSON(w, r, resp.Error("not found"))
+
+			return
+		}
+		if errors.Is(err, storage.ErrLastOwner) {
+			log.Info("cannot remove last owner", slog.Int64("workspace_id", workspaceID))
+
+			render.JSON(w, r, resp.Error(err.Error()))
+
+			return
+		}
+		if err != nil {
+			log.Error("failed to remove member", sl.Err(err))
+
+			render.JSON(w, r, resp.Error("failed to remove member"))
+
+			return
+		}
+
+		log.Info("member removed", slog.Int64("workspace_id", workspaceID), slog.Int64("uid", memberUID))
+
+		render.JSON(w, r, resp.OK())
+	}
+}
+
+// idParam разбирает числовой параметр пути
+func idParam(r *http.Request, name string) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, name), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+
+	return id, true
+}
+
+// accessDenied отвечает на ошибку auth.Authorize
+func accessDenied(w http.ResponseWriter, r *http.Request, log *slog.Logger, err error) {
+	if status := auth.AccessStatus(err); status != 0 {
+		log.Info("access denied", sl.Err(err))
+
+		render.Status(r, status)
+		render.JSON(w, r, resp.Error(err.Error()))
+
+		return
+	}
+
+	log.Error("failed to check access", sl.Err(err))
+
+	render.JSON(w, r, resp.Error("internal error"))
+}
@@ -0,0 +1,97 @@
+package workspaces_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/handlers/workspaces"
+	"url-shortener/internal/http-server/handlers/workspaces/mocks"
+	"url-shortener/internal/http-server/middleware/auth"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/storage"
+)
+
+func TestSetMemberHandler(t *testing.T) {
+	cases := []struct {
+		name      string
+		uid       int64  // пользователь из JWT-токена, 0 - не авторизован
+		role      string // его роль в пространстве, пусто - не участник
+		code      int    // ожидаемый код ответа
+		respError string // ожидаемая ошибка
+	}{
+		{
+			name: "Owner",
+			uid:  1,
+			role: storage.RoleOwner,
+			code: http.StatusOK,
+		},
+		{
+			name:      "Editor",
+			uid:       2,
+			role:      storage.RoleEditor,
+			code:      http.StatusForbidden,
+			respError: auth.ErrForbidden.Error(),
+		},
+		{
+			name:      "Not a member",
+			uid:       3,
+			code:      http.StatusForbidden,
+			respError: auth.ErrForbidden.Error(),
+		},
+		{
+			name:      "Unauthorized",
+			code:      http.StatusUnauthorized,
+			respError: auth.ErrUnauthorized.Error(),
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			setterMock := mocks.NewMemberSetter(t)
+			if tc.uid != 0 {
+				if tc.role != "" {
+					setterMock.On("GetMember", int64(10), tc.uid).
+						Return(storage.Member{WorkspaceID: 10, UID: tc.uid, Role: tc.role}, nil).Once()
+				} else {
+					setterMock.On("GetMember", int64(10), tc.uid).
+						Return(storage.Member{}, storage.ErrMemberNotFound).Once()
+				}
+			}
+			if tc.respError == "" {
+				setterMock.On("SetMember", storage.Member{WorkspaceID: 10, UID: 42, Role: storage.RoleEditor}).
+					Return(nil).Once()
+			}
+
+			router := chi.NewRouter()
+			router.Put("/workspaces/{id}/members/{uid}", workspaces.NewSetMember(slogdiscard.NewDiscardLogger(), setterMock))
+
+			body, err := json.Marshal(workspaces.MemberRequest{Role: storage.RoleEditor})
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPut, "/workspaces/10/members/42", bytes.NewReader(body))
+			if tc.uid != 0 {
+				req = req.WithContext(auth.WithUID(req.Context(), tc.uid))
+			}
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.code, rr.Code)
+
+			var response resp.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+			require.Equal(t, tc.respError, response.Error)
+		})
+	}
+}
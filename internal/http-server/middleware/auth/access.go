@@ -0,0 +1,85 @@
+// internal/http-server/middleware/auth/access.go
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"url-shortener/internal/storage"
+)
+
+var (
+	ErrUnauthorized = errors.New("authentication required")
+	ErrForbidden    = errors.New("access denied")
+)
+
+// MemberGetter is an interface for getting workspace member.
+type MemberGetter interface {
+	GetMember(workspaceID int64, uid int64) (storage.Member, error)
+}
+
+// Authorize проверяет, что у текущего пользователя есть в рабочем пространстве роль не ниже minRole.
+// Общие ссылки (workspaceID = 0) и администраторы сервиса проверку проходят всегда.
+// Возвращает ErrUnauthorized, если пользователь не авторизован, и ErrForbidden, если прав не хватает
+func Authorize(ctx context.Context, members MemberGetter, workspaceID int64, minRole string) error {
+	if workspaceID == 0 || IsAdminFromContext(ctx) {
+		return nil
+	}
+
+	uid, ok := UIDFromContext(ctx)
+	if !ok {
+		return ErrUnauthorized
+	}
+
+	member, err := members.GetMember(workspaceID, uid)
+	if errors.Is(err, storage.ErrMemberNotFound) {
+		return ErrForbidden
+	}
+	if err != nil {
+		return fmt.Errorf("middleware.auth.Authorize: %w", err)
+	}
+
+	if !storage.RoleAtLeast(member.Role, minRole) {
+		return ErrForbidden
+	}
+
+	return nil
+}
+
+// AuthorizeLink проверяет права текущего пользователя на ссылку.
+// Ссылки рабочих пространств проверяются как в Authorize, а личные ссылки (без рабочего пространства)
+// доступны только их автору и администраторам сервиса
+func AuthorizeLink(ctx context.Context, members MemberGetter, link storage.Link, minRole string) error {
+	if link.WorkspaceID != 0 {
+		return Authorize(ctx, members, link.WorkspaceID, minRole)
+	}
+
+	if IsAdminFromContext(ctx) {
+		return nil
+	}
+
+	uid, ok := UIDFromContext(ctx)
+	if !ok {
+		return ErrUnauthorized
+	}
+	if link.OwnerUID != uid {
+		return ErrForbidden
+	}
+
+	return nil
+}
+
+// AccessStatus - HTTP-код ответа для ошибки Authorize. 0 - ошибка не связана с доступом
+func AccessStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrUnauthorized):
+		return http.StatusUnauthorized
+	case errors.Is(err, ErrForbidden):
+		return http.StatusForbidden
+	default:
+		return 0
+	}
+}
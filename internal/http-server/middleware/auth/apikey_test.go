@@ -0,0 +1,90 @@
+package auth_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/middleware/auth"
+	"url-shortener/internal/lib/apikey"
+	"url-shortener/internal/lib/jwt"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/storage"
+)
+
+// keyStore - хранилище API-ключей в памяти
+type keyStore struct {
+	keys    map[string]storage.APIKey // по хешу
+	touched []int64
+}
+
+func (s *keyStore) GetAPIKeyByHash(keyHash string) (storage.APIKey, error) {
+	key, ok := s.keys[keyHash]
+	if !ok {
+		return storage.APIKey{}, storage.ErrAPIKeyNotFound
+	}
+
+	return key, nil
+}
+
+func (s *keyStore) TouchAPIKey(id int64, _ time.Time) error {
+	s.touched = append(s.touched, id)
+	return nil
+}
+
+func TestAPIKeyAuth(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+
+	store := &keyStore{keys: map[string]storage.APIKey{
+		apikey.Hash("us_reader"):  {ID: 1, UID: 7, Scopes: []string{auth.ScopeLinksRead}},
+		apikey.Hash("us_revoked"): {ID: 2, UID: 7, Scopes: []string{auth.ScopeLinksRead}, RevokedAt: &past},
+		apikey.Hash("us_expired"): {ID: 3, UID: 7, Scopes: []string{auth.ScopeLinksRead}, ExpiresAt: &past},
+	}}
+
+	cases := []struct {
+		name   string
+		header string // заголовок с ключом
+		value  string
+		scope  string // область действия, которую требует эндпоинт
+		code   int
+		uid    int64 // ожидаемый пользователь
+	}{
+		{name: "Bearer", header: "Authorization", value: "Bearer us_reader", scope: auth.ScopeLinksRead, code: http.StatusOK, uid: 7},
+		{name: "X-API-Key", header: "X-API-Key", value: "us_reader", scope: auth.ScopeLinksRead, code: http.StatusOK, uid: 7},
+		{name: "Missing scope", header: "X-API-Key", value: "us_reader", scope: auth.ScopeLinksWrite, code: http.StatusForbidden},
+		{name: "Missing workspaces scope", header: "X-API-Key", value: "us_reader", scope: auth.ScopeWorkspacesWrite, code: http.StatusForbidden},
+		{name: "Unknown key", header: "X-API-Key", value: "us_unknown", scope: auth.ScopeLinksRead, code: http.StatusUnauthorized},
+		{name: "Revoked key", header: "X-API-Key", value: "us_revoked", scope: auth.ScopeLinksRead, code: http.StatusUnauthorized},
+		{name: "Expired key", header: "Authorization", value: "Bearer us_expired", scope: auth.ScopeLinksRead, code: http.StatusUnauthorized},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var uid int64
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				uid, _ = auth.UIDFromContext(r.Context())
+			})
+
+			basic, err := auth.NewBasic("", map[string]string{"user": "pass"})
+			require.NoError(t, err)
+
+			mw := auth.Chain(slogdiscard.NewDiscardLogger(), "test", basic, auth.NewAPIKey(store), auth.NewJWT(jwt.NewHMACVerifier("secret")))
+			chain := mw(auth.RequireScope(tc.scope)(handler))
+
+			req := httptest.NewRequest(http.MethodGet, "/url", nil)
+			req.Header.Set(tc.header, tc.value)
+			rr := httptest.NewRecorder()
+
+			chain.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.code, rr.Code)
+			require.Equal(t, tc.uid, uid)
+		})
+	}
+
+	require.Contains(t, store.touched, int64(1))
+	require.NotContains(t, store.touched, int64(2))
+}
@@ -12,9 +12,12 @@ import (
 	"log/slog"
 	"net/http"
 	"strings"
+	"time"
 
+	"url-shortener/internal/lib/apikey"
 	"url-shortener/internal/lib/jwt"
 	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/storage"
 )
 
 //type PermissionProvider struct{
@@ -23,9 +26,16 @@ import (
 
 var (
 	ErrInvalidToken       = errors.New("invalid token")
+	ErrInvalidAPIKey      = errors.New("invalid api key")
 	ErrFailedIsAdminCheck = errors.New("failed to check is user is admin")
 )
 
+// APIKeyVerifier is an interface for looking up API keys by hash.
+type APIKeyVerifier interface {
+	GetAPIKeyByHash(keyHash string) (storage.APIKey, error)
+	TouchAPIKey(id int64, at time.Time) error
+}
+
 // extractBeclaims, err:=arerToken извлекает JWT-токен из заголовка http-запроса
 func extractBearerToken(r *http.Request) string {
 	authHeader := r.Header.Get("Authorization")
@@ -37,82 +47,117 @@ func extractBearerToken(r *http.Request) string {
 	return splitToken[1]
 }
 
+// extractAPIKey извлекает API-ключ из заголовка X-API-Key или Authorization: Bearer
+func extractAPIKey(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+
+	if token := extractBearerToken(r); apikey.Looks(token) {
+		return token
+	}
+
+	return ""
+}
+
 // сам middleware:
 // added by Alexx:
 
-type keyUID int64
-type myBool bool
-type myError error
+// ctxKey - тип ключей контекста. Ключ должен быть не nil
+// (context.WithValue паникует на nil-ключе) и не совпадать с ключами других пакетов
+type ctxKey int
 
-var (
-	uidKey     keyUID
-	errorKey   myError
-	isAdminKey myBool
+const (
+	principalKey ctxKey = iota
+	errorKey
 )
 
 // New creates new auth middleware.
+// Запрос может быть авторизован JWT-токеном или API-ключом (см. extractAPIKey),
+// в обоих случаях в контекст сохраняется Principal. keys может быть nil - тогда API-ключи не принимаются.
+// Middleware ничего не запрещает: неавторизованный запрос проходит дальше,
+// а ошибка авторизации доступна через ErrorFromContext. Для обязательной аутентификации см. Chain
 func New(
 	log *slog.Logger,
 	appSecret string,
+	keys APIKeyVerifier,
 	//permProvider PermissionProvider,
 ) func(next http.Handler) http.Handler {
 	const op = "middleware.auth.New"
 
 	log = log.With(slog.String("op", op))
 
+	authenticators := []Authenticator{NewJWT(jwt.NewHMACVerifier(appSecret))}
+	if keys != nil {
+		authenticators = append([]Authenticator{NewAPIKey(keys)}, authenticators...)
+	}
+
 	// Возвращаем функцию-обработчик
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Получаем JWT-токен из запроса
-			tokenStr := extractBearerToken(r)
-			if tokenStr == "" {
-				// It's ok, if user is not authorized
-				next.ServeHTTP(w, r)
-				return
-			}
-
-			// Парсим и валидируем токен, используя appSecret
-			claims, err := jwt.Parse(tokenStr, appSecret)
+			principal, ok, err := authenticate(r, authenticators)
 			if err != nil {
-				log.Warn("failed to parse token", sl.Err(err))
+				log.Warn("failed to authenticate", sl.Err(err))
 
 				// But if token is invalid, we shouldn't handle request
-				ctx := context.WithValue(r.Context(), errorKey, ErrInvalidToken)
+				ctx := context.WithValue(r.Context(), errorKey, err)
 				next.ServeHTTP(w, r.WithContext(ctx))
 
 				return
 			}
+			if !ok {
+				// It's ok, if user is not authorized
+				next.ServeHTTP(w, r)
+				return
+			}
 
-			log.Info("user authorized", slog.Any("claims", claims))
-
-			// Отправляем запрос для проверки, является ли пользователь админом
-			// пока закомментировал
-			isAdmin := false
-			/*
-				isAdmin, err := permProvider.IsAdmin(r.Context(), claims.UID)
-				if err != nil {
-					log.Error("failed to check if user is admin", sl.Err(err))
-
-					ctx := context.WithValue(r.Context(), errorKey, ErrFailedIsAdminCheck)
-					next.ServeHTTP(w, r.WithContext(ctx))
+			log.Info("user authorized", slog.Int64("uid", principal.UID), slog.String("method", principal.Method))
 
-					return
-				}
-			*/
 			// Полученные данные сохраняем в контекст,
 			// откуда его смогут получить следующие хэндлеры.
+			next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
 
-			ctx := context.WithValue(r.Context(), uidKey, claims.UID)
-			ctx = context.WithValue(r.Context(), isAdminKey, isAdmin)
+// verifyAPIKey ищет ключ по хешу и проверяет, что он действует
+func verifyAPIKey(keys APIKeyVerifier, key string) (Principal, error) {
+	stored, err := keys.GetAPIKeyByHash(apikey.Hash(key))
+	if err != nil {
+		return Principal{}, err
+	}
 
-			next.ServeHTTP(w, r.WithContext(ctx))
-		})
+	now := time.Now()
+	if !stored.ActiveAt(now) {
+		return Principal{}, errors.New("api key is revoked or expired")
 	}
+
+	// Ошибка записи времени использования не должна мешать запросу
+	_ = keys.TouchAPIKey(stored.ID, now)
+
+	return Principal{UID: stored.UID, KeyID: stored.ID, Scopes: stored.Scopes, Method: MethodAPIKey}, nil
 }
 
+// WithUID возвращает контекст с UID пользователя, авторизованного JWT-токеном
+func WithUID(ctx context.Context, uid int64) context.Context {
+	return WithPrincipal(ctx, Principal{UID: uid, Method: MethodJWT})
+}
+
+// UIDFromContext - UID пользователя SSO, от имени которого выполняется запрос.
+// Для basic auth и анонимных запросов пользователя нет
 func UIDFromContext(ctx context.Context) (int64, bool) {
-	uid, ok := ctx.Value(uidKey).(int64)
-	return uid, ok
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok || principal.UID == 0 {
+		return 0, false
+	}
+
+	return principal.UID, true
+}
+
+// IsAdminFromContext - является ли пользователь администратором сервиса
+func IsAdminFromContext(ctx context.Context) bool {
+	principal, _ := PrincipalFromContext(ctx)
+	return principal.IsAdmin
 }
 
 func ErrorFromContext(ctx context.Context) (error, bool) {
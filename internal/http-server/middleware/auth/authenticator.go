@@ -0,0 +1,323 @@
+// internal/http-server/middleware/auth/authenticator.go
+
+package auth
+
+import (
+	"bufio"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/go-chi/render"
+	"golang.org/x/crypto/bcrypt"
+
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/apikey"
+	"url-shortener/internal/lib/jwt"
+	"url-shortener/internal/lib/logger/sl"
+)
+
+var ErrInvalidCredentials = errors.New("invalid username or password")
+
+// Authenticator - один из способов аутентификации запроса.
+// Если в запросе нет учетных данных этого способа, возвращает ok = false и nil-ошибку,
+// и проверка переходит к следующему способу цепочки.
+// Если учетные данные есть, но неверны - возвращает ошибку: цепочка дальше не проверяется
+type Authenticator interface {
+	Authenticate(r *http.Request) (principal Principal, ok bool, err error)
+}
+
+// BasicAuthenticator - basic auth по логинам и bcrypt-хешам паролей из htpasswd-файла
+type BasicAuthenticator struct {
+	hashes map[string][]byte
+	// пароли в открытом виде из http_server.user/password (для совместимости со старыми конфигами)
+	plain map[string]string
+}
+
+// NewBasic загружает пользователей из htpasswd-файла (путь может быть пустым)
+// и добавляет к ним пары логин-пароль legacy
+func NewBasic(htpasswdPath string, legacy map[string]string) (*BasicAuthenticator, error) {
+	const op = "middleware.auth.NewBasic"
+
+	a := &BasicAuthenticator{hashes: map[string][]byte{}, plain: map[string]string{}}
+
+	if htpasswdPath != "" {
+		hashes, err := LoadHtpasswd(htpasswdPath)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		a.hashes = hashes
+	}
+
+	for user, pass := range legacy {
+		if user == "" {
+			continue
+		}
+		if _, ok := a.hashes[user]; !ok {
+			a.plain[user] = pass
+		}
+	}
+
+	if len(a.hashes)+len(a.plain) == 0 {
+		return nil, fmt.Errorf("%s: no users configured", op)
+	}
+
+	return a, nil
+}
+
+// LoadHtpasswd читает файл формата htpasswd: строки "логин:bcrypt-хеш",
+// пустые строки и строки с # игнорируются. Поддерживаются только bcrypt-хеши ($2a$, $2b$, $2y$):
+// htpasswd -B -c ./config/htpasswd my_user
+func LoadHtpasswd(path string) (map[string][]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open htpasswd: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	hashes := make(map[string][]byte)
+
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		user, hash, ok := strings.Cut(text, ":")
+		if !ok || user == "" {
+			return nil, fmt.Errorf("htpasswd line %d: expected user:hash", line)
+		}
+
+		if _, err := bcrypt.Cost([]byte(hash)); err != nil {
+			return nil, fmt.Errorf("htpasswd line %d: only bcrypt hashes are supported", line)
+		}
+
+		hashes[user] = []byte(hash)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read htpasswd: %w", err)
+	}
+
+	return hashes, nil
+}
+
+func (a *BasicAuthenticator) Authenticate(r *http.Request) (Principal, bool, error) {
+	user, pass, ok := r.BasicAuth()
+	if !ok {
+		return Principal{}, false, nil
+	}
+
+	valid := false
+	if hash, ok := a.hashes[user]; ok {
+		valid = bcrypt.CompareHashAndPassword(hash, []byte(pass)) == nil
+	} else if plain, ok := a.plain[user]; ok {
+		valid = subtle.ConstantTimeCompare([]byte(pass), []byte(plain)) == 1
+	}
+
+	if !valid {
+		return Principal{}, false, ErrInvalidCredentials
+	}
+
+	// Basic auth - общая учетная запись сервиса, поэтому она администраторская
+	return Principal{Method: MethodBasic, Name: user, IsAdmin: true}, true, nil
+}
+
+// TokenVerifier проверяет JWT-токен SSO (см. jwt.Verifier)
+type TokenVerifier interface {
+	Verify(token string) (*jwt.Claims, error)
+}
+
+// AdminChecker узнает у SSO, является ли пользователь администратором сервиса (см. ssogrpc.Client)
+type AdminChecker interface {
+	IsAdmin(ctx context.Context, userID int64) (bool, error)
+}
+
+// JWTAuthenticator - JWT-токен SSO в заголовке Authorization: Bearer
+type JWTAuthenticator struct {
+	verifier TokenVerifier
+	cookie   string
+	admins   AdminChecker
+}
+
+func NewJWT(verifier TokenVerifier) *JWTAuthenticator {
+	return &JWTAuthenticator{verifier: verifier}
+}
+
+// WithCookie включает прием токена из cookie с именем name (если нет заголовка Authorization).
+// Пустое имя - cookie не проверяется
+func (a *JWTAuthenticator) WithCookie(name string) *JWTAuthenticator {
+	a.cookie = name
+	return a
+}
+
+// WithAdminChecker включает проверку, является ли владелец токена администратором.
+// Недоступность SSO обрабатывается политикой клиента (clients.sso.degraded.is_admin):
+// при fail_open пользователь считается не администратором, при fail_closed запрос отклоняется с кодом 503
+func (a *JWTAuthenticator) WithAdminChecker(admins AdminChecker) *JWTAuthenticator {
+	a.admins = admins
+	return a
+}
+
+func (a *JWTAuthenticator) Authenticate(r *http.Request) (Principal, bool, error) {
+	token := extractBearerToken(r)
+	if token == "" && a.cookie != "" {
+		if c, err := r.Cookie(a.cookie); err == nil {
+			token = c.Value
+		}
+	}
+	if token == "" || apikey.Looks(token) {
+		return Principal{}, false, nil
+	}
+
+	// Проверяем подпись, алгоритм и содержимое токена
+	claims, err := a.verifier.Verify(token)
+	if err != nil {
+		return Principal{}, false, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	principal := Principal{Method: MethodJWT, Name: claims.Email, UID: claims.UID}
+
+	if a.admins != nil && claims.UID != 0 {
+		isAdmin, err := a.admins.IsAdmin(r.Context(), claims.UID)
+		if err != nil {
+			return Principal{}, false, fmt.Errorf("%w: %w", ErrFailedIsAdminCheck, err)
+		}
+		principal.IsAdmin = isAdmin
+	}
+
+	return principal, true, nil
+}
+
+// APIKeyAuthenticator - API-ключ в заголовке X-API-Key или Authorization: Bearer
+type APIKeyAuthenticator struct {
+	keys APIKeyVerifier
+}
+
+func NewAPIKey(keys APIKeyVerifier) *APIKeyAuthenticator {
+	return &APIKeyAuthenticator{keys: keys}
+}
+
+func (a *APIKeyAuthenticator) Authenticate(r *http.Request) (Principal, bool, error) {
+	key := extractAPIKey(r)
+	if key == "" {
+		return Principal{}, false, nil
+	}
+
+	principal, err := verifyAPIKey(a.keys, key)
+	if err != nil {
+		return Principal{}, false, fmt.Errorf("%w: %w", ErrInvalidAPIKey, err)
+	}
+
+	return principal, true, nil
+}
+
+// Anonymous пропускает любой запрос без аутентификации.
+// Ставится в конец цепочки, если эндпоинты группы должны быть доступны всем
+type Anonymous struct{}
+
+func (Anonymous) Authenticate(*http.Request) (Principal, bool, error) {
+	return Principal{Method: MethodAnonymous}, true, nil
+}
+
+// Build собирает цепочку по именам способов аутентификации (basic, jwt, api_key, anonymous)
+func Build(names []string, available map[string]Authenticator) ([]Authenticator, error) {
+	if len(names) == 0 {
+		return nil, errors.New("empty authenticator chain")
+	}
+
+	chain := make([]Authenticator, 0, len(names))
+	for _, name := range names {
+		a, ok := available[name]
+		if !ok || a == nil {
+			return nil, fmt.Errorf("unknown or unavailable authenticator %q", name)
+		}
+		chain = append(chain, a)
+	}
+
+	return chain, nil
+}
+
+// Chain - middleware обязательной аутентификации: способы проверяются по порядку,
+// первый распознавший учетные данные определяет Principal запроса.
+// Если учетных данных нет или они неверны - ответ 401
+func Chain(log *slog.Logger, realm string, authenticators ...Authenticator) func(next http.Handler) http.Handler {
+	const op = "middleware.auth.Chain"
+
+	log = log.With(slog.String("op", op))
+
+	// Браузеру нужно подсказать, что можно ввести логин и пароль
+	askBasic := false
+	for _, a := range authenticators {
+		if _, ok := a.(*BasicAuthenticator); ok {
+			askBasic = true
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok, err := authenticate(r, authenticators)
+			if err == nil && ok {
+				next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+				return
+			}
+
+			// Проверить права не удалось из-за SSO - учетные данные при этом могут быть верны
+			if errors.Is(err, ErrFailedIsAdminCheck) {
+				log.Error("failed to check if user is admin", sl.Err(err))
+
+				render.Status(r, http.StatusServiceUnavailable)
+				render.JSON(w, r, resp.Error(ErrFailedIsAdminCheck.Error()))
+
+				return
+			}
+
+			if err != nil {
+				log.Info("authentication failed", sl.Err(err))
+			} else {
+				err = ErrUnauthorized
+			}
+
+			if askBasic {
+				w.Header().Add("WWW-Authenticate", fmt.Sprintf(`Basic realm="%s"`, realm))
+			}
+
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, resp.Error(errorMessage(err)))
+		})
+	}
+}
+
+// authenticate проверяет способы аутентификации по порядку
+func authenticate(r *http.Request, authenticators []Authenticator) (Principal, bool, error) {
+	for _, a := range authenticators {
+		principal, ok, err := a.Authenticate(r)
+		if err != nil || ok {
+			return principal, ok, err
+		}
+	}
+
+	return Principal{}, false, nil
+}
+
+// errorMessage - текст ошибки для клиента без внутренних подробностей
+func errorMessage(err error) string {
+	// Клиенту полезно знать, что токен просто истек и его нужно обновить
+	if errors.Is(err, jwt.ErrExpired) {
+		return jwt.ErrExpired.Error()
+	}
+
+	for _, known := range []error{ErrInvalidCredentials, ErrInvalidToken, ErrInvalidAPIKey} {
+		if errors.Is(err, known) {
+			return known.Error()
+		}
+	}
+
+	return ErrUnauthorized.Error()
+}
@@ -0,0 +1,285 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"url-shortener/internal/http-server/middleware/auth"
+	"url-shortener/internal/lib/jwt"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+)
+
+// writeHtpasswd создает временный htpasswd-файл с одним пользователем
+func writeHtpasswd(t *testing.T, user, pass string) string {
+	t.Helper()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(pass), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "htpasswd")
+	content := "# users\n\n" + user + ":" + string(hash) + "\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func TestLoadHtpasswd(t *testing.T) {
+	path := writeHtpasswd(t, "alice", "secret")
+
+	hashes, err := auth.LoadHtpasswd(path)
+	require.NoError(t, err)
+	require.Contains(t, hashes, "alice")
+
+	plain := filepath.Join(t.TempDir(), "plain")
+	require.NoError(t, os.WriteFile(plain, []byte("bob:{SHA}abc\n"), 0o600))
+
+	_, err = auth.LoadHtpasswd(plain)
+	require.ErrorContains(t, err, "only bcrypt")
+
+	_, err = auth.NewBasic("", nil)
+	require.Error(t, err)
+}
+
+func TestChain(t *testing.T) {
+	basic, err := auth.NewBasic(writeHtpasswd(t, "alice", "secret"), map[string]string{"legacy": "pass"})
+	require.NoError(t, err)
+
+	cases := []struct {
+		name    string
+		chain   []auth.Authenticator
+		prepare func(r *http.Request)
+		code    int
+		method  string // способ, которым аутентифицирован запрос
+	}{
+		{
+			name:    "Htpasswd user",
+			chain:   []auth.Authenticator{basic},
+			prepare: func(r *http.Request) { r.SetBasicAuth("alice", "secret") },
+			code:    http.StatusOK,
+			method:  auth.MethodBasic,
+		},
+		{
+			name:    "Legacy user",
+			chain:   []auth.Authenticator{basic},
+			prepare: func(r *http.Request) { r.SetBasicAuth("legacy", "pass") },
+			code:    http.StatusOK,
+			method:  auth.MethodBasic,
+		},
+		{
+			name:    "Wrong password",
+			chain:   []auth.Authenticator{basic},
+			prepare: func(r *http.Request) { r.SetBasicAuth("alice", "wrong") },
+			code:    http.StatusUnauthorized,
+		},
+		{
+			name:    "No credentials",
+			chain:   []auth.Authenticator{basic, auth.NewJWT(jwt.NewHMACVerifier("secret"))},
+			prepare: func(r *http.Request) {},
+			code:    http.StatusUnauthorized,
+		},
+		{
+			name:    "Invalid token is not skipped",
+			chain:   []auth.Authenticator{auth.NewJWT(jwt.NewHMACVerifier("secret")), auth.Anonymous{}},
+			prepare: func(r *http.Request) { r.Header.Set("Authorization", "Bearer garbage") },
+			code:    http.StatusUnauthorized,
+		},
+		{
+			name:    "Anonymous fallback",
+			chain:   []auth.Authenticator{basic, auth.Anonymous{}},
+			prepare: func(r *http.Request) {},
+			code:    http.StatusOK,
+			method:  auth.MethodAnonymous,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			var method string
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				principal, _ := auth.PrincipalFromContext(r.Context())
+				method = principal.Method
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/url", nil)
+			tc.prepare(req)
+			rr := httptest.NewRecorder()
+
+			auth.Chain(slogdiscard.NewDiscardLogger(), "test", tc.chain...)(handler).ServeHTTP(rr, req)
+
+			require.Equal(t, tc.code, rr.Code)
+			require.Equal(t, tc.method, method)
+		})
+	}
+}
+
+func TestBuild(t *testing.T) {
+	available := map[string]auth.Authenticator{"jwt": auth.NewJWT(jwt.NewHMACVerifier("secret"))}
+
+	chain, err := auth.Build([]string{"jwt"}, available)
+	require.NoError(t, err)
+	require.Len(t, chain, 1)
+
+	_, err = auth.Build([]string{"jwt", "basic"}, available)
+	require.Error(t, err)
+
+	_, err = auth.Build(nil, available)
+	require.Error(t, err)
+}
+
+// fakeAdmins - ответ SSO на проверку прав администратора
+type fakeAdmins struct {
+	admins map[int64]bool
+	err    error
+}
+
+func (f fakeAdmins) IsAdmin(_ context.Context, userID int64) (bool, error) {
+	return f.admins[userID], f.err
+}
+
+func TestJWTAdminCheck(t *testing.T) {
+	token, err := gojwt.NewWithClaims(gojwt.SigningMethodHS256, gojwt.MapClaims{
+		"uid":   42,
+		"email": "user@example.com",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte("secret"))
+	require.NoError(t, err)
+
+	cases := []struct {
+		name    string
+		admins  auth.AdminChecker
+		code    int
+		isAdmin bool
+	}{
+		{name: "No checker", code: http.StatusOK},
+		{name: "Admin", admins: fakeAdmins{admins: map[int64]bool{42: true}}, code: http.StatusOK, isAdmin: true},
+		{name: "Not admin", admins: fakeAdmins{}, code: http.StatusOK},
+		{name: "SSO unavailable", admins: fakeAdmins{err: errors.New("sso is down")}, code: http.StatusServiceUnavailable},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			isAdmin := false
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				isAdmin = auth.IsAdminFromContext(r.Context())
+			})
+
+			jwtAuth := auth.NewJWT(jwt.NewHMACVerifier("secret"))
+			if tc.admins != nil {
+				jwtAuth = jwtAuth.WithAdminChecker(tc.admins)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/url", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rr := httptest.NewRecorder()
+
+			auth.Chain(slogdiscard.NewDiscardLogger(), "test", jwtAuth)(handler).ServeHTTP(rr, req)
+
+			require.Equal(t, tc.code, rr.Code)
+			require.Equal(t, tc.isAdmin, isAdmin)
+		})
+	}
+}
+
+func TestRequireAdmin(t *testing.T) {
+	token, err := gojwt.NewWithClaims(gojwt.SigningMethodHS256, gojwt.MapClaims{
+		"uid":   42,
+		"email": "user@example.com",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte("secret"))
+	require.NoError(t, err)
+
+	basic, err := auth.NewBasic(writeHtpasswd(t, "admin", "pass"), nil)
+	require.NoError(t, err)
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	cases := []struct {
+		name   string
+		admins map[int64]bool
+		basic  bool // запрос с basic auth вместо JWT-токена
+		code   int
+	}{
+		{name: "Not admin JWT", admins: map[int64]bool{}, code: http.StatusForbidden},
+		{name: "Admin JWT", admins: map[int64]bool{42: true}, code: http.StatusOK},
+		{name: "Basic auth", basic: true, code: http.StatusOK},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Группа admin, для которой в конфиге разрешен вход по JWT-токену
+			jwtAuth := auth.NewJWT(jwt.NewHMACVerifier("secret")).WithAdminChecker(fakeAdmins{admins: tc.admins})
+			handler := auth.Chain(slogdiscard.NewDiscardLogger(), "test", basic, jwtAuth)(auth.RequireAdmin(ok))
+
+			req := httptest.NewRequest(http.MethodGet, "/admin/audit", nil)
+			if tc.basic {
+				req.SetBasicAuth("admin", "pass")
+			} else {
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.code, rr.Code)
+		})
+	}
+
+	// Без аутентификации
+	rr := httptest.NewRecorder()
+	auth.RequireAdmin(ok).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/admin/audit", nil))
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+}
+
+func TestJWTCookie(t *testing.T) {
+	token, err := gojwt.NewWithClaims(gojwt.SigningMethodHS256, gojwt.MapClaims{
+		"uid":   42,
+		"email": "user@example.com",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte("secret"))
+	require.NoError(t, err)
+
+	var uid int64
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		uid, _ = auth.UIDFromContext(r.Context())
+	})
+
+	for _, cookie := range []string{"token", ""} {
+		uid = 0
+
+		jwtAuth := auth.NewJWT(jwt.NewHMACVerifier("secret")).WithCookie(cookie)
+
+		req := httptest.NewRequest(http.MethodGet, "/url", nil)
+		req.AddCookie(&http.Cookie{Name: "token", Value: token})
+		rr := httptest.NewRecorder()
+
+		auth.Chain(slogdiscard.NewDiscardLogger(), "test", jwtAuth)(handler).ServeHTTP(rr, req)
+
+		if cookie == "" {
+			// Прием токена из cookie выключен
+			require.Equal(t, http.StatusUnauthorized, rr.Code)
+			continue
+		}
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, int64(42), uid)
+	}
+}
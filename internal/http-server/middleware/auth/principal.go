@@ -0,0 +1,121 @@
+// internal/http-server/middleware/auth/principal.go
+
+package auth
+
+import (
+	"context"
+	"net/http"
+	"slices"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/storage"
+)
+
+// Области действия API-ключей
+const (
+	ScopeLinksRead  = "links:read"  // просмотр ссылок и их правил
+	ScopeLinksWrite = "links:write" // создание, изменение и удаление ссылок
+	ScopeStatsRead  = "stats:read"  // просмотр статистики переходов
+
+	ScopeWorkspacesRead  = "workspaces:read"  // просмотр своих рабочих пространств и их участников
+	ScopeWorkspacesWrite = "workspaces:write" // создание пространств и управление участниками
+
+	ScopeWebhooksRead  = "webhooks:read"  // просмотр своих вебхуков и журнала доставок
+	ScopeWebhooksWrite = "webhooks:write" // создание и удаление вебхуков
+)
+
+// Способы аутентификации
+const (
+	MethodBasic     = "basic"     // логин и пароль (htpasswd), общая администраторская учетная запись
+	MethodJWT       = "jwt"       // JWT-токен пользователя SSO
+	MethodAPIKey    = "api_key"   // API-ключ пользователя SSO
+	MethodAnonymous = "anonymous" // без аутентификации
+)
+
+// Principal - от чьего имени выполняется запрос
+type Principal struct {
+	Method string `json:"method"` // одно из Method*
+	// Имя пользователя: логин basic auth или email из JWT-токена
+	Name  string `json:"name,omitempty"`
+	UID   int64  `json:"uid,omitempty"`    // пользователь SSO, 0 - нет (basic auth, анонимный запрос)
+	KeyID int64  `json:"key_id,omitempty"` // API-ключ запроса, 0 - пользователь авторизован JWT-токеном
+	// Разрешенные области действия API-ключа. Для JWT-токена ограничений нет
+	Scopes []string `json:"scopes,omitempty"`
+	// Администратор сервиса: доступ ко всем ссылкам без проверки ролей в рабочих пространствах
+	IsAdmin bool `json:"is_admin,omitempty"`
+}
+
+// HasScope - разрешено ли действие scope
+func (p Principal) HasScope(scope string) bool {
+	if p.KeyID == 0 {
+		return true
+	}
+
+	return slices.Contains(p.Scopes, scope)
+}
+
+// WithPrincipal возвращает контекст с авторизованным пользователем
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey, principal)
+}
+
+// PrincipalFromContext - авторизованный пользователь запроса
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey).(Principal)
+	return principal, ok
+}
+
+// RequireScope пропускает запрос с API-ключом, только если ключу разрешено действие scope.
+// Запросы без ключа (JWT-токен, basic auth) не ограничиваются: их аутентификацию проверяют другие middleware
+func RequireScope(scope string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if principal, ok := PrincipalFromContext(r.Context()); ok && !principal.HasScope(scope) {
+				render.Status(r, http.StatusForbidden)
+				render.JSON(w, r, resp.Error("api key has no scope "+scope))
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireAdmin пропускает только запросы администраторов сервиса (basic auth или пользователь,
+// которого SSO считает администратором). Нужен потому, что цепочку аутентификации группы
+// можно задать в конфиге: аутентификация сама по себе прав администратора не дает
+func RequireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := PrincipalFromContext(r.Context())
+		if !ok {
+			render.Status(r, http.StatusUnauthorized)
+			render.JSON(w, r, resp.Error(ErrUnauthorized.Error()))
+
+			return
+		}
+		if !principal.IsAdmin {
+			render.Status(r, http.StatusForbidden)
+			render.JSON(w, r, resp.Error(ErrForbidden.Error()))
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ActorFromRequest - автор изменения для журнала аудита: пользователь запроса и request_id
+func ActorFromRequest(r *http.Request) storage.Actor {
+	principal, _ := PrincipalFromContext(r.Context())
+
+	return storage.Actor{
+		UID:       principal.UID,
+		KeyID:     principal.KeyID,
+		Name:      principal.Name,
+		RequestID: middleware.GetReqID(r.Context()),
+	}
+}
@@ -0,0 +1,248 @@
+// internal/http-server/middleware/idempotency/idempotency.go
+
+// Package idempotency - повтор запросов на создание с заголовком Idempotency-Key.
+// Первый запрос с ключом выполняется, а ответ на него сохраняется на время window.
+// Повтор с тем же ключом и тем же телом получает сохраненный ответ без повторного выполнения,
+// повтор с другим телом - 422. Ответы с кодом 5xx не сохраняются: такой запрос можно повторить.
+// Если первый запрос не завершился за staleAfter (например, сервис упал), ключ можно занять заново
+package idempotency
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+
+	"url-shortener/internal/http-server/middleware/auth"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/storage"
+)
+
+const (
+	HeaderKey      = "Idempotency-Key"
+	HeaderReplayed = "Idempotent-Replayed" // "true" в сохраненном ответе
+
+	maxKeyLength = 255
+	// Самый большой запрос, который можно повторить: тело читается в память целиком
+	maxBodySize = 16 << 20
+)
+
+var (
+	ErrInvalidKey = errors.New("idempotency key must be 1-255 printable ASCII characters")
+	ErrInProgress = errors.New("a request with this idempotency key is still in progress")
+	ErrMismatch   = errors.New("idempotency key was already used with a different request")
+	ErrTooLarge   = errors.New("request body is too large for an idempotent request")
+)
+
+// Store хранит ключи идемпотентности и ответы
+type Store interface {
+	ReserveIdempotencyKey(rec storage.IdempotencyRecord, staleBefore time.Time) (storage.IdempotencyRecord, bool, error)
+	CompleteIdempotencyKey(scope string, key string, statusCode int, contentType string, body []byte) error
+	ReleaseIdempotencyKey(scope string, key string) error
+}
+
+// New - middleware идемпотентности. Подключается после аутентификации: ключи разных клиентов не пересекаются.
+// window <= 0 - заголовок Idempotency-Key игнорируется. staleAfter должен быть больше времени обработки
+// самого долгого запроса, иначе повтор выполнится еще раз, пока первый запрос обрабатывается
+func New(log *slog.Logger, store Store, window time.Duration, staleAfter time.Duration) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if window <= 0 {
+			return next
+		}
+
+		log := log.With(slog.String("component", "middleware/idempotency"))
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(HeaderKey)
+			if key == "" {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			log := log.With(slog.String("request_id", middleware.GetReqID(r.Context())))
+
+			if !validKey(key) {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, resp.Error(ErrInvalidKey.Error()))
+
+				return
+			}
+
+			body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize+1))
+			if err != nil {
+				log.Error("failed to read request body", sl.Err(err))
+
+				render.JSON(w, r, resp.Error("failed to read request"))
+
+				return
+			}
+			if len(body) > maxBodySize {
+				render.Status(r, http.StatusRequestEntityTooLarge)
+				render.JSON(w, r, resp.Error(ErrTooLarge.Error()))
+
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			now := time.Now()
+			rec := storage.IdempotencyRecord{
+				Scope:       scope(r),
+				Key:         key,
+				Fingerprint: fingerprint(r, body),
+				CreatedAt:   now,
+				ExpiresAt:   now.Add(window),
+			}
+
+			existing, reserved, err := store.ReserveIdempotencyKey(rec, now.Add(-staleAfter))
+			if err != nil {
+				log.Error("failed to reserve idempotency key", sl.Err(err))
+
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, resp.Error("internal error"))
+
+				return
+			}
+
+			if !reserved {
+				replay(w, r, log, rec, existing)
+
+				return
+			}
+
+			rw := &recorder{ResponseWriter: w}
+
+			// ключ освобождается, если ответ не сохранен (5xx или паника обработчика)
+			completed := false
+			defer func() {
+				if completed {
+					return
+				}
+				if err := store.ReleaseIdempotencyKey(rec.Scope, rec.Key); err != nil {
+					log.Error("failed to release idempotency key", sl.Err(err))
+				}
+			}()
+
+			next.ServeHTTP(rw, r)
+
+			status := rw.statusCode()
+			if status >= http.StatusInternalServerError {
+				return
+			}
+
+			err = store.CompleteIdempotencyKey(rec.Scope, rec.Key, status, rw.Header().Get("Content-Type"), rw.body.Bytes())
+			if err != nil {
+				log.Error("failed to save idempotent response", sl.Err(err))
+
+				return
+			}
+			completed = true
+		})
+	}
+}
+
+// replay отвечает на повтор запроса с занятым ключом
+func replay(w http.ResponseWriter, r *http.Request, log *slog.Logger, rec, existing storage.IdempotencyRecord) {
+	if existing.Fingerprint != rec.Fingerprint {
+		log.Info("idempotency key reused with a different request")
+
+		render.Status(r, http.StatusUnprocessableEntity)
+		render.JSON(w, r, resp.Error(ErrMismatch.Error()))
+
+		return
+	}
+
+	if !existing.Completed {
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, resp.Error(ErrInProgress.Error()))
+
+		return
+	}
+
+	log.Info("replaying idempotent response", slog.Int("status", existing.StatusCode))
+
+	if existing.ContentType != "" {
+		w.Header().Set("Content-Type", existing.ContentType)
+	}
+	w.Header().Set(HeaderReplayed, "true")
+	w.WriteHeader(existing.StatusCode)
+	_, _ = w.Write(existing.Body)
+}
+
+// scope - от чьего имени отправлен запрос
+func scope(r *http.Request) string {
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	switch {
+	case !ok:
+		return auth.MethodAnonymous
+	case principal.KeyID != 0:
+		return "key:" + strconv.FormatInt(principal.KeyID, 10)
+	case principal.UID != 0:
+		return "uid:" + strconv.FormatInt(principal.UID, 10)
+	default:
+		return principal.Method + ":" + principal.Name
+	}
+}
+
+// fingerprint - хеш запроса: метод, путь с параметрами и тело
+func fingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	h.Write(body)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func validKey(key string) bool {
+	if len(key) > maxKeyLength {
+		return false
+	}
+
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x21 || key[i] > 0x7e {
+			return false
+		}
+	}
+
+	return true
+}
+
+// recorder пишет ответ клиенту и запоминает его для сохранения
+type recorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rw *recorder) WriteHeader(code int) {
+	if rw.status == 0 {
+		rw.status = code
+	}
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *recorder) Write(b []byte) (int, error) {
+	if rw.status == 0 {
+		rw.status = http.StatusOK
+	}
+	rw.body.Write(b)
+
+	return rw.ResponseWriter.Write(b)
+}
+
+func (rw *recorder) statusCode() int {
+	if rw.status == 0 {
+		return http.StatusOK
+	}
+
+	return rw.status
+}
@@ -0,0 +1,192 @@
+package idempotency_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-chi/render"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/http-server/middleware/auth"
+	"url-shortener/internal/http-server/middleware/idempotency"
+	resp "url-shortener/internal/lib/api/response"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/storage"
+	"url-shortener/internal/storage/sqlite"
+)
+
+type saveResponse struct {
+	resp.Response
+	Alias string `json:"alias,omitempty"`
+}
+
+// newServer - обработчик, который на каждый вызов выдает новый "случайный" алиас
+func newServer(t *testing.T, status int) (http.Handler, *atomic.Int64) {
+	t.Helper()
+
+	store, err := sqlite.NewStorage(filepath.Join(t.TempDir(), "storage.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+
+	calls := &atomic.Int64{}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.ReadAll(r.Body)
+		n := calls.Add(1)
+
+		render.Status(r, status)
+		render.JSON(w, r, saveResponse{Response: resp.OK(), Alias: "alias" + strconv.FormatInt(n, 10)})
+	})
+
+	return idempotency.New(slogdiscard.NewDiscardLogger(), store, time.Hour, 10*time.Minute)(handler), calls
+}
+
+func send(h http.Handler, uid int64, key string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/url", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(idempotency.HeaderKey, key)
+	}
+	req = req.WithContext(auth.WithUID(req.Context(), uid))
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	return rr
+}
+
+func alias(t *testing.T, rr *httptest.ResponseRecorder) string {
+	t.Helper()
+
+	var response saveResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+
+	return response.Alias
+}
+
+func TestReplay(t *testing.T) {
+	h, calls := newServer(t, http.StatusOK)
+
+	first := send(h, 1, "key-1", `{"url":"https://example.com"}`)
+	require.Equal(t, http.StatusOK, first.Code)
+	require.Empty(t, first.Header().Get(idempotency.HeaderReplayed))
+
+	retry := send(h, 1, "key-1", `{"url":"https://example.com"}`)
+	require.Equal(t, http.StatusOK, retry.Code)
+	require.Equal(t, "true", retry.Header().Get(idempotency.HeaderReplayed))
+	require.Equal(t, first.Header().Get("Content-Type"), retry.Header().Get("Content-Type"))
+	require.Equal(t, first.Body.String(), retry.Body.String())
+	require.Equal(t, int64(1), calls.Load())
+
+	// тот же ключ другого пользователя - другой запрос
+	other := send(h, 2, "key-1", `{"url":"https://example.com"}`)
+	require.Equal(t, "alias2", alias(t, other))
+
+	// без ключа запросы не отслеживаются
+	send(h, 1, "", `{"url":"https://example.com"}`)
+	send(h, 1, "", `{"url":"https://example.com"}`)
+	require.Equal(t, int64(4), calls.Load())
+}
+
+func TestMismatch(t *testing.T) {
+	h, calls := newServer(t, http.StatusOK)
+
+	send(h, 1, "key-1", `{"url":"https://example.com"}`)
+
+	rr := send(h, 1, "key-1", `{"url":"https://example.org"}`)
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+
+	var response resp.Response
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	require.Equal(t, idempotency.ErrMismatch.Error(), response.Error)
+	require.Equal(t, int64(1), calls.Load())
+}
+
+func TestServerErrorIsNotStored(t *testing.T) {
+	h, calls := newServer(t, http.StatusInternalServerError)
+
+	send(h, 1, "key-1", `{"url":"https://example.com"}`)
+	rr := send(h, 1, "key-1", `{"url":"https://example.com"}`)
+
+	require.Empty(t, rr.Header().Get(idempotency.HeaderReplayed))
+	require.Equal(t, int64(2), calls.Load())
+}
+
+func TestInvalidKey(t *testing.T) {
+	h, calls := newServer(t, http.StatusOK)
+
+	for _, key := range []string{"with space", strings.Repeat("k", 256), "ключ"} {
+		rr := send(h, 1, key, `{}`)
+		require.Equal(t, http.StatusBadRequest, rr.Code, key)
+	}
+	require.Zero(t, calls.Load())
+}
+
+func TestInProgress(t *testing.T) {
+	store, err := sqlite.NewStorage(filepath.Join(t.TempDir(), "storage.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+
+	started, release := make(chan struct{}), make(chan struct{})
+	h := idempotency.New(slogdiscard.NewDiscardLogger(), store, time.Hour, 10*time.Minute)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-release
+			render.JSON(w, r, resp.OK())
+		}),
+	)
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- send(h, 1, "key-1", `{}`) }()
+	<-started
+
+	rr := send(h, 1, "key-1", `{}`)
+	require.Equal(t, http.StatusConflict, rr.Code)
+
+	close(release)
+	require.Equal(t, http.StatusOK, (<-done).Code)
+	require.Equal(t, "true", send(h, 1, "key-1", `{}`).Header().Get(idempotency.HeaderReplayed))
+}
+
+func TestStaleReservation(t *testing.T) {
+	store, err := sqlite.NewStorage(filepath.Join(t.TempDir(), "storage.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+
+	calls := &atomic.Int64{}
+	h := idempotency.New(slogdiscard.NewDiscardLogger(), store, time.Hour, 10*time.Minute)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			render.JSON(w, r, resp.OK())
+		}),
+	)
+
+	// Запрос, занявший ключ, не завершился (например, сервис упал во время обработки)
+	sum := sha256.Sum256([]byte("POST /url\n{}"))
+	reserve := func(age time.Duration) {
+		createdAt := time.Now().Add(-age)
+		_, reserved, err := store.ReserveIdempotencyKey(storage.IdempotencyRecord{
+			Scope: "uid:1", Key: "key-" + age.String(), Fingerprint: hex.EncodeToString(sum[:]),
+			CreatedAt: createdAt, ExpiresAt: createdAt.Add(time.Hour),
+		}, createdAt)
+		require.NoError(t, err)
+		require.True(t, reserved)
+	}
+
+	// Пока не прошло staleAfter, ключ считается занятым
+	reserve(5 * time.Minute)
+	require.Equal(t, http.StatusConflict, send(h, 1, "key-5m0s", `{}`).Code)
+	require.Zero(t, calls.Load())
+
+	reserve(15 * time.Minute)
+	require.Equal(t, http.StatusOK, send(h, 1, "key-15m0s", `{}`).Code)
+	require.Equal(t, int64(1), calls.Load())
+}
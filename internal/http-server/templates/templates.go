@@ -0,0 +1,38 @@
+// internal/http-server/templates/templates.go
+
+// HTML-шаблоны страниц, которые сервис отдает браузеру.
+// Шаблоны встроены в бинарник, поэтому их не нужно раскладывать на сервере рядом с приложением.
+package templates
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"net/http"
+)
+
+//go:embed *.html
+var files embed.FS
+
+// Шаблоны разбираются один раз при старте: ошибка в шаблоне - ошибка сборки, а не запроса
+var pages = template.Must(template.ParseFS(files, "*.html"))
+
+// Render выполняет шаблон name и отдает результат как HTML-страницу с кодом status
+func Render(w http.ResponseWriter, status int, name string, data any) error {
+	const op = "templates.Render"
+
+	// Сначала рендерим в буфер, чтобы при ошибке не отдать клиенту половину страницы
+	var buf bytes.Buffer
+	if err := pages.ExecuteTemplate(&buf, name, data); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+
+	_, err := w.Write(buf.Bytes())
+
+	return err
+}
@@ -0,0 +1,58 @@
+// internal/jobs/backup/backup.go
+
+// Package backup - резервное копирование БД по расписанию (см. пакет snapshot)
+package backup
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/lib/snapshot"
+)
+
+// Creator создает резервную копию БД
+type Creator interface {
+	Create(ctx context.Context) (snapshot.Snapshot, error)
+}
+
+// Run раз в interval создает резервную копию. Первая копия - через interval после запуска,
+// чтобы частые перезапуски сервиса не вытесняли ротацией старые копии. Работает до отмены ctx.
+// interval <= 0 - копии только по запросу, Run сразу возвращается
+func Run(ctx context.Context, log *slog.Logger, creator Creator, interval time.Duration) {
+	const op = "jobs.backup.Run"
+
+	log = log.With(slog.String("op", op))
+
+	if interval <= 0 {
+		log.Info("backup interval is not set, scheduled backups are disabled")
+
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		Once(ctx, log, creator)
+	}
+}
+
+// Once создает резервную копию. Ошибка только пишется в лог: копия повторится на следующем проходе
+func Once(ctx context.Context, log *slog.Logger, creator Creator) {
+	s, err := creator.Create(ctx)
+	if err != nil {
+		log.Error("failed to create backup", sl.Err(err))
+
+		return
+	}
+
+	log.Info("backup created", slog.String("name", s.Name), slog.Int64("size", s.Size))
+}
@@ -0,0 +1,50 @@
+package backup_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/jobs/backup"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/lib/snapshot"
+)
+
+// fakeCreator считает созданные копии
+type fakeCreator struct {
+	count atomic.Int32
+}
+
+func (c *fakeCreator) Create(context.Context) (snapshot.Snapshot, error) {
+	c.count.Add(1)
+
+	return snapshot.Snapshot{Name: "snapshot-20240501T100000Z.db"}, nil
+}
+
+func TestRun(t *testing.T) {
+	creator := &fakeCreator{}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		backup.Run(ctx, slogdiscard.NewDiscardLogger(), creator, 10*time.Millisecond)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool { return creator.count.Load() >= 2 }, time.Second, 5*time.Millisecond)
+
+	cancel()
+	<-done
+}
+
+func TestRun_Disabled(t *testing.T) {
+	creator := &fakeCreator{}
+
+	// без интервала Run сразу возвращается, не создавая копий
+	backup.Run(context.Background(), slogdiscard.NewDiscardLogger(), creator, 0)
+
+	require.Zero(t, creator.count.Load())
+}
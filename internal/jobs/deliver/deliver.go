@@ -0,0 +1,224 @@
+// internal/jobs/deliver/deliver.go
+
+// Package deliver - доставка событий вебхукам из очереди в БД.
+// Доставка "хотя бы один раз": если сервис остановился после отправки, но до записи результата,
+// событие будет отправлено повторно - получатель отбрасывает дубли по ID события
+package deliver
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/lib/safedial"
+	"url-shortener/internal/lib/signature"
+	"url-shortener/internal/storage"
+)
+
+// Сколько байт ответа получателя сохранять в журнал доставок при ошибке
+const maxErrorBody = 512
+
+const defaultBatchSize = 100
+
+// Queue - очередь доставок
+type Queue interface {
+	DueDeliveries(now time.Time, limit int) ([]storage.PendingDelivery, error)
+	RecordDeliveryAttempt(id int64, attempt storage.DeliveryAttempt) error
+}
+
+// Options - параметры доставки
+type Options struct {
+	Timeout     time.Duration // ожидание ответа получателя
+	MaxAttempts int           // после стольких неудачных попыток доставка помечается failed
+	BackoffBase time.Duration // пауза после первой неудачи, дальше удваивается
+	BackoffMax  time.Duration
+	BatchSize   int // сколько доставок выбирать из очереди за раз
+	// Разрешить адреса внутренних сетей (loopback, частные сети). Только для тестов и закрытых инсталляций
+	AllowPrivate bool
+}
+
+// Worker отправляет события из очереди
+type Worker struct {
+	log    *slog.Logger
+	queue  Queue
+	client *http.Client
+	opts   Options
+	now    func() time.Time
+}
+
+// New создает Worker. Редиректы получателя не выполняются: ответ 3xx считается неудачей.
+// Адреса внутренних сетей запрещены (защита от SSRF): адрес вебхука задает пользователь,
+// а ответ получателя попадает в журнал доставок
+func New(log *slog.Logger, queue Queue, opts Options) *Worker {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultBatchSize
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 1
+	}
+
+	dialer := &net.Dialer{Timeout: opts.Timeout}
+	if !opts.AllowPrivate {
+		dialer.Control = safedial.Control
+	}
+
+	transport := &http.Transport{
+		Proxy:                 nil, // через прокси проверка адресов не работала бы
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   opts.Timeout,
+		ResponseHeaderTimeout: opts.Timeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       30 * time.Second,
+	}
+
+	return &Worker{
+		log:   log.With(slog.String("op", "jobs.deliver")),
+		queue: queue,
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   opts.Timeout,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		opts: opts,
+		now:  time.Now,
+	}
+}
+
+// Run раз в interval отправляет накопившиеся доставки. Работает до отмены ctx.
+// interval = 0 - доставка выключена, события копятся в очереди
+func (w *Worker) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		w.log.Info("webhook delivery is disabled")
+
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		// Пока очередь выбирается полными пачками, не ждем следующего тика
+		for {
+			if n := w.Once(ctx); n < w.opts.BatchSize || ctx.Err() != nil {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Once отправляет одну пачку доставок, время которых наступило. Возвращает размер пачки
+func (w *Worker) Once(ctx context.Context) int {
+	due, err := w.queue.DueDeliveries(w.now(), w.opts.BatchSize)
+	if err != nil {
+		w.log.Error("failed to read delivery queue", sl.Err(err))
+
+		return 0
+	}
+
+	for _, d := range due {
+		if ctx.Err() != nil {
+			// Доставка останется в очереди и будет выполнена после перезапуска
+			return len(due)
+		}
+
+		attempt := w.deliver(ctx, d)
+		if err := w.queue.RecordDeliveryAttempt(d.ID, attempt); err != nil {
+			w.log.Error("failed to record delivery attempt", slog.Int64("delivery_id", d.ID), sl.Err(err))
+		}
+	}
+
+	return len(due)
+}
+
+// deliver выполняет одну попытку доставки
+func (w *Worker) deliver(ctx context.Context, d storage.PendingDelivery) storage.DeliveryAttempt {
+	log := w.log.With(
+		slog.Int64("delivery_id", d.ID),
+		slog.Int64("webhook_id", d.WebhookID),
+		slog.String("event", d.EventType),
+	)
+
+	now := w.now()
+	attempt := storage.DeliveryAttempt{At: now}
+
+	statusCode, err := w.post(ctx, d, now)
+	attempt.StatusCode = statusCode
+	if err == nil {
+		attempt.Delivered = true
+
+		log.Info("webhook delivered", slog.Int("status", statusCode))
+
+		return attempt
+	}
+
+	attempt.Error = err.Error()
+
+	attempts := d.Attempts + 1
+	if attempts < w.opts.MaxAttempts {
+		next := now.Add(Backoff(attempts, w.opts.BackoffBase, w.opts.BackoffMax))
+		attempt.NextAttemptAt = &next
+
+		log.Info("webhook delivery failed, will retry", slog.Int("attempt", attempts), slog.Time("next", next), sl.Err(err))
+	} else {
+		log.Warn("webhook delivery failed, giving up", slog.Int("attempts", attempts), sl.Err(err))
+	}
+
+	return attempt
+}
+
+// post отправляет подписанный запрос. Ошибка - если ответа нет или он не 2xx
+func (w *Worker) post(ctx context.Context, d storage.PendingDelivery, now time.Time) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "url-shortener-webhooks")
+	req.Header.Set(signature.HeaderEvent, d.EventType)
+	req.Header.Set(signature.HeaderDelivery, strconv.FormatInt(d.ID, 10))
+	req.Header.Set(signature.HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(signature.HeaderSignature, signature.Sign(d.Secret, now.Unix(), d.Payload))
+
+	res, err := w.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	body, _ := io.ReadAll(io.LimitReader(res.Body, maxErrorBody))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("unexpected status %d: %s", res.StatusCode, bytes.TrimSpace(body))
+	}
+
+	return res.StatusCode, nil
+}
+
+// Backoff - пауза перед попыткой номер attempt+1: base, 2*base, 4*base, ..., но не больше max
+func Backoff(attempt int, base, max time.Duration) time.Duration {
+	d := base
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if d >= max {
+			return max
+		}
+	}
+
+	return min(d, max)
+}
@@ -0,0 +1,181 @@
+package deliver_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/jobs/deliver"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/lib/signature"
+	"url-shortener/internal/storage"
+	"url-shortener/internal/storage/sqlite"
+)
+
+const secret = "whsec_test"
+
+// receiver - получатель вебхуков: отвечает кодами из statuses по очереди (последний - на все остальные запросы)
+type receiver struct {
+	t        *testing.T
+	mu       sync.Mutex
+	statuses []int
+	events   []storage.Event
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	require.NoError(rc.t, err)
+
+	err = signature.Verify(secret, r.Header.Get(signature.HeaderTimestamp), body,
+		r.Header.Get(signature.HeaderSignature), time.Minute, time.Now())
+	require.NoError(rc.t, err)
+
+	var event storage.Event
+	require.NoError(rc.t, json.Unmarshal(body, &event))
+	require.Equal(rc.t, event.Type, r.Header.Get(signature.HeaderEvent))
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.events = append(rc.events, event)
+
+	status := rc.statuses[0]
+	if len(rc.statuses) > 1 {
+		rc.statuses = rc.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func setup(t *testing.T, statuses ...int) (*sqlite.Storage, *receiver, int64) {
+	t.Helper()
+
+	store, err := sqlite.NewStorage(filepath.Join(t.TempDir(), "storage.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+
+	rc := &receiver{t: t, statuses: statuses}
+	srv := httptest.NewServer(rc)
+	t.Cleanup(srv.Close)
+
+	id, err := store.SaveWebhook(storage.Webhook{
+		UID:       7,
+		URL:       srv.URL,
+		Events:    []string{storage.EventLinkCreated, storage.EventLinkDeleted},
+		Secret:    secret,
+		CreatedAt: time.Now(),
+	})
+	require.NoError(t, err)
+
+	return store, rc, id
+}
+
+func TestWorker_RetryThenDeliver(t *testing.T) {
+	store, rc, webhookID := setup(t, http.StatusInternalServerError, http.StatusOK)
+
+	linkID, err := store.SaveLink(storage.Link{Alias: "promo", URL: "https://example.com", OwnerUID: 7}, storage.Actor{})
+	require.NoError(t, err)
+	// переход не входит в события вебхука, чужая ссылка - не его
+	require.NoError(t, store.RecordClick(linkID, 0))
+	_, err = store.SaveLink(storage.Link{Alias: "other", URL: "https://example.com", OwnerUID: 8}, storage.Actor{})
+	require.NoError(t, err)
+
+	worker := deliver.New(slogdiscard.NewDiscardLogger(), store, deliver.Options{
+		Timeout:      time.Second,
+		MaxAttempts:  5,
+		BackoffBase:  time.Millisecond,
+		BackoffMax:   time.Millisecond,
+		BatchSize:    10,
+		AllowPrivate: true, // получатель - тестовый сервер на loopback
+	})
+
+	require.Equal(t, 1, worker.Once(context.Background()))
+	require.Equal(t, 1, worker.Once(context.Background()))
+	require.Equal(t, 0, worker.Once(context.Background()))
+
+	require.Len(t, rc.events, 2)
+	require.Equal(t, rc.events[0].ID, rc.events[1].ID, "retry carries the same event ID")
+	require.Equal(t, storage.EventLinkCreated, rc.events[0].Type)
+	require.Equal(t, "promo", rc.events[0].Link.Alias)
+
+	deliveries, err := store.ListDeliveries(7, webhookID, 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	require.Equal(t, storage.DeliveryDelivered, deliveries[0].Status)
+	require.Equal(t, 2, deliveries[0].Attempts)
+	require.Equal(t, http.StatusOK, deliveries[0].LastStatusCode)
+	require.NotNil(t, deliveries[0].DeliveredAt)
+}
+
+func TestWorker_GiveUp(t *testing.T) {
+	store, _, webhookID := setup(t, http.StatusServiceUnavailable)
+
+	_, err := store.SaveLink(storage.Link{Alias: "promo", URL: "https://example.com", OwnerUID: 7}, storage.Actor{})
+	require.NoError(t, err)
+	require.NoError(t, store.DeleteURL("", "promo", storage.Actor{}))
+
+	worker := deliver.New(slogdiscard.NewDiscardLogger(), store, deliver.Options{
+		Timeout:      time.Second,
+		MaxAttempts:  2,
+		BackoffBase:  time.Millisecond,
+		BackoffMax:   time.Millisecond,
+		BatchSize:    10,
+		AllowPrivate: true, // получатель - тестовый сервер на loopback
+	})
+
+	require.Equal(t, 2, worker.Once(context.Background()))
+	require.Equal(t, 2, worker.Once(context.Background()))
+	require.Equal(t, 0, worker.Once(context.Background()))
+
+	deliveries, err := store.ListDeliveries(7, webhookID, 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
+	for _, d := range deliveries {
+		require.Equal(t, storage.DeliveryFailed, d.Status)
+		require.Equal(t, 2, d.Attempts)
+		require.Equal(t, http.StatusServiceUnavailable, d.LastStatusCode)
+		require.Nil(t, d.NextAttemptAt)
+	}
+	require.Equal(t, storage.EventLinkDeleted, deliveries[0].EventType)
+}
+
+func TestWorker_PrivateAddress(t *testing.T) {
+	store, rc, webhookID := setup(t, http.StatusInternalServerError)
+
+	_, err := store.SaveLink(storage.Link{Alias: "promo", URL: "https://example.com", OwnerUID: 7}, storage.Actor{})
+	require.NoError(t, err)
+
+	// без AllowPrivate соединение с получателем на loopback запрещено
+	worker := deliver.New(slogdiscard.NewDiscardLogger(), store, deliver.Options{
+		Timeout:     time.Second,
+		MaxAttempts: 1,
+		BatchSize:   10,
+	})
+
+	require.Equal(t, 1, worker.Once(context.Background()))
+
+	deliveries, err := store.ListDeliveries(7, webhookID, 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	require.Equal(t, storage.DeliveryFailed, deliveries[0].Status)
+	require.Zero(t, deliveries[0].LastStatusCode)
+	require.Contains(t, deliveries[0].LastError, "address is not allowed")
+	require.Empty(t, rc.events)
+}
+
+func TestBackoff(t *testing.T) {
+	base, max := 30*time.Second, 10*time.Minute
+
+	require.Equal(t, 30*time.Second, deliver.Backoff(1, base, max))
+	require.Equal(t, time.Minute, deliver.Backoff(2, base, max))
+	require.Equal(t, 8*time.Minute, deliver.Backoff(5, base, max))
+	require.Equal(t, max, deliver.Backoff(6, base, max))
+	require.Equal(t, max, deliver.Backoff(100, base, max))
+}
@@ -0,0 +1,272 @@
+// internal/jobs/healthcheck/healthcheck.go
+
+// Package healthcheck - периодическая проверка доступности страниц перехода.
+// Каждая ссылка проверяется не чаще раза в Interval: HEAD-запросом, а если сервер
+// HEAD не поддерживает - GET. Проверяются все адреса ссылки (основной, запасной, варианты A/B-теста
+// и адреса правил), неудача любого из них - неудачная проверка ссылки. Несколько неудачных проверок
+// подряд помечают ссылку как broken, о чем отправляется событие link.broken (и link.recovered при восстановлении)
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/lib/safedial"
+	"url-shortener/internal/storage"
+)
+
+const (
+	defaultBatchSize        = 100
+	defaultFailureThreshold = 2
+)
+
+// Queue - ссылки, которые пора проверить
+type Queue interface {
+	DueHealthChecks(before time.Time, limit int) ([]storage.HealthTask, error)
+	SaveHealth(task storage.HealthTask, health storage.LinkHealth) error
+}
+
+// Prober проверяет страницу: код ответа или ошибка, если ответа не было
+type Prober interface {
+	Probe(ctx context.Context, rawURL string) (int, error)
+}
+
+// Options - параметры проверки
+type Options struct {
+	Interval         time.Duration // как часто проверять каждую ссылку
+	Concurrency      int           // сколько страниц проверять одновременно
+	HostDelay        time.Duration // минимальная пауза между запросами к одному хосту
+	FailureThreshold int           // после скольких неудачных проверок подряд ссылка считается broken
+	BatchSize        int           // сколько ссылок выбирать за раз
+}
+
+// Worker проверяет ссылки
+type Worker struct {
+	log    *slog.Logger
+	queue  Queue
+	prober Prober
+	opts   Options
+	hosts  *hostLimiter
+}
+
+// New создает Worker
+func New(log *slog.Logger, queue Queue, prober Prober, opts Options) *Worker {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+	if opts.FailureThreshold <= 0 {
+		opts.FailureThreshold = defaultFailureThreshold
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultBatchSize
+	}
+
+	return &Worker{
+		log:    log.With(slog.String("op", "jobs.healthcheck")),
+		queue:  queue,
+		prober: prober,
+		opts:   opts,
+		hosts:  newHostLimiter(opts.HostDelay),
+	}
+}
+
+// Run проверяет ссылки, подошедшие по времени, раз в pollInterval. Работает до отмены ctx.
+// pollInterval <= 0 - проверка отключена
+func (w *Worker) Run(ctx context.Context, pollInterval time.Duration) {
+	if pollInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Пока ссылки выбираются полными пачками, не ждем следующего тика
+		for {
+			if n := w.Once(ctx); n < w.opts.BatchSize || ctx.Err() != nil {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Once проверяет очередную пачку ссылок. Возвращает число проверенных
+func (w *Worker) Once(ctx context.Context) int {
+	tasks, err := w.queue.DueHealthChecks(time.Now().Add(-w.opts.Interval), w.opts.BatchSize)
+	if err != nil {
+		w.log.Error("failed to read links to check", sl.Err(err))
+
+		return 0
+	}
+
+	var (
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, w.opts.Concurrency)
+	)
+
+	for _, task := range tasks {
+		sem <- struct{}{}
+		wg.Add(1)
+
+		go func(task storage.HealthTask) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			w.check(ctx, task)
+		}(task)
+	}
+
+	wg.Wait()
+
+	return len(tasks)
+}
+
+func (w *Worker) check(ctx context.Context, task storage.HealthTask) {
+	target, code, err := w.probe(ctx, task)
+	if ctx.Err() != nil {
+		// сервис останавливается - ссылка проверится после перезапуска
+		return
+	}
+
+	now := time.Now()
+	health := storage.LinkHealth{Status: storage.HealthOK, StatusCode: code, CheckedAt: &now}
+
+	if err != nil || !healthy(code) {
+		health.Failures = task.Failures + 1
+		health.Error = reason(code, err)
+		if target != task.URL {
+			health.Error = target + ": " + health.Error
+		}
+		health.Status = storage.HealthFailing
+		if health.Failures >= w.opts.FailureThreshold {
+			health.Status = storage.HealthBroken
+		}
+
+		log := w.log.With(slog.Int64("link_id", task.LinkID), slog.Int("failures", health.Failures))
+		if err != nil {
+			log.Info("link target is unreachable", sl.Err(err))
+		} else {
+			log.Info("link target responded with error status", slog.Int("status", code))
+		}
+	}
+
+	if err := w.queue.SaveHealth(task, health); err != nil {
+		w.log.Error("failed to save link health", slog.Int64("link_id", task.LinkID), sl.Err(err))
+	}
+}
+
+// probe проверяет адреса ссылки по очереди до первой неудачи и возвращает ее адрес, код ответа и ошибку.
+// Если все адреса доступны - основной адрес и его код ответа
+func (w *Worker) probe(ctx context.Context, task storage.HealthTask) (string, int, error) {
+	var mainCode int
+
+	for i, target := range append([]string{task.URL}, task.Targets...) {
+		if err := w.hosts.wait(ctx, hostOf(target)); err != nil {
+			return target, 0, err
+		}
+
+		code, err := w.prober.Probe(ctx, target)
+		if err != nil || !healthy(code) {
+			return target, code, err
+		}
+		if i == 0 {
+			mainCode = code
+		}
+	}
+
+	return task.URL, mainCode, nil
+}
+
+// healthy - страница считается доступной. 401 и 403 - страница существует, но закрыта
+// для анонимного запроса (или для ботов): пользователь по ссылке ее, скорее всего, откроет
+func healthy(code int) bool {
+	return code < 400 || code == 401 || code == 403
+}
+
+// reason - причина неудачи для пользователя. Подробности ошибки (адреса, внутренние сети) остаются в логе
+func reason(code int, err error) string {
+	var netErr net.Error
+
+	switch {
+	case err == nil:
+		return "the page responded with status " + strconv.Itoa(code)
+	case errors.Is(err, safedial.ErrForbiddenAddress):
+		return "the page address is not allowed"
+	case errors.Is(err, ErrTooManyRedirects):
+		return "the page redirects too many times"
+	case errors.Is(err, ErrUnsupportedScheme):
+		return "only http and https pages are checked"
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return "the page did not respond in time"
+	default:
+		return "the page could not be reached"
+	}
+}
+
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+
+	return u.Hostname()
+}
+
+// hostLimiter выдерживает паузу между запросами к одному хосту
+type hostLimiter struct {
+	delay time.Duration
+	mu    sync.Mutex
+	next  map[string]time.Time // когда хост можно запросить следующий раз
+}
+
+func newHostLimiter(delay time.Duration) *hostLimiter {
+	return &hostLimiter{delay: delay, next: make(map[string]time.Time)}
+}
+
+// wait резервирует очередной слот хоста и ждет его наступления
+func (l *hostLimiter) wait(ctx context.Context, host string) error {
+	if l.delay <= 0 || host == "" {
+		return ctx.Err()
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	slot := l.next[host]
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next[host] = slot.Add(l.delay)
+
+	// прошедшие слоты больше не нужны
+	for h, t := range l.next {
+		if t.Before(now) {
+			delete(l.next, h)
+		}
+	}
+	l.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(slot))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
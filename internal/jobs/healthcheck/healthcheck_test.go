@@ -0,0 +1,214 @@
+package healthcheck_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/jobs/healthcheck"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/storage"
+	"url-shortener/internal/storage/sqlite"
+)
+
+func TestOnce(t *testing.T) {
+	store, err := sqlite.NewStorage(filepath.Join(t.TempDir(), "storage.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	store.SetOutboxEvents([]string{storage.EventLinkBroken, storage.EventLinkRecovered})
+
+	var pageUp atomic.Bool
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/private", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+	mux.HandleFunc("/get-only", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+	mux.HandleFunc("/flaky", func(w http.ResponseWriter, r *http.Request) {
+		if !pageUp.Load() {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	site := httptest.NewServer(mux)
+	t.Cleanup(site.Close)
+
+	for alias, path := range map[string]string{"ok": "/ok", "private": "/private", "get-only": "/get-only", "flaky": "/flaky"} {
+		_, err := store.SaveLink(storage.Link{Alias: alias, URL: site.URL + path}, storage.Actor{})
+		require.NoError(t, err)
+	}
+
+	prober := healthcheck.NewProber(healthcheck.ProberOptions{Timeout: time.Second, AllowPrivate: true})
+	// Interval < 0: каждая ссылка подходит для проверки при каждом вызове Once
+	worker := healthcheck.New(slogdiscard.NewDiscardLogger(), store, prober, healthcheck.Options{
+		Interval: -time.Hour, Concurrency: 2, FailureThreshold: 2, BatchSize: 10,
+	})
+
+	link, err := store.GetLink("", "flaky")
+	require.NoError(t, err)
+	require.Nil(t, link.Health)
+
+	require.Equal(t, 4, worker.Once(context.Background()))
+
+	for _, alias := range []string{"ok", "private", "get-only"} {
+		link, err := store.GetLink("", alias)
+		require.NoError(t, err)
+		require.NotNil(t, link.Health, alias)
+		require.Equal(t, storage.HealthOK, link.Health.Status, alias)
+		require.NotNil(t, link.Health.CheckedAt)
+	}
+
+	link, err = store.GetLink("", "flaky")
+	require.NoError(t, err)
+	require.NotNil(t, link.Health)
+	require.Equal(t, storage.HealthFailing, link.Health.Status)
+	require.Equal(t, http.StatusNotFound, link.Health.StatusCode)
+	require.Equal(t, 1, link.Health.Failures)
+	require.Equal(t, "the page responded with status 404", link.Health.Error)
+
+	// вторая неудача подряд - ссылка сломана
+	require.Equal(t, 4, worker.Once(context.Background()))
+
+	broken, err := store.ListLinks(storage.ListFilter{Health: storage.HealthBroken})
+	require.NoError(t, err)
+	require.Len(t, broken, 1)
+	require.Equal(t, "flaky", broken[0].Alias)
+	require.Equal(t, 2, broken[0].Health.Failures)
+
+	// страница снова открывается
+	pageUp.Store(true)
+	require.Equal(t, 4, worker.Once(context.Background()))
+
+	link, err = store.GetLink("", "flaky")
+	require.NoError(t, err)
+	require.NotNil(t, link.Health)
+	require.Equal(t, storage.HealthOK, link.Health.Status)
+	require.Zero(t, link.Health.Failures)
+
+	messages, err := store.PendingOutbox(10)
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	require.Equal(t, storage.EventLinkBroken, messages[0].EventType)
+	require.Equal(t, storage.EventLinkRecovered, messages[1].EventType)
+}
+
+func TestOnce_NotDue(t *testing.T) {
+	store, err := sqlite.NewStorage(filepath.Join(t.TempDir(), "storage.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+
+	var requests atomic.Int32
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	t.Cleanup(site.Close)
+
+	_, err = store.SaveLink(storage.Link{Alias: "page", URL: site.URL}, storage.Actor{})
+	require.NoError(t, err)
+
+	prober := healthcheck.NewProber(healthcheck.ProberOptions{Timeout: time.Second, AllowPrivate: true})
+	worker := healthcheck.New(slogdiscard.NewDiscardLogger(), store, prober, healthcheck.Options{Interval: time.Hour})
+
+	require.Equal(t, 1, worker.Once(context.Background()))
+	require.Zero(t, worker.Once(context.Background()))
+	require.EqualValues(t, 1, requests.Load())
+
+	// новый адрес перехода проверяется сразу
+	link, err := store.GetLink("", "page")
+	require.NoError(t, err)
+	link.URL = site.URL + "/moved"
+	require.NoError(t, store.UpdateLink(link, storage.Actor{}))
+
+	require.Equal(t, 1, worker.Once(context.Background()))
+	require.EqualValues(t, 2, requests.Load())
+}
+
+func TestOnce_AllDestinations(t *testing.T) {
+	store, err := sqlite.NewStorage(filepath.Join(t.TempDir(), "storage.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+
+	var requests atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) { requests.Add(1) })
+	mux.HandleFunc("/gone", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	})
+	site := httptest.NewServer(mux)
+	t.Cleanup(site.Close)
+
+	_, err = store.SaveLink(storage.Link{
+		Alias: "split", URL: site.URL + "/ok",
+		Targets: []storage.Target{{URL: site.URL + "/ok", Weight: 1}, {URL: site.URL + "/gone", Weight: 1}},
+	}, storage.Actor{})
+	require.NoError(t, err)
+	_, err = store.SaveLink(storage.Link{Alias: "rules", URL: site.URL + "/ok"}, storage.Actor{})
+	require.NoError(t, err)
+
+	prober := healthcheck.NewProber(healthcheck.ProberOptions{Timeout: time.Second, AllowPrivate: true})
+	worker := healthcheck.New(slogdiscard.NewDiscardLogger(), store, prober, healthcheck.Options{Interval: time.Hour})
+
+	require.Equal(t, 2, worker.Once(context.Background()))
+	// основной адрес варианта A/B-теста не запрашивается повторно
+	require.EqualValues(t, 3, requests.Load())
+
+	// недоступный вариант A/B-теста - неудачная проверка ссылки
+	link, err := store.GetLink("", "split")
+	require.NoError(t, err)
+	require.NotNil(t, link.Health)
+	require.Equal(t, storage.HealthFailing, link.Health.Status)
+	require.Equal(t, http.StatusNotFound, link.Health.StatusCode)
+	require.Equal(t, site.URL+"/gone: the page responded with status 404", link.Health.Error)
+
+	link, err = store.GetLink("", "rules")
+	require.NoError(t, err)
+	require.Equal(t, storage.HealthOK, link.Health.Status)
+
+	// новые адреса правил проверяются сразу
+	require.NoError(t, store.SetRules("", "rules", []storage.Rule{{Platform: "ios", TargetURL: site.URL + "/gone"}}, storage.Actor{}))
+	require.Equal(t, 1, worker.Once(context.Background()))
+
+	link, err = store.GetLink("", "rules")
+	require.NoError(t, err)
+	require.Equal(t, storage.HealthFailing, link.Health.Status)
+	require.Equal(t, site.URL+"/gone: the page responded with status 404", link.Health.Error)
+
+	// новый запасной адрес тоже
+	link.FallbackURL = site.URL + "/ok?fallback"
+	require.NoError(t, store.UpdateLink(link, storage.Actor{}))
+
+	tasks, err := store.DueHealthChecks(time.Now().Add(-time.Hour), 10)
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	require.Equal(t, []string{site.URL + "/ok?fallback", site.URL + "/gone"}, tasks[0].Targets)
+
+	// результат проверки прежних адресов не сохраняется
+	stale := tasks[0]
+	stale.Targets = []string{site.URL + "/gone"}
+	now := time.Now()
+	require.NoError(t, store.SaveHealth(stale, storage.LinkHealth{Status: storage.HealthOK, CheckedAt: &now}))
+
+	link, err = store.GetLink("", "rules")
+	require.NoError(t, err)
+	require.Nil(t, link.Health)
+}
+
+func TestProbe_ForbiddenAddress(t *testing.T) {
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(site.Close)
+
+	prober := healthcheck.NewProber(healthcheck.ProberOptions{Timeout: time.Second})
+
+	_, err := prober.Probe(context.Background(), site.URL)
+	require.Error(t, err)
+}
@@ -0,0 +1,121 @@
+// internal/jobs/healthcheck/prober.go
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"url-shortener/internal/lib/safedial"
+)
+
+const maxRedirects = 5
+
+var (
+	ErrUnsupportedScheme = errors.New("only http and https addresses are supported")
+	ErrTooManyRedirects  = errors.New("too many redirects")
+)
+
+// ProberOptions - ограничения запроса страницы
+type ProberOptions struct {
+	Timeout time.Duration // на весь запрос, включая редиректы
+	// Разрешить адреса внутренних сетей (loopback, частные сети). Только для тестов и закрытых инсталляций
+	AllowPrivate bool
+	UserAgent    string
+}
+
+// HTTPProber проверяет страницы HTTP-запросами
+type HTTPProber struct {
+	client    *http.Client
+	userAgent string
+}
+
+// NewProber создает HTTPProber
+func NewProber(opts ProberOptions) *HTTPProber {
+	dialer := &net.Dialer{Timeout: opts.Timeout}
+	if !opts.AllowPrivate {
+		dialer.Control = safedial.Control
+	}
+
+	transport := &http.Transport{
+		Proxy:                 nil, // через прокси проверка адресов не работала бы
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   opts.Timeout,
+		ResponseHeaderTimeout: opts.Timeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       30 * time.Second,
+	}
+
+	return &HTTPProber{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   opts.Timeout,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= maxRedirects {
+					return ErrTooManyRedirects
+				}
+
+				return checkScheme(req.URL)
+			},
+		},
+		userAgent: opts.UserAgent,
+	}
+}
+
+// Probe запрашивает rawURL методом HEAD, а если сервер его не поддерживает - GET.
+// Возвращает код ответа после редиректов. Тело ответа не читается
+func (p *HTTPProber) Probe(ctx context.Context, rawURL string) (int, error) {
+	const op = "jobs.healthcheck.Probe"
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := checkScheme(u); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	code, err := p.do(ctx, http.MethodHead, u.String())
+	if err == nil && (code == http.StatusMethodNotAllowed || code == http.StatusNotImplemented) {
+		code, err = p.do(ctx, http.MethodGet, u.String())
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return code, nil
+}
+
+func (p *HTTPProber) do(ctx context.Context, method string, rawURL string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return 0, err
+	}
+	if p.userAgent != "" {
+		req.Header.Set("User-Agent", p.userAgent)
+	}
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+
+	// немного дочитываем тело, чтобы соединение можно было переиспользовать
+	_, _ = io.CopyN(io.Discard, res.Body, 4096)
+	_ = res.Body.Close()
+
+	return res.StatusCode, nil
+}
+
+func checkScheme(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return ErrUnsupportedScheme
+	}
+
+	return nil
+}
@@ -0,0 +1,182 @@
+// internal/jobs/preview/preview.go
+
+// Package preview - фоновое получение метаданных страниц перехода (заголовок, описание, картинка, иконка).
+// Очередь - ссылки со статусом pending в БД: новые ссылки, ссылки со смененным адресом
+// и ссылки, не обработанные до перезапуска сервиса
+package preview
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/lib/pagemeta"
+	"url-shortener/internal/lib/safedial"
+	"url-shortener/internal/storage"
+)
+
+const defaultBatchSize = 20
+
+// Queue - ссылки, ожидающие метаданных
+type Queue interface {
+	PendingPreviews(limit int) ([]storage.PreviewTask, error)
+	SavePreview(linkID int64, url string, preview storage.LinkPreview) error
+}
+
+// MetaFetcher получает метаданные страницы
+type MetaFetcher interface {
+	Fetch(ctx context.Context, rawURL string) (pagemeta.Meta, error)
+}
+
+// Options - параметры обработки очереди
+type Options struct {
+	Concurrency int // сколько страниц запрашивать одновременно
+	BatchSize   int // сколько ссылок выбирать из очереди за раз
+}
+
+// Worker обрабатывает очередь
+type Worker struct {
+	log     *slog.Logger
+	queue   Queue
+	fetcher MetaFetcher
+	opts    Options
+	wake    chan struct{}
+}
+
+// New создает Worker
+func New(log *slog.Logger, queue Queue, fetcher MetaFetcher, opts Options) *Worker {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultBatchSize
+	}
+
+	return &Worker{
+		log:     log.With(slog.String("op", "jobs.preview")),
+		queue:   queue,
+		fetcher: fetcher,
+		opts:    opts,
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+// Enqueue сообщает о новой ссылке: очередь обрабатывается сразу, не дожидаясь следующего тика.
+// Ссылка уже в очереди (статус pending), поэтому linkID нужен только для лога. Не блокируется
+func (w *Worker) Enqueue(linkID int64) {
+	select {
+	case w.wake <- struct{}{}:
+		w.log.Debug("preview requested", slog.Int64("link_id", linkID))
+	default:
+		// обработка уже запрошена - ссылка попадет в нее
+	}
+}
+
+// Run обрабатывает очередь раз в interval и по Enqueue. Работает до отмены ctx
+func (w *Worker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		// Пока очередь выбирается полными пачками, не ждем следующего тика
+		for {
+			if n := w.Once(ctx); n < w.opts.BatchSize || ctx.Err() != nil {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-w.wake:
+		}
+	}
+}
+
+// Once получает метаданные для очередной пачки ссылок. Возвращает число обработанных
+func (w *Worker) Once(ctx context.Context) int {
+	tasks, err := w.queue.PendingPreviews(w.opts.BatchSize)
+	if err != nil {
+		w.log.Error("failed to read preview queue", sl.Err(err))
+
+		return 0
+	}
+
+	var (
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, w.opts.Concurrency)
+	)
+
+	for _, task := range tasks {
+		sem <- struct{}{}
+		wg.Add(1)
+
+		go func(task storage.PreviewTask) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			w.process(ctx, task)
+		}(task)
+	}
+
+	wg.Wait()
+
+	return len(tasks)
+}
+
+func (w *Worker) process(ctx context.Context, task storage.PreviewTask) {
+	now := time.Now()
+	preview := storage.LinkPreview{Status: storage.PreviewOK, FetchedAt: &now}
+
+	meta, err := w.fetcher.Fetch(ctx, task.URL)
+	switch {
+	case ctx.Err() != nil:
+		// сервис останавливается - ссылка останется в очереди
+		return
+	case err != nil:
+		w.log.Info("failed to fetch page metadata", slog.Int64("link_id", task.LinkID), sl.Err(err))
+
+		preview.Status = storage.PreviewFailed
+		preview.Error = reason(err)
+	default:
+		preview.Title = meta.Title
+		preview.Description = meta.Description
+		preview.Image = meta.Image
+		preview.Favicon = meta.Favicon
+	}
+
+	if err := w.queue.SavePreview(task.LinkID, task.URL, preview); err != nil {
+		w.log.Error("failed to save page metadata", slog.Int64("link_id", task.LinkID), sl.Err(err))
+	}
+}
+
+// reason - причина неудачи для пользователя. Подробности ошибки (адреса, внутренние сети) остаются в логе
+func reason(err error) string {
+	var (
+		statusErr *pagemeta.StatusError
+		netErr    net.Error
+	)
+
+	switch {
+	case errors.As(err, &statusErr):
+		return "the page responded with status " + strconv.Itoa(statusErr.Code)
+	case errors.Is(err, safedial.ErrForbiddenAddress):
+		return "the page address is not allowed"
+	case errors.Is(err, pagemeta.ErrNotHTML):
+		return "the page is not an HTML document"
+	case errors.Is(err, pagemeta.ErrUnsupportedScheme):
+		return "only http and https pages are supported"
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return "the page did not respond in time"
+	default:
+		return "the page could not be fetched"
+	}
+}
@@ -0,0 +1,120 @@
+package preview_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/jobs/preview"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/lib/pagemeta"
+	"url-shortener/internal/storage"
+	"url-shortener/internal/storage/sqlite"
+)
+
+func setup(t *testing.T) (*sqlite.Storage, *preview.Worker, *httptest.Server) {
+	t.Helper()
+
+	store, err := sqlite.NewStorage(filepath.Join(t.TempDir(), "storage.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/article", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><head><title>Article</title>
+			<meta name="description" content="About links">
+			<link rel="icon" href="/icon.svg"></head></html>`))
+	})
+	mux.HandleFunc("/news", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><head><title>News</title></head></html>`))
+	})
+	site := httptest.NewServer(mux)
+	t.Cleanup(site.Close)
+
+	fetcher := pagemeta.New(pagemeta.Options{Timeout: time.Second, MaxBytes: 64 << 10, AllowPrivate: true})
+	worker := preview.New(slogdiscard.NewDiscardLogger(), store, fetcher, preview.Options{Concurrency: 2, BatchSize: 10})
+
+	return store, worker, site
+}
+
+func TestOnce(t *testing.T) {
+	store, worker, site := setup(t)
+
+	_, err := store.SaveLink(storage.Link{Alias: "article", URL: site.URL + "/article"}, storage.Actor{})
+	require.NoError(t, err)
+	_, err = store.SaveLink(storage.Link{Alias: "gone", URL: site.URL + "/gone"}, storage.Actor{})
+	require.NoError(t, err)
+
+	link, err := store.GetLink("", "article")
+	require.NoError(t, err)
+	require.Equal(t, storage.PreviewPending, link.Preview.Status)
+
+	require.Equal(t, 2, worker.Once(context.Background()))
+	require.Zero(t, worker.Once(context.Background()))
+
+	link, err = store.GetLink("", "article")
+	require.NoError(t, err)
+	require.Equal(t, storage.PreviewOK, link.Preview.Status)
+	require.Equal(t, "Article", link.Preview.Title)
+	require.Equal(t, "About links", link.Preview.Description)
+	require.Equal(t, site.URL+"/icon.svg", link.Preview.Favicon)
+	require.NotNil(t, link.Preview.FetchedAt)
+
+	link, err = store.GetLink("", "gone")
+	require.NoError(t, err)
+	require.Equal(t, storage.PreviewFailed, link.Preview.Status)
+	require.Equal(t, "the page responded with status 404", link.Preview.Error)
+
+	// новый адрес перехода - метаданные запрашиваются заново
+	link.URL = site.URL + "/news"
+	require.NoError(t, store.UpdateLink(link, storage.Actor{}))
+
+	link, err = store.GetLink("", "gone")
+	require.NoError(t, err)
+	require.Equal(t, storage.PreviewPending, link.Preview.Status)
+
+	// метаданные старого адреса, полученные с опозданием, не сохраняются
+	require.NoError(t, store.SavePreview(link.ID, site.URL+"/gone", storage.LinkPreview{Status: storage.PreviewFailed}))
+
+	require.Equal(t, 1, worker.Once(context.Background()))
+
+	link, err = store.GetLink("", "gone")
+	require.NoError(t, err)
+	require.Equal(t, storage.PreviewOK, link.Preview.Status)
+	require.Equal(t, "News", link.Preview.Title)
+	require.Empty(t, link.Preview.Error)
+}
+
+func TestEnqueue(t *testing.T) {
+	store, worker, site := setup(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		worker.Run(ctx, time.Hour)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	id, err := store.SaveLink(storage.Link{Alias: "article", URL: site.URL + "/article"}, storage.Actor{})
+	require.NoError(t, err)
+
+	// без Enqueue ссылка ждала бы следующего тика
+	worker.Enqueue(id)
+
+	require.Eventually(t, func() bool {
+		link, err := store.GetLink("", "article")
+		require.NoError(t, err)
+		return link.Preview.Status == storage.PreviewOK
+	}, 2*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,61 @@
+// internal/jobs/purge/purge.go
+
+// Package purge - фоновая очистка удаленных ссылок по истечении срока хранения.
+// До очистки удаленную ссылку можно восстановить, после - ее алиас снова свободен
+package purge
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"url-shortener/internal/lib/logger/sl"
+)
+
+// Purger окончательно удаляет ссылки, удаленные раньше before
+type Purger interface {
+	PurgeDeleted(before time.Time) (int64, error)
+}
+
+// Run раз в interval очищает ссылки, удаленные больше retention назад.
+// Первая очистка выполняется сразу. Работает до отмены ctx.
+// retention <= 0 - удаленные ссылки хранятся бессрочно, Run сразу возвращается
+func Run(ctx context.Context, log *slog.Logger, purger Purger, retention, interval time.Duration) {
+	const op = "jobs.purge.Run"
+
+	log = log.With(slog.String("op", op))
+
+	if retention <= 0 {
+		log.Info("retention is not set, deleted links are kept forever")
+
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		Once(log, purger, time.Now().Add(-retention))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Once очищает ссылки, удаленные раньше before. Ошибка только пишется в лог:
+// очистка повторится на следующем проходе
+func Once(log *slog.Logger, purger Purger, before time.Time) {
+	count, err := purger.PurgeDeleted(before)
+	if err != nil {
+		log.Error("failed to purge deleted links", sl.Err(err))
+
+		return
+	}
+
+	if count > 0 {
+		log.Info("deleted links purged", slog.Int64("count", count))
+	}
+}
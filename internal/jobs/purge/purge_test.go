@@ -0,0 +1,64 @@
+package purge_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/jobs/purge"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+)
+
+// fakePurger запоминает границы, с которыми вызывалась очистка
+type fakePurger struct {
+	mu     sync.Mutex
+	before []time.Time
+}
+
+func (p *fakePurger) PurgeDeleted(before time.Time) (int64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.before = append(p.before, before)
+
+	return 1, nil
+}
+
+func (p *fakePurger) calls() []time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]time.Time(nil), p.before...)
+}
+
+func TestRun(t *testing.T) {
+	purger := &fakePurger{}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		purge.Run(ctx, slogdiscard.NewDiscardLogger(), purger, time.Hour, 10*time.Millisecond)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool { return len(purger.calls()) >= 2 }, time.Second, 5*time.Millisecond)
+
+	cancel()
+	<-done
+
+	// Очищаются ссылки, удаленные больше часа назад
+	before := purger.calls()[0]
+	require.WithinDuration(t, time.Now().Add(-time.Hour), before, time.Second)
+}
+
+func TestRun_NoRetention(t *testing.T) {
+	purger := &fakePurger{}
+
+	// Без срока хранения Run не блокируется и ничего не удаляет
+	purge.Run(context.Background(), slogdiscard.NewDiscardLogger(), purger, 0, time.Millisecond)
+
+	require.Empty(t, purger.calls())
+}
@@ -0,0 +1,138 @@
+// internal/jobs/relay/relay.go
+
+// Package relay - публикация событий из outbox во внешнюю шину сообщений.
+// Событие отмечается опубликованным только после того, как шина его приняла, поэтому
+// после сбоя оно может быть опубликовано повторно (доставка "хотя бы один раз").
+// События публикуются в порядке записи: при ошибке публикация останавливается до следующего тика
+package relay
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/lib/publisher"
+	"url-shortener/internal/storage"
+)
+
+const defaultBatchSize = 100
+
+// Outbox - хранилище неопубликованных событий
+type Outbox interface {
+	PendingOutbox(limit int) ([]storage.OutboxMessage, error)
+	MarkOutboxPublished(id int64, at time.Time) error
+	RecordOutboxFailure(id int64, errText string) error
+	PurgeOutbox(before time.Time) (int64, error)
+}
+
+// Options - параметры публикации
+type Options struct {
+	Interval  time.Duration // как часто проверять outbox
+	BatchSize int           // сколько событий выбирать за раз
+	Retention time.Duration // сколько хранить опубликованные события, 0 - бессрочно
+}
+
+// Run раз в opts.Interval публикует накопившиеся события и удаляет опубликованные
+// больше opts.Retention назад. Работает до отмены ctx
+func Run(ctx context.Context, log *slog.Logger, outbox Outbox, pub publisher.EventPublisher, opts Options) {
+	const op = "jobs.relay.Run"
+
+	log = log.With(slog.String("op", op))
+
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultBatchSize
+	}
+
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		// Пока outbox выбирается полными пачками без ошибок, не ждем следующего тика
+		for {
+			n, ok := Once(ctx, log, outbox, pub, opts.BatchSize)
+			if !ok || n < opts.BatchSize || ctx.Err() != nil {
+				break
+			}
+		}
+
+		if opts.Retention > 0 {
+			purge(log, outbox, time.Now().Add(-opts.Retention))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Once публикует до batchSize событий по порядку. Возвращает число опубликованных
+// и false, если публикация остановлена ошибкой
+func Once(ctx context.Context, log *slog.Logger, outbox Outbox, pub publisher.EventPublisher, batchSize int) (int, bool) {
+	messages, err := outbox.PendingOutbox(batchSize)
+	if err != nil {
+		log.Error("failed to read outbox", sl.Err(err))
+
+		return 0, false
+	}
+
+	published := 0
+	for _, m := range messages {
+		if err := ctx.Err(); err != nil {
+			return published, false
+		}
+
+		err := pub.Publish(ctx, publisher.Message{
+			IdempotencyKey: m.EventID,
+			Type:           m.EventType,
+			PartitionKey:   strconv.FormatInt(m.LinkID, 10),
+			CreatedAt:      m.CreatedAt,
+			Payload:        json.RawMessage(m.Payload),
+		})
+		if err != nil {
+			log.Warn("failed to publish event",
+				slog.String("event_id", m.EventID),
+				slog.Int("attempt", m.Attempts+1),
+				sl.Err(err),
+			)
+
+			if err := outbox.RecordOutboxFailure(m.ID, err.Error()); err != nil {
+				log.Error("failed to record publish failure", slog.String("event_id", m.EventID), sl.Err(err))
+			}
+
+			return published, false
+		}
+
+		// если отметка не сохранится, событие будет опубликовано повторно
+		if err := outbox.MarkOutboxPublished(m.ID, time.Now()); err != nil {
+			log.Error("failed to mark event published", slog.String("event_id", m.EventID), sl.Err(err))
+
+			return published, false
+		}
+
+		published++
+	}
+
+	if published > 0 {
+		log.Debug("events published", slog.Int("count", published))
+	}
+
+	return published, true
+}
+
+func purge(log *slog.Logger, outbox Outbox, before time.Time) {
+	count, err := outbox.PurgeOutbox(before)
+	if err != nil {
+		log.Error("failed to purge outbox", sl.Err(err))
+
+		return
+	}
+
+	if count > 0 {
+		log.Info("published events purged", slog.Int64("count", count))
+	}
+}
@@ -0,0 +1,133 @@
+package relay_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/jobs/relay"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/lib/publisher"
+	"url-shortener/internal/storage"
+	"url-shortener/internal/storage/sqlite"
+)
+
+func newStorage(t *testing.T, events ...string) *sqlite.Storage {
+	t.Helper()
+
+	store, err := sqlite.NewStorage(filepath.Join(t.TempDir(), "storage.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+
+	store.SetOutboxEvents(events)
+
+	return store
+}
+
+func TestOnce_PublishesInOrder(t *testing.T) {
+	store := newStorage(t, storage.EventLinkCreated, storage.EventLinkDeleted)
+	log := slogdiscard.NewDiscardLogger()
+
+	id, err := store.SaveURL("https://example.com", "promo")
+	require.NoError(t, err)
+	// переходы в outbox не пишутся
+	require.NoError(t, store.RecordClick(id, 0))
+	require.NoError(t, store.DeleteURL("", "promo", storage.Actor{}))
+
+	pub := publisher.NewMemory()
+
+	n, ok := relay.Once(context.Background(), log, store, pub, 10)
+	require.True(t, ok)
+	require.Equal(t, 2, n)
+
+	messages := pub.Messages()
+	require.Len(t, messages, 2)
+	require.Equal(t, storage.EventLinkCreated, messages[0].Type)
+	require.Equal(t, storage.EventLinkDeleted, messages[1].Type)
+
+	for _, m := range messages {
+		require.Equal(t, strconv.FormatInt(id, 10), m.PartitionKey)
+
+		var event storage.Event
+		require.NoError(t, json.Unmarshal(m.Payload, &event))
+		require.Equal(t, m.IdempotencyKey, event.ID)
+		require.Equal(t, "promo", event.Link.Alias)
+	}
+
+	// опубликованное повторно не отправляется
+	n, ok = relay.Once(context.Background(), log, store, pub, 10)
+	require.True(t, ok)
+	require.Zero(t, n)
+}
+
+func TestOnce_RetriesAfterFailure(t *testing.T) {
+	store := newStorage(t, storage.EventTypes...)
+	log := slogdiscard.NewDiscardLogger()
+
+	_, err := store.SaveURL("https://example.com/a", "a")
+	require.NoError(t, err)
+	_, err = store.SaveURL("https://example.com/b", "b")
+	require.NoError(t, err)
+
+	pub := publisher.NewMemory()
+	pub.FailNext(errors.New("broker unavailable"))
+
+	// первое событие не опубликовано - второе не отправляется раньше него
+	n, ok := relay.Once(context.Background(), log, store, pub, 10)
+	require.False(t, ok)
+	require.Zero(t, n)
+
+	pending, err := store.PendingOutbox(10)
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	require.Equal(t, 1, pending[0].Attempts)
+	require.Equal(t, "broker unavailable", pending[0].LastError)
+
+	n, ok = relay.Once(context.Background(), log, store, pub, 10)
+	require.True(t, ok)
+	require.Equal(t, 2, n)
+	require.Equal(t, pending[0].EventID, pub.Messages()[0].IdempotencyKey)
+}
+
+func TestOutboxDisabled(t *testing.T) {
+	store := newStorage(t)
+
+	_, err := store.SaveURL("https://example.com", "promo")
+	require.NoError(t, err)
+
+	pending, err := store.PendingOutbox(10)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+}
+
+func TestRun(t *testing.T) {
+	store := newStorage(t, storage.EventTypes...)
+	pub := publisher.NewMemory()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		relay.Run(ctx, slogdiscard.NewDiscardLogger(), store, pub, relay.Options{
+			Interval:  10 * time.Millisecond,
+			BatchSize: 1,
+		})
+	}()
+
+	for _, alias := range []string{"a", "b", "c"} {
+		_, err := store.SaveURL("https://example.com/"+alias, alias)
+		require.NoError(t, err)
+	}
+
+	require.Eventually(t, func() bool { return len(pub.Messages()) == 3 }, time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-done
+}
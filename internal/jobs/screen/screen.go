@@ -0,0 +1,123 @@
+// internal/jobs/screen/screen.go
+
+// Package screen - периодическая проверка адресов перехода всех ссылок по списку опасных адресов.
+// Список локальный, поэтому проверяются все ссылки за проход: так срабатывают и новые записи
+// списка, и ссылки, созданные в обход проверки при создании (импорт, изменение адреса)
+package screen
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"url-shortener/internal/lib/logger/sl"
+	"url-shortener/internal/storage"
+)
+
+const defaultBatchSize = 500
+
+// Links - ссылки для проверки
+type Links interface {
+	ScreeningBatch(afterID int64, limit int) ([]storage.ScreenTask, error)
+	SetThreat(linkID int64, url string, threatType string) (bool, error)
+}
+
+// ThreatList - список опасных адресов, который перечитывается при изменении
+type ThreatList interface {
+	Reload() (bool, error)
+	Match(urls ...string) (string, bool)
+}
+
+// Run проверяет все ссылки раз в interval, перед каждым проходом перечитывая список.
+// Работает до отмены ctx. interval <= 0 - проверка отключена
+func Run(ctx context.Context, log *slog.Logger, links Links, list ThreatList, interval time.Duration, batchSize int) {
+	if interval <= 0 {
+		return
+	}
+
+	log = log.With(slog.String("op", "jobs.screen.Run"))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if reloaded, err := list.Reload(); err != nil {
+			log.Error("failed to reload threat list, keeping the previous one", sl.Err(err))
+		} else if reloaded {
+			log.Info("threat list reloaded")
+		}
+
+		checked, flagged, cleared := Once(ctx, log, links, list, batchSize)
+		log.Info("links screened",
+			slog.Int("checked", checked),
+			slog.Int("flagged", flagged),
+			slog.Int("cleared", cleared),
+		)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Once проверяет все ссылки по текущему списку. Возвращает число проверенных ссылок,
+// отключенных и снова включенных (адрес ушел из списка)
+func Once(ctx context.Context, log *slog.Logger, links Links, list ThreatList, batchSize int) (checked, flagged, cleared int) {
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+
+	var afterID int64
+	for ctx.Err() == nil {
+		tasks, err := links.ScreeningBatch(afterID, batchSize)
+		if err != nil {
+			log.Error("failed to read links to screen", sl.Err(err))
+
+			return
+		}
+
+		for _, task := range tasks {
+			afterID = task.LinkID
+			checked++
+
+			threat, match := list.Match(task.URLs...)
+			// ничего не меняется: отметка уже стоит или ее нет и не нужно
+			if match == (task.Threat != "") {
+				continue
+			}
+			// решение администратора не пересматривается автоматически
+			if !match && task.Threat != storage.ThreatFlagged {
+				continue
+			}
+
+			changed, err := links.SetThreat(task.LinkID, task.URL, threat)
+			if err != nil {
+				log.Error("failed to save screening result", slog.Int64("link_id", task.LinkID), sl.Err(err))
+
+				continue
+			}
+			if !changed {
+				continue
+			}
+
+			if match {
+				flagged++
+				log.Warn("link disabled: target is in the threat list",
+					slog.Int64("link_id", task.LinkID),
+					slog.String("threat", threat),
+				)
+			} else {
+				cleared++
+				log.Info("link enabled: target left the threat list", slog.Int64("link_id", task.LinkID))
+			}
+		}
+
+		if len(tasks) < batchSize {
+			break
+		}
+	}
+
+	return checked, flagged, cleared
+}
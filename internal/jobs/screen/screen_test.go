@@ -0,0 +1,99 @@
+package screen_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/jobs/screen"
+	"url-shortener/internal/lib/logger/handlers/slogdiscard"
+	"url-shortener/internal/lib/threatlist"
+	"url-shortener/internal/storage"
+	"url-shortener/internal/storage/sqlite"
+)
+
+func TestOnce(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := sqlite.NewStorage(filepath.Join(dir, "storage.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+
+	listPath := filepath.Join(dir, "threats.txt")
+	require.NoError(t, os.WriteFile(listPath, []byte("MALWARE evil.example/\nSOCIAL_ENGINEERING phish.example/login/\n"), 0o600))
+	list, err := threatlist.Open(listPath)
+	require.NoError(t, err)
+
+	links := []storage.Link{
+		{Alias: "clean", URL: "https://example.com/"},
+		{Alias: "malware", URL: "http://cdn.evil.example/setup.exe"},
+		{Alias: "ab", URL: "https://example.com/a", Targets: []storage.Target{
+			{URL: "https://example.com/a", Weight: 1},
+			{URL: "https://phish.example/login/?next=/", Weight: 1},
+		}},
+		{Alias: "reviewed", URL: "https://evil.example/"},
+	}
+	for _, link := range links {
+		_, err := store.SaveLink(link, storage.Actor{})
+		require.NoError(t, err)
+	}
+
+	log := slogdiscard.NewDiscardLogger()
+
+	checked, flagged, cleared := screen.Once(context.Background(), log, store, list, 2)
+	require.Equal(t, 4, checked)
+	require.Equal(t, 3, flagged)
+	require.Zero(t, cleared)
+
+	link, err := store.GetLink("", "clean")
+	require.NoError(t, err)
+	require.Nil(t, link.Threat)
+
+	link, err = store.GetLink("", "ab")
+	require.NoError(t, err)
+	require.True(t, link.Threat.Blocked())
+	require.Equal(t, threatlist.SocialEngineering, link.Threat.Type)
+
+	// администратор признает срабатывание ложным
+	_, err = store.ReviewThreat("", "reviewed", storage.ThreatCleared, storage.Actor{Name: "admin"})
+	require.NoError(t, err)
+
+	queue, err := store.ListLinks(storage.ListFilter{Threat: storage.ThreatFlagged})
+	require.NoError(t, err)
+	require.Len(t, queue, 2)
+
+	// повторный проход ничего не меняет, решение администратора остается в силе
+	_, flagged, cleared = screen.Once(context.Background(), log, store, list, 2)
+	require.Zero(t, flagged)
+	require.Zero(t, cleared)
+
+	link, err = store.GetLink("", "reviewed")
+	require.NoError(t, err)
+	require.Equal(t, storage.ThreatCleared, link.Threat.Status)
+	require.Equal(t, "admin", link.Threat.ReviewedBy)
+	require.False(t, link.Threat.Blocked())
+
+	// запись ушла из списка - ссылка снова работает
+	require.NoError(t, os.WriteFile(listPath, []byte("MALWARE evil.example/\n"), 0o600))
+	later := time.Now().Add(time.Second)
+	require.NoError(t, os.Chtimes(listPath, later, later))
+	_, err = list.Reload()
+	require.NoError(t, err)
+
+	_, flagged, cleared = screen.Once(context.Background(), log, store, list, 2)
+	require.Zero(t, flagged)
+	require.Equal(t, 1, cleared)
+
+	link, err = store.GetLink("", "ab")
+	require.NoError(t, err)
+	require.Nil(t, link.Threat)
+
+	link, err = store.GetLink("", "malware")
+	require.NoError(t, err)
+	require.Equal(t, storage.ThreatFlagged, link.Threat.Status)
+	require.NotNil(t, link.Threat.FlaggedAt)
+}
@@ -0,0 +1,190 @@
+// internal/lib/aliaspolicy/aliaspolicy.go
+
+// Package aliaspolicy - правила для алиасов коротких ссылок: допустимые символы и длина,
+// зарезервированные слова (в том числе первые сегменты маршрутов сервиса) и фильтр нецензурных слов.
+// Алиасы, зарезервированные администраторами, хранятся в БД и проверяются отдельно
+package aliaspolicy
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Текст ошибок предназначен для пользователя
+var (
+	ErrInvalidAlias = errors.New("invalid alias")
+	ErrReserved     = errors.New("alias is reserved")
+	ErrProfane      = errors.New("alias contains a disallowed word")
+)
+
+// Границы длины алиаса по умолчанию
+const (
+	DefaultMinLength = 3
+	DefaultMaxLength = 64
+)
+
+// DefaultReserved - слова, занятые текущими или будущими маршрутами сервиса и служебными путями.
+// Маршруты, зарегистрированные в роутере, добавляются через ReserveRoutes
+var DefaultReserved = []string{
+	"admin", "api", "auth", "url", "keys", "workspaces",
+	"metrics", "healthz", "health", "readyz", "livez", "status",
+	"login", "logout", "register", "static", "assets", "docs", "swagger", "debug", "www",
+}
+
+// Policy - правила для алиасов. Безопасна для одновременного использования
+type Policy struct {
+	minLength, maxLength int
+
+	mu        sync.RWMutex
+	reserved  map[string]bool // в нижнем регистре
+	profanity []string        // нормализованные (см. normalize)
+}
+
+// New создает политику с границами длины алиаса и словами DefaultReserved.
+// minLength, maxLength <= 0 - значения по умолчанию
+func New(minLength, maxLength int) *Policy {
+	if minLength <= 0 {
+		minLength = DefaultMinLength
+	}
+	if maxLength <= 0 {
+		maxLength = DefaultMaxLength
+	}
+
+	p := &Policy{
+		minLength: minLength,
+		maxLength: maxLength,
+		reserved:  make(map[string]bool),
+	}
+	p.Reserve(DefaultReserved...)
+
+	return p
+}
+
+// Reserve запрещает использовать слова как алиасы (без учета регистра)
+func (p *Policy) Reserve(words ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, w := range words {
+		if w = strings.ToLower(strings.TrimSpace(w)); w != "" {
+			p.reserved[w] = true
+		}
+	}
+}
+
+// ReserveRoutes резервирует первые сегменты всех маршрутов роутера (например, "url" для /url/{alias}),
+// чтобы алиас не перекрывал существующий путь. Параметры маршрута ({alias}) и "*" пропускаются
+func (p *Policy) ReserveRoutes(routes chi.Routes) error {
+	const op = "lib.aliaspolicy.ReserveRoutes"
+
+	err := chi.Walk(routes, func(_ string, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		segment, _, _ := strings.Cut(strings.TrimPrefix(route, "/"), "/")
+		if segment != "" && segment != "*" && !strings.Contains(segment, "{") {
+			p.Reserve(segment)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// SetProfanity задает список нецензурных слов. Алиас отклоняется, если содержит любое из них,
+// в том числе в другом регистре, с разделителями "-" и "_" или с цифрами вместо похожих букв
+func (p *Policy) SetProfanity(words []string) {
+	normalized := make([]string, 0, len(words))
+	for _, w := range words {
+		if w = normalize(w); w != "" {
+			normalized = append(normalized, w)
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.profanity = normalized
+}
+
+// Check проверяет алиас. Ошибки - ErrInvalidAlias (с пояснением), ErrReserved или ErrProfane
+func (p *Policy) Check(alias string) error {
+	if n := len(alias); n < p.minLength || n > p.maxLength {
+		return fmt.Errorf("%w: length must be between %d and %d characters", ErrInvalidAlias, p.minLength, p.maxLength)
+	}
+
+	for _, c := range alias {
+		if !isAllowed(c) {
+			return fmt.Errorf("%w: allowed characters are a-z, A-Z, 0-9, '-' and '_'", ErrInvalidAlias)
+		}
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.reserved[strings.ToLower(alias)] {
+		return ErrReserved
+	}
+
+	normalized := normalize(alias)
+	for _, w := range p.profanity {
+		if strings.Contains(normalized, w) {
+			return ErrProfane
+		}
+	}
+
+	return nil
+}
+
+func isAllowed(c rune) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_'
+}
+
+// Цифры, которыми часто заменяют похожие буквы
+var leet = strings.NewReplacer("0", "o", "1", "i", "3", "e", "4", "a", "5", "s", "7", "t", "@", "a", "$", "s")
+
+// normalize приводит слово к виду для поиска нецензурных слов: нижний регистр,
+// без разделителей, цифры заменены похожими буквами
+func normalize(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = strings.NewReplacer("-", "", "_", "").Replace(s)
+
+	return leet.Replace(s)
+}
+
+// LoadWords читает список слов из файла: по одному на строку,
+// пустые строки и строки, начинающиеся с '#', пропускаются
+func LoadWords(path string) ([]string, error) {
+	const op = "lib.aliaspolicy.LoadWords"
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var words []string
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		words = append(words, text)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return words, nil
+}
@@ -0,0 +1,81 @@
+package aliaspolicy_test
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/lib/aliaspolicy"
+)
+
+func TestPolicy_Check(t *testing.T) {
+	p := aliaspolicy.New(3, 10)
+	p.SetProfanity([]string{"darn"})
+
+	cases := []struct {
+		alias string
+		err   error
+	}{
+		{alias: "my-link_1"},
+		{alias: "ab", err: aliaspolicy.ErrInvalidAlias},
+		{alias: "abcdefghijk", err: aliaspolicy.ErrInvalidAlias},
+		{alias: "hello/x", err: aliaspolicy.ErrInvalidAlias},
+		{alias: "привет", err: aliaspolicy.ErrInvalidAlias},
+		{alias: "admin", err: aliaspolicy.ErrReserved},
+		{alias: "Metrics", err: aliaspolicy.ErrReserved},
+		{alias: "sodarnit", err: aliaspolicy.ErrProfane},
+		{alias: "D4-R_N", err: aliaspolicy.ErrProfane},
+	}
+
+	for _, tc := range cases {
+		err := p.Check(tc.alias)
+		if tc.err == nil {
+			require.NoError(t, err, tc.alias)
+		} else {
+			require.ErrorIs(t, err, tc.err, tc.alias)
+		}
+	}
+}
+
+func TestPolicy_ReserveRoutes(t *testing.T) {
+	noop := func(http.ResponseWriter, *http.Request) {}
+
+	router := chi.NewRouter()
+	router.Get("/{alias}", noop)
+	router.Route("/reports", func(r chi.Router) {
+		r.Get("/{id}", noop)
+	})
+	router.Get("/ping", noop)
+
+	p := aliaspolicy.New(0, 0)
+	require.NoError(t, p.ReserveRoutes(router))
+
+	require.ErrorIs(t, p.Check("reports"), aliaspolicy.ErrReserved)
+	require.ErrorIs(t, p.Check("ping"), aliaspolicy.ErrReserved)
+	require.NoError(t, p.Check("alias"))
+}
+
+func TestLoadWords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "words.txt")
+	require.NoError(t, os.WriteFile(path, []byte("# comment\nfoo\n\n  bar  \n"), 0o600))
+
+	words, err := aliaspolicy.LoadWords(path)
+	require.NoError(t, err)
+	require.Equal(t, []string{"foo", "bar"}, words)
+}
+
+func TestMatchMode_Key(t *testing.T) {
+	require.NotEqual(t, aliaspolicy.MatchExact.Key("Promo"), aliaspolicy.MatchExact.Key("promo"))
+
+	require.Equal(t, aliaspolicy.MatchCaseInsensitive.Key("Promo"), aliaspolicy.MatchCaseInsensitive.Key("pROMO"))
+	require.NotEqual(t, aliaspolicy.MatchCaseInsensitive.Key("pr0mo"), aliaspolicy.MatchCaseInsensitive.Key("promo"))
+
+	require.Equal(t, aliaspolicy.MatchConfusable.Key("PR0MO"), aliaspolicy.MatchConfusable.Key("promo"))
+	require.Equal(t, aliaspolicy.MatchConfusable.Key("sale1"), aliaspolicy.MatchConfusable.Key("SALEl"))
+	require.Equal(t, aliaspolicy.MatchConfusable.Key("Ilya"), aliaspolicy.MatchConfusable.Key("1lya"))
+	require.NotEqual(t, aliaspolicy.MatchConfusable.Key("promo"), aliaspolicy.MatchConfusable.Key("prom0-"))
+}
@@ -0,0 +1,42 @@
+// internal/lib/aliaspolicy/match.go
+package aliaspolicy
+
+import "strings"
+
+// MatchMode - как сравниваются алиасы при сохранении (поиск коллизий) и при редиректе
+type MatchMode string
+
+const (
+	// MatchExact - алиасы сравниваются как есть: Promo и promo - разные ссылки
+	MatchExact MatchMode = "exact"
+	// MatchCaseInsensitive - без учета регистра
+	MatchCaseInsensitive MatchMode = "case_insensitive"
+	// MatchConfusable - без учета регистра, а похожие символы считаются одинаковыми: O и 0, l, I и 1
+	MatchConfusable MatchMode = "confusable"
+)
+
+// ValidMatchMode - известен ли режим сравнения
+func ValidMatchMode(mode MatchMode) bool {
+	switch mode {
+	case MatchExact, MatchCaseInsensitive, MatchConfusable:
+		return true
+	default:
+		return false
+	}
+}
+
+// Похожие символы после приведения к нижнему регистру
+var confusables = strings.NewReplacer("0", "o", "1", "l", "i", "l")
+
+// Key возвращает ключ алиаса: алиасы с одинаковым ключом в режиме mode считаются одним и тем же.
+// Неизвестный режим работает как MatchExact
+func (mode MatchMode) Key(alias string) string {
+	switch mode {
+	case MatchCaseInsensitive:
+		return strings.ToLower(alias)
+	case MatchConfusable:
+		return confusables.Replace(strings.ToLower(alias))
+	default:
+		return alias
+	}
+}
@@ -0,0 +1,49 @@
+// internal/lib/apikey/apikey.go
+
+// Генерация и хеширование API-ключей.
+// Ключ имеет вид "us_<48 hex-символов>": по префиксу его легко отличить от JWT-токена
+// и найти в логах или репозиториях, если он утек
+package apikey
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"url-shortener/internal/lib/random"
+)
+
+const (
+	Prefix = "us_"
+
+	secretSize    = 24 // случайных байт в ключе
+	displayLength = len(Prefix) + 8
+)
+
+// Generate создает новый ключ. Возвращает сам ключ (показывается пользователю один раз),
+// его начало для отображения в списке ключей и хеш для хранения в БД
+func Generate() (key string, display string, hash string, err error) {
+	secret, err := random.NewSecureToken(secretSize)
+	if err != nil {
+		return "", "", "", fmt.Errorf("lib.apikey.Generate: %w", err)
+	}
+
+	key = Prefix + secret
+
+	return key, key[:displayLength], Hash(key), nil
+}
+
+// Hash - хеш ключа для поиска в БД.
+// Ключ содержит достаточно случайных байт, поэтому медленный хеш (bcrypt) не нужен:
+// SHA-256 позволяет искать ключ по индексу
+func Hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// Looks - похожа ли строка на API-ключ (а не на JWT-токен)
+func Looks(s string) bool {
+	return strings.HasPrefix(s, Prefix)
+}
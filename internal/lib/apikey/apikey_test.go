@@ -0,0 +1,30 @@
+package apikey_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/lib/apikey"
+)
+
+func TestGenerate(t *testing.T) {
+	key, display, hash, err := apikey.Generate()
+	require.NoError(t, err)
+
+	require.True(t, apikey.Looks(key))
+	require.Len(t, key, len(apikey.Prefix)+48)
+	require.Equal(t, key[:len(display)], display)
+	require.Equal(t, apikey.Hash(key), hash)
+	require.NotContains(t, hash, key)
+
+	other, _, otherHash, err := apikey.Generate()
+	require.NoError(t, err)
+	require.NotEqual(t, key, other)
+	require.NotEqual(t, hash, otherHash)
+}
+
+func TestLooks(t *testing.T) {
+	require.False(t, apikey.Looks("eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9.e30.sig"))
+	require.False(t, apikey.Looks(""))
+}
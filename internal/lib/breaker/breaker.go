@@ -0,0 +1,139 @@
+// internal/lib/breaker/breaker.go
+
+// Package breaker - простой автомат (circuit breaker) для вызовов внешних сервисов.
+// После threshold сбоев подряд автомат размыкается, и вызовы сразу получают ErrOpen,
+// не дожидаясь таймаута. Через cooldown пропускается один пробный вызов:
+// успех замыкает автомат, сбой снова размыкает его на cooldown
+package breaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrOpen = errors.New("circuit breaker is open")
+
+// State - состояние автомата
+type State int
+
+const (
+	Closed   State = iota // вызовы проходят
+	Open                  // вызовы сразу отклоняются
+	HalfOpen              // идет пробный вызов
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+type Breaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	state    State
+	failures int       // сбоев подряд
+	openedAt time.Time // когда автомат разомкнулся
+	onChange func(from, to State)
+}
+
+// New создает автомат. threshold <= 0 - автомат никогда не размыкается
+func New(threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{threshold: threshold, cooldown: cooldown, now: time.Now}
+}
+
+// OnStateChange задает функцию, которая вызывается при смене состояния (например, для логирования).
+// Функция вызывается под блокировкой автомата и не должна вызывать его методы
+func (b *Breaker) OnStateChange(f func(from, to State)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.onChange = f
+}
+
+// State возвращает текущее состояние автомата
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state
+}
+
+// Allow сообщает, можно ли выполнить вызов. Если можно, по завершении вызова
+// нужно сообщить результат через Done
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case Open:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return ErrOpen
+		}
+		// Пора проверить, не восстановился ли сервис
+		b.setState(HalfOpen)
+
+		return nil
+	case HalfOpen:
+		// Пробный вызов уже идет, остальные ждут его результата
+		return ErrOpen
+	default:
+		return nil
+	}
+}
+
+// Done сообщает результат вызова: failed - сбой сервиса (а не, например, неверные данные запроса)
+func (b *Breaker) Done(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !failed {
+		b.failures = 0
+		if b.state != Closed {
+			b.setState(Closed)
+		}
+
+		return
+	}
+
+	b.failures++
+	if b.state == HalfOpen || b.threshold > 0 && b.failures >= b.threshold {
+		b.openedAt = b.now()
+		if b.state != Open {
+			b.setState(Open)
+		}
+	}
+}
+
+// Do выполняет f, если автомат замкнут. isFailure решает, считать ли ошибку сбоем сервиса
+func (b *Breaker) Do(f func() error, isFailure func(error) bool) error {
+	if err := b.Allow(); err != nil {
+		return err
+	}
+
+	err := f()
+	b.Done(err != nil && isFailure(err))
+
+	return err
+}
+
+// setState меняет состояние. Вызывается под мьютексом
+func (b *Breaker) setState(to State) {
+	from := b.state
+	b.state = to
+
+	if b.onChange != nil {
+		b.onChange(from, to)
+	}
+}
@@ -0,0 +1,84 @@
+package breaker_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/lib/breaker"
+)
+
+var (
+	errDown    = errors.New("service is down")
+	errRequest = errors.New("bad request")
+)
+
+func isFailure(err error) bool { return errors.Is(err, errDown) }
+
+func TestBreaker(t *testing.T) {
+	const cooldown = 30 * time.Millisecond
+
+	b := breaker.New(2, cooldown)
+
+	var changes []string
+	b.OnStateChange(func(from, to breaker.State) {
+		changes = append(changes, from.String()+"->"+to.String())
+	})
+
+	// Ошибки запроса не размыкают автомат
+	for i := 0; i < 3; i++ {
+		require.ErrorIs(t, b.Do(func() error { return errRequest }, isFailure), errRequest)
+	}
+	require.Equal(t, breaker.Closed, b.State())
+
+	// Два сбоя подряд - автомат разомкнут, вызов не выполняется
+	require.ErrorIs(t, b.Do(func() error { return errDown }, isFailure), errDown)
+	require.ErrorIs(t, b.Do(func() error { return errDown }, isFailure), errDown)
+	require.Equal(t, breaker.Open, b.State())
+
+	called := false
+	err := b.Do(func() error { called = true; return nil }, isFailure)
+	require.ErrorIs(t, err, breaker.ErrOpen)
+	require.False(t, called)
+
+	// После cooldown пробный вызов со сбоем снова размыкает автомат
+	time.Sleep(cooldown)
+	require.ErrorIs(t, b.Do(func() error { return errDown }, isFailure), errDown)
+	require.Equal(t, breaker.Open, b.State())
+
+	// Успешный пробный вызов замыкает автомат
+	time.Sleep(cooldown)
+	require.NoError(t, b.Do(func() error { return nil }, isFailure))
+	require.Equal(t, breaker.Closed, b.State())
+
+	require.Equal(t, []string{
+		"closed->open",
+		"open->half-open", "half-open->open",
+		"open->half-open", "half-open->closed",
+	}, changes)
+}
+
+func TestBreaker_HalfOpenAllowsSingleProbe(t *testing.T) {
+	b := breaker.New(1, time.Millisecond)
+
+	b.Done(true)
+	time.Sleep(2 * time.Millisecond)
+
+	require.NoError(t, b.Allow())
+	require.ErrorIs(t, b.Allow(), breaker.ErrOpen)
+
+	b.Done(false)
+	require.NoError(t, b.Allow())
+}
+
+func TestBreaker_Disabled(t *testing.T) {
+	b := breaker.New(0, time.Second)
+
+	for i := 0; i < 10; i++ {
+		b.Done(true)
+	}
+
+	require.NoError(t, b.Allow())
+}
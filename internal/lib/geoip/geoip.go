@@ -0,0 +1,116 @@
+// internal/lib/geoip/geoip.go
+
+// Определение страны по IP-адресу с помощью локального файла базы.
+//
+// Формат файла - CSV из двух колонок: сеть в нотации CIDR и код страны ISO 3166-1 alpha-2.
+// Строки, начинающиеся с '#', пропускаются; первая строка после них может быть заголовком:
+//
+//	network,country
+//	1.0.0.0/24,AU
+//	2a02:6b8::/32,RU
+//
+// Сети не должны пересекаться (как в выгрузках GeoLite2).
+package geoip
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+	"sort"
+	"strings"
+)
+
+var ErrInvalidFormat = errors.New("invalid geoip database format")
+
+type network struct {
+	prefix  netip.Prefix
+	country string
+}
+
+// DB - база сетей, отсортированная по начальному адресу
+type DB struct {
+	networks []network
+}
+
+// Open загружает базу из файла
+func Open(path string) (*DB, error) {
+	const op = "lib.geoip.Open"
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var db DB
+
+	scanner := bufio.NewScanner(f)
+	line := 0
+	first := true
+	for scanner.Scan() {
+		line++
+
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		// заголовком может быть только первая строка после комментариев
+		header := first
+		first = false
+
+		fields := strings.Split(text, ",")
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("%s: %w: line %d", op, ErrInvalidFormat, line)
+		}
+
+		prefix, err := netip.ParsePrefix(strings.TrimSpace(fields[0]))
+		if err != nil {
+			if header {
+				continue
+			}
+			return nil, fmt.Errorf("%s: %w: line %d: %v", op, ErrInvalidFormat, line, err)
+		}
+
+		db.networks = append(db.networks, network{
+			prefix:  prefix.Masked(),
+			country: strings.ToUpper(strings.TrimSpace(fields[1])),
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	sort.Slice(db.networks, func(i, j int) bool {
+		return db.networks[i].prefix.Addr().Less(db.networks[j].prefix.Addr())
+	})
+
+	return &db, nil
+}
+
+// Country возвращает код страны для адреса или пустую строку, если страна неизвестна.
+// Для nil-базы всегда возвращает пустую строку - это позволяет работать без файла базы
+func (db *DB) Country(ip netip.Addr) string {
+	if db == nil || !ip.IsValid() {
+		return ""
+	}
+
+	ip = ip.Unmap()
+
+	// ищем последнюю сеть, которая начинается не позже адреса
+	i := sort.Search(len(db.networks), func(i int) bool {
+		return ip.Less(db.networks[i].prefix.Addr())
+	})
+	if i == 0 {
+		return ""
+	}
+
+	if n := db.networks[i-1]; n.prefix.Contains(ip) {
+		return n.country
+	}
+
+	return ""
+}
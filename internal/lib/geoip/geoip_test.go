@@ -0,0 +1,77 @@
+package geoip_test
+
+import (
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/lib/geoip"
+)
+
+// writeDB создает временный файл базы
+func writeDB(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "geoip.csv")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func TestDB_Country(t *testing.T) {
+	db, err := geoip.Open(writeDB(t, "# GeoLite2 country networks\n"+
+		"\n"+
+		"network,country\n"+
+		"1.0.0.0/24,au\n"+
+		"5.255.255.0/24,RU\n"+
+		"10.1.2.3/8,ZZ\n"+
+		"2a02:6b8::/32,RU\n"))
+	require.NoError(t, err)
+
+	cases := []struct {
+		ip      string
+		country string
+	}{
+		{ip: "1.0.0.1", country: "AU"},
+		{ip: "1.0.0.255", country: "AU"},
+		{ip: "1.0.1.0", country: ""},
+		{ip: "0.255.255.255", country: ""},
+		{ip: "5.255.255.5", country: "RU"},
+		// сеть с ненулевыми битами хоста приводится к началу сети
+		{ip: "10.0.0.1", country: "ZZ"},
+		{ip: "2a02:6b8::1", country: "RU"},
+		{ip: "2a03::1", country: ""},
+		// IPv4, записанный как IPv6
+		{ip: "::ffff:1.0.0.7", country: "AU"},
+	}
+
+	for _, tc := range cases {
+		require.Equal(t, tc.country, db.Country(netip.MustParseAddr(tc.ip)), tc.ip)
+	}
+
+	require.Empty(t, db.Country(netip.Addr{}))
+}
+
+func TestDB_CountryWithoutDB(t *testing.T) {
+	var db *geoip.DB
+	require.Empty(t, db.Country(netip.MustParseAddr("1.0.0.1")))
+}
+
+func TestOpen_InvalidFormat(t *testing.T) {
+	cases := []struct {
+		name    string
+		content string
+	}{
+		{name: "Missing country", content: "1.0.0.0/24\n"},
+		{name: "Invalid network after header", content: "network,country\n1.0.0.0/24,AU\nnot-a-network,RU\n"},
+		{name: "Second header", content: "# comment\nnetwork,country\nnetwork,country\n"},
+	}
+
+	for _, tc := range cases {
+		_, err := geoip.Open(writeDB(t, tc.content))
+		require.ErrorIs(t, err, geoip.ErrInvalidFormat, tc.name)
+	}
+}
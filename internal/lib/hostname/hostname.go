@@ -0,0 +1,20 @@
+// internal/lib/hostname/hostname.go
+package hostname
+
+import (
+	"net"
+	"strings"
+)
+
+// Normalize приводит имя хоста к виду, в котором домены хранятся в БД:
+// нижний регистр, без порта и без завершающей точки.
+// Подходит и для заголовка Host ("Example.com:8080" -> "example.com")
+func Normalize(host string) string {
+	host = strings.TrimSpace(host)
+
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
@@ -0,0 +1,195 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+)
+
+// JWKS - набор открытых ключей SSO из локального файла в формате JSON Web Key Set (RFC 7517).
+// Ключ для токена выбирается по kid из его заголовка. При ротации SSO добавляет в файл новый ключ:
+// встретив незнакомый kid, JWKS перечитывает файл, если тот изменился с последней загрузки
+type JWKS struct {
+	path string
+
+	mu      sync.RWMutex
+	keys    map[string]Key
+	modTime time.Time
+}
+
+// jwk - ключ в формате JWK. Поддерживаются RSA и Ed25519 (kty OKP)
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+	// OKP
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+}
+
+// LoadJWKS загружает набор ключей из файла
+func LoadJWKS(path string) (*JWKS, error) {
+	s := &JWKS{path: path}
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Reload перечитывает файл. При ошибке остаются ранее загруженные ключи
+func (s *JWKS) Reload() error {
+	const op = "lib.jwt.JWKS.Reload"
+
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.mu.Lock()
+	s.keys = keys
+	s.modTime = info.ModTime()
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Key возвращает ключ по kid. Пустой kid допустим, только если ключ в наборе один
+func (s *JWKS) Key(kid string) (Key, error) {
+	if key, ok := s.lookup(kid); ok {
+		return key, nil
+	}
+
+	// Возможно, ключи ротировали - перечитываем файл, но только если он изменился,
+	// чтобы токены с выдуманным kid не заставляли читать файл на каждый запрос
+	if info, err := os.Stat(s.path); err == nil && s.changed(info.ModTime()) {
+		if err := s.Reload(); err != nil {
+			return Key{}, fmt.Errorf("%w: %q: %w", ErrUnknownKey, kid, err)
+		}
+
+		if key, ok := s.lookup(kid); ok {
+			return key, nil
+		}
+	}
+
+	return Key{}, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+}
+
+func (s *JWKS) lookup(kid string) (Key, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, true
+		}
+	}
+
+	key, ok := s.keys[kid]
+	return key, ok
+}
+
+func (s *JWKS) changed(modTime time.Time) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return !modTime.Equal(s.modTime)
+}
+
+// parseJWKS разбирает набор ключей. Ключи не для подписи (use != sig) пропускаются
+func parseJWKS(data []byte) (map[string]Key, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("decode jwks: %w", err)
+	}
+
+	keys := make(map[string]Key, len(set.Keys))
+	for i, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		key, err := k.parse()
+		if err != nil {
+			return nil, fmt.Errorf("key %d (kid %q): %w", i, k.Kid, err)
+		}
+
+		if _, ok := keys[k.Kid]; ok {
+			return nil, fmt.Errorf("duplicate kid %q", k.Kid)
+		}
+		keys[k.Kid] = key
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("jwks has no signing keys")
+	}
+
+	return keys, nil
+}
+
+func (k jwk) parse() (Key, error) {
+	switch k.Kty {
+	case "RSA":
+		if k.Alg != "" && k.Alg != AlgRS256 && k.Alg != AlgRS384 && k.Alg != AlgRS512 {
+			return Key{}, fmt.Errorf("alg %q does not match RSA key", k.Alg)
+		}
+
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return Key{}, fmt.Errorf("decode n: %w", err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return Key{}, fmt.Errorf("decode e: %w", err)
+		}
+
+		exp := new(big.Int).SetBytes(e)
+		if len(n) == 0 || !exp.IsInt64() || exp.Int64() < 3 || exp.Int64() > 1<<31-1 {
+			return Key{}, errors.New("invalid RSA key")
+		}
+
+		return Key{Alg: k.Alg, Public: &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exp.Int64())}}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return Key{}, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		if k.Alg != "" && k.Alg != AlgEdDSA {
+			return Key{}, fmt.Errorf("alg %q does not match Ed25519 key", k.Alg)
+		}
+
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return Key{}, fmt.Errorf("decode x: %w", err)
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return Key{}, errors.New("invalid Ed25519 key size")
+		}
+
+		return Key{Alg: AlgEdDSA, Public: ed25519.PublicKey(x)}, nil
+	default:
+		return Key{}, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
@@ -1,79 +1,236 @@
 package jwt
 
 import (
+	"crypto"
+	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-type myClaims struct {
+// Ошибки проверки токена. Verify всегда возвращает одну из них (обернутой),
+// поэтому вызывающий код может различать причины через errors.Is
+var (
+	ErrMalformed    = errors.New("malformed token")
+	ErrAlgorithm    = errors.New("unexpected signing algorithm")
+	ErrUnknownKey   = errors.New("unknown signing key")
+	ErrSignature    = errors.New("invalid token signature")
+	ErrExpired      = errors.New("token is expired")
+	ErrNotYetValid  = errors.New("token is not valid yet")
+	ErrIssuer       = errors.New("invalid token issuer")
+	ErrAudience     = errors.New("invalid token audience")
+	ErrAppID        = errors.New("invalid token app_id")
+	ErrInvalidClaim = errors.New("invalid token claims")
+)
+
+// Алгоритмы подписи, которые умеет проверять Verifier
+const (
+	AlgHS256 = "HS256"
+	AlgHS384 = "HS384"
+	AlgHS512 = "HS512"
+	AlgRS256 = "RS256"
+	AlgRS384 = "RS384"
+	AlgRS512 = "RS512"
+	AlgEdDSA = "EdDSA"
+)
+
+// Claims - данные пользователя из токена SSO
+type Claims struct {
 	UID   int64
 	Email string
+	AppID int
 	Exp   time.Time
 }
 
-func Parse(tokenStr string, appSecret string) (*myClaims, error) {
-	const op = "internal.lib.jwt.Parse"
-	// Парсим и валидируем токен
-	tokenParsed, err := jwt.Parse(tokenStr, func(tokenStr *jwt.Token) (any, error) {
-		return []byte(appSecret), nil
-	})
-	// Если ключ окажется невалидным, мы получим соответствующую ошибку
-	if err != nil {
+// tokenClaims - содержимое токена. Типизированная структура вместо jwt.MapClaims:
+// поле неверного типа дает ошибку разбора, а не панику при приведении типа
+type tokenClaims struct {
+	jwt.RegisteredClaims
+	UID   int64  `json:"uid"`
+	Email string `json:"email"`
+	AppID int    `json:"app_id"`
+}
+
+// Key - открытый ключ подписи из JWKS
+type Key struct {
+	Alg    string // алгоритм, для которого предназначен ключ. Пусто - любой подходящий по типу ключа
+	Public crypto.PublicKey
+}
+
+// KeyProvider - источник открытых ключей для RS256/EdDSA (см. JWKS)
+type KeyProvider interface {
+	Key(kid string) (Key, error)
+}
+
+// Options - параметры проверки токенов
+type Options struct {
+	// Разрешенные алгоритмы подписи. По умолчанию только HS256.
+	// Алгоритм из заголовка токена не из этого списка - ошибка, даже если подпись верна
+	Algorithms []string
+	// Секрет для HS256/HS384/HS512
+	Secret string
+	// Ключи для RS256/RS384/RS512/EdDSA
+	Keys KeyProvider
+	// Ожидаемые iss и aud. Пусто - не проверяются
+	Issuer   string
+	Audience string
+	// Ожидаемый app_id. 0 - не проверяется
+	AppID int
+	// Допустимое расхождение часов с сервером SSO при проверке exp/nbf/iat
+	Leeway time.Duration
+}
 
-		return nil, fmt.Errorf("%s: %w", op, err)
+// Verifier проверяет подпись и содержимое JWT-токенов
+type Verifier struct {
+	opts   Options
+	parser *jwt.Parser
+}
+
+// NewVerifier создает Verifier и проверяет, что для каждого алгоритма есть ключи
+func NewVerifier(opts Options) (*Verifier, error) {
+	const op = "lib.jwt.NewVerifier"
+
+	if len(opts.Algorithms) == 0 {
+		opts.Algorithms = []string{AlgHS256}
 	}
 
-	// Преобразуем к типу jwt.MapClaims, в котором мы сохраняли данные
-	claims, ok := tokenParsed.Claims.(jwt.MapClaims)
-	if !ok {
-		return nil, fmt.Errorf("%s: %w", op, err)
+	for _, alg := range opts.Algorithms {
+		switch {
+		case isHMAC(alg):
+			if opts.Secret == "" {
+				return nil, fmt.Errorf("%s: algorithm %s requires a secret", op, alg)
+			}
+		case isAsymmetric(alg):
+			if opts.Keys == nil {
+				return nil, fmt.Errorf("%s: algorithm %s requires a key set", op, alg)
+			}
+		default:
+			return nil, fmt.Errorf("%s: unsupported algorithm %q", op, alg)
+		}
 	}
 
-	clms := myClaims{
-		UID:   int64(claims["uid"].(float64)),
-		Email: claims["email"].(string),
-		Exp:   time.Unix(int64(claims["exp"].(float64)), int64(0)),
+	parserOpts := []jwt.ParserOption{
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(opts.Leeway),
+	}
+	if opts.Issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(opts.Issuer))
+	}
+	if opts.Audience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(opts.Audience))
 	}
 
-	return &clms, nil
+	return &Verifier{opts: opts, parser: jwt.NewParser(parserOpts...)}, nil
 }
 
-/*
-func Parse(tokenStr string, appSecret string) (*jwt.MapClaims, error) {
-	const op = "internal.lib.jwt.Parse"
-	// Парсим и валидируем токен
-	tokenParsed, err := jwt.Parse(tokenStr, func(tokenStr *jwt.Token) (any, error) {
-		return []byte(appSecret), nil
-	})
-	// Если ключ окажется невалидным, мы получим соответствующую ошибку
+// NewHMACVerifier - Verifier для токенов HS256, подписанных секретом приложения
+func NewHMACVerifier(secret string) *Verifier {
+	v, err := NewVerifier(Options{Algorithms: []string{AlgHS256}, Secret: secret})
 	if err != nil {
+		// Пустой секрет: такой Verifier отклонит любой токен
+		return &Verifier{opts: Options{Algorithms: []string{AlgHS256}}, parser: jwt.NewParser()}
+	}
+
+	return v
+}
+
+// Verify проверяет токен и возвращает данные пользователя
+func (v *Verifier) Verify(tokenStr string) (*Claims, error) {
+	const op = "lib.jwt.Verify"
+
+	var claims tokenClaims
+	if _, err := v.parser.ParseWithClaims(tokenStr, &claims, v.key); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, classify(err))
+	}
 
-		return nil, fmt.Errorf("%s: %w", op, err)
+	if v.opts.AppID != 0 && claims.AppID != v.opts.AppID {
+		return nil, fmt.Errorf("%s: %w: got %d", op, ErrAppID, claims.AppID)
 	}
 
-	// Преобразуем к типу jwt.MapClaims, в котором мы сохраняли данные
-	claims, ok := tokenParsed.Claims.(jwt.MapClaims)
-	if !ok {
-		return nil, fmt.Errorf("%s: %w", op, err)
+	if claims.UID <= 0 {
+		return nil, fmt.Errorf("%s: %w: uid is missing", op, ErrInvalidClaim)
 	}
 
-	return &claims, nil
+	return &Claims{
+		UID:   claims.UID,
+		Email: claims.Email,
+		AppID: claims.AppID,
+		Exp:   claims.ExpiresAt.Time,
+	}, nil
 }
-*/
-/*
-func Parse(tokenStr string, appSecret string) (*jwt.Token, error) {
-	const op = "internal.lib.jwt.Parse"
-	// Парсим и валидируем токен
-	tokenParsed, err := jwt.Parse(tokenStr, func(tokenStr *jwt.Token) (any, error) {
-		return []byte(appSecret), nil
-	})
-	// Если ключ окажется невалидным, мы получим соответствующую ошибку
-	if err != nil {
 
+// key выбирает ключ проверки подписи. Алгоритм берется из заголовка токена,
+// поэтому он сверяется со списком разрешенных и с типом ключа -
+// иначе токен, подписанный открытым RSA-ключом как HMAC-секретом, прошел бы проверку
+func (v *Verifier) key(token *jwt.Token) (any, error) {
+	alg := token.Method.Alg()
+	if !slices.Contains(v.opts.Algorithms, alg) {
+		return nil, fmt.Errorf("%w: %s", ErrAlgorithm, alg)
+	}
+
+	if isHMAC(alg) {
+		if v.opts.Secret == "" {
+			return nil, ErrUnknownKey
+		}
+
+		return []byte(v.opts.Secret), nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, err := v.opts.Keys.Key(kid)
+	if err != nil {
 		return nil, err
 	}
-	return tokenParsed, nil
+
+	if key.Alg != "" && key.Alg != alg {
+		return nil, fmt.Errorf("%w: key %q is for %s, token uses %s", ErrAlgorithm, kid, key.Alg, alg)
+	}
+
+	return key.Public, nil
+}
+
+// classify приводит ошибку golang-jwt к одной из ошибок пакета
+func classify(err error) error {
+	// Ошибки из key уже типизированы
+	for _, own := range []error{ErrAlgorithm, ErrUnknownKey} {
+		if errors.Is(err, own) {
+			return err
+		}
+	}
+
+	mapping := []struct {
+		lib, own error
+	}{
+		{jwt.ErrTokenMalformed, ErrMalformed},
+		{jwt.ErrTokenSignatureInvalid, ErrSignature},
+		{jwt.ErrTokenUnverifiable, ErrSignature},
+		{jwt.ErrTokenExpired, ErrExpired},
+		{jwt.ErrTokenNotValidYet, ErrNotYetValid},
+		{jwt.ErrTokenUsedBeforeIssued, ErrNotYetValid},
+		{jwt.ErrTokenInvalidIssuer, ErrIssuer},
+		{jwt.ErrTokenInvalidAudience, ErrAudience},
+	}
+	for _, m := range mapping {
+		if errors.Is(err, m.lib) {
+			return fmt.Errorf("%w: %w", m.own, err)
+		}
+	}
+
+	return fmt.Errorf("%w: %w", ErrInvalidClaim, err)
+}
+
+func isHMAC(alg string) bool {
+	return alg == AlgHS256 || alg == AlgHS384 || alg == AlgHS512
+}
+
+func isAsymmetric(alg string) bool {
+	return alg == AlgRS256 || alg == AlgRS384 || alg == AlgRS512 || alg == AlgEdDSA
+}
+
+// Parse проверяет токен HS256, подписанный секретом приложения
+func Parse(tokenStr string, appSecret string) (*Claims, error) {
+	return NewHMACVerifier(appSecret).Verify(tokenStr)
 }
-*/
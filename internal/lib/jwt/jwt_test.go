@@ -0,0 +1,276 @@
+package jwt_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/lib/jwt"
+)
+
+const secret = "test-secret"
+
+// sign подписывает токен с данными пользователя SSO
+func sign(t *testing.T, method gojwt.SigningMethod, key any, kid string, claims gojwt.MapClaims) string {
+	t.Helper()
+
+	token := gojwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+
+	s, err := token.SignedString(key)
+	require.NoError(t, err)
+
+	return s
+}
+
+// validClaims - данные токена, который проходит все проверки
+func validClaims() gojwt.MapClaims {
+	return gojwt.MapClaims{
+		"uid":    42,
+		"email":  "user@example.com",
+		"app_id": 1,
+		"iss":    "sso",
+		"aud":    "url-shortener",
+		"exp":    time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func with(claims gojwt.MapClaims, key string, value any) gojwt.MapClaims {
+	claims[key] = value
+	return claims
+}
+
+func TestVerifier_HMAC(t *testing.T) {
+	v, err := jwt.NewVerifier(jwt.Options{
+		Algorithms: []string{jwt.AlgHS256},
+		Secret:     secret,
+		Issuer:     "sso",
+		Audience:   "url-shortener",
+		AppID:      1,
+		Leeway:     time.Minute,
+	})
+	require.NoError(t, err)
+
+	cases := []struct {
+		name  string
+		token string
+		err   error
+	}{
+		{
+			name:  "Valid",
+			token: sign(t, gojwt.SigningMethodHS256, []byte(secret), "", validClaims()),
+		},
+		{
+			name:  "Expired within leeway",
+			token: sign(t, gojwt.SigningMethodHS256, []byte(secret), "", with(validClaims(), "exp", time.Now().Add(-30*time.Second).Unix())),
+		},
+		{
+			name:  "Expired",
+			token: sign(t, gojwt.SigningMethodHS256, []byte(secret), "", with(validClaims(), "exp", time.Now().Add(-time.Hour).Unix())),
+			err:   jwt.ErrExpired,
+		},
+		{
+			name:  "Not valid yet",
+			token: sign(t, gojwt.SigningMethodHS256, []byte(secret), "", with(validClaims(), "nbf", time.Now().Add(time.Hour).Unix())),
+			err:   jwt.ErrNotYetValid,
+		},
+		{
+			name:  "Without exp",
+			token: sign(t, gojwt.SigningMethodHS256, []byte(secret), "", with(validClaims(), "exp", nil)),
+			err:   jwt.ErrInvalidClaim,
+		},
+		{
+			name:  "Wrong secret",
+			token: sign(t, gojwt.SigningMethodHS256, []byte("other"), "", validClaims()),
+			err:   jwt.ErrSignature,
+		},
+		{
+			name:  "Algorithm not allowed",
+			token: sign(t, gojwt.SigningMethodHS512, []byte(secret), "", validClaims()),
+			err:   jwt.ErrAlgorithm,
+		},
+		{
+			name:  "Alg none",
+			token: sign(t, gojwt.SigningMethodNone, gojwt.UnsafeAllowNoneSignatureType, "", validClaims()),
+			err:   jwt.ErrAlgorithm,
+		},
+		{
+			name:  "Wrong issuer",
+			token: sign(t, gojwt.SigningMethodHS256, []byte(secret), "", with(validClaims(), "iss", "evil")),
+			err:   jwt.ErrIssuer,
+		},
+		{
+			name:  "Wrong audience",
+			token: sign(t, gojwt.SigningMethodHS256, []byte(secret), "", with(validClaims(), "aud", "other-app")),
+			err:   jwt.ErrAudience,
+		},
+		{
+			name:  "Wrong app_id",
+			token: sign(t, gojwt.SigningMethodHS256, []byte(secret), "", with(validClaims(), "app_id", 2)),
+			err:   jwt.ErrAppID,
+		},
+		{
+			name:  "Uid of wrong type",
+			token: sign(t, gojwt.SigningMethodHS256, []byte(secret), "", with(validClaims(), "uid", "42")),
+			err:   jwt.ErrMalformed,
+		},
+		{
+			name:  "Without uid",
+			token: sign(t, gojwt.SigningMethodHS256, []byte(secret), "", with(validClaims(), "uid", nil)),
+			err:   jwt.ErrInvalidClaim,
+		},
+		{
+			name:  "Garbage",
+			token: "not.a.token",
+			err:   jwt.ErrMalformed,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			claims, err := v.Verify(tc.token)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, int64(42), claims.UID)
+			require.Equal(t, "user@example.com", claims.Email)
+		})
+	}
+}
+
+func TestNewVerifier(t *testing.T) {
+	_, err := jwt.NewVerifier(jwt.Options{Algorithms: []string{jwt.AlgRS256}, Secret: secret})
+	require.Error(t, err, "RS256 without key set")
+
+	_, err = jwt.NewVerifier(jwt.Options{Algorithms: []string{jwt.AlgHS256}})
+	require.Error(t, err, "HS256 without secret")
+
+	_, err = jwt.NewVerifier(jwt.Options{Algorithms: []string{"none"}, Secret: secret})
+	require.Error(t, err)
+}
+
+// jwksFile - JWKS во временном файле
+type jwksFile struct {
+	path string
+	keys []map[string]string
+}
+
+func (f *jwksFile) addRSA(kid string, key *rsa.PublicKey) {
+	f.keys = append(f.keys, map[string]string{
+		"kty": "RSA",
+		"kid": kid,
+		"alg": jwt.AlgRS256,
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	})
+}
+
+func (f *jwksFile) addEd25519(kid string, key ed25519.PublicKey) {
+	f.keys = append(f.keys, map[string]string{
+		"kty": "OKP",
+		"crv": "Ed25519",
+		"kid": kid,
+		"x":   base64.RawURLEncoding.EncodeToString(key),
+	})
+}
+
+func (f *jwksFile) write(t *testing.T, modTime time.Time) {
+	t.Helper()
+
+	data, err := json.Marshal(map[string]any{"keys": f.keys})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(f.path, data, 0o600))
+	require.NoError(t, os.Chtimes(f.path, modTime, modTime))
+}
+
+func TestVerifier_JWKS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	_, rotatedKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	file := &jwksFile{path: filepath.Join(t.TempDir(), "jwks.json")}
+	file.addRSA("rsa-1", &rsaKey.PublicKey)
+	file.addEd25519("ed-1", edPub)
+	file.write(t, time.Now().Add(-time.Hour))
+
+	keys, err := jwt.LoadJWKS(file.path)
+	require.NoError(t, err)
+
+	v, err := jwt.NewVerifier(jwt.Options{
+		Algorithms: []string{jwt.AlgHS256, jwt.AlgRS256, jwt.AlgEdDSA},
+		Secret:     secret,
+		Keys:       keys,
+	})
+	require.NoError(t, err)
+
+	claims, err := v.Verify(sign(t, gojwt.SigningMethodRS256, rsaKey, "rsa-1", validClaims()))
+	require.NoError(t, err)
+	require.Equal(t, int64(42), claims.UID)
+
+	_, err = v.Verify(sign(t, gojwt.SigningMethodEdDSA, edKey, "ed-1", validClaims()))
+	require.NoError(t, err)
+
+	// Ключ выбирается по kid: подпись другим ключом не проходит
+	_, err = v.Verify(sign(t, gojwt.SigningMethodEdDSA, rotatedKey, "ed-1", validClaims()))
+	require.ErrorIs(t, err, jwt.ErrSignature)
+
+	_, err = v.Verify(sign(t, gojwt.SigningMethodEdDSA, rotatedKey, "ed-2", validClaims()))
+	require.ErrorIs(t, err, jwt.ErrUnknownKey)
+
+	// RSA-ключ с alg RS256 нельзя использовать для RS512
+	v512, err := jwt.NewVerifier(jwt.Options{Algorithms: []string{jwt.AlgRS512}, Keys: keys})
+	require.NoError(t, err)
+
+	_, err = v512.Verify(sign(t, gojwt.SigningMethodRS512, rsaKey, "rsa-1", validClaims()))
+	require.ErrorIs(t, err, jwt.ErrAlgorithm)
+
+	// Подмена алгоритма: токен HS256, подписанный открытым RSA-ключом как секретом
+	pubKey := rsaKey.PublicKey.N.Bytes()
+	_, err = v.Verify(sign(t, gojwt.SigningMethodHS256, pubKey, "rsa-1", validClaims()))
+	require.ErrorIs(t, err, jwt.ErrSignature)
+
+	// Ротация: SSO добавил новый ключ - файл перечитывается при встрече нового kid
+	file.addEd25519("ed-2", rotatedKey.Public().(ed25519.PublicKey))
+	file.write(t, time.Now())
+
+	_, err = v.Verify(sign(t, gojwt.SigningMethodEdDSA, rotatedKey, "ed-2", validClaims()))
+	require.NoError(t, err)
+}
+
+func TestLoadJWKS_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jwks.json")
+
+	for _, content := range []string{
+		`not json`,
+		`{"keys": []}`,
+		`{"keys": [{"kty": "EC", "kid": "1"}]}`,
+		`{"keys": [{"kty": "OKP", "crv": "Ed25519", "kid": "1", "x": "AAAA"}]}`,
+		`{"keys": [{"kty": "OKP", "crv": "Ed25519", "kid": "1", "alg": "RS256", "x": "` +
+			base64.RawURLEncoding.EncodeToString(make([]byte, ed25519.PublicKeySize)) + `"}]}`,
+	} {
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+		_, err := jwt.LoadJWKS(path)
+		require.Error(t, err, content)
+	}
+}
@@ -0,0 +1,108 @@
+// internal/lib/linkfile/bitly.go
+package linkfile
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Колонки выгрузки bit.ly (имена сравниваются без учета регистра, "_" равно пробелу).
+// Из нескольких подходящих колонок берется первая непустая
+var (
+	bitlyLongURL = []string{"long url", "destination url", "destination", "original url"}
+	bitlyLink    = []string{"custom bitlink", "bitlink", "short link", "short url", "link"}
+	bitlyTitle   = []string{"title"}
+)
+
+// bitlyReader читает CSV-выгрузку ссылок bit.ly. Алиас - последний сегмент пути
+// короткой ссылки (bit.ly/3xYz -> 3xYz). Домен bit.ly не переносится:
+// ссылки создаются на основном домене или на домене, заданном при импорте
+type bitlyReader struct {
+	r       *csv.Reader
+	columns map[string]int
+}
+
+func newBitlyReader(r io.Reader) (*bitlyReader, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := readHeader(cr)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int, len(header))
+	for name, i := range header {
+		columns[strings.ReplaceAll(name, "_", " ")] = i
+	}
+
+	if !hasAny(columns, bitlyLongURL) || !hasAny(columns, bitlyLink) {
+		return nil, errors.New("not a bit.ly export: long url and bitlink columns are required")
+	}
+
+	return &bitlyReader{r: cr, columns: columns}, nil
+}
+
+func hasAny(columns map[string]int, names []string) bool {
+	for _, name := range names {
+		if _, ok := columns[name]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *bitlyReader) Read() (Record, error) {
+	row, err := r.r.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return Record{}, fmt.Errorf("%w: %v", ErrInvalidRecord, err)
+		}
+
+		return Record{}, err
+	}
+
+	first := func(names []string) string {
+		for _, name := range names {
+			if i, ok := r.columns[name]; ok && i < len(row) {
+				if v := strings.TrimSpace(row[i]); v != "" {
+					return v
+				}
+			}
+		}
+
+		return ""
+	}
+
+	link := first(bitlyLink)
+	// В колонке может быть несколько ссылок через "|" или пробел - берем первую
+	link, _, _ = strings.Cut(link, "|")
+	link, _, _ = strings.Cut(strings.TrimSpace(link), " ")
+
+	return Record{
+		Alias: bitlyAlias(link),
+		URL:   first(bitlyLongURL),
+		Title: first(bitlyTitle),
+	}, nil
+}
+
+// bitlyAlias - последний сегмент пути короткой ссылки
+func bitlyAlias(link string) string {
+	link = strings.TrimRight(link, "/")
+	if i := strings.LastIndex(link, "/"); i >= 0 {
+		return link[i+1:]
+	}
+
+	// Ссылка без пути (только алиас)
+	if strings.Contains(link, ".") {
+		return ""
+	}
+
+	return link
+}
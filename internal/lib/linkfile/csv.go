@@ -0,0 +1,212 @@
+// internal/lib/linkfile/csv.go
+package linkfile
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Columns - колонки CSV в порядке выгрузки
+var Columns = []string{
+	"domain", "alias", "url", "title", "not_before", "not_after", "fallback_url", "sticky",
+	"targets", "rules", "utm_source", "utm_medium", "utm_campaign", "query_policy",
+	"redirect_code", "interstitial", "clicks",
+}
+
+type csvWriter struct {
+	w           *csv.Writer
+	wroteHeader bool
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+func (w *csvWriter) Write(rec Record) error {
+	if !w.wroteHeader {
+		if err := w.w.Write(Columns); err != nil {
+			return err
+		}
+		w.wroteHeader = true
+	}
+
+	targets, err := jsonCell(rec.Targets)
+	if err != nil {
+		return err
+	}
+	rules, err := jsonCell(rec.Rules)
+	if err != nil {
+		return err
+	}
+
+	redirectCode := ""
+	if rec.RedirectCode != 0 {
+		redirectCode = strconv.Itoa(rec.RedirectCode)
+	}
+
+	return w.w.Write([]string{
+		rec.Domain, rec.Alias, rec.URL, rec.Title, timeCell(rec.NotBefore), timeCell(rec.NotAfter),
+		rec.FallbackURL, rec.Sticky, targets, rules, rec.UTMSource, rec.UTMMedium, rec.UTMCampaign,
+		rec.QueryPolicy, redirectCode, strconv.FormatBool(rec.Interstitial), strconv.FormatInt(rec.Clicks, 10),
+	})
+}
+
+// Flush дописывает буфер. Пустая выгрузка - файл из одной строки заголовка
+func (w *csvWriter) Flush() error {
+	if !w.wroteHeader {
+		if err := w.w.Write(Columns); err != nil {
+			return err
+		}
+		w.wroteHeader = true
+	}
+
+	w.w.Flush()
+
+	return w.w.Error()
+}
+
+// jsonCell - ячейка с JSON-массивом. Пустой массив - пустая ячейка
+func jsonCell(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	if s := string(data); s == "null" || s == "[]" {
+		return "", nil
+	}
+
+	return string(data), nil
+}
+
+func timeCell(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
+
+type csvReader struct {
+	r       *csv.Reader
+	columns map[string]int
+}
+
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	columns, err := readHeader(cr)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := columns["url"]; !ok {
+		return nil, errors.New("csv header has no url column")
+	}
+
+	return &csvReader{r: cr, columns: columns}, nil
+}
+
+// readHeader читает строку заголовка: имя колонки в нижнем регистре -> номер
+func readHeader(r *csv.Reader) (map[string]int, error) {
+	header, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("empty file")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read csv header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		// Excel добавляет в начало файла BOM
+		name = strings.TrimPrefix(name, "\ufeff")
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	return columns, nil
+}
+
+func (r *csvReader) Read() (Record, error) {
+	row, err := r.r.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return Record{}, fmt.Errorf("%w: %v", ErrInvalidRecord, err)
+		}
+
+		return Record{}, err
+	}
+
+	cell := func(name string) string {
+		i, ok := r.columns[name]
+		if !ok || i >= len(row) {
+			return ""
+		}
+
+		return strings.TrimSpace(row[i])
+	}
+
+	rec := Record{
+		Domain:      cell("domain"),
+		Alias:       cell("alias"),
+		URL:         cell("url"),
+		Title:       cell("title"),
+		FallbackURL: cell("fallback_url"),
+		Sticky:      cell("sticky"),
+		UTMSource:   cell("utm_source"),
+		UTMMedium:   cell("utm_medium"),
+		UTMCampaign: cell("utm_campaign"),
+		QueryPolicy: cell("query_policy"),
+	}
+
+	if rec.NotBefore, err = parseTimeCell("not_before", cell("not_before")); err != nil {
+		return Record{}, err
+	}
+	if rec.NotAfter, err = parseTimeCell("not_after", cell("not_after")); err != nil {
+		return Record{}, err
+	}
+
+	if v := cell("redirect_code"); v != "" {
+		if rec.RedirectCode, err = strconv.Atoi(v); err != nil {
+			return Record{}, fmt.Errorf("%w: redirect_code: %v", ErrInvalidRecord, err)
+		}
+	}
+	if v := cell("interstitial"); v != "" {
+		if rec.Interstitial, err = strconv.ParseBool(v); err != nil {
+			return Record{}, fmt.Errorf("%w: interstitial: %v", ErrInvalidRecord, err)
+		}
+	}
+
+	if v := cell("targets"); v != "" {
+		if err := json.Unmarshal([]byte(v), &rec.Targets); err != nil {
+			return Record{}, fmt.Errorf("%w: targets: %v", ErrInvalidRecord, err)
+		}
+	}
+	if v := cell("rules"); v != "" {
+		if err := json.Unmarshal([]byte(v), &rec.Rules); err != nil {
+			return Record{}, fmt.Errorf("%w: rules: %v", ErrInvalidRecord, err)
+		}
+	}
+
+	return rec, nil
+}
+
+func parseTimeCell(name, v string) (*time.Time, error) {
+	if v == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidRecord, name, err)
+	}
+
+	return &t, nil
+}
@@ -0,0 +1,168 @@
+// internal/lib/linkfile/linkfile.go
+
+// Package linkfile - чтение и запись ссылок в файлах импорта и экспорта.
+//
+// Форматы:
+//   - ndjson - одна ссылка (Record в JSON) на строку,
+//   - csv - строка заголовка с именами колонок (см. Columns), затем по ссылке на строку.
+//     Правила редиректа и варианты A/B-теста записываются в ячейки как JSON-массивы,
+//     время - в RFC 3339. При чтении порядок колонок не важен, неизвестные колонки пропускаются,
+//   - bitly - только чтение: CSV-выгрузка ссылок bit.ly (см. newBitlyReader).
+package linkfile
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"url-shortener/internal/storage"
+)
+
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+	FormatBitly  = "bitly"
+)
+
+var (
+	ErrUnknownFormat = errors.New("unknown format")
+	// ErrInvalidRecord - запись не разобрана. Чтение можно продолжить со следующей записи
+	ErrInvalidRecord = errors.New("invalid record")
+)
+
+// Record - ссылка в файле импорта или экспорта
+type Record struct {
+	Domain      string         `json:"domain,omitempty"`
+	Alias       string         `json:"alias"`
+	URL         string         `json:"url"`
+	Title       string         `json:"title,omitempty"`
+	NotBefore   *time.Time     `json:"not_before,omitempty"`
+	NotAfter    *time.Time     `json:"not_after,omitempty"`
+	FallbackURL string         `json:"fallback_url,omitempty"`
+	Sticky      string         `json:"sticky,omitempty"`
+	Targets     []Target       `json:"targets,omitempty"`
+	Rules       []storage.Rule `json:"rules,omitempty"`
+
+	UTMSource    string `json:"utm_source,omitempty"`
+	UTMMedium    string `json:"utm_medium,omitempty"`
+	UTMCampaign  string `json:"utm_campaign,omitempty"`
+	QueryPolicy  string `json:"query_policy,omitempty"`
+	RedirectCode int    `json:"redirect_code,omitempty"`
+	Interstitial bool   `json:"interstitial,omitempty"`
+
+	// Переходы - только при экспорте, при импорте не учитываются
+	Clicks int64 `json:"clicks,omitempty"`
+}
+
+// Target - вариант A/B-теста
+type Target struct {
+	URL    string `json:"url"`
+	Weight int    `json:"weight"`
+	Clicks int64  `json:"clicks,omitempty"` // только при экспорте
+}
+
+// FromLink - запись для выгрузки ссылки
+func FromLink(link storage.Link) Record {
+	rec := Record{
+		Domain:       link.Domain,
+		Alias:        link.Alias,
+		URL:          link.URL,
+		Title:        link.Title,
+		NotBefore:    link.NotBefore,
+		NotAfter:     link.NotAfter,
+		FallbackURL:  link.FallbackURL,
+		Sticky:       link.Sticky,
+		Rules:        link.Rules,
+		UTMSource:    link.UTMSource,
+		UTMMedium:    link.UTMMedium,
+		UTMCampaign:  link.UTMCampaign,
+		QueryPolicy:  link.QueryPolicy,
+		RedirectCode: link.RedirectCode,
+		Interstitial: link.Interstitial,
+		Clicks:       link.Clicks,
+	}
+
+	for _, t := range link.Targets {
+		rec.Targets = append(rec.Targets, Target{URL: t.URL, Weight: t.Weight, Clicks: t.Clicks})
+	}
+
+	return rec
+}
+
+// Link - ссылка для сохранения. Переходы не переносятся
+func (rec Record) Link() storage.Link {
+	link := storage.Link{
+		Domain:       rec.Domain,
+		Alias:        rec.Alias,
+		URL:          rec.URL,
+		Title:        rec.Title,
+		NotBefore:    rec.NotBefore,
+		NotAfter:     rec.NotAfter,
+		FallbackURL:  rec.FallbackURL,
+		Sticky:       rec.Sticky,
+		Rules:        rec.Rules,
+		UTMSource:    rec.UTMSource,
+		UTMMedium:    rec.UTMMedium,
+		UTMCampaign:  rec.UTMCampaign,
+		QueryPolicy:  rec.QueryPolicy,
+		RedirectCode: rec.RedirectCode,
+		Interstitial: rec.Interstitial,
+		Targets:      make([]storage.Target, 0, len(rec.Targets)),
+	}
+
+	for _, t := range rec.Targets {
+		link.Targets = append(link.Targets, storage.Target{URL: t.URL, Weight: t.Weight})
+	}
+
+	return link
+}
+
+// Writer записывает ссылки в файл выгрузки
+type Writer interface {
+	Write(rec Record) error
+	// Flush дописывает буферизованные данные
+	Flush() error
+}
+
+// NewWriter создает Writer формата format (csv или ndjson)
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w), nil
+	case FormatNDJSON:
+		return newNDJSONWriter(w), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+	}
+}
+
+// Reader читает ссылки из файла импорта
+type Reader interface {
+	// Read возвращает следующую запись, в конце файла - io.EOF.
+	// Ошибка ErrInvalidRecord относится только к текущей записи, чтение можно продолжить
+	Read() (Record, error)
+}
+
+// NewReader создает Reader формата format (csv, ndjson или bitly)
+func NewReader(format string, r io.Reader) (Reader, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVReader(r)
+	case FormatNDJSON:
+		return newNDJSONReader(r), nil
+	case FormatBitly:
+		return newBitlyReader(r)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+	}
+}
+
+// ContentType - MIME-тип файла формата format
+func ContentType(format string) string {
+	if format == FormatNDJSON {
+		return "application/x-ndjson"
+	}
+
+	return "text/csv"
+}
@@ -0,0 +1,115 @@
+package linkfile_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/lib/linkfile"
+	"url-shortener/internal/storage"
+)
+
+func readAll(t *testing.T, r linkfile.Reader) (recs []linkfile.Record, invalid int) {
+	t.Helper()
+
+	for {
+		rec, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return recs, invalid
+		}
+		if errors.Is(err, linkfile.ErrInvalidRecord) {
+			invalid++
+			continue
+		}
+		require.NoError(t, err)
+		recs = append(recs, rec)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	notBefore := time.Date(2030, 1, 1, 10, 0, 0, 0, time.UTC)
+	rec := linkfile.Record{
+		Domain:       "go.example.com",
+		Alias:        "promo",
+		URL:          "https://example.com/promo",
+		Title:        "Promo, \"quoted\"",
+		NotBefore:    &notBefore,
+		Sticky:       storage.StickyCookie,
+		Targets:      []linkfile.Target{{URL: "https://a.example", Weight: 1}, {URL: "https://b.example", Weight: 3}},
+		Rules:        []storage.Rule{{Platform: "ios", TargetURL: "https://apps.apple.com"}},
+		UTMSource:    "newsletter",
+		RedirectCode: 301,
+		Interstitial: true,
+	}
+
+	for _, format := range []string{linkfile.FormatCSV, linkfile.FormatNDJSON} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			w, err := linkfile.NewWriter(format, &buf)
+			require.NoError(t, err)
+			require.NoError(t, w.Write(rec))
+			require.NoError(t, w.Write(linkfile.Record{Alias: "plain", URL: "https://example.com"}))
+			require.NoError(t, w.Flush())
+
+			r, err := linkfile.NewReader(format, &buf)
+			require.NoError(t, err)
+
+			recs, invalid := readAll(t, r)
+			require.Zero(t, invalid)
+			require.Equal(t, []linkfile.Record{rec, {Alias: "plain", URL: "https://example.com"}}, recs)
+		})
+	}
+}
+
+func TestCSVReader_InvalidRows(t *testing.T) {
+	data := "Alias,URL,not_before,extra\n" +
+		"ok,https://example.com,,x\n" +
+		"bad-time,https://example.com,tomorrow,\n" +
+		"short\n"
+
+	r, err := linkfile.NewReader(linkfile.FormatCSV, strings.NewReader(data))
+	require.NoError(t, err)
+
+	recs, invalid := readAll(t, r)
+	require.Equal(t, 1, invalid)
+	require.Equal(t, []linkfile.Record{
+		{Alias: "ok", URL: "https://example.com"},
+		{Alias: "short"},
+	}, recs)
+
+	_, err = linkfile.NewReader(linkfile.FormatCSV, strings.NewReader("alias,title\n"))
+	require.Error(t, err)
+}
+
+func TestBitlyReader(t *testing.T) {
+	data := "Title,Bitlink,Long URL,Created,Clicks\n" +
+		"Spring sale,https://bit.ly/3xYz,https://shop.example.com/spring,2024-03-01,42\n" +
+		",bit.ly/Promo2024,https://shop.example.com/promo,2024-03-02,0\n"
+
+	r, err := linkfile.NewReader(linkfile.FormatBitly, strings.NewReader(data))
+	require.NoError(t, err)
+
+	recs, invalid := readAll(t, r)
+	require.Zero(t, invalid)
+	require.Equal(t, []linkfile.Record{
+		{Alias: "3xYz", URL: "https://shop.example.com/spring", Title: "Spring sale"},
+		{Alias: "Promo2024", URL: "https://shop.example.com/promo"},
+	}, recs)
+
+	_, err = linkfile.NewReader(linkfile.FormatBitly, strings.NewReader("alias,url\n"))
+	require.Error(t, err)
+}
+
+func TestUnknownFormat(t *testing.T) {
+	_, err := linkfile.NewReader("xml", strings.NewReader(""))
+	require.ErrorIs(t, err, linkfile.ErrUnknownFormat)
+
+	_, err = linkfile.NewWriter("xml", io.Discard)
+	require.ErrorIs(t, err, linkfile.ErrUnknownFormat)
+}
@@ -0,0 +1,171 @@
+// internal/lib/pagemeta/pagemeta.go
+
+// Package pagemeta - метаданные веб-страницы для предпросмотра ссылки:
+// заголовок, описание и картинка (OpenGraph) и иконка сайта.
+// Страница запрашивается со строгими таймаутом и лимитом размера, адреса
+// внутренних сетей запрещены (защита от SSRF)
+package pagemeta
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"url-shortener/internal/lib/safedial"
+)
+
+const (
+	maxRedirects = 5
+
+	// Ограничения длины сохраняемых значений (в символах)
+	maxTextLength = 500
+	maxURLLength  = 2048
+)
+
+var (
+	ErrUnsupportedScheme = errors.New("only http and https addresses are supported")
+	ErrNotHTML           = errors.New("the page is not an HTML document")
+	ErrTooManyRedirects  = errors.New("too many redirects")
+)
+
+// StatusError - страница ответила кодом, отличным от 2xx
+type StatusError struct {
+	Code int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d", e.Code)
+}
+
+// Meta - метаданные страницы. Адреса картинки и иконки абсолютные
+type Meta struct {
+	Title       string
+	Description string
+	Image       string
+	Favicon     string
+}
+
+// Options - ограничения запроса страницы
+type Options struct {
+	Timeout  time.Duration // на весь запрос, включая редиректы и чтение тела
+	MaxBytes int64         // сколько байт страницы читать: метаданные обычно в начале, в <head>
+	// Разрешить адреса внутренних сетей (loopback, частные сети). Только для тестов и закрытых инсталляций
+	AllowPrivate bool
+	UserAgent    string
+}
+
+// Fetcher запрашивает страницы
+type Fetcher struct {
+	client    *http.Client
+	maxBytes  int64
+	userAgent string
+}
+
+// New создает Fetcher
+func New(opts Options) *Fetcher {
+	dialer := &net.Dialer{Timeout: opts.Timeout}
+	if !opts.AllowPrivate {
+		dialer.Control = safedial.Control
+	}
+
+	transport := &http.Transport{
+		Proxy:                 nil, // через прокси проверка адресов не работала бы
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   opts.Timeout,
+		ResponseHeaderTimeout: opts.Timeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       30 * time.Second,
+	}
+
+	return &Fetcher{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   opts.Timeout,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= maxRedirects {
+					return ErrTooManyRedirects
+				}
+
+				return checkScheme(req.URL)
+			},
+		},
+		maxBytes:  opts.MaxBytes,
+		userAgent: opts.UserAgent,
+	}
+}
+
+// Fetch запрашивает страницу rawURL и извлекает ее метаданные
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (Meta, error) {
+	const op = "lib.pagemeta.Fetch"
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return Meta{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := checkScheme(u); err != nil {
+		return Meta{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return Meta{}, fmt.Errorf("%s: %w", op, err)
+	}
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.1")
+	if f.userAgent != "" {
+		req.Header.Set("User-Agent", f.userAgent)
+	}
+
+	res, err := f.client.Do(req)
+	if err != nil {
+		return Meta{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return Meta{}, fmt.Errorf("%s: %w", op, &StatusError{Code: res.StatusCode})
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return Meta{}, fmt.Errorf("%s: %w: %s", op, ErrNotHTML, mediaType)
+	}
+
+	// относительные адреса разрешаются от адреса после редиректов
+	meta, err := Parse(io.LimitReader(res.Body, f.maxBytes), res.Request.URL)
+	if err != nil {
+		return Meta{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return meta, nil
+}
+
+func checkScheme(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return ErrUnsupportedScheme
+	}
+
+	return nil
+}
+
+// clean - значение без лишних пробелов, обрезанное до limit символов
+func clean(s string, limit int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if !utf8.ValidString(s) {
+		s = strings.ToValidUTF8(s, "")
+	}
+
+	if utf8.RuneCountInString(s) > limit {
+		runes := []rune(s)
+		s = strings.TrimSpace(string(runes[:limit-1])) + "…"
+	}
+
+	return s
+}
@@ -0,0 +1,144 @@
+package pagemeta_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/lib/pagemeta"
+	"url-shortener/internal/lib/safedial"
+)
+
+const page = `<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>  Spring   sale
+  </title>
+  <meta name="description" content="Plain description">
+  <meta property="og:description" content="Everything is 50% off">
+  <meta property="og:image" content="/img/sale.png">
+  <link rel="shortcut icon" href="static/favicon.png">
+</head>
+<body><meta property="og:title" content="Ignored: in body"></body>
+</html>`
+
+func newSite(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sale/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(page))
+	})
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/sale/", http.StatusFound)
+	})
+	mux.HandleFunc("/bare", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><head><meta property="og:title" content="Bare"></head></html>`))
+	})
+	mux.HandleFunc("/huge", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte("<html><head><title>Huge</title>" + strings.Repeat("<!-- padding -->", 1<<16)))
+		_, _ = w.Write([]byte(`<meta property="og:description" content="too far"></head></html>`))
+	})
+	mux.HandleFunc("/file.zip", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/zip")
+		_, _ = w.Write([]byte("PK"))
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	})
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestFetch(t *testing.T) {
+	srv := newSite(t)
+	f := pagemeta.New(pagemeta.Options{Timeout: 200 * time.Millisecond, MaxBytes: 64 << 10, AllowPrivate: true})
+
+	cases := []struct {
+		name string
+		path string
+		want pagemeta.Meta
+		err  string
+	}{
+		{
+			name: "Full page",
+			path: "/sale/",
+			want: pagemeta.Meta{
+				Title:       "Spring sale",
+				Description: "Everything is 50% off",
+				Image:       srv.URL + "/img/sale.png",
+				Favicon:     srv.URL + "/sale/static/favicon.png",
+			},
+		},
+		{
+			name: "After redirect",
+			path: "/moved",
+			want: pagemeta.Meta{
+				Title:       "Spring sale",
+				Description: "Everything is 50% off",
+				Image:       srv.URL + "/img/sale.png",
+				Favicon:     srv.URL + "/sale/static/favicon.png",
+			},
+		},
+		{
+			name: "Default favicon",
+			path: "/bare",
+			want: pagemeta.Meta{Title: "Bare", Favicon: srv.URL + "/favicon.ico"},
+		},
+		{
+			name: "Size limit",
+			path: "/huge",
+			want: pagemeta.Meta{Title: "Huge", Favicon: srv.URL + "/favicon.ico"},
+		},
+		{name: "Not HTML", path: "/file.zip", err: pagemeta.ErrNotHTML.Error()},
+		{name: "Not found", path: "/missing", err: "unexpected status 404"},
+		{name: "Timeout", path: "/slow", err: "Client.Timeout exceeded"},
+		{name: "Redirect loop", path: "/loop", err: pagemeta.ErrTooManyRedirects.Error()},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			meta, err := f.Fetch(context.Background(), srv.URL+tc.path)
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.want, meta)
+		})
+	}
+}
+
+func TestFetch_PrivateAddress(t *testing.T) {
+	srv := newSite(t)
+	f := pagemeta.New(pagemeta.Options{Timeout: time.Second, MaxBytes: 64 << 10})
+
+	_, err := f.Fetch(context.Background(), srv.URL+"/sale/")
+	require.ErrorIs(t, err, safedial.ErrForbiddenAddress)
+
+	_, err = f.Fetch(context.Background(), "file:///etc/passwd")
+	require.ErrorIs(t, err, pagemeta.ErrUnsupportedScheme)
+}
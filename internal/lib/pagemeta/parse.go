@@ -0,0 +1,158 @@
+// internal/lib/pagemeta/parse.go
+package pagemeta
+
+import (
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Parse извлекает метаданные из HTML-страницы. base - адрес страницы для относительных ссылок.
+// Разбор останавливается на <body>: все нужное находится в <head>
+func Parse(r io.Reader, base *url.URL) (Meta, error) {
+	var (
+		meta          Meta
+		title         string
+		ogTitle       string
+		description   string
+		ogDescription string
+		icon          string
+		inTitle       bool
+	)
+
+	z := html.NewTokenizer(r)
+
+loop:
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			if errors.Is(z.Err(), io.EOF) {
+				break loop
+			}
+			// страница обрезана лимитом или сломана - берем то, что успели прочитать
+			if title == "" && ogTitle == "" {
+				return Meta{}, z.Err()
+			}
+			break loop
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+
+			switch tok.DataAtom {
+			case atom.Body:
+				break loop
+			case atom.Title:
+				inTitle = tt == html.StartTagToken && title == ""
+			case atom.Meta:
+				key := strings.ToLower(attr(tok, "property"))
+				if key == "" {
+					key = strings.ToLower(attr(tok, "name"))
+				}
+				content := attr(tok, "content")
+
+				switch key {
+				case "og:title":
+					ogTitle = content
+				case "og:description":
+					ogDescription = content
+				case "description":
+					description = content
+				case "og:image", "og:image:url", "og:image:secure_url":
+					if meta.Image == "" {
+						meta.Image = resolve(base, content)
+					}
+				}
+			case atom.Link:
+				if icon == "" && isIcon(attr(tok, "rel")) {
+					icon = resolve(base, attr(tok, "href"))
+				}
+			}
+
+		case html.TextToken:
+			if inTitle {
+				title += string(z.Text())
+			}
+
+		case html.EndTagToken:
+			if tok := z.Token(); tok.DataAtom == atom.Title {
+				inTitle = false
+			} else if tok.DataAtom == atom.Head {
+				break loop
+			}
+		}
+	}
+
+	meta.Title = clean(firstNonEmpty(ogTitle, title), maxTextLength)
+	meta.Description = clean(firstNonEmpty(ogDescription, description), maxTextLength)
+
+	// без явной иконки браузеры запрашивают /favicon.ico сайта
+	if icon == "" && base != nil {
+		icon = (&url.URL{Scheme: base.Scheme, Host: base.Host, Path: "/favicon.ico"}).String()
+	}
+	meta.Favicon = icon
+
+	if len(meta.Image) > maxURLLength {
+		meta.Image = ""
+	}
+	if len(meta.Favicon) > maxURLLength {
+		meta.Favicon = ""
+	}
+
+	return meta, nil
+}
+
+func attr(tok html.Token, name string) string {
+	for _, a := range tok.Attr {
+		if a.Key == name {
+			return strings.TrimSpace(a.Val)
+		}
+	}
+
+	return ""
+}
+
+// isIcon - является ли <link rel="..."> иконкой сайта
+func isIcon(rel string) bool {
+	for _, r := range strings.Fields(strings.ToLower(rel)) {
+		if r == "icon" || r == "apple-touch-icon" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// resolve - абсолютный http(s)-адрес ref относительно base. Пусто, если адрес некорректен
+func resolve(base *url.URL, ref string) string {
+	if ref == "" {
+		return ""
+	}
+
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+	if checkScheme(u) != nil {
+		return ""
+	}
+
+	return u.String()
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,60 @@
+// internal/lib/publisher/memory.go
+package publisher
+
+import (
+	"context"
+	"sync"
+)
+
+// Memory хранит опубликованные события в памяти. Для тестов
+type Memory struct {
+	mu       sync.Mutex
+	messages []Message
+	failures []error
+}
+
+// NewMemory - публикатор в память
+func NewMemory() *Memory {
+	return &Memory{}
+}
+
+// Publish сохраняет событие или возвращает ошибку, заданную FailNext
+func (p *Memory) Publish(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.failures) > 0 {
+		err := p.failures[0]
+		p.failures = p.failures[1:]
+
+		return err
+	}
+
+	p.messages = append(p.messages, msg)
+
+	return nil
+}
+
+// FailNext - следующие вызовы Publish вернут ошибки errs по очереди
+func (p *Memory) FailNext(errs ...error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.failures = append(p.failures, errs...)
+}
+
+// Messages - опубликованные события по порядку
+func (p *Memory) Messages() []Message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]Message(nil), p.messages...)
+}
+
+func (p *Memory) Close() error {
+	return nil
+}
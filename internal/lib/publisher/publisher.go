@@ -0,0 +1,55 @@
+// internal/lib/publisher/publisher.go
+
+// Публикация событий ссылок во внешнюю шину сообщений.
+// Доставка "хотя бы один раз": одно событие может быть опубликовано повторно
+// (например, если сервис остановился сразу после публикации), получатель отбрасывает дубли
+// по IdempotencyKey. Адаптеры для Kafka, NATS и т.п. реализуют EventPublisher
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Виды публикаторов для конфигурации
+const (
+	KindNone   = "none"
+	KindStdout = "stdout"
+	KindFile   = "file"
+)
+
+// Message - событие для публикации
+type Message struct {
+	IdempotencyKey string          `json:"idempotency_key"` // ID события, одинаков при повторной публикации
+	Type           string          `json:"type"`
+	PartitionKey   string          `json:"partition_key"` // ID ссылки: события одной ссылки идут по порядку
+	CreatedAt      time.Time       `json:"created_at"`
+	Payload        json.RawMessage `json:"payload"` // storage.Event в JSON
+}
+
+// EventPublisher публикует события. Publish возвращает nil, только если шина приняла сообщение
+type EventPublisher interface {
+	Publish(ctx context.Context, msg Message) error
+	Close() error
+}
+
+// Open создает публикатор вида kind. path - файл для KindFile
+func Open(kind string, path string) (EventPublisher, error) {
+	const op = "lib.publisher.Open"
+
+	switch kind {
+	case KindStdout:
+		return NewStdout(), nil
+	case KindFile:
+		p, err := NewFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		return p, nil
+	default:
+		return nil, fmt.Errorf("%s: unknown publisher %q", op, kind)
+	}
+}
@@ -0,0 +1,71 @@
+package publisher_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/lib/publisher"
+)
+
+func message(key string) publisher.Message {
+	return publisher.Message{
+		IdempotencyKey: key,
+		Type:           "link.created",
+		PartitionKey:   "12",
+		CreatedAt:      time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		Payload:        json.RawMessage(`{"id":"` + key + `","type":"link.created"}`),
+	}
+}
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.ndjson")
+
+	p, err := publisher.Open(publisher.KindFile, path)
+	require.NoError(t, err)
+	require.NoError(t, p.Publish(context.Background(), message("a")))
+	require.NoError(t, p.Close())
+
+	// файл дописывается, а не перезаписывается
+	p, err = publisher.Open(publisher.KindFile, path)
+	require.NoError(t, err)
+	require.NoError(t, p.Publish(context.Background(), message("b")))
+	require.NoError(t, p.Close())
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var got []publisher.Message
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var m publisher.Message
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &m))
+		got = append(got, m)
+	}
+	require.NoError(t, scanner.Err())
+
+	require.Equal(t, []publisher.Message{message("a"), message("b")}, got)
+}
+
+func TestMemory(t *testing.T) {
+	p := publisher.NewMemory()
+	failure := errors.New("broker unavailable")
+	p.FailNext(failure)
+
+	require.ErrorIs(t, p.Publish(context.Background(), message("a")), failure)
+	require.NoError(t, p.Publish(context.Background(), message("a")))
+	require.Equal(t, []publisher.Message{message("a")}, p.Messages())
+}
+
+func TestOpen_Unknown(t *testing.T) {
+	_, err := publisher.Open("kafka", "")
+	require.ErrorContains(t, err, `unknown publisher "kafka"`)
+}
@@ -0,0 +1,76 @@
+// internal/lib/publisher/writer.go
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// Writer пишет события в поток по одному JSON на строку (NDJSON)
+type Writer struct {
+	mu   sync.Mutex
+	w    io.Writer
+	file *os.File // nil - поток не закрывается и не синхронизируется на диск
+}
+
+// NewWriter - публикатор в поток w
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// NewStdout - публикатор в стандартный вывод
+func NewStdout() *Writer {
+	return NewWriter(os.Stdout)
+}
+
+// NewFile - публикатор в файл path (дописывает в конец). Каждое событие сбрасывается на диск
+// до возврата из Publish
+func NewFile(path string) (*Writer, error) {
+	const op = "lib.publisher.NewFile"
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &Writer{w: f, file: f}, nil
+}
+
+// Publish записывает событие строкой
+func (p *Writer) Publish(_ context.Context, msg Message) error {
+	const op = "lib.publisher.Writer.Publish"
+
+	line, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	line = append(line, '\n')
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, err := p.w.Write(line); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if p.file != nil {
+		if err := p.file.Sync(); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return nil
+}
+
+// Close закрывает файл. Стандартный вывод и переданный поток не закрываются
+func (p *Writer) Close() error {
+	if p.file == nil {
+		return nil
+	}
+
+	return p.file.Close()
+}
@@ -2,6 +2,9 @@
 package random
 
 import (
+	cryptorand "crypto/rand"
+	"encoding/hex"
+	"fmt"
 	"math/rand"
 	"time"
 )
@@ -22,4 +25,16 @@ func NewRandomString(size int) string {
 	return string(b)
 }
 
+// NewSecureToken generates cryptographically secure random token
+// from size random bytes, encoded as hex string (2*size characters).
+// В отличие от NewRandomString подходит для секретов: токенов, ключей и т.п.
+func NewSecureToken(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := cryptorand.Read(b); err != nil {
+		return "", fmt.Errorf("lib.random.NewSecureToken: %w", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
 //TODO покрыть функцию тестами
@@ -0,0 +1,69 @@
+// internal/lib/safedial/safedial.go
+
+// Package safedial - защита исходящих запросов к адресам пользователей от SSRF:
+// соединения с адресами внутренних сетей запрещаются. Адрес проверяется после разрешения
+// имени, непосредственно перед соединением, поэтому DNS rebinding не помогает
+package safedial
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"syscall"
+)
+
+// ErrForbiddenAddress - адрес внутренней сети: такие страницы не запрашиваются
+var ErrForbiddenAddress = errors.New("address is not allowed")
+
+// Диапазоны, не входящие в стандартные проверки netip.Addr
+var forbiddenPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("100.64.0.0/10"), // CGNAT
+	netip.MustParsePrefix("192.0.0.0/24"),  // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"), // тестирование производительности
+	netip.MustParsePrefix("240.0.0.0/4"),   // зарезервировано
+	netip.MustParsePrefix("64:ff9b::/96"),  // NAT64 - может вести во внутреннюю IPv4-сеть
+}
+
+// Control - net.Dialer.Control: запрещает соединения с адресами внутренних сетей.
+// Клиент не должен использовать прокси, иначе проверяется адрес прокси
+func Control(_ string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+
+	if !IsPublic(ip) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, ip)
+	}
+
+	return nil
+}
+
+// IsPublic - является ли адрес публичным адресом интернета
+func IsPublic(ip netip.Addr) bool {
+	ip = ip.Unmap()
+
+	if !ip.IsValid() || ip.IsUnspecified() || ip.IsLoopback() || ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() {
+		return false
+	}
+
+	for _, p := range forbiddenPrefixes {
+		if p.Contains(ip) {
+			return false
+		}
+	}
+
+	if ip.Is4() && ip.As4()[0] == 0 { // 0.0.0.0/8
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,79 @@
+// internal/lib/signature/signature.go
+
+// Package signature - подпись тела запросов вебхуков HMAC-SHA256.
+//
+// Подписывается строка "<timestamp>.<body>", где timestamp - unix-время отправки из заголовка
+// X-Webhook-Timestamp. Подпись передается в заголовке X-Webhook-Signature в виде "sha256=<hex>".
+// Получатель пересчитывает подпись своим ключом и отклоняет запросы со старым timestamp (см. Verify),
+// чтобы перехваченный запрос нельзя было повторить
+package signature
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Заголовки запроса вебхука
+const (
+	HeaderSignature = "X-Webhook-Signature"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderEvent     = "X-Webhook-Event"    // тип события
+	HeaderDelivery  = "X-Webhook-Delivery" // ID доставки, одинаков при повторах
+)
+
+const (
+	prefix       = "sha256="
+	secretPrefix = "whsec_"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrExpired          = errors.New("signature timestamp is out of tolerance")
+)
+
+// NewSecret генерирует ключ подписи вебхука
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate secret: %w", err)
+	}
+
+	return secretPrefix + hex.EncodeToString(b), nil
+}
+
+// Sign возвращает значение заголовка X-Webhook-Signature для тела body, отправленного в момент timestamp
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return prefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify проверяет подпись signature тела body с заголовком timestamp.
+// Запрос, отправленный дальше tolerance от now, отклоняется с ErrExpired
+func Verify(secret, timestamp string, body []byte, signature string, tolerance time.Duration, now time.Time) error {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: bad timestamp", ErrInvalidSignature)
+	}
+
+	if d := now.Sub(time.Unix(ts, 0)); d > tolerance || d < -tolerance {
+		return ErrExpired
+	}
+
+	if !strings.HasPrefix(signature, prefix) ||
+		!hmac.Equal([]byte(signature), []byte(Sign(secret, ts, body))) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
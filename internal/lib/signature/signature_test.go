@@ -0,0 +1,40 @@
+package signature_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/lib/signature"
+)
+
+func TestSignVerify(t *testing.T) {
+	secret, err := signature.NewSecret()
+	require.NoError(t, err)
+
+	now := time.Unix(1714557600, 0)
+	ts := strconv.FormatInt(now.Unix(), 10)
+	body := []byte(`{"type":"link.created"}`)
+	sig := signature.Sign(secret, now.Unix(), body)
+
+	require.NoError(t, signature.Verify(secret, ts, body, sig, 5*time.Minute, now.Add(time.Minute)))
+
+	require.ErrorIs(t, signature.Verify(secret, ts, []byte(`{"type":"link.deleted"}`), sig, 5*time.Minute, now),
+		signature.ErrInvalidSignature)
+	require.ErrorIs(t, signature.Verify("whsec_other", ts, body, sig, 5*time.Minute, now),
+		signature.ErrInvalidSignature)
+	require.ErrorIs(t, signature.Verify(secret, "yesterday", body, sig, 5*time.Minute, now),
+		signature.ErrInvalidSignature)
+	require.ErrorIs(t, signature.Verify(secret, ts, body, sig, 5*time.Minute, now.Add(time.Hour)),
+		signature.ErrExpired)
+}
+
+func TestSign_Known(t *testing.T) {
+	// echo -n '1714557600.{}' | openssl dgst -sha256 -hmac secret
+	require.Equal(t,
+		"sha256=1a93cfcd5bd60288e12dd4d9101efd1c3de905185e81811ee6545dfffb059e25",
+		signature.Sign("secret", 1714557600, []byte("{}")),
+	)
+}
@@ -0,0 +1,416 @@
+// internal/lib/snapshot/snapshot.go
+
+// Package snapshot - резервные копии БД в каталоге: создание, ротация, проверка и восстановление.
+//
+// Копия - файл snapshot-<время UTC>.db (или .db.gz при сжатии) и рядом файл <копия>.sha256
+// с контрольной суммой в формате sha256sum, так что копию можно проверить и без сервиса:
+//
+//	cd ./storage/backups && sha256sum -c snapshot-20240501T100000Z.db.gz.sha256
+package snapshot
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	prefix        = "snapshot-"
+	extDB         = ".db"
+	extGzip       = ".gz"
+	extChecksum   = ".sha256"
+	timeLayout    = "20060102T150405Z"
+	tmpSuffix     = ".tmp"
+	restoreSuffix = ".restoring"
+)
+
+var (
+	ErrNotFound         = errors.New("snapshot not found")
+	ErrChecksumMismatch = errors.New("snapshot checksum mismatch")
+	ErrNoChecksum       = errors.New("snapshot checksum file not found")
+	ErrExists           = errors.New("snapshot already exists")
+)
+
+// Snapshot - резервная копия в каталоге
+type Snapshot struct {
+	Name       string    `json:"name"`
+	CreatedAt  time.Time `json:"created_at"`
+	Size       int64     `json:"size"`
+	Compressed bool      `json:"compressed"`
+	SHA256     string    `json:"sha256,omitempty"` // пусто - файл контрольной суммы не найден
+}
+
+// Backuper записывает согласованную копию БД в новый файл
+type Backuper interface {
+	BackupTo(ctx context.Context, path string) error
+}
+
+// Manager создает копии БД в каталоге и хранит заданное число последних копий
+type Manager struct {
+	src      Backuper
+	dir      string
+	keep     int
+	compress bool
+
+	// копии создаются по одной: по расписанию и по запросу администратора одновременно
+	mu  sync.Mutex
+	now func() time.Time
+}
+
+// New создает Manager. keep - сколько последних копий хранить (0 - все), compress - сжимать копии gzip
+func New(src Backuper, dir string, keep int, compress bool) *Manager {
+	return &Manager{
+		src:      src,
+		dir:      dir,
+		keep:     keep,
+		compress: compress,
+		now:      time.Now,
+	}
+}
+
+// Create создает копию БД, записывает ее контрольную сумму и удаляет лишние старые копии
+func (m *Manager) Create(ctx context.Context) (Snapshot, error) {
+	const op = "lib.snapshot.Create"
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := os.MkdirAll(m.dir, 0o750); err != nil {
+		return Snapshot{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	createdAt := m.now().UTC().Truncate(time.Second)
+	name := prefix + createdAt.Format(timeLayout) + extDB
+	if m.compress {
+		name += extGzip
+	}
+	path := filepath.Join(m.dir, name)
+
+	if _, err := os.Stat(path); err == nil {
+		return Snapshot{}, fmt.Errorf("%s: %w: %s", op, ErrExists, name)
+	}
+
+	raw := filepath.Join(m.dir, prefix+createdAt.Format(timeLayout)+extDB+tmpSuffix)
+	_ = os.Remove(raw) // остаток прерванной копии
+	if err := m.src.BackupTo(ctx, raw); err != nil {
+		return Snapshot{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() { _ = os.Remove(raw) }()
+
+	sum, size, err := writeSnapshot(raw, path, m.compress)
+	if err != nil {
+		_ = os.Remove(path)
+		return Snapshot{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	checksum := fmt.Sprintf("%s  %s\n", sum, name)
+	if err := os.WriteFile(path+extChecksum, []byte(checksum), 0o640); err != nil {
+		_ = os.Remove(path)
+		return Snapshot{}, fmt.Errorf("%s: write checksum: %w", op, err)
+	}
+
+	if err := m.rotate(); err != nil {
+		return Snapshot{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return Snapshot{
+		Name:       name,
+		CreatedAt:  createdAt,
+		Size:       size,
+		Compressed: m.compress,
+		SHA256:     sum,
+	}, nil
+}
+
+// writeSnapshot переносит копию raw в path (при compress - со сжатием)
+// и возвращает контрольную сумму и размер итогового файла
+func writeSnapshot(raw, path string, compress bool) (string, int64, error) {
+	in, err := os.Open(raw)
+	if err != nil {
+		return "", 0, err
+	}
+	defer func() { _ = in.Close() }()
+
+	out, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o640)
+	if err != nil {
+		return "", 0, err
+	}
+	defer func() { _ = out.Close() }()
+
+	hash := sha256.New()
+	counter := &countWriter{w: io.MultiWriter(out, hash)}
+
+	if compress {
+		zw := gzip.NewWriter(counter)
+		if _, err := io.Copy(zw, in); err != nil {
+			return "", 0, fmt.Errorf("compress: %w", err)
+		}
+		if err := zw.Close(); err != nil {
+			return "", 0, fmt.Errorf("compress: %w", err)
+		}
+	} else if _, err := io.Copy(counter, in); err != nil {
+		return "", 0, fmt.Errorf("copy: %w", err)
+	}
+
+	if err := out.Sync(); err != nil {
+		return "", 0, err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), counter.n, nil
+}
+
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// rotate удаляет самые старые копии сверх keep
+func (m *Manager) rotate() error {
+	if m.keep <= 0 {
+		return nil
+	}
+
+	snapshots, err := m.List()
+	if err != nil {
+		return err
+	}
+
+	// List возвращает копии от новых к старым
+	for _, s := range snapshots[min(m.keep, len(snapshots)):] {
+		path := filepath.Join(m.dir, s.Name)
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("remove old snapshot: %w", err)
+		}
+		_ = os.Remove(path + extChecksum)
+	}
+
+	return nil
+}
+
+// List возвращает копии в каталоге от новых к старым
+func (m *Manager) List() ([]Snapshot, error) {
+	const op = "lib.snapshot.List"
+
+	entries, err := os.ReadDir(m.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []Snapshot{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	snapshots := make([]Snapshot, 0, len(entries))
+	for _, entry := range entries {
+		createdAt, compressed, ok := parseName(entry.Name())
+		if !ok || !entry.Type().IsRegular() {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		sum, _ := readChecksum(filepath.Join(m.dir, entry.Name()))
+
+		snapshots = append(snapshots, Snapshot{
+			Name:       entry.Name(),
+			CreatedAt:  createdAt,
+			Size:       info.Size(),
+			Compressed: compressed,
+			SHA256:     sum,
+		})
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].CreatedAt.After(snapshots[j].CreatedAt)
+	})
+
+	return snapshots, nil
+}
+
+// Verify сверяет копию name с ее контрольной суммой
+func (m *Manager) Verify(name string) (Snapshot, error) {
+	const op = "lib.snapshot.Verify"
+
+	if _, _, ok := parseName(name); !ok || filepath.Base(name) != name {
+		return Snapshot{}, fmt.Errorf("%s: %w", op, ErrNotFound)
+	}
+
+	snapshots, err := m.List()
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	for _, s := range snapshots {
+		if s.Name != name {
+			continue
+		}
+
+		if err := VerifyFile(filepath.Join(m.dir, name)); err != nil {
+			return s, fmt.Errorf("%s: %w", op, err)
+		}
+
+		return s, nil
+	}
+
+	return Snapshot{}, fmt.Errorf("%s: %w", op, ErrNotFound)
+}
+
+// VerifyFile сверяет файл копии с контрольной суммой из файла <path>.sha256
+func VerifyFile(path string) error {
+	want, err := readChecksum(path)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return err
+	}
+
+	if got := hex.EncodeToString(hash.Sum(nil)); got != want {
+		return fmt.Errorf("%w: expected %s, got %s", ErrChecksumMismatch, want, got)
+	}
+
+	return nil
+}
+
+// Restore восстанавливает БД dest из копии path. Сервис на время восстановления должен быть остановлен.
+//   - контрольная сумма копии сверяется с файлом <path>.sha256 (verifyChecksum = false - не сверяется),
+//   - сжатая копия (.gz) распаковывается рядом с dest,
+//   - check проверяет распакованную БД (целостность, версию схемы) до замены dest,
+//   - текущая БД dest, если она есть, не удаляется, а переименовывается в <dest>.before-restore-<время>.
+//
+// Возвращает путь, куда перенесена прежняя БД (пусто, если ее не было)
+func Restore(path, dest string, verifyChecksum bool, check func(path string) error) (string, error) {
+	const op = "lib.snapshot.Restore"
+
+	if verifyChecksum {
+		if err := VerifyFile(path); err != nil {
+			return "", fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	tmp := dest + restoreSuffix
+	if err := unpack(path, tmp); err != nil {
+		_ = os.Remove(tmp)
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := check(tmp); err != nil {
+		_ = os.Remove(tmp)
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	var previous string
+	if _, err := os.Stat(dest); err == nil {
+		previous = dest + ".before-restore-" + time.Now().UTC().Format(timeLayout)
+		if err := os.Rename(dest, previous); err != nil {
+			_ = os.Remove(tmp)
+			return "", fmt.Errorf("%s: keep current database: %w", op, err)
+		}
+	}
+
+	if err := os.Rename(tmp, dest); err != nil {
+		return previous, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return previous, nil
+}
+
+// unpack копирует файл копии в dst, распаковывая .gz
+func unpack(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+
+	var r io.Reader = in
+	if strings.HasSuffix(src, extGzip) {
+		zr, err := gzip.NewReader(in)
+		if err != nil {
+			return fmt.Errorf("decompress: %w", err)
+		}
+		defer func() { _ = zr.Close() }()
+		r = zr
+	}
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o640)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = out.Close() }()
+
+	if _, err := io.Copy(out, r); err != nil {
+		return fmt.Errorf("unpack: %w", err)
+	}
+
+	return out.Sync()
+}
+
+// readChecksum читает контрольную сумму копии path из файла <path>.sha256
+func readChecksum(path string) (string, error) {
+	data, err := os.ReadFile(path + extChecksum)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", ErrNoChecksum
+	}
+	if err != nil {
+		return "", err
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("empty checksum file %s", path+extChecksum)
+	}
+
+	return fields[0], nil
+}
+
+// parseName разбирает имя файла копии: время создания и сжата ли копия
+func parseName(name string) (time.Time, bool, bool) {
+	rest, ok := strings.CutPrefix(name, prefix)
+	if !ok {
+		return time.Time{}, false, false
+	}
+
+	compressed := false
+	if r, ok := strings.CutSuffix(rest, extGzip); ok {
+		rest, compressed = r, true
+	}
+
+	rest, ok = strings.CutSuffix(rest, extDB)
+	if !ok {
+		return time.Time{}, false, false
+	}
+
+	createdAt, err := time.Parse(timeLayout, rest)
+	if err != nil {
+		return time.Time{}, false, false
+	}
+
+	return createdAt, compressed, true
+}
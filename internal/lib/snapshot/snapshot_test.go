@@ -0,0 +1,116 @@
+package snapshot_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/lib/snapshot"
+)
+
+const dbContent = "SQLite format 3\x00 test database"
+
+type fileBackuper struct{}
+
+func (fileBackuper) BackupTo(_ context.Context, path string) error {
+	return os.WriteFile(path, []byte(dbContent), 0o600)
+}
+
+func TestCreateRotateRestore(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		compress := compress
+
+		t.Run(map[bool]string{false: "Plain", true: "Gzip"}[compress], func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+
+			// Старые копии: после создания новой при keep=2 должна остаться только самая свежая из них
+			for _, name := range []string{"snapshot-20200101T000000Z.db", "snapshot-20210101T000000Z.db"} {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("old"), 0o600))
+			}
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), nil, 0o600))
+
+			m := snapshot.New(fileBackuper{}, dir, 2, compress)
+
+			created, err := m.Create(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, compress, created.Compressed)
+			require.NotEmpty(t, created.SHA256)
+
+			list, err := m.List()
+			require.NoError(t, err)
+			require.Len(t, list, 2)
+			require.Equal(t, created.Name, list[0].Name)
+			require.Equal(t, "snapshot-20210101T000000Z.db", list[1].Name)
+			require.Empty(t, list[1].SHA256)
+
+			_, err = os.Stat(filepath.Join(dir, "notes.txt"))
+			require.NoError(t, err)
+
+			_, err = m.Verify(created.Name)
+			require.NoError(t, err)
+
+			_, err = m.Verify("../" + created.Name)
+			require.ErrorIs(t, err, snapshot.ErrNotFound)
+
+			_, err = m.Verify(list[1].Name)
+			require.ErrorIs(t, err, snapshot.ErrNoChecksum)
+
+			dest := filepath.Join(t.TempDir(), "storage.db")
+			require.NoError(t, os.WriteFile(dest, []byte("current"), 0o600))
+
+			previous, err := snapshot.Restore(filepath.Join(dir, created.Name), dest, true, func(path string) error {
+				data, err := os.ReadFile(path)
+				require.NoError(t, err)
+				require.Equal(t, dbContent, string(data))
+				return nil
+			})
+			require.NoError(t, err)
+
+			data, err := os.ReadFile(dest)
+			require.NoError(t, err)
+			require.Equal(t, dbContent, string(data))
+
+			data, err = os.ReadFile(previous)
+			require.NoError(t, err)
+			require.Equal(t, "current", string(data))
+		})
+	}
+}
+
+func TestVerify_Corrupted(t *testing.T) {
+	dir := t.TempDir()
+	m := snapshot.New(fileBackuper{}, dir, 0, false)
+
+	created, err := m.Create(context.Background())
+	require.NoError(t, err)
+
+	path := filepath.Join(dir, created.Name)
+	require.NoError(t, os.WriteFile(path, []byte("corrupted"), 0o600))
+
+	_, err = m.Verify(created.Name)
+	require.ErrorIs(t, err, snapshot.ErrChecksumMismatch)
+
+	// Поврежденная копия не заменяет текущую БД
+	dest := filepath.Join(t.TempDir(), "storage.db")
+	require.NoError(t, os.WriteFile(dest, []byte("current"), 0o600))
+
+	_, err = snapshot.Restore(path, dest, true, func(string) error { return nil })
+	require.ErrorIs(t, err, snapshot.ErrChecksumMismatch)
+
+	// Отказ проверки схемы тоже
+	checkErr := errors.New("schema version is newer than supported")
+	_, err = snapshot.Restore(path, dest, false, func(string) error { return checkErr })
+	require.ErrorIs(t, err, checkErr)
+
+	data, err := os.ReadFile(dest)
+	require.NoError(t, err)
+	require.Equal(t, "current", string(data))
+	_, err = os.Stat(dest + ".restoring")
+	require.True(t, os.IsNotExist(err))
+}
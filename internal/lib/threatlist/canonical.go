@@ -0,0 +1,239 @@
+// internal/lib/threatlist/canonical.go
+package threatlist
+
+import (
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// Ограничения Safe Browsing на число вариантов хоста и пути
+const (
+	maxHostSuffixes = 4
+	maxPathPrefixes = 4
+)
+
+var errEmptyHost = errors.New("url has no host")
+
+// Expressions возвращает выражения "хост/путь" адреса rawURL, хеши которых ищутся в списке,
+// по правилам Safe Browsing: адрес канонизируется, затем комбинируются точный хост и до
+// четырех его суффиксов с точным путем (с запросом и без) и до четырех префиксов пути.
+// Первое выражение - точный адрес
+func Expressions(rawURL string) ([]string, error) {
+	host, path, query, err := canonicalize(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var exprs []string
+	seen := make(map[string]bool)
+	for _, h := range hostSuffixes(host) {
+		for _, p := range pathPrefixes(path, query) {
+			if expr := h + p; !seen[expr] {
+				seen[expr] = true
+				exprs = append(exprs, expr)
+			}
+		}
+	}
+
+	return exprs, nil
+}
+
+// canonicalize разбирает и канонизирует адрес: хост в нижнем регистре без лишних точек и порта,
+// путь без "." / ".." и повторных "/", экранирование только управляющих символов, не-ASCII, '#' и '%'
+func canonicalize(rawURL string) (host, path, query string, err error) {
+	// Работаем с байтами: адрес может быть не в UTF-8
+	s := strings.NewReplacer("\t", "", "\r", "", "\n", "").Replace(strings.TrimSpace(rawURL))
+
+	s, _, _ = strings.Cut(s, "#")
+	s = unescape(s)
+
+	if _, rest, ok := strings.Cut(s, "://"); ok {
+		s = rest
+	}
+
+	authority, rest := s, ""
+	if i := strings.IndexAny(s, "/?"); i >= 0 {
+		authority, rest = s[:i], s[i:]
+	}
+	path, query, hasQuery := strings.Cut(rest, "?")
+
+	host = canonicalHost(authority)
+	if host == "" {
+		return "", "", "", errEmptyHost
+	}
+
+	path = escape(canonicalPath(path))
+	if hasQuery {
+		query = "?" + escape(query)
+	}
+
+	return escape(host), path, query, nil
+}
+
+func canonicalHost(authority string) string {
+	if i := strings.LastIndexByte(authority, '@'); i >= 0 {
+		authority = authority[i+1:]
+	}
+	if i := strings.LastIndexByte(authority, ':'); i >= 0 && !strings.Contains(authority[i:], "]") {
+		authority = authority[:i]
+	}
+
+	host := strings.Trim(asciiLower(authority), ".")
+	for strings.Contains(host, "..") {
+		host = strings.ReplaceAll(host, "..", ".")
+	}
+
+	// IPv4 в виде одного числа ("3279880203") приводится к виду с точками
+	if n, err := strconv.ParseUint(host, 10, 32); err == nil {
+		return net.IPv4(byte(n>>24), byte(n>>16), byte(n>>8), byte(n)).String()
+	}
+
+	return host
+}
+
+func canonicalPath(path string) string {
+	if path == "" {
+		return "/"
+	}
+
+	var segments []string
+	for _, seg := range strings.Split(path, "/") {
+		switch seg {
+		case "", ".":
+		case "..":
+			if len(segments) > 0 {
+				segments = segments[:len(segments)-1]
+			}
+		default:
+			segments = append(segments, seg)
+		}
+	}
+
+	res := "/" + strings.Join(segments, "/")
+	last := path[strings.LastIndexByte(path, '/')+1:]
+	if len(segments) > 0 && (last == "" || last == "." || last == "..") {
+		res += "/"
+	}
+
+	return res
+}
+
+// hostSuffixes - точный хост и до четырех суффиксов из последних пяти компонентов (без домена верхнего уровня)
+func hostSuffixes(host string) []string {
+	hosts := []string{host}
+	if net.ParseIP(host) != nil {
+		return hosts
+	}
+
+	parts := strings.Split(host, ".")
+	start := len(parts) - maxHostSuffixes - 1
+	if start < 1 {
+		start = 1
+	}
+	for i := start; i < len(parts)-1; i++ {
+		hosts = append(hosts, strings.Join(parts[i:], "."))
+	}
+
+	return hosts
+}
+
+// pathPrefixes - точный путь с запросом и без, и до четырех префиксов пути от корня
+func pathPrefixes(path, query string) []string {
+	var paths []string
+	if query != "" {
+		paths = append(paths, path+query)
+	}
+	paths = append(paths, path)
+
+	dirs := strings.Split(strings.Trim(path, "/"), "/")
+	if !strings.HasSuffix(path, "/") {
+		dirs = dirs[:len(dirs)-1] // последний компонент - не каталог
+	}
+
+	prefix := "/"
+	for i := 0; i < maxPathPrefixes; i++ {
+		if prefix != path {
+			paths = append(paths, prefix)
+		}
+		if i >= len(dirs) || dirs[i] == "" {
+			break
+		}
+		prefix += dirs[i] + "/"
+	}
+
+	return paths
+}
+
+// unescape повторно раскодирует %XX, пока в строке есть что раскодировать.
+// Некорректные последовательности остаются как есть
+func unescape(s string) string {
+	for {
+		next := unescapeOnce(s)
+		if next == s {
+			return s
+		}
+		s = next
+	}
+}
+
+func unescapeOnce(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '%' && i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]) {
+			b.WriteByte(unhex(s[i+1])<<4 | unhex(s[i+2]))
+			i += 2
+
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+
+	return b.String()
+}
+
+func escape(s string) string {
+	const hexDigits = "0123456789ABCDEF"
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c <= 0x20 || c >= 0x7f || c == '#' || c == '%' {
+			b.WriteByte('%')
+			b.WriteByte(hexDigits[c>>4])
+			b.WriteByte(hexDigits[c&0xf])
+
+			continue
+		}
+		b.WriteByte(c)
+	}
+
+	return b.String()
+}
+
+func asciiLower(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if 'A' <= c && c <= 'Z' {
+			b[i] = c + 'a' - 'A'
+		}
+	}
+
+	return string(b)
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
@@ -0,0 +1,75 @@
+// internal/lib/threatlist/file.go
+package threatlist
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// File - список из файла, который перечитывается при изменении (см. Reload)
+type File struct {
+	path string
+
+	mu      sync.RWMutex
+	list    *List
+	modTime time.Time
+	size    int64
+}
+
+// Open загружает список из файла path
+func Open(path string) (*File, error) {
+	f := &File{path: path}
+	if _, err := f.Reload(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// Reload перечитывает файл, если он изменился с прошлой загрузки. Возвращает true, если список обновлен.
+// При ошибке продолжает действовать прежний список
+func (f *File) Reload() (bool, error) {
+	const op = "lib.threatlist.Reload"
+
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	f.mu.RLock()
+	unchanged := f.list != nil && info.ModTime().Equal(f.modTime) && info.Size() == f.size
+	f.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	list, err := Load(f.path)
+	if err != nil {
+		return false, err
+	}
+
+	f.mu.Lock()
+	f.list, f.modTime, f.size = list, info.ModTime(), info.Size()
+	f.mu.Unlock()
+
+	return true, nil
+}
+
+// Len - число записей в текущем списке
+func (f *File) Len() int {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return f.list.Len()
+}
+
+// Match проверяет адреса по текущему списку, см. List.Match
+func (f *File) Match(urls ...string) (string, bool) {
+	f.mu.RLock()
+	list := f.list
+	f.mu.RUnlock()
+
+	return list.Match(urls...)
+}
@@ -0,0 +1,240 @@
+// internal/lib/threatlist/threatlist.go
+
+// Package threatlist - локальный список опасных адресов (вредоносное ПО, фишинг) в формате
+// префиксов хешей Safe Browsing. Адрес канонизируется, для его вариантов "хост/путь"
+// считается SHA-256, и адрес считается опасным, если начало хеша есть в списке.
+//
+// Поддерживаются два формата файла:
+//   - ответ Safe Browsing API v4 threatListUpdates:fetch (JSON) с полными обновлениями
+//     и несжатыми хешами (compressionType RAW);
+//   - текстовый, для списков, которые ведутся вручную: строки "ТИП ЗНАЧЕНИЕ", где значение -
+//     префикс хеша в hex (4-32 байта) или выражение "хост/путь" (например evil.example/login/).
+//     Пустые строки и строки, начинающиеся с #, пропускаются
+package threatlist
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Типы угроз Safe Browsing
+const (
+	Malware           = "MALWARE"
+	SocialEngineering = "SOCIAL_ENGINEERING" // фишинг
+	UnwantedSoftware  = "UNWANTED_SOFTWARE"
+)
+
+const (
+	minPrefixSize = 4
+	maxPrefixSize = sha256.Size
+)
+
+var (
+	ErrUnsupportedUpdate = errors.New("only full updates with raw hashes are supported")
+	ErrInvalidPrefix     = errors.New("invalid hash prefix")
+	// ErrListed - адрес перехода есть в списке (ответ клиенту при изменении ссылки)
+	ErrListed = errors.New("target url is in the threat list")
+)
+
+// List - загруженный список. Безопасен для одновременного чтения
+type List struct {
+	prefixes map[string]string // префикс хеша -> тип угрозы
+	sizes    []int             // длины префиксов, которые есть в списке
+}
+
+// Load читает список из файла path
+func Load(path string) (*List, error) {
+	const op = "lib.threatlist.Load"
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	list, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s: %w", op, path, err)
+	}
+
+	return list, nil
+}
+
+// Parse читает список в одном из поддерживаемых форматов: JSON определяется по первому символу
+func Parse(r io.Reader) (*List, error) {
+	br := bufio.NewReader(r)
+
+	list := &List{prefixes: make(map[string]string)}
+
+	for {
+		c, err := br.Peek(1)
+		if err != nil {
+			// пустой файл - пустой список
+			return list, nil
+		}
+		if !bytes.ContainsAny(c, " \t\r\n") {
+			if c[0] == '{' {
+				return list, list.parseUpdate(br)
+			}
+
+			return list, list.parseText(br)
+		}
+		_, _ = br.ReadByte()
+	}
+}
+
+// Len - число записей в списке
+func (l *List) Len() int {
+	return len(l.prefixes)
+}
+
+// Match проверяет адреса и возвращает тип угрозы первого опасного из них.
+// Адреса, которые не удалось разобрать, пропускаются
+func (l *List) Match(urls ...string) (string, bool) {
+	if len(l.prefixes) == 0 {
+		return "", false
+	}
+
+	for _, rawURL := range urls {
+		exprs, err := Expressions(rawURL)
+		if err != nil {
+			continue
+		}
+
+		for _, expr := range exprs {
+			sum := sha256.Sum256([]byte(expr))
+			for _, size := range l.sizes {
+				if threat, ok := l.prefixes[string(sum[:size])]; ok {
+					return threat, true
+				}
+			}
+		}
+	}
+
+	return "", false
+}
+
+func (l *List) add(prefix []byte, threat string) error {
+	if len(prefix) < minPrefixSize || len(prefix) > maxPrefixSize {
+		return fmt.Errorf("%w: %d bytes", ErrInvalidPrefix, len(prefix))
+	}
+
+	if _, ok := l.prefixes[string(prefix)]; !ok {
+		l.prefixes[string(prefix)] = threat
+	}
+
+	i := sort.SearchInts(l.sizes, len(prefix))
+	if i == len(l.sizes) || l.sizes[i] != len(prefix) {
+		l.sizes = append(l.sizes, 0)
+		copy(l.sizes[i+1:], l.sizes[i:])
+		l.sizes[i] = len(prefix)
+	}
+
+	return nil
+}
+
+// update - ответ threatListUpdates:fetch, только нужные поля
+type update struct {
+	ListUpdateResponses []struct {
+		ThreatType   string `json:"threatType"`
+		ResponseType string `json:"responseType"`
+		Additions    []struct {
+			CompressionType string `json:"compressionType"`
+			RawHashes       *struct {
+				PrefixSize int    `json:"prefixSize"`
+				RawHashes  string `json:"rawHashes"` // base64, префиксы подряд
+			} `json:"rawHashes"`
+		} `json:"additions"`
+		Removals []json.RawMessage `json:"removals"`
+	} `json:"listUpdateResponses"`
+}
+
+func (l *List) parseUpdate(r io.Reader) error {
+	var upd update
+	if err := json.NewDecoder(r).Decode(&upd); err != nil {
+		return fmt.Errorf("decode update: %w", err)
+	}
+
+	for _, res := range upd.ListUpdateResponses {
+		// частичное обновление меняет предыдущее состояние списка, которого у нас нет
+		if res.ResponseType == "PARTIAL_UPDATE" || len(res.Removals) > 0 {
+			return ErrUnsupportedUpdate
+		}
+
+		for _, add := range res.Additions {
+			if add.CompressionType != "" && add.CompressionType != "RAW" || add.RawHashes == nil {
+				return ErrUnsupportedUpdate
+			}
+
+			raw, err := base64.StdEncoding.DecodeString(add.RawHashes.RawHashes)
+			if err != nil {
+				return fmt.Errorf("decode raw hashes: %w", err)
+			}
+
+			size := add.RawHashes.PrefixSize
+			if size < minPrefixSize || size > maxPrefixSize || len(raw)%size != 0 {
+				return fmt.Errorf("%w: prefix size %d", ErrInvalidPrefix, size)
+			}
+
+			for i := 0; i < len(raw); i += size {
+				if err := l.add(raw[i:i+size], res.ThreatType); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (l *List) parseText(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		threat, value, ok := strings.Cut(line, " ")
+		value = strings.TrimSpace(value)
+		if !ok || value == "" {
+			return fmt.Errorf("line %d: expected \"TYPE VALUE\"", n)
+		}
+
+		prefix, err := parseValue(value)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", n, err)
+		}
+		if err := l.add(prefix, threat); err != nil {
+			return fmt.Errorf("line %d: %w", n, err)
+		}
+	}
+
+	return scanner.Err()
+}
+
+// parseValue - префикс хеша в hex или полный хеш выражения "хост/путь"
+func parseValue(value string) ([]byte, error) {
+	if prefix, err := hex.DecodeString(value); err == nil {
+		return prefix, nil
+	}
+
+	exprs, err := Expressions(value)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256([]byte(exprs[0]))
+
+	return sum[:], nil
+}
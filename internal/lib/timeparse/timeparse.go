@@ -0,0 +1,90 @@
+// internal/lib/timeparse/timeparse.go
+
+// Разбор моментов времени, введенных пользователем, с учетом часового пояса.
+package timeparse
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	// встраиваем базу часовых поясов, чтобы не зависеть от tzdata на сервере
+	_ "time/tzdata"
+)
+
+var (
+	ErrInvalidTime     = errors.New("invalid time format")
+	ErrUnknownTimezone = errors.New("unknown timezone")
+	ErrInvalidWindow   = errors.New("not_after must be later than not_before")
+)
+
+// Форматы без указания смещения. Время в них трактуется в переданном часовом поясе
+var localLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// Parse разбирает момент времени.
+// Если значение содержит смещение (RFC 3339, например "2024-05-01T10:00:00+03:00"),
+// оно имеет приоритет, и timezone игнорируется.
+// Иначе время трактуется в часовом поясе timezone (имя IANA, например "Europe/Moscow"),
+// а при пустом timezone - в UTC.
+// Текст ошибок предназначен для пользователя, поэтому в него не добавляется имя функции
+func Parse(value string, timezone string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	loc := time.UTC
+	if timezone != "" {
+		l, err := time.LoadLocation(timezone)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%w: %s", ErrUnknownTimezone, timezone)
+		}
+		loc = l
+	}
+
+	for _, layout := range localLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%w: %s", ErrInvalidTime, value)
+}
+
+// ParseOptional - то же, что Parse, но пустая строка дает nil
+func ParseOptional(value string, timezone string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := Parse(value, timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+// ParseWindow разбирает границы окна активности. Любая из границ может быть пустой
+func ParseWindow(notBefore, notAfter, timezone string) (*time.Time, *time.Time, error) {
+	from, err := ParseOptional(notBefore, timezone)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	to, err := ParseOptional(notAfter, timezone)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if from != nil && to != nil && !to.After(*from) {
+		return nil, nil, ErrInvalidWindow
+	}
+
+	return from, to, nil
+}
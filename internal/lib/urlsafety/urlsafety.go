@@ -0,0 +1,58 @@
+// internal/lib/urlsafety/urlsafety.go
+
+// Эвристические предупреждения об адресе перехода для страницы предпросмотра.
+// Это не проверка на вредоносность, а подсказки пользователю, на что обратить внимание.
+package urlsafety
+
+import (
+	"net"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// Расширения файлов, переход по которым сразу запускает скачивание программы
+var executableExts = map[string]bool{
+	".exe": true, ".msi": true, ".scr": true, ".bat": true, ".cmd": true,
+	".apk": true, ".dmg": true, ".pkg": true, ".jar": true, ".ps1": true,
+}
+
+// Warnings возвращает список предупреждений об адресе. Пустой список - замечаний нет
+func Warnings(rawURL string) []string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return []string{"the address could not be parsed"}
+	}
+
+	var warnings []string
+
+	if u.Scheme != "https" {
+		warnings = append(warnings, "the connection to this site is not encrypted")
+	}
+
+	if u.User != nil {
+		warnings = append(warnings, "the address contains embedded credentials, which is a common phishing trick")
+	}
+
+	host := u.Hostname()
+	if net.ParseIP(host) != nil {
+		warnings = append(warnings, "the address points to a bare IP address instead of a domain name")
+	}
+
+	for _, label := range strings.Split(strings.ToLower(host), ".") {
+		if strings.HasPrefix(label, "xn--") {
+			warnings = append(warnings, "the domain name contains international characters and may imitate another site")
+			break
+		}
+	}
+
+	if port := u.Port(); port != "" && port != "80" && port != "443" {
+		warnings = append(warnings, "the address uses a non-standard port "+port)
+	}
+
+	if executableExts[strings.ToLower(path.Ext(u.Path))] {
+		warnings = append(warnings, "the link downloads an executable file")
+	}
+
+	return warnings
+}
@@ -0,0 +1,30 @@
+// internal/lib/useragent/useragent.go
+
+// Грубое определение платформы клиента по заголовку User-Agent.
+// Для правил редиректа достаточно отличать iOS, Android и все остальное.
+package useragent
+
+import "strings"
+
+const (
+	PlatformIOS     = "ios"
+	PlatformAndroid = "android"
+	PlatformDesktop = "desktop"
+)
+
+// Platform возвращает платформу клиента: ios, android или desktop.
+// Все, что не удалось отнести к мобильным платформам, считается desktop
+func Platform(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+
+	switch {
+	case strings.Contains(ua, "iphone"),
+		strings.Contains(ua, "ipad"),
+		strings.Contains(ua, "ipod"):
+		return PlatformIOS
+	case strings.Contains(ua, "android"):
+		return PlatformAndroid
+	default:
+		return PlatformDesktop
+	}
+}
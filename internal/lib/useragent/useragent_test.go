@@ -0,0 +1,51 @@
+package useragent_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/lib/useragent"
+)
+
+func TestPlatform(t *testing.T) {
+	cases := []struct {
+		userAgent string
+		platform  string
+	}{
+		{
+			userAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 Mobile/15E148",
+			platform:  useragent.PlatformIOS,
+		},
+		{
+			userAgent: "Mozilla/5.0 (iPad; CPU OS 16_6 like Mac OS X) AppleWebKit/605.1.15 Mobile/15E148",
+			platform:  useragent.PlatformIOS,
+		},
+		{
+			userAgent: "Mozilla/5.0 (iPod touch; CPU iPhone OS 12_5 like Mac OS X)",
+			platform:  useragent.PlatformIOS,
+		},
+		{
+			userAgent: "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 Chrome/120.0 Mobile Safari/537.36",
+			platform:  useragent.PlatformAndroid,
+		},
+		{
+			userAgent: "Dalvik/2.1.0 (Linux; U; ANDROID 13; SM-S911B)",
+			platform:  useragent.PlatformAndroid,
+		},
+		{
+			userAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/120.0 Safari/537.36",
+			platform:  useragent.PlatformDesktop,
+		},
+		{
+			userAgent: "Mozilla/5.0 (Macintosh; Intel Mac OS X 14_0) AppleWebKit/605.1.15 Safari/605.1.15",
+			platform:  useragent.PlatformDesktop,
+		},
+		{userAgent: "curl/8.4.0", platform: useragent.PlatformDesktop},
+		{userAgent: "", platform: useragent.PlatformDesktop},
+	}
+
+	for _, tc := range cases {
+		require.Equal(t, tc.platform, useragent.Platform(tc.userAgent), tc.userAgent)
+	}
+}
@@ -0,0 +1,85 @@
+// internal/lib/utm/utm.go
+
+// Формирование итогового адреса перехода: перенос параметров запроса
+// короткой ссылки и добавление UTM-меток по умолчанию.
+package utm
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// Политики переноса параметров запроса короткой ссылки в адрес перехода
+const (
+	PolicyDrop     = "drop"     // параметры короткой ссылки отбрасываются
+	PolicyMerge    = "merge"    // добавляются только параметры, которых нет в адресе перехода
+	PolicyOverride = "override" // параметры короткой ссылки заменяют одноименные параметры адреса
+)
+
+// ValidPolicy проверяет название политики. Пустая политика допустима и означает PolicyDrop
+func ValidPolicy(policy string) bool {
+	switch policy {
+	case "", PolicyDrop, PolicyMerge, PolicyOverride:
+		return true
+	default:
+		return false
+	}
+}
+
+// Tags - UTM-метки по умолчанию
+type Tags struct {
+	Source   string
+	Medium   string
+	Campaign string
+}
+
+// Apply возвращает адрес target, дополненный параметрами.
+// Сначала параметры запроса короткой ссылки переносятся согласно policy
+// (пустая политика равносильна PolicyDrop), затем добавляются UTM-метки,
+// которых еще нет в адресе. Явно заданные метки никогда не перезаписываются метками по умолчанию
+func Apply(target string, tags Tags, incoming url.Values, policy string) (string, error) {
+	const op = "lib.utm.Apply"
+
+	if tags == (Tags{}) && (len(incoming) == 0 || policy == "" || policy == PolicyDrop) {
+		// нечего добавлять - не трогаем адрес, чтобы не менять порядок его параметров
+		return target, nil
+	}
+
+	u, err := url.Parse(target)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	query := u.Query()
+
+	switch policy {
+	case PolicyMerge:
+		for key, values := range incoming {
+			if !query.Has(key) {
+				query[key] = values
+			}
+		}
+	case PolicyOverride:
+		for key, values := range incoming {
+			query[key] = values
+		}
+	default:
+		if !ValidPolicy(policy) {
+			return "", fmt.Errorf("%s: unknown query policy %q", op, policy)
+		}
+	}
+
+	setDefault(query, "utm_source", tags.Source)
+	setDefault(query, "utm_medium", tags.Medium)
+	setDefault(query, "utm_campaign", tags.Campaign)
+
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+func setDefault(query url.Values, key, value string) {
+	if value != "" && query.Get(key) == "" {
+		query.Set(key, value)
+	}
+}
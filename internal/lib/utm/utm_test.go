@@ -0,0 +1,85 @@
+package utm_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/lib/utm"
+)
+
+func TestApply(t *testing.T) {
+	cases := []struct {
+		name     string
+		target   string
+		tags     utm.Tags
+		incoming string
+		policy   string
+		expected string
+	}{
+		{
+			name:     "Nothing to add",
+			target:   "https://example.com/?b=2&a=1",
+			incoming: "x=1",
+			expected: "https://example.com/?b=2&a=1",
+		},
+		{
+			name:     "UTM defaults",
+			target:   "https://example.com/page",
+			tags:     utm.Tags{Source: "newsletter", Medium: "email", Campaign: "spring"},
+			expected: "https://example.com/page?utm_campaign=spring&utm_medium=email&utm_source=newsletter",
+		},
+		{
+			name:     "Explicit UTM wins",
+			target:   "https://example.com/?utm_source=twitter",
+			tags:     utm.Tags{Source: "newsletter"},
+			expected: "https://example.com/?utm_source=twitter",
+		},
+		{
+			name:     "Merge keeps target values",
+			target:   "https://example.com/?a=1",
+			incoming: "a=2&b=3",
+			policy:   utm.PolicyMerge,
+			expected: "https://example.com/?a=1&b=3",
+		},
+		{
+			name:     "Override replaces target values",
+			target:   "https://example.com/?a=1",
+			incoming: "a=2&b=3",
+			policy:   utm.PolicyOverride,
+			expected: "https://example.com/?a=2&b=3",
+		},
+		{
+			name:     "Incoming UTM beats defaults",
+			target:   "https://example.com/",
+			tags:     utm.Tags{Source: "newsletter"},
+			incoming: "utm_source=partner",
+			policy:   utm.PolicyMerge,
+			expected: "https://example.com/?utm_source=partner",
+		},
+		{
+			name:     "Drop ignores incoming",
+			target:   "https://example.com/",
+			tags:     utm.Tags{Medium: "qr"},
+			incoming: "a=1",
+			policy:   utm.PolicyDrop,
+			expected: "https://example.com/?utm_medium=qr",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			incoming, err := url.ParseQuery(tc.incoming)
+			require.NoError(t, err)
+
+			res, err := utm.Apply(tc.target, tc.tags, incoming, tc.policy)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, res)
+		})
+	}
+}
@@ -0,0 +1,132 @@
+// internal/storage/sqlite/aliases.go
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+
+	"url-shortener/internal/storage"
+)
+
+// ReserveAlias - зарезервировать алиас. Если алиас уже занят ссылкой (в том числе удаленной,
+// но еще не очищенной) - storage.ErrURLExists, если уже зарезервирован - storage.ErrAliasReserved
+func (s *Storage) ReserveAlias(reserved storage.ReservedAlias) error {
+	const op = "storage.sqlite.ReserveAlias"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var id int64
+	err = tx.QueryRow("SELECT id FROM url WHERE domain = ? AND alias_key = ?", reserved.Domain, s.match.Key(reserved.Alias)).Scan(&id)
+	if err == nil {
+		return fmt.Errorf("%s: %w", op, storage.ErrURLExists)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%s: find url: %w", op, err)
+	}
+
+	_, err = tx.Exec(
+		"INSERT INTO reserved_alias(domain, alias, note, reserved_by, created_at) VALUES (?, ?, ?, ?, ?)",
+		reserved.Domain, reserved.Alias, reserved.Note, reserved.ReservedBy, time.Now().Unix(),
+	)
+	if err != nil {
+		if sqliteErr, ok := err.(sqlite3.Error); ok && sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey {
+			return fmt.Errorf("%s: %w", op, storage.ErrAliasReserved)
+		}
+
+		return fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+
+	return nil
+}
+
+// IsAliasReserved - зарезервирован ли алиас администратором.
+// Алиасы сравниваются в текущем режиме сравнения (см. SetAliasMatch)
+func (s *Storage) IsAliasReserved(domain string, alias string) (bool, error) {
+	const op = "storage.sqlite.IsAliasReserved"
+
+	// Резервирований немного, поэтому ключи сравниваем здесь, а не храним в таблице
+	rows, err := s.db.Query("SELECT alias FROM reserved_alias WHERE domain = ?", domain)
+	if err != nil {
+		return false, fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	key := s.match.Key(alias)
+	for rows.Next() {
+		var reserved string
+		if err := rows.Scan(&reserved); err != nil {
+			return false, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		if s.match.Key(reserved) == key {
+			return true, nil
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return false, nil
+}
+
+// ListReservedAliases - список зарезервированных алиасов
+func (s *Storage) ListReservedAliases() ([]storage.ReservedAlias, error) {
+	const op = "storage.sqlite.ListReservedAliases"
+
+	rows, err := s.db.Query("SELECT domain, alias, note, reserved_by, created_at FROM reserved_alias ORDER BY domain, alias")
+	if err != nil {
+		return nil, fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	aliases := make([]storage.ReservedAlias, 0)
+	for rows.Next() {
+		var (
+			a         storage.ReservedAlias
+			createdAt int64
+		)
+		if err := rows.Scan(&a.Domain, &a.Alias, &a.Note, &a.ReservedBy, &createdAt); err != nil {
+			return nil, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		a.CreatedAt = time.Unix(createdAt, 0).UTC()
+		aliases = append(aliases, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return aliases, nil
+}
+
+// ReleaseAlias - снять резервирование алиаса
+func (s *Storage) ReleaseAlias(domain string, alias string) error {
+	const op = "storage.sqlite.ReleaseAlias"
+
+	res, err := s.db.Exec("DELETE FROM reserved_alias WHERE domain = ? AND alias = ?", domain, alias)
+	if err != nil {
+		return fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		return storage.ErrReservationNotFound
+	}
+
+	return nil
+}
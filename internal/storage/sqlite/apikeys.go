@@ -0,0 +1,135 @@
+// internal/storage/sqlite/apikeys.go
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"url-shortener/internal/storage"
+)
+
+const apiKeyColumns = "id, uid, name, prefix, scopes, created_at, last_used_at, expires_at, revoked_at"
+
+func scanAPIKey(row rowScanner) (storage.APIKey, error) {
+	var (
+		key                              storage.APIKey
+		scopes                           string
+		createdAt                        int64
+		lastUsedAt, expiresAt, revokedAt sql.NullInt64
+	)
+
+	err := row.Scan(&key.ID, &key.UID, &key.Name, &key.Prefix, &scopes,
+		&createdAt, &lastUsedAt, &expiresAt, &revokedAt)
+	if err != nil {
+		return storage.APIKey{}, err
+	}
+
+	// scopes хранятся одной строкой через пробел
+	key.Scopes = strings.Fields(scopes)
+	key.CreatedAt = time.Unix(createdAt, 0).UTC()
+	key.LastUsedAt = fromUnix(lastUsedAt)
+	key.ExpiresAt = fromUnix(expiresAt)
+	key.RevokedAt = fromUnix(revokedAt)
+
+	return key, nil
+}
+
+// SaveAPIKey - сохранить новый API-ключ по его хешу
+func (s *Storage) SaveAPIKey(key storage.APIKey, keyHash string) (int64, error) {
+	const op = "storage.sqlite.SaveAPIKey"
+
+	res, err := s.db.Exec(
+		`INSERT INTO api_key(uid, name, prefix, key_hash, scopes, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		key.UID, key.Name, key.Prefix, keyHash, strings.Join(key.Scopes, " "),
+		key.CreatedAt.Unix(), toUnix(key.ExpiresAt),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("%s: failed to get last insert id: %w", op, err)
+	}
+
+	return id, nil
+}
+
+// GetAPIKeyByHash - найти ключ по хешу (в том числе отозванный или истекший)
+func (s *Storage) GetAPIKeyByHash(keyHash string) (storage.APIKey, error) {
+	const op = "storage.sqlite.GetAPIKeyByHash"
+
+	key, err := scanAPIKey(s.db.QueryRow("SELECT "+apiKeyColumns+" FROM api_key WHERE key_hash = ?", keyHash))
+	if errors.Is(err, sql.ErrNoRows) {
+		return storage.APIKey{}, storage.ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return storage.APIKey{}, fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	return key, nil
+}
+
+// ListAPIKeys - ключи пользователя, включая отозванные
+func (s *Storage) ListAPIKeys(uid int64) ([]storage.APIKey, error) {
+	const op = "storage.sqlite.ListAPIKeys"
+
+	rows, err := s.db.Query("SELECT "+apiKeyColumns+" FROM api_key WHERE uid = ? ORDER BY id", uid)
+	if err != nil {
+		return nil, fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	keys := make([]storage.APIKey, 0)
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return keys, nil
+}
+
+// RevokeAPIKey - отозвать ключ пользователя. Повторный отзыв не меняет время отзыва
+func (s *Storage) RevokeAPIKey(uid int64, id int64, at time.Time) error {
+	const op = "storage.sqlite.RevokeAPIKey"
+
+	res, err := s.db.Exec(
+		"UPDATE api_key SET revoked_at = COALESCE(revoked_at, ?) WHERE id = ? AND uid = ?",
+		at.Unix(), id, uid,
+	)
+	if err != nil {
+		return fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if affected == 0 {
+		return storage.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+// TouchAPIKey - запомнить время последнего использования ключа
+func (s *Storage) TouchAPIKey(id int64, at time.Time) error {
+	const op = "storage.sqlite.TouchAPIKey"
+
+	if _, err := s.db.Exec("UPDATE api_key SET last_used_at = ? WHERE id = ?", at.Unix(), id); err != nil {
+		return fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,177 @@
+// internal/storage/sqlite/audit.go
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"url-shortener/internal/storage"
+)
+
+// writeAudit добавляет запись в журнал аудита в транзакции изменения.
+// before и after сохраняются в JSON, nil - значения нет
+func writeAudit(tx *sql.Tx, actor storage.Actor, action, domain, alias string, before, after any) error {
+	beforeJSON, err := auditValue(before)
+	if err != nil {
+		return fmt.Errorf("audit: marshal before: %w", err)
+	}
+	afterJSON, err := auditValue(after)
+	if err != nil {
+		return fmt.Errorf("audit: marshal after: %w", err)
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO audit_log(
+			created_at, actor_uid, actor_key_id, actor_name, request_id,
+			action, domain, alias, before_value, after_value
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		time.Now().UnixMilli(), actor.UID, actor.KeyID, actor.Name, actor.RequestID,
+		action, domain, alias, beforeJSON, afterJSON,
+	)
+	if err != nil {
+		return fmt.Errorf("audit: %w", err)
+	}
+
+	return nil
+}
+
+func auditValue(v any) (sql.NullString, error) {
+	if v == nil {
+		return sql.NullString{}, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+// linkSnapshot читает ссылку по ключу алиаса (см. aliaspolicy.MatchMode.Key) в транзакции изменения
+// вместе с правилами и вариантами - состояние для журнала аудита
+func linkSnapshot(tx *sql.Tx, domain, key string) (storage.Link, error) {
+	link, err := scanLink(tx.QueryRow("SELECT "+linkColumns+" FROM url WHERE domain = ? AND alias_key = ?", domain, key))
+	if err != nil {
+		return storage.Link{}, err
+	}
+
+	if link.Rules, err = rulesByURLID(tx, link.ID); err != nil {
+		return storage.Link{}, err
+	}
+	if link.Targets, err = targetsByURLID(tx, link.ID); err != nil {
+		return storage.Link{}, err
+	}
+
+	return link, nil
+}
+
+// ListAudit - записи журнала аудита, от новых к старым
+func (s *Storage) ListAudit(filter storage.AuditFilter) ([]storage.AuditEntry, error) {
+	const op = "storage.sqlite.ListAudit"
+
+	entries := make([]storage.AuditEntry, 0)
+	err := s.ExportAudit(filter, func(entry storage.AuditEntry) error {
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return entries, nil
+}
+
+// ExportAudit передает записи журнала аудита в fn по одной, от новых к старым,
+// не загружая всю выборку в память. Ошибка fn прерывает выборку
+func (s *Storage) ExportAudit(filter storage.AuditFilter, fn func(storage.AuditEntry) error) error {
+	const op = "storage.sqlite.ExportAudit"
+
+	var (
+		where []string
+		args  []any
+	)
+
+	if filter.Domain != "" {
+		where = append(where, "domain = ?")
+		args = append(args, filter.Domain)
+	}
+	if filter.Alias != "" {
+		where = append(where, "alias = ?")
+		args = append(args, filter.Alias)
+	}
+	if filter.Action != "" {
+		where = append(where, "action = ?")
+		args = append(args, filter.Action)
+	}
+	if filter.ActorUID != 0 {
+		where = append(where, "actor_uid = ?")
+		args = append(args, filter.ActorUID)
+	}
+	if filter.Since != nil {
+		where = append(where, "created_at >= ?")
+		args = append(args, filter.Since.UnixMilli())
+	}
+	if filter.Until != nil {
+		where = append(where, "created_at < ?")
+		args = append(args, filter.Until.UnixMilli())
+	}
+	if filter.BeforeID != 0 {
+		where = append(where, "id < ?")
+		args = append(args, filter.BeforeID)
+	}
+
+	query := `SELECT id, created_at, actor_uid, actor_key_id, actor_name, request_id,
+		action, domain, alias, before_value, after_value FROM audit_log`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY id DESC"
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var (
+			entry         storage.AuditEntry
+			createdAt     int64
+			before, after sql.NullString
+		)
+
+		err := rows.Scan(
+			&entry.ID, &createdAt, &entry.ActorUID, &entry.ActorKeyID, &entry.ActorName, &entry.RequestID,
+			&entry.Action, &entry.Domain, &entry.Alias, &before, &after,
+		)
+		if err != nil {
+			return fmt.Errorf("%s: scan row: %w", op, err)
+		}
+
+		entry.CreatedAt = time.UnixMilli(createdAt).UTC()
+		if before.Valid {
+			entry.Before = json.RawMessage(before.String)
+		}
+		if after.Valid {
+			entry.After = json.RawMessage(after.String)
+		}
+
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+// internal/storage/sqlite/domains.go
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+
+	"url-shortener/internal/storage"
+)
+
+const domainColumns = "id, host, verify_token, verified_at, default_url, created_at"
+
+func scanDomain(row rowScanner) (storage.Domain, error) {
+	var (
+		d          storage.Domain
+		verifiedAt sql.NullInt64
+		createdAt  int64
+	)
+
+	if err := row.Scan(&d.ID, &d.Host, &d.VerifyToken, &verifiedAt, &d.DefaultURL, &createdAt); err != nil {
+		return storage.Domain{}, err
+	}
+
+	d.VerifiedAt = fromUnix(verifiedAt)
+	d.CreatedAt = time.Unix(createdAt, 0).UTC()
+
+	return d, nil
+}
+
+// SaveDomain - зарегистрировать новый короткий домен (еще не подтвержденный)
+func (s *Storage) SaveDomain(domain storage.Domain) (int64, error) {
+	const op = "storage.sqlite.SaveDomain"
+
+	res, err := s.db.Exec(
+		"INSERT INTO domain(host, verify_token, default_url, created_at) VALUES (?, ?, ?, ?)",
+		domain.Host, domain.VerifyToken, domain.DefaultURL, time.Now().Unix(),
+	)
+	if err != nil {
+		if sqliteErr, ok := err.(sqlite3.Error); ok && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+			return 0, fmt.Errorf("%s: %w", op, storage.ErrDomainExists)
+		}
+
+		return 0, fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("%s: failed to get last insert id: %w", op, err)
+	}
+
+	return id, nil
+}
+
+// GetDomain - получить домен по имени хоста
+func (s *Storage) GetDomain(host string) (storage.Domain, error) {
+	const op = "storage.sqlite.GetDomain"
+
+	d, err := scanDomain(s.db.QueryRow("SELECT "+domainColumns+" FROM domain WHERE host = ?", host))
+	if errors.Is(err, sql.ErrNoRows) {
+		return storage.Domain{}, storage.ErrDomainNotFound
+	}
+	if err != nil {
+		return storage.Domain{}, fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	return d, nil
+}
+
+// ListDomains - список всех зарегистрированных доменов
+func (s *Storage) ListDomains() ([]storage.Domain, error) {
+	const op = "storage.sqlite.ListDomains"
+
+	rows, err := s.db.Query("SELECT " + domainColumns + " FROM domain ORDER BY host")
+	if err != nil {
+		return nil, fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	domains := make([]storage.Domain, 0)
+	for rows.Next() {
+		d, err := scanDomain(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		domains = append(domains, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return domains, nil
+}
+
+// MarkDomainVerified - отметить, что владение доменом подтверждено
+func (s *Storage) MarkDomainVerified(host string, at time.Time) error {
+	const op = "storage.sqlite.MarkDomainVerified"
+
+	return s.execDomainUpdate(op, "UPDATE domain SET verified_at = ? WHERE host = ?", at.Unix(), host)
+}
+
+// SetDomainDefaultURL - задать адрес перехода для неизвестных алиасов домена
+func (s *Storage) SetDomainDefaultURL(host string, defaultURL string) error {
+	const op = "storage.sqlite.SetDomainDefaultURL"
+
+	return s.execDomainUpdate(op, "UPDATE domain SET default_url = ? WHERE host = ?", defaultURL, host)
+}
+
+func (s *Storage) execDomainUpdate(op string, query string, args ...any) error {
+	res, err := s.db.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if affected == 0 {
+		return storage.ErrDomainNotFound
+	}
+
+	return nil
+}
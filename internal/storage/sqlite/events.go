@@ -0,0 +1,78 @@
+// internal/storage/sqlite/events.go
+package sqlite
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"url-shortener/internal/storage"
+)
+
+// recordEvent записывает событие ссылки в очередь доставки подписанных вебхуков и в outbox.
+// Вызывается в транзакции изменения ссылки: событие сохраняется, только если сохранено изменение
+func (s *Storage) recordEvent(tx *sql.Tx, eventType string, link storage.Link, click *storage.EventClick) error {
+	webhookIDs, err := webhookSubscribers(tx, eventType, link)
+	if err != nil {
+		return err
+	}
+
+	toOutbox := s.outbox[eventType]
+	if len(webhookIDs) == 0 && !toOutbox {
+		return nil
+	}
+
+	eventID, err := newEventID()
+	if err != nil {
+		return fmt.Errorf("events: %w", err)
+	}
+
+	event := storage.Event{
+		ID:        eventID,
+		Type:      eventType,
+		CreatedAt: time.Now().UTC(),
+		Link: storage.EventLink{
+			ID:          link.ID,
+			Domain:      link.Domain,
+			Alias:       link.Alias,
+			URL:         link.URL,
+			Title:       link.Title,
+			WorkspaceID: link.WorkspaceID,
+			OwnerUID:    link.OwnerUID,
+			Clicks:      link.Clicks,
+			Health:      link.Health,
+		},
+		Click: click,
+	}
+
+	// тело события одно для всех получателей
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("events: marshal event: %w", err)
+	}
+
+	if err := enqueueDeliveries(tx, webhookIDs, event, payload); err != nil {
+		return err
+	}
+
+	if toOutbox {
+		if err := insertOutbox(tx, event, payload); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// newEventID - случайный идентификатор события
+func newEventID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate event id: %w", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,170 @@
+// internal/storage/sqlite/health.go
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"url-shortener/internal/storage"
+)
+
+// resetHealth - часть UPDATE url: при смене адреса перехода или запасного адреса (параметры - новые адреса)
+// ссылка проверяется в ближайший проход, не дожидаясь интервала. Статус остается до проверки
+const resetHealth = `health_checked_at = CASE WHEN url = ? AND fallback_url = ? THEN health_checked_at ELSE NULL END`
+
+// DueHealthChecks - ссылки, которые еще не проверялись или проверялись раньше before.
+// Сначала непроверенные, затем давно проверенные
+func (s *Storage) DueHealthChecks(before time.Time, limit int) ([]storage.HealthTask, error) {
+	const op = "storage.sqlite.DueHealthChecks"
+
+	rows, err := s.db.Query(
+		`SELECT id, url, fallback_url, health_status, health_failures FROM url
+		WHERE deleted_at IS NULL AND (health_checked_at IS NULL OR health_checked_at < ?)
+		ORDER BY health_checked_at IS NOT NULL, health_checked_at, id LIMIT ?`,
+		before.Unix(), limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	var (
+		tasks     []storage.HealthTask
+		fallbacks []string
+	)
+	for rows.Next() {
+		var (
+			task     storage.HealthTask
+			fallback string
+		)
+		if err := rows.Scan(&task.LinkID, &task.URL, &fallback, &task.Status, &task.Failures); err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		tasks = append(tasks, task)
+		fallbacks = append(fallbacks, fallback)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	for i := range tasks {
+		targets, err := healthTargets(s.db, tasks[i].LinkID, tasks[i].URL, fallbacks[i])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		tasks[i].Targets = targets
+	}
+
+	return tasks, nil
+}
+
+// healthTargets - адреса перехода ссылки id, кроме основного url: запасной адрес,
+// варианты A/B-теста и адреса правил в порядке их хранения, без повторов
+func healthTargets(q queryer, id int64, url string, fallback string) ([]string, error) {
+	targets, err := targetsByURLID(q, id)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := rulesByURLID(q, id)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{url: true, "": true}
+	var res []string
+	add := func(u string) {
+		if !seen[u] {
+			seen[u] = true
+			res = append(res, u)
+		}
+	}
+
+	add(fallback)
+	for _, target := range targets {
+		add(target.URL)
+	}
+	for _, rule := range rules {
+		add(rule.TargetURL)
+	}
+
+	return res, nil
+}
+
+// SaveHealth сохраняет результат проверки адресов перехода task.
+// При переходе в статус broken и выходе из него записывается событие link.broken / link.recovered.
+// Если адреса перехода успели измениться или ссылку удалили, ничего не делает
+func (s *Storage) SaveHealth(task storage.HealthTask, health storage.LinkHealth) error {
+	const op = "storage.sqlite.SaveHealth"
+
+	checkedAt := health.CheckedAt
+	if checkedAt == nil {
+		now := time.Now()
+		checkedAt = &now
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	linkID := task.LinkID
+
+	var prevStatus, fallback string
+	err = tx.QueryRow(
+		"SELECT health_status, fallback_url FROM url WHERE id = ? AND url = ? AND deleted_at IS NULL", linkID, task.URL,
+	).Scan(&prevStatus, &fallback)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%s: read link: %w", op, err)
+	}
+
+	// Результат относится к прежним адресам: новые проверятся в ближайший проход
+	targets, err := healthTargets(tx, linkID, task.URL, fallback)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if !slices.Equal(targets, task.Targets) {
+		return nil
+	}
+
+	_, err = tx.Exec(
+		`UPDATE url SET health_status = ?, health_code = ?, health_error = ?, health_failures = ?, health_checked_at = ?
+		WHERE id = ?`,
+		health.Status, health.StatusCode, health.Error, health.Failures, toUnix(checkedAt),
+		linkID,
+	)
+	if err != nil {
+		return fmt.Errorf("%s: update url: %w", op, err)
+	}
+
+	var eventType string
+	switch {
+	case health.Status == storage.HealthBroken && prevStatus != storage.HealthBroken:
+		eventType = storage.EventLinkBroken
+	case health.Status != storage.HealthBroken && prevStatus == storage.HealthBroken:
+		eventType = storage.EventLinkRecovered
+	}
+
+	if eventType != "" {
+		link, err := scanLink(tx.QueryRow("SELECT "+linkColumns+" FROM url WHERE id = ?", linkID))
+		if err != nil {
+			return fmt.Errorf("%s: read link: %w", op, err)
+		}
+		if err := s.recordEvent(tx, eventType, link, nil); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+// internal/storage/sqlite/idempotency.go
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"url-shortener/internal/storage"
+)
+
+// ReserveIdempotencyKey занимает ключ rec.Scope/rec.Key под обрабатываемый запрос.
+// Если ключ уже занят, возвращает сохраненную запись и false. Истекшие записи удаляются,
+// а незавершенная запись, созданная раньше staleBefore (обработка прервалась), занимается заново
+func (s *Storage) ReserveIdempotencyKey(rec storage.IdempotencyRecord, staleBefore time.Time) (storage.IdempotencyRecord, bool, error) {
+	const op = "storage.sqlite.ReserveIdempotencyKey"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return storage.IdempotencyRecord{}, false, fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = tx.Exec(
+		`DELETE FROM idempotency_key WHERE expires_at <= ?
+		OR (scope = ? AND key = ? AND completed = 0 AND created_at < ?)`,
+		rec.CreatedAt.Unix(), rec.Scope, rec.Key, staleBefore.Unix(),
+	)
+	if err != nil {
+		return storage.IdempotencyRecord{}, false, fmt.Errorf("%s: delete expired: %w", op, err)
+	}
+
+	res, err := tx.Exec(
+		`INSERT INTO idempotency_key(scope, key, fingerprint, created_at, expires_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(scope, key) DO NOTHING`,
+		rec.Scope, rec.Key, rec.Fingerprint, rec.CreatedAt.Unix(), rec.ExpiresAt.Unix(),
+	)
+	if err != nil {
+		return storage.IdempotencyRecord{}, false, fmt.Errorf("%s: insert: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return storage.IdempotencyRecord{}, false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if n == 1 {
+		if err := tx.Commit(); err != nil {
+			return storage.IdempotencyRecord{}, false, fmt.Errorf("%s: commit: %w", op, err)
+		}
+
+		return rec, true, nil
+	}
+
+	existing, err := scanIdempotencyRecord(tx.QueryRow(
+		`SELECT scope, key, fingerprint, completed, status_code, content_type, body, created_at, expires_at
+		FROM idempotency_key WHERE scope = ? AND key = ?`,
+		rec.Scope, rec.Key,
+	))
+	if err != nil {
+		return storage.IdempotencyRecord{}, false, fmt.Errorf("%s: select: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return storage.IdempotencyRecord{}, false, fmt.Errorf("%s: commit: %w", op, err)
+	}
+
+	return existing, false, nil
+}
+
+// CompleteIdempotencyKey сохраняет ответ на запрос с ключом scope/key
+func (s *Storage) CompleteIdempotencyKey(scope string, key string, statusCode int, contentType string, body []byte) error {
+	const op = "storage.sqlite.CompleteIdempotencyKey"
+
+	res, err := s.db.Exec(
+		`UPDATE idempotency_key SET completed = 1, status_code = ?, content_type = ?, body = ?
+		WHERE scope = ? AND key = ?`,
+		statusCode, contentType, body, scope, key,
+	)
+	if err != nil {
+		return fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrIdempotencyKeyNotFound)
+	}
+
+	return nil
+}
+
+// ReleaseIdempotencyKey освобождает ключ: запрос можно будет повторить с тем же ключом
+func (s *Storage) ReleaseIdempotencyKey(scope string, key string) error {
+	const op = "storage.sqlite.ReleaseIdempotencyKey"
+
+	_, err := s.db.Exec("DELETE FROM idempotency_key WHERE scope = ? AND key = ? AND completed = 0", scope, key)
+	if err != nil {
+		return fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	return nil
+}
+
+func scanIdempotencyRecord(row rowScanner) (storage.IdempotencyRecord, error) {
+	var (
+		rec                  storage.IdempotencyRecord
+		body                 []byte
+		createdAt, expiresAt int64
+	)
+
+	err := row.Scan(&rec.Scope, &rec.Key, &rec.Fingerprint, &rec.Completed, &rec.StatusCode, &rec.ContentType,
+		&body, &createdAt, &expiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return storage.IdempotencyRecord{}, storage.ErrIdempotencyKeyNotFound
+	}
+	if err != nil {
+		return storage.IdempotencyRecord{}, err
+	}
+
+	rec.Body = body
+	rec.CreatedAt = time.Unix(createdAt, 0).UTC()
+	rec.ExpiresAt = time.Unix(expiresAt, 0).UTC()
+
+	return rec, nil
+}
@@ -0,0 +1,207 @@
+// internal/storage/sqlite/maintenance.go
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// Close закрывает соединения с БД
+func (s *Storage) Close() error {
+	const op = "storage.sqlite.Close"
+
+	if err := s.db.Close(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Vacuum перестраивает файл БД, возвращая место, освободившееся после удаления данных
+func (s *Storage) Vacuum() error {
+	const op = "storage.sqlite.Vacuum"
+
+	if _, err := s.db.Exec("VACUUM"); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Сколько страниц БД копировать за один шаг резервного копирования.
+// Между шагами БД не заблокирована и сервис продолжает обслуживать запросы
+const backupStepPages = 256
+
+// BackupTo записывает согласованную копию БД в новый файл path через backup API SQLite.
+// Копирование идет по шагам, не останавливая запись в БД: если БД изменилась
+// во время копирования, SQLite сам докопирует измененные страницы.
+// Существующий файл не перезаписывается, при ошибке недописанный файл удаляется
+func (s *Storage) BackupTo(ctx context.Context, path string) (err error) {
+	const op = "storage.sqlite.BackupTo"
+
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s: file %s already exists", op, path)
+	}
+
+	dest, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return fmt.Errorf("%s: open destination: %w", op, err)
+	}
+	defer func() {
+		_ = dest.Close()
+		if err != nil {
+			_ = os.Remove(path)
+		}
+	}()
+
+	destConn, err := dest.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: connect destination: %w", op, err)
+	}
+	defer func() { _ = destConn.Close() }()
+
+	srcConn, err := s.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: connect source: %w", op, err)
+	}
+	defer func() { _ = srcConn.Close() }()
+
+	err = destConn.Raw(func(destDriver any) error {
+		return srcConn.Raw(func(srcDriver any) error {
+			return backup(ctx, destDriver.(*sqlite3.SQLiteConn), srcDriver.(*sqlite3.SQLiteConn))
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func backup(ctx context.Context, dest, src *sqlite3.SQLiteConn) error {
+	b, err := dest.Backup("main", src, "main")
+	if err != nil {
+		return fmt.Errorf("start backup: %w", err)
+	}
+
+	for {
+		done, err := b.Step(backupStepPages)
+		if err != nil {
+			_ = b.Finish()
+			return fmt.Errorf("backup step: %w", err)
+		}
+		if done {
+			break
+		}
+
+		if err := ctx.Err(); err != nil {
+			_ = b.Finish()
+			return err
+		}
+	}
+
+	if err := b.Finish(); err != nil {
+		return fmt.Errorf("finish backup: %w", err)
+	}
+
+	return nil
+}
+
+// CheckSnapshot проверяет файл резервной копии, не изменяя его: целостность файла
+// и версию схемы. Копию новее текущей схемы сервис открыть не сможет, копия без схемы -
+// не БД сервиса. Копии старых версий допустимы: при запуске сервис применит недостающие миграции.
+// Возвращает версию схемы копии
+func CheckSnapshot(path string) (int, error) {
+	const op = "storage.sqlite.CheckSnapshot"
+
+	if _, err := os.Stat(path); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	db, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() { _ = db.Close() }()
+
+	var result string
+	if err := db.QueryRow("PRAGMA integrity_check").Scan(&result); err != nil {
+		return 0, fmt.Errorf("%s: integrity check: %w", op, err)
+	}
+	if result != "ok" {
+		return 0, fmt.Errorf("%s: integrity check: %s", op, result)
+	}
+
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return 0, fmt.Errorf("%s: read schema version: %w", op, err)
+	}
+
+	switch {
+	case version == 0:
+		return 0, fmt.Errorf("%s: no schema version, not a service database", op)
+	case version > SchemaVersion():
+		return version, fmt.Errorf("%s: schema version %d is newer than supported %d", op, version, SchemaVersion())
+	}
+
+	return version, nil
+}
+
+// Check проверяет целостность файла БД и внешних ключей.
+// Возвращает найденные проблемы, пустой список - БД в порядке
+func (s *Storage) Check() ([]string, error) {
+	const op = "storage.sqlite.Check"
+
+	var problems []string
+
+	rows, err := s.db.Query("PRAGMA integrity_check")
+	if err != nil {
+		return nil, fmt.Errorf("%s: integrity check: %w", op, err)
+	}
+	for rows.Next() {
+		var msg string
+		if err := rows.Scan(&msg); err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("%s: scan integrity check: %w", op, err)
+		}
+		if msg != "ok" {
+			problems = append(problems, msg)
+		}
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: integrity check: %w", op, err)
+	}
+
+	rows, err = s.db.Query("PRAGMA foreign_key_check")
+	if err != nil {
+		return nil, fmt.Errorf("%s: foreign key check: %w", op, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var (
+			table, parent string
+			rowID         *int64
+			fkID          int64
+		)
+		if err := rows.Scan(&table, &rowID, &parent, &fkID); err != nil {
+			return nil, fmt.Errorf("%s: scan foreign key check: %w", op, err)
+		}
+
+		row := "?"
+		if rowID != nil {
+			row = fmt.Sprint(*rowID)
+		}
+		problems = append(problems, fmt.Sprintf("%s row %s references missing %s", table, row, parent))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: foreign key check: %w", op, err)
+	}
+
+	return problems, nil
+}
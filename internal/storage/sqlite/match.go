@@ -0,0 +1,136 @@
+// internal/storage/sqlite/match.go
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"url-shortener/internal/lib/aliaspolicy"
+	"url-shortener/internal/storage"
+)
+
+// aliasMatch читает режим сравнения алиасов, по которому построены ключи alias_key
+func aliasMatch(db *sql.DB) (aliaspolicy.MatchMode, error) {
+	var mode string
+	if err := db.QueryRow("SELECT value FROM meta WHERE key = 'alias_match'").Scan(&mode); err != nil {
+		return "", fmt.Errorf("read alias match mode: %w", err)
+	}
+
+	return aliaspolicy.MatchMode(mode), nil
+}
+
+// SetAliasMatch переключает режим сравнения алиасов: перестраивает ключи alias_key всех ссылок
+// (включая удаленные, но еще не очищенные). Если в новом режиме разные ссылки одного домена
+// совпадают (например, Promo и promo без учета регистра), режим не меняется и возвращается
+// ошибка storage.ErrAliasConflict со списком таких ссылок - их нужно переименовать или удалить.
+// Если режим не изменился, ничего не делает
+func (s *Storage) SetAliasMatch(mode aliaspolicy.MatchMode) error {
+	const op = "storage.sqlite.SetAliasMatch"
+
+	if !aliaspolicy.ValidMatchMode(mode) {
+		return fmt.Errorf("%s: unknown alias match mode %q", op, mode)
+	}
+	if mode == s.match {
+		return nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Пока ключи перестраиваются, они могут временно совпадать
+	if _, err := tx.Exec("DROP INDEX IF EXISTS idx_url_alias_key"); err != nil {
+		return fmt.Errorf("%s: drop index: %w", op, err)
+	}
+
+	conflicts, err := rebuildAliasKeys(tx, mode)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("%s: %w: %s", op, storage.ErrAliasConflict, strings.Join(conflicts, "; "))
+	}
+
+	if _, err := tx.Exec("CREATE UNIQUE INDEX idx_url_alias_key ON url(domain, alias_key)"); err != nil {
+		return fmt.Errorf("%s: create index: %w", op, err)
+	}
+	if _, err := tx.Exec("UPDATE meta SET value = ? WHERE key = 'alias_match'", string(mode)); err != nil {
+		return fmt.Errorf("%s: save mode: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+
+	s.match = mode
+
+	return nil
+}
+
+// rebuildAliasKeys пересчитывает ключи алиасов для режима mode и возвращает
+// описания конфликтов вида "go.example.com: Promo, promo" (для основного домена - без префикса)
+func rebuildAliasKeys(tx *sql.Tx, mode aliaspolicy.MatchMode) ([]string, error) {
+	rows, err := tx.Query("SELECT id, alias FROM url")
+	if err != nil {
+		return nil, fmt.Errorf("select aliases: %w", err)
+	}
+
+	keys := make(map[int64]string)
+	for rows.Next() {
+		var (
+			id    int64
+			alias string
+		)
+		if err := rows.Scan(&id, &alias); err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("scan alias: %w", err)
+		}
+		keys[id] = mode.Key(alias)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("select aliases: %w", err)
+	}
+
+	stmt, err := tx.Prepare("UPDATE url SET alias_key = ? WHERE id = ?")
+	if err != nil {
+		return nil, fmt.Errorf("prepare statement: %w", err)
+	}
+	defer func() { _ = stmt.Close() }()
+
+	for id, key := range keys {
+		if _, err := stmt.Exec(key, id); err != nil {
+			return nil, fmt.Errorf("update alias key: %w", err)
+		}
+	}
+
+	rows, err = tx.Query(`SELECT domain, GROUP_CONCAT(alias, ', ') FROM url
+		GROUP BY domain, alias_key HAVING COUNT(*) > 1`)
+	if err != nil {
+		return nil, fmt.Errorf("find conflicts: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var conflicts []string
+	for rows.Next() {
+		var domain, aliases string
+		if err := rows.Scan(&domain, &aliases); err != nil {
+			return nil, fmt.Errorf("scan conflict: %w", err)
+		}
+		if domain != "" {
+			aliases = domain + ": " + aliases
+		}
+		conflicts = append(conflicts, aliases)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("find conflicts: %w", err)
+	}
+
+	sort.Strings(conflicts)
+
+	return conflicts, nil
+}
@@ -0,0 +1,298 @@
+// internal/storage/sqlite/migrations.go
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// migrations - список миграций схемы БД.
+// Номер последней примененной миграции хранится в PRAGMA user_version,
+// поэтому миграции можно только добавлять в конец списка, менять старые нельзя.
+var migrations = []string{
+	// 1: базовая таблица ссылок (в старых БД уже существует)
+	`CREATE TABLE IF NOT EXISTS url(
+		id INTEGER PRIMARY KEY,
+		alias TEXT NOT NULL UNIQUE,
+		url TEXT NOT NULL);
+	CREATE INDEX IF NOT EXISTS idx_alias ON url(alias);`,
+
+	// 2: окно активности ссылки (unix-время в секундах) и запасной URL
+	`ALTER TABLE url ADD COLUMN not_before INTEGER;
+	ALTER TABLE url ADD COLUMN not_after INTEGER;
+	ALTER TABLE url ADD COLUMN fallback_url TEXT NOT NULL DEFAULT '';`,
+
+	// 3: правила умного редиректа
+	`CREATE TABLE IF NOT EXISTS url_rule(
+		id INTEGER PRIMARY KEY,
+		url_id INTEGER NOT NULL,
+		position INTEGER NOT NULL,
+		platform TEXT NOT NULL DEFAULT '',
+		language TEXT NOT NULL DEFAULT '',
+		country TEXT NOT NULL DEFAULT '',
+		target_url TEXT NOT NULL);
+	CREATE INDEX IF NOT EXISTS idx_url_rule_url_id ON url_rule(url_id, position);`,
+
+	// 4: варианты A/B-теста и счетчики переходов
+	`CREATE TABLE IF NOT EXISTS url_target(
+		id INTEGER PRIMARY KEY,
+		url_id INTEGER NOT NULL,
+		position INTEGER NOT NULL,
+		url TEXT NOT NULL,
+		weight INTEGER NOT NULL,
+		clicks INTEGER NOT NULL DEFAULT 0);
+	CREATE INDEX IF NOT EXISTS idx_url_target_url_id ON url_target(url_id, position);
+	ALTER TABLE url ADD COLUMN sticky TEXT NOT NULL DEFAULT '';
+	ALTER TABLE url ADD COLUMN clicks INTEGER NOT NULL DEFAULT 0;`,
+
+	// 5: UTM-метки по умолчанию и политика переноса параметров запроса
+	`ALTER TABLE url ADD COLUMN utm_source TEXT NOT NULL DEFAULT '';
+	ALTER TABLE url ADD COLUMN utm_medium TEXT NOT NULL DEFAULT '';
+	ALTER TABLE url ADD COLUMN utm_campaign TEXT NOT NULL DEFAULT '';
+	ALTER TABLE url ADD COLUMN query_policy TEXT NOT NULL DEFAULT '';`,
+
+	// 6: код редиректа, промежуточная страница и заголовок ссылки
+	`ALTER TABLE url ADD COLUMN redirect_code INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE url ADD COLUMN interstitial INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE url ADD COLUMN title TEXT NOT NULL DEFAULT '';`,
+
+	// 7: короткие домены. Алиас теперь уникален в пределах домена, а не глобально,
+	// поэтому таблицу url приходится пересоздать: SQLite не умеет удалять ограничения
+	`CREATE TABLE url_new(
+		id INTEGER PRIMARY KEY,
+		domain TEXT NOT NULL DEFAULT '',
+		alias TEXT NOT NULL,
+		url TEXT NOT NULL,
+		not_before INTEGER,
+		not_after INTEGER,
+		fallback_url TEXT NOT NULL DEFAULT '',
+		sticky TEXT NOT NULL DEFAULT '',
+		clicks INTEGER NOT NULL DEFAULT 0,
+		utm_source TEXT NOT NULL DEFAULT '',
+		utm_medium TEXT NOT NULL DEFAULT '',
+		utm_campaign TEXT NOT NULL DEFAULT '',
+		query_policy TEXT NOT NULL DEFAULT '',
+		redirect_code INTEGER NOT NULL DEFAULT 0,
+		interstitial INTEGER NOT NULL DEFAULT 0,
+		title TEXT NOT NULL DEFAULT '');
+	INSERT INTO url_new(
+		id, alias, url, not_before, not_after, fallback_url, sticky, clicks,
+		utm_source, utm_medium, utm_campaign, query_policy, redirect_code, interstitial, title)
+	SELECT
+		id, alias, url, not_before, not_after, fallback_url, sticky, clicks,
+		utm_source, utm_medium, utm_campaign, query_policy, redirect_code, interstitial, title
+	FROM url;
+	DROP TABLE url;
+	ALTER TABLE url_new RENAME TO url;
+	CREATE UNIQUE INDEX idx_url_domain_alias ON url(domain, alias);
+
+	CREATE TABLE IF NOT EXISTS domain(
+		id INTEGER PRIMARY KEY,
+		host TEXT NOT NULL UNIQUE,
+		verify_token TEXT NOT NULL,
+		verified_at INTEGER,
+		default_url TEXT NOT NULL DEFAULT '',
+		created_at INTEGER NOT NULL);`,
+
+	// 8: рабочие пространства, их участники и принадлежность ссылок
+	`CREATE TABLE IF NOT EXISTS workspace(
+		id INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		created_at INTEGER NOT NULL);
+	CREATE TABLE IF NOT EXISTS workspace_member(
+		workspace_id INTEGER NOT NULL,
+		uid INTEGER NOT NULL,
+		role TEXT NOT NULL,
+		created_at INTEGER NOT NULL,
+		PRIMARY KEY(workspace_id, uid));
+	CREATE INDEX IF NOT EXISTS idx_workspace_member_uid ON workspace_member(uid);
+	ALTER TABLE url ADD COLUMN workspace_id INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE url ADD COLUMN owner_uid INTEGER NOT NULL DEFAULT 0;
+	CREATE INDEX IF NOT EXISTS idx_url_workspace_id ON url(workspace_id);`,
+
+	// 9: API-ключи пользователей. Хранится только хеш ключа
+	`CREATE TABLE IF NOT EXISTS api_key(
+		id INTEGER PRIMARY KEY,
+		uid INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		prefix TEXT NOT NULL,
+		key_hash TEXT NOT NULL UNIQUE,
+		scopes TEXT NOT NULL DEFAULT '',
+		created_at INTEGER NOT NULL,
+		last_used_at INTEGER,
+		expires_at INTEGER,
+		revoked_at INTEGER);
+	CREATE INDEX IF NOT EXISTS idx_api_key_uid ON api_key(uid);`,
+
+	// 10: журнал аудита изменений ссылок. Триггеры запрещают изменять и удалять записи
+	`CREATE TABLE IF NOT EXISTS audit_log(
+		id INTEGER PRIMARY KEY,
+		created_at INTEGER NOT NULL,
+		actor_uid INTEGER NOT NULL DEFAULT 0,
+		actor_key_id INTEGER NOT NULL DEFAULT 0,
+		actor_name TEXT NOT NULL DEFAULT '',
+		request_id TEXT NOT NULL DEFAULT '',
+		action TEXT NOT NULL,
+		domain TEXT NOT NULL DEFAULT '',
+		alias TEXT NOT NULL,
+		before_value TEXT,
+		after_value TEXT);
+	CREATE INDEX IF NOT EXISTS idx_audit_log_link ON audit_log(domain, alias);
+	CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log(actor_uid);
+	CREATE TRIGGER IF NOT EXISTS audit_log_no_update BEFORE UPDATE ON audit_log
+	BEGIN SELECT RAISE(ABORT, 'audit log is append-only'); END;
+	CREATE TRIGGER IF NOT EXISTS audit_log_no_delete BEFORE DELETE ON audit_log
+	BEGIN SELECT RAISE(ABORT, 'audit log is append-only'); END;`,
+
+	// 11: мягкое удаление ссылок. Удаленная ссылка хранится до очистки по сроку хранения
+	`ALTER TABLE url ADD COLUMN deleted_at INTEGER;
+	CREATE INDEX IF NOT EXISTS idx_url_deleted_at ON url(deleted_at);`,
+
+	// 12: алиасы, зарезервированные администраторами
+	`CREATE TABLE IF NOT EXISTS reserved_alias(
+		domain TEXT NOT NULL DEFAULT '',
+		alias TEXT NOT NULL,
+		note TEXT NOT NULL DEFAULT '',
+		reserved_by TEXT NOT NULL DEFAULT '',
+		created_at INTEGER NOT NULL,
+		PRIMARY KEY(domain, alias));`,
+
+	// 13: ключ алиаса для поиска в выбранном режиме сравнения (см. Storage.SetAliasMatch).
+	// Изначально режим exact: ключ совпадает с алиасом
+	`ALTER TABLE url ADD COLUMN alias_key TEXT NOT NULL DEFAULT '';
+	UPDATE url SET alias_key = alias;
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_url_alias_key ON url(domain, alias_key);
+	CREATE TABLE IF NOT EXISTS meta(
+		key TEXT PRIMARY KEY,
+		value TEXT NOT NULL);
+	INSERT OR REPLACE INTO meta(key, value) VALUES ('alias_match', 'exact');`,
+
+	// 14: вебхуки и очередь их доставок (переживает перезапуск сервиса).
+	// events - типы событий через пробел, пусто - все
+	`CREATE TABLE IF NOT EXISTS webhook(
+		id INTEGER PRIMARY KEY,
+		uid INTEGER NOT NULL,
+		workspace_id INTEGER NOT NULL DEFAULT 0,
+		url TEXT NOT NULL,
+		events TEXT NOT NULL DEFAULT '',
+		secret TEXT NOT NULL,
+		created_at INTEGER NOT NULL);
+	CREATE INDEX IF NOT EXISTS idx_webhook_uid ON webhook(uid);
+	CREATE INDEX IF NOT EXISTS idx_webhook_workspace_id ON webhook(workspace_id);
+	CREATE TABLE IF NOT EXISTS webhook_delivery(
+		id INTEGER PRIMARY KEY,
+		webhook_id INTEGER NOT NULL,
+		event_id TEXT NOT NULL,
+		event_type TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		status TEXT NOT NULL,
+		attempts INTEGER NOT NULL DEFAULT 0,
+		next_attempt_at INTEGER,
+		last_status_code INTEGER NOT NULL DEFAULT 0,
+		last_error TEXT NOT NULL DEFAULT '',
+		created_at INTEGER NOT NULL,
+		delivered_at INTEGER);
+	CREATE INDEX IF NOT EXISTS idx_webhook_delivery_due ON webhook_delivery(status, next_attempt_at);
+	CREATE INDEX IF NOT EXISTS idx_webhook_delivery_webhook_id ON webhook_delivery(webhook_id, id);`,
+
+	// 15: outbox событий ссылок для публикации во внешнюю шину (см. jobs/relay).
+	// Пишется в одной транзакции с изменением ссылки, event_id - ключ идемпотентности
+	`CREATE TABLE IF NOT EXISTS outbox(
+		id INTEGER PRIMARY KEY,
+		event_id TEXT NOT NULL UNIQUE,
+		event_type TEXT NOT NULL,
+		link_id INTEGER NOT NULL,
+		payload TEXT NOT NULL,
+		created_at INTEGER NOT NULL,
+		published_at INTEGER,
+		attempts INTEGER NOT NULL DEFAULT 0,
+		last_error TEXT NOT NULL DEFAULT '');
+	CREATE INDEX IF NOT EXISTS idx_outbox_pending ON outbox(id) WHERE published_at IS NULL;
+	CREATE INDEX IF NOT EXISTS idx_outbox_published_at ON outbox(published_at) WHERE published_at IS NOT NULL;`,
+
+	// 16: ключи идемпотентности запросов на создание ссылок и сохраненные ответы на них.
+	// scope - кто отправил запрос: одинаковые ключи разных клиентов не пересекаются
+	`CREATE TABLE IF NOT EXISTS idempotency_key(
+		scope TEXT NOT NULL,
+		key TEXT NOT NULL,
+		fingerprint TEXT NOT NULL,
+		completed INTEGER NOT NULL DEFAULT 0,
+		status_code INTEGER NOT NULL DEFAULT 0,
+		content_type TEXT NOT NULL DEFAULT '',
+		body BLOB,
+		created_at INTEGER NOT NULL,
+		expires_at INTEGER NOT NULL,
+		PRIMARY KEY(scope, key));
+	CREATE INDEX IF NOT EXISTS idx_idempotency_key_expires_at ON idempotency_key(expires_at);`,
+
+	// 17: метаданные страницы перехода для предпросмотра (см. jobs/preview).
+	// Существующие ссылки тоже получают статус pending: метаданные для них запросятся в фоне
+	`ALTER TABLE url ADD COLUMN preview_status TEXT NOT NULL DEFAULT 'pending';
+	ALTER TABLE url ADD COLUMN preview_title TEXT NOT NULL DEFAULT '';
+	ALTER TABLE url ADD COLUMN preview_description TEXT NOT NULL DEFAULT '';
+	ALTER TABLE url ADD COLUMN preview_image TEXT NOT NULL DEFAULT '';
+	ALTER TABLE url ADD COLUMN preview_favicon TEXT NOT NULL DEFAULT '';
+	ALTER TABLE url ADD COLUMN preview_error TEXT NOT NULL DEFAULT '';
+	ALTER TABLE url ADD COLUMN preview_fetched_at INTEGER;
+	CREATE INDEX IF NOT EXISTS idx_url_preview_pending ON url(id) WHERE preview_status = 'pending';`,
+
+	// 18: доступность страницы перехода (см. jobs/healthcheck). health_checked_at NULL - не проверялась
+	`ALTER TABLE url ADD COLUMN health_status TEXT NOT NULL DEFAULT '';
+	ALTER TABLE url ADD COLUMN health_code INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE url ADD COLUMN health_error TEXT NOT NULL DEFAULT '';
+	ALTER TABLE url ADD COLUMN health_failures INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE url ADD COLUMN health_checked_at INTEGER;
+	CREATE INDEX IF NOT EXISTS idx_url_health_checked_at ON url(health_checked_at);`,
+
+	// 19: проверка адресов перехода по списку опасных адресов (см. lib/threatlist, jobs/screen)
+	`ALTER TABLE url ADD COLUMN threat_status TEXT NOT NULL DEFAULT '';
+	ALTER TABLE url ADD COLUMN threat_type TEXT NOT NULL DEFAULT '';
+	ALTER TABLE url ADD COLUMN threat_flagged_at INTEGER;
+	ALTER TABLE url ADD COLUMN threat_reviewed_by TEXT NOT NULL DEFAULT '';
+	ALTER TABLE url ADD COLUMN threat_reviewed_at INTEGER;
+	CREATE INDEX IF NOT EXISTS idx_url_threat_status ON url(threat_status) WHERE threat_status <> '';`,
+}
+
+// SchemaVersion - версия схемы, которую ожидает текущая версия приложения
+func SchemaVersion() int {
+	return len(migrations)
+}
+
+// migrate применяет к БД все еще не примененные миграции.
+// Каждая миграция выполняется в отдельной транзакции вместе с обновлением user_version
+func migrate(db *sql.DB) error {
+	const op = "storage.sqlite.migrate"
+
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("%s: read schema version: %w", op, err)
+	}
+
+	if version > len(migrations) {
+		return fmt.Errorf("%s: schema version %d is newer than supported %d", op, version, len(migrations))
+	}
+
+	for i := version; i < len(migrations); i++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		if _, err := tx.Exec(migrations[i]); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("%s: migration %d: %w", op, i+1, err)
+		}
+
+		// PRAGMA не поддерживает плейсхолдеры
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("%s: migration %d: %w", op, i+1, err)
+		}
+
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("%s: migration %d: %w", op, i+1, err)
+		}
+	}
+
+	return nil
+}
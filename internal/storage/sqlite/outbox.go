@@ -0,0 +1,113 @@
+// internal/storage/sqlite/outbox.go
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"url-shortener/internal/storage"
+)
+
+// SetOutboxEvents включает запись событий eventTypes в outbox (пусто - выключает).
+// Вызывается при запуске, до обработки запросов: без публикатора outbox копился бы бесконечно
+func (s *Storage) SetOutboxEvents(eventTypes []string) {
+	outbox := make(map[string]bool, len(eventTypes))
+	for _, t := range eventTypes {
+		outbox[t] = true
+	}
+
+	s.outbox = outbox
+}
+
+// insertOutbox записывает событие в outbox в транзакции изменения ссылки
+func insertOutbox(tx *sql.Tx, event storage.Event, payload []byte) error {
+	_, err := tx.Exec(
+		"INSERT INTO outbox(event_id, event_type, link_id, payload, created_at) VALUES (?, ?, ?, ?, ?)",
+		event.ID, event.Type, event.Link.ID, string(payload), event.CreatedAt.Unix(),
+	)
+	if err != nil {
+		return fmt.Errorf("outbox: insert event: %w", err)
+	}
+
+	return nil
+}
+
+// PendingOutbox - неопубликованные события в порядке записи, не более limit
+func (s *Storage) PendingOutbox(limit int) ([]storage.OutboxMessage, error) {
+	const op = "storage.sqlite.PendingOutbox"
+
+	rows, err := s.db.Query(
+		`SELECT id, event_id, event_type, link_id, payload, created_at, attempts, last_error
+		FROM outbox WHERE published_at IS NULL ORDER BY id LIMIT ?`,
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var messages []storage.OutboxMessage
+	for rows.Next() {
+		var (
+			m         storage.OutboxMessage
+			payload   string
+			createdAt int64
+		)
+
+		err := rows.Scan(&m.ID, &m.EventID, &m.EventType, &m.LinkID, &payload, &createdAt, &m.Attempts, &m.LastError)
+		if err != nil {
+			return nil, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+
+		m.Payload = []byte(payload)
+		m.CreatedAt = time.Unix(createdAt, 0).UTC()
+		messages = append(messages, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return messages, nil
+}
+
+// MarkOutboxPublished отмечает событие опубликованным
+func (s *Storage) MarkOutboxPublished(id int64, at time.Time) error {
+	const op = "storage.sqlite.MarkOutboxPublished"
+
+	_, err := s.db.Exec("UPDATE outbox SET published_at = ?, last_error = '' WHERE id = ?", at.Unix(), id)
+	if err != nil {
+		return fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	return nil
+}
+
+// RecordOutboxFailure записывает неудачную попытку публикации события
+func (s *Storage) RecordOutboxFailure(id int64, errText string) error {
+	const op = "storage.sqlite.RecordOutboxFailure"
+
+	_, err := s.db.Exec("UPDATE outbox SET attempts = attempts + 1, last_error = ? WHERE id = ?", errText, id)
+	if err != nil {
+		return fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	return nil
+}
+
+// PurgeOutbox удаляет события, опубликованные раньше before. Возвращает число удаленных
+func (s *Storage) PurgeOutbox(before time.Time) (int64, error) {
+	const op = "storage.sqlite.PurgeOutbox"
+
+	res, err := s.db.Exec("DELETE FROM outbox WHERE published_at IS NOT NULL AND published_at < ?", before.Unix())
+	if err != nil {
+		return 0, fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return n, nil
+}
@@ -0,0 +1,67 @@
+// internal/storage/sqlite/preview.go
+package sqlite
+
+import (
+	"fmt"
+	"time"
+
+	"url-shortener/internal/storage"
+)
+
+// resetPreview - часть UPDATE url: при смене адреса перехода (параметр - новый адрес)
+// метаданные страницы запрашиваются заново. В SET справа используются старые значения колонок
+const resetPreview = `preview_status = CASE WHEN url = ? THEN preview_status ELSE 'pending' END`
+
+// PendingPreviews - ссылки, для которых еще не получены метаданные страницы, в порядке создания
+func (s *Storage) PendingPreviews(limit int) ([]storage.PreviewTask, error) {
+	const op = "storage.sqlite.PendingPreviews"
+
+	rows, err := s.db.Query(
+		"SELECT id, url FROM url WHERE preview_status = ? AND deleted_at IS NULL ORDER BY id LIMIT ?",
+		storage.PreviewPending, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var tasks []storage.PreviewTask
+	for rows.Next() {
+		var task storage.PreviewTask
+		if err := rows.Scan(&task.LinkID, &task.URL); err != nil {
+			return nil, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		tasks = append(tasks, task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return tasks, nil
+}
+
+// SavePreview сохраняет метаданные страницы url ссылки linkID.
+// Если адрес перехода успел измениться, ничего не делает: метаданные запросятся для нового адреса
+func (s *Storage) SavePreview(linkID int64, url string, preview storage.LinkPreview) error {
+	const op = "storage.sqlite.SavePreview"
+
+	fetchedAt := preview.FetchedAt
+	if fetchedAt == nil {
+		now := time.Now()
+		fetchedAt = &now
+	}
+
+	_, err := s.db.Exec(
+		`UPDATE url SET preview_status = ?, preview_title = ?, preview_description = ?, preview_image = ?,
+			preview_favicon = ?, preview_error = ?, preview_fetched_at = ?
+		WHERE id = ? AND url = ?`,
+		preview.Status, preview.Title, preview.Description, preview.Image,
+		preview.Favicon, preview.Error, toUnix(fetchedAt),
+		linkID, url,
+	)
+	if err != nil {
+		return fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	return nil
+}
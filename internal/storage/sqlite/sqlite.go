@@ -7,6 +7,9 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
+	"url-shortener/internal/lib/aliaspolicy"
 	"url-shortener/internal/storage"
 
 	"github.com/mattn/go-sqlite3"
@@ -15,63 +18,90 @@ import (
 // Структура объекта Storage
 type Storage struct {
 	db *sql.DB //из пакета "database/sql"
+
+	// Режим сравнения алиасов, по которому построены ключи alias_key (см. SetAliasMatch)
+	match aliaspolicy.MatchMode
+
+	// Типы событий, которые пишутся в outbox (см. SetOutboxEvents). Пусто - outbox не ведется
+	outbox map[string]bool
 }
 
+// Сколько ждать освобождения блокировки БД другим соединением, в миллисекундах
+const busyTimeout = 5000
+
 // Конструктор объекта Storage
 func NewStorage(storagePath string) (*Storage, error) {
 	const op = "storage.sqlite.NewStorage" // Имя текущей функции для логов и ошибок
 
 	// Подключаемся к БД
-	db, err := sql.Open("sqlite3", storagePath)
+	db, err := sql.Open("sqlite3", dsn(storagePath))
 
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	// создаем таблицу, если ее еще нет
-	// TODO: можно прикрутить миграции, для тренировки
-	stmt, err := db.Prepare(`
-	CREATE TABLE IF NOT EXISTS url(
-		id INTEGER PRIMARY KEY,
-		alias TEXT NOT NULL UNIQUE,
-		url TEXT NOT NULL);
-	CREATE INDEX IF NOT EXISTS idx_alias ON url(alias);
-	`)
-
-	if err != nil {
+	// создаем/обновляем схему БД
+	if err := migrate(db); err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	_, err = stmt.Exec()
+	match, err := aliasMatch(db)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	return &Storage{db: db}, nil
+	return &Storage{db: db, match: match}, nil
 }
 
 func (s *Storage) SaveURL(urlToSave string, alias string) (int64, error) {
-	const op = "storage.sqlite.SaveURL"
+	return s.SaveLink(storage.Link{URL: urlToSave, Alias: alias}, storage.Actor{})
+}
+
+// SaveLink - сохранить ссылку со всеми параметрами (вместе с правилами редиректа и вариантами A/B-теста).
+// Создание ссылки записывается в журнал аудита от имени actor
+func (s *Storage) SaveLink(link storage.Link, actor storage.Actor) (int64, error) {
+	const op = "storage.sqlite.SaveLink"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Адрес перехода мог быть отмечен проверкой по списку опасных адресов
+	var threat storage.LinkThreat
+	if link.Threat != nil {
+		threat = *link.Threat
+	}
 
 	// Подготавливаем запрос (проверка корректности синтаксиса)
-	stmt, err := s.db.Prepare("INSERT INTO url(url, alias) VALUES (?, ?)")
+	stmt, err := tx.Prepare(`INSERT INTO url(
+		domain, url, alias, alias_key, not_before, not_after, fallback_url, sticky,
+		utm_source, utm_medium, utm_campaign, query_policy,
+		redirect_code, interstitial, title, workspace_id, owner_uid,
+		threat_status, threat_type, threat_flagged_at
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
 	if err != nil {
 		return 0, fmt.Errorf("%s: prepare statement: %w", op, err)
 	}
+	defer func() { _ = stmt.Close() }()
 
 	//выполняем запрос
-	res, err := stmt.Exec(urlToSave, alias)
+	res, err := stmt.Exec(
+		link.Domain, link.URL, link.Alias, s.match.Key(link.Alias), toUnix(link.NotBefore), toUnix(link.NotAfter), link.FallbackURL, link.Sticky,
+		link.UTMSource, link.UTMMedium, link.UTMCampaign, link.QueryPolicy,
+		link.RedirectCode, link.Interstitial, link.Title, link.WorkspaceID, link.OwnerUID,
+		threat.Status, threat.Type, toUnix(threat.FlaggedAt),
+	)
 	if err != nil {
 		// Здесь мы приводим полученную ошибку ко внутреннему типу библиотеки sqlite3,
 		// чтобы посмотреть, не является ли эта ошибка sqlite3.ErrConstraintUnique.
-		// Если это так, значит, мы попытались добавить дубликат имеющейся записи. Об этом мы сообщим в вызывающую функцию, вернув уже свою ошибку для данной ситуации: storage.ErrURLExists. Получив ее, сервер сможет сообщить клиенту о том, что такой alias у нас уже есть.
+		// Если это так, значит, мы попытались добавить дубликат имеющейся записи
+		// (в том числе алиас, который совпадает с имеющимся в текущем режиме сравнения алиасов). Об этом мы сообщим в вызывающую функцию, вернув уже свою ошибку для данной ситуации: storage.ErrURLExists. Получив ее, сервер сможет сообщить клиенту о том, что такой alias у нас уже есть.
 		if sqliteErr, ok := err.(sqlite3.Error); ok && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique {
 			return 0, fmt.Errorf("%s: %w", op, storage.ErrURLExists)
 		}
 
-		var e sqlite3.Error
-		fmt.Println(e)
-
 		return 0, fmt.Errorf("%s: execute statement: %w", op, err)
 	}
 
@@ -80,23 +110,47 @@ func (s *Storage) SaveURL(urlToSave string, alias string) (int64, error) {
 		return 0, fmt.Errorf("%s: failed to get last insert id: %w", op, err)
 	}
 
+	if err := insertTargets(tx, id, link.Targets); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	if len(link.Rules) > 0 {
+		if err := replaceRules(tx, id, link.Rules); err != nil {
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	after, err := linkSnapshot(tx, link.Domain, s.match.Key(link.Alias))
+	if err != nil {
+		return 0, fmt.Errorf("%s: read saved link: %w", op, err)
+	}
+	if err := writeAudit(tx, actor, storage.AuditLinkCreate, link.Domain, link.Alias, nil, after); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := s.recordEvent(tx, storage.EventLinkCreated, after, nil); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+
 	//Возвращаем ID
 	return id, nil
 }
 
-// GetURL - получить ссылку по ее алиасу
+// GetURL - получить ссылку по ее алиасу на основном домене
 func (s *Storage) GetURL(alias string) (string, error) {
 	const op = "storage.sqlite.GetURL"
 
 	// Подготавливаем запрос (проверка корректности синтаксиса)
-	stmt, err := s.db.Prepare("SELECT url FROM url WHERe alias = ?")
+	stmt, err := s.db.Prepare("SELECT url FROM url WHERE domain = '' AND alias_key = ? AND deleted_at IS NULL")
 	if err != nil {
 		return "", fmt.Errorf("%s: prepare statement: %w", op, err)
 	}
 
 	var resURL string
 
-	err = stmt.QueryRow(alias).Scan(&resURL) //в параметрах используем указатель, чтобы получить результаты
+	err = stmt.QueryRow(s.match.Key(alias)).Scan(&resURL) //в параметрах используем указатель, чтобы получить результаты
 
 	//если строки не найдено - возвращаем пустую строку
 	if errors.Is(err, sql.ErrNoRows) {
@@ -110,21 +164,649 @@ func (s *Storage) GetURL(alias string) (string, error) {
 	return resURL, nil
 }
 
-// Удалить запись из БД по алиасу
-func (s *Storage) DeleteURL(alias string) error {
+// Удалить ссылку по алиасу. Удаление мягкое: ссылка перестает обслуживаться,
+// но хранится вместе с правилами и вариантами A/B-теста до очистки (см. PurgeDeleted)
+// и может быть восстановлена через RestoreURL.
+// Если ссылки нет или она уже удалена - storage.ErrURLNotFound.
+// Удаление записывается в журнал аудита от имени actor
+func (s *Storage) DeleteURL(domain string, alias string, actor storage.Actor) error {
 	const op = "storage.sqlite.DeleteURL"
 
-	// Подготавливаем запрос (проверка корректности синтаксиса)
-	stmt, err := s.db.Prepare("DELETE FROM url WHERe alias = ?")
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	before, err := linkSnapshot(tx, domain, s.match.Key(alias))
+	if errors.Is(err, sql.ErrNoRows) || err == nil && before.DeletedAt != nil {
+		return storage.ErrURLNotFound
+	}
 	if err != nil {
-		return fmt.Errorf("%s: prepare statement: %w", op, err)
+		return fmt.Errorf("%s: read link: %w", op, err)
 	}
 
 	//выполняем запрос
-	_, err = stmt.Exec(alias)
+	if _, err := tx.Exec("UPDATE url SET deleted_at = ? WHERE id = ?", time.Now().Unix(), before.ID); err != nil {
+		return fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	if err := writeAudit(tx, actor, storage.AuditLinkDelete, domain, before.Alias, before, nil); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := s.recordEvent(tx, storage.EventLinkDeleted, before, nil); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+
+	return nil
+}
+
+// RestoreURL - восстановить удаленную ссылку. Если удаленной ссылки с таким алиасом нет
+// (не было, не удалена или уже очищена) - storage.ErrURLNotFound.
+// Восстановление записывается в журнал аудита от имени actor
+func (s *Storage) RestoreURL(domain string, alias string, actor storage.Actor) error {
+	const op = "storage.sqlite.RestoreURL"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	link, err := linkSnapshot(tx, domain, s.match.Key(alias))
+	if errors.Is(err, sql.ErrNoRows) || err == nil && link.DeletedAt == nil {
+		return storage.ErrURLNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("%s: read link: %w", op, err)
+	}
+
+	if _, err := tx.Exec("UPDATE url SET deleted_at = NULL WHERE id = ?", link.ID); err != nil {
+		return fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	link.DeletedAt = nil
+	if err := writeAudit(tx, actor, storage.AuditLinkRestore, domain, link.Alias, nil, link); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := s.recordEvent(tx, storage.EventLinkRestored, link, nil); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+
+	return nil
+}
+
+// PurgeDeleted окончательно удаляет ссылки (вместе с правилами редиректа и вариантами A/B-теста),
+// удаленные раньше before, и освобождает их алиасы. Возвращает количество удаленных ссылок.
+// Очистка записывается в журнал аудита без пользователя
+func (s *Storage) PurgeDeleted(before time.Time) (int64, error) {
+	const op = "storage.sqlite.PurgeDeleted"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	rows, err := tx.Query("SELECT id, domain, alias FROM url WHERE deleted_at IS NOT NULL AND deleted_at < ?", before.Unix())
+	if err != nil {
+		return 0, fmt.Errorf("%s: select links: %w", op, err)
+	}
+
+	type purged struct {
+		id            int64
+		domain, alias string
+	}
+
+	var links []purged
+	for rows.Next() {
+		var link purged
+		if err := rows.Scan(&link.id, &link.domain, &link.alias); err != nil {
+			_ = rows.Close()
+			return 0, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		links = append(links, link)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("%s: select links: %w", op, err)
+	}
+
+	for _, link := range links {
+		if _, err := tx.Exec("DELETE FROM url_rule WHERE url_id = ?", link.id); err != nil {
+			return 0, fmt.Errorf("%s: delete rules: %w", op, err)
+		}
+		if _, err := tx.Exec("DELETE FROM url_target WHERE url_id = ?", link.id); err != nil {
+			return 0, fmt.Errorf("%s: delete targets: %w", op, err)
+		}
+		if _, err := tx.Exec("DELETE FROM url WHERE id = ?", link.id); err != nil {
+			return 0, fmt.Errorf("%s: delete url: %w", op, err)
+		}
+
+		if err := writeAudit(tx, storage.Actor{}, storage.AuditLinkPurge, link.domain, link.alias, nil, nil); err != nil {
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+
+	return int64(len(links)), nil
+}
+
+// linkColumns - список колонок для чтения storage.Link (см. scanLink)
+const linkColumns = `id, domain, alias, url, not_before, not_after, fallback_url, sticky, clicks,
+	utm_source, utm_medium, utm_campaign, query_policy,
+	redirect_code, interstitial, title, workspace_id, owner_uid, deleted_at,
+	preview_status, preview_title, preview_description, preview_image, preview_favicon, preview_error, preview_fetched_at,
+	health_status, health_code, health_error, health_failures, health_checked_at,
+	threat_status, threat_type, threat_flagged_at, threat_reviewed_by, threat_reviewed_at`
+
+// rowScanner - общий интерфейс для *sql.Row и *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanLink(row rowScanner) (storage.Link, error) {
+	var (
+		link                           storage.Link
+		notBefore, notAfter, deletedAt sql.NullInt64
+		preview                        storage.LinkPreview
+		previewFetchedAt               sql.NullInt64
+		health                         storage.LinkHealth
+		healthCheckedAt                sql.NullInt64
+		threat                         storage.LinkThreat
+		threatFlaggedAt                sql.NullInt64
+		threatReviewedAt               sql.NullInt64
+	)
+
+	err := row.Scan(
+		&link.ID, &link.Domain, &link.Alias, &link.URL, &notBefore, &notAfter, &link.FallbackURL,
+		&link.Sticky, &link.Clicks,
+		&link.UTMSource, &link.UTMMedium, &link.UTMCampaign, &link.QueryPolicy,
+		&link.RedirectCode, &link.Interstitial, &link.Title, &link.WorkspaceID, &link.OwnerUID, &deletedAt,
+		&preview.Status, &preview.Title, &preview.Description, &preview.Image, &preview.Favicon, &preview.Error,
+		&previewFetchedAt,
+		&health.Status, &health.StatusCode, &health.Error, &health.Failures, &healthCheckedAt,
+		&threat.Status, &threat.Type, &threatFlaggedAt, &threat.ReviewedBy, &threatReviewedAt,
+	)
+	if err != nil {
+		return storage.Link{}, err
+	}
+
+	link.NotBefore = fromUnix(notBefore)
+	link.NotAfter = fromUnix(notAfter)
+	link.DeletedAt = fromUnix(deletedAt)
+
+	preview.FetchedAt = fromUnix(previewFetchedAt)
+	link.Preview = &preview
+
+	if healthCheckedAt.Valid {
+		health.CheckedAt = fromUnix(healthCheckedAt)
+		link.Health = &health
+	}
+
+	if threat.Status != "" {
+		threat.FlaggedAt = fromUnix(threatFlaggedAt)
+		threat.ReviewedAt = fromUnix(threatReviewedAt)
+		link.Threat = &threat
+	}
+
+	return link, nil
+}
+
+// GetLink - получить ссылку со всеми параметрами по домену и алиасу.
+// Окно активности здесь не проверяется, это задача вызывающего кода.
+// Удаленная ссылка не находится
+func (s *Storage) GetLink(domain string, alias string) (storage.Link, error) {
+	const op = "storage.sqlite.GetLink"
+
+	return s.getLink(op, "SELECT "+linkColumns+" FROM url WHERE domain = ? AND alias_key = ? AND deleted_at IS NULL", domain, s.match.Key(alias))
+}
+
+// GetDeletedLink - получить удаленную, но еще не очищенную ссылку
+func (s *Storage) GetDeletedLink(domain string, alias string) (storage.Link, error) {
+	const op = "storage.sqlite.GetDeletedLink"
+
+	return s.getLink(op, "SELECT "+linkColumns+" FROM url WHERE domain = ? AND alias_key = ? AND deleted_at IS NOT NULL", domain, s.match.Key(alias))
+}
+
+func (s *Storage) getLink(op string, query string, domain string, key string) (storage.Link, error) {
+	link, err := scanLink(s.db.QueryRow(query, domain, key))
+	if errors.Is(err, sql.ErrNoRows) {
+		return storage.Link{}, storage.ErrURLNotFound
+	}
+	if err != nil {
+		return storage.Link{}, fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	link.Rules, err = rulesByURLID(s.db, link.ID)
+	if err != nil {
+		return storage.Link{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	link.Targets, err = targetsByURLID(s.db, link.ID)
+	if err != nil {
+		return storage.Link{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return link, nil
+}
+
+// ListLinks - получить список ссылок с учетом фильтра
+func (s *Storage) ListLinks(filter storage.ListFilter) ([]storage.Link, error) {
+	const op = "storage.sqlite.ListLinks"
+
+	where, args, err := listWhere(filter)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	query := "SELECT " + linkColumns + " FROM url WHERE " + strings.Join(where, " AND ") + " ORDER BY id"
+
+	links, err := s.queryLinks(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return links, nil
+}
+
+// listWhere - условия выборки ссылок по фильтру
+func listWhere(filter storage.ListFilter) ([]string, []any, error) {
+	var (
+		where []string
+		args  []any
+	)
+
+	now := time.Now().Unix()
+
+	if filter.State == storage.StateDeleted {
+		where = append(where, "deleted_at IS NOT NULL")
+	} else {
+		where = append(where, "deleted_at IS NULL")
+	}
+
+	switch filter.State {
+	case "", storage.StateDeleted:
+	case storage.StateActive:
+		where = append(where, "(not_before IS NULL OR not_before <= ?) AND (not_after IS NULL OR not_after > ?)")
+		args = append(args, now, now)
+	case storage.StateScheduled:
+		where = append(where, "not_before IS NOT NULL AND not_before > ?")
+		args = append(args, now)
+	case storage.StateEnded:
+		where = append(where, "not_after IS NOT NULL AND not_after <= ?")
+		args = append(args, now)
+	default:
+		return nil, nil, fmt.Errorf("unknown state %q", filter.State)
+	}
+
+	if filter.Domain != "" {
+		where = append(where, "domain = ?")
+		args = append(args, filter.Domain)
+	}
+
+	if filter.WorkspaceID != 0 {
+		where = append(where, "workspace_id = ?")
+		args = append(args, filter.WorkspaceID)
+	}
+
+	if filter.Threat != "" {
+		where = append(where, "threat_status = ?")
+		args = append(args, filter.Threat)
+	}
+
+	if filter.Health != "" {
+		where = append(where, "health_status = ?")
+		args = append(args, filter.Health)
+	}
+
+	if filter.MemberUID != 0 {
+		where = append(where, "((workspace_id = 0 AND owner_uid = ?) OR workspace_id IN (SELECT workspace_id FROM workspace_member WHERE uid = ?))")
+		args = append(args, filter.MemberUID, filter.MemberUID)
+	}
+
+	return where, args, nil
+}
+
+// queryLinks выполняет запрос, выбирающий linkColumns (без правил и вариантов A/B-теста)
+func (s *Storage) queryLinks(query string, args ...any) ([]storage.Link, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("execute statement: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	links := make([]storage.Link, 0)
+	for rows.Next() {
+		link, err := scanLink(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+		links = append(links, link)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return links, nil
+}
+
+// UpdateLink - обновить изменяемые параметры ссылки (поиск по домену и алиасу).
+// Изменение записывается в журнал аудита от имени actor
+func (s *Storage) UpdateLink(link storage.Link, actor storage.Actor) error {
+	const op = "storage.sqlite.UpdateLink"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	before, err := linkSnapshot(tx, link.Domain, s.match.Key(link.Alias))
+	if errors.Is(err, sql.ErrNoRows) || err == nil && before.DeletedAt != nil {
+		return storage.ErrURLNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("%s: read link: %w", op, err)
+	}
+
+	_, err = tx.Exec(
+		`UPDATE url SET url = ?, not_before = ?, not_after = ?, fallback_url = ?,
+			utm_source = ?, utm_medium = ?, utm_campaign = ?, query_policy = ?,
+			redirect_code = ?, interstitial = ?, title = ?,
+			`+resetPreview+`, `+resetHealth+`, `+resetThreat+`
+		WHERE id = ?`,
+		link.URL, toUnix(link.NotBefore), toUnix(link.NotAfter), link.FallbackURL,
+		link.UTMSource, link.UTMMedium, link.UTMCampaign, link.QueryPolicy,
+		link.RedirectCode, link.Interstitial, link.Title,
+		link.URL, link.URL, link.FallbackURL, link.URL,
+		before.ID,
+	)
 	if err != nil {
 		return fmt.Errorf("%s: execute statement: %w", op, err)
 	}
 
+	after, err := linkSnapshot(tx, link.Domain, s.match.Key(link.Alias))
+	if err != nil {
+		return fmt.Errorf("%s: read updated link: %w", op, err)
+	}
+	if err := writeAudit(tx, actor, storage.AuditLinkUpdate, link.Domain, before.Alias, before, after); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := s.recordEvent(tx, storage.EventLinkUpdated, after, nil); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+
 	return nil
 }
+
+// GetRules - получить упорядоченные правила редиректа ссылки
+func (s *Storage) GetRules(domain string, alias string) ([]storage.Rule, error) {
+	const op = "storage.sqlite.GetRules"
+
+	var id int64
+	err := s.db.QueryRow("SELECT id FROM url WHERE domain = ? AND alias_key = ? AND deleted_at IS NULL", domain, s.match.Key(alias)).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, storage.ErrURLNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	rules, err := rulesByURLID(s.db, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return rules, nil
+}
+
+// SetRules - заменить правила редиректа ссылки новым упорядоченным набором.
+// Пустой набор удаляет все правила. Замена записывается в журнал аудита от имени actor
+func (s *Storage) SetRules(domain string, alias string, rules []storage.Rule, actor storage.Actor) error {
+	const op = "storage.sqlite.SetRules"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var (
+		id     int64
+		stored string // алиас в том виде, в каком сохранен
+	)
+	err = tx.QueryRow(
+		"SELECT id, alias FROM url WHERE domain = ? AND alias_key = ? AND deleted_at IS NULL", domain, s.match.Key(alias),
+	).Scan(&id, &stored)
+	if errors.Is(err, sql.ErrNoRows) {
+		return storage.ErrURLNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("%s: find url: %w", op, err)
+	}
+
+	before, err := rulesByURLID(tx, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := replaceRules(tx, id, rules); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// Адреса правил тоже проверяются на доступность: новые - в ближайший проход
+	if _, err := tx.Exec("UPDATE url SET health_checked_at = NULL WHERE id = ?", id); err != nil {
+		return fmt.Errorf("%s: reset health check: %w", op, err)
+	}
+
+	// В журнал пишем сами наборы правил: остальные параметры ссылки не меняются
+	after := rules
+	if after == nil {
+		after = []storage.Rule{}
+	}
+	if before == nil {
+		before = []storage.Rule{}
+	}
+	if err := writeAudit(tx, actor, storage.AuditLinkRules, domain, stored, before, after); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	link, err := scanLink(tx.QueryRow("SELECT "+linkColumns+" FROM url WHERE id = ?", id))
+	if err != nil {
+		return fmt.Errorf("%s: read link: %w", op, err)
+	}
+	if err := s.recordEvent(tx, storage.EventLinkUpdated, link, nil); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+
+	return nil
+}
+
+// insertTargets добавляет ссылке варианты A/B-теста в заданном порядке
+func insertTargets(tx *sql.Tx, id int64, targets []storage.Target) error {
+	for i, target := range targets {
+		_, err := tx.Exec(
+			"INSERT INTO url_target(url_id, position, url, weight) VALUES (?, ?, ?, ?)",
+			id, i, target.URL, target.Weight,
+		)
+		if err != nil {
+			return fmt.Errorf("insert target: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// replaceRules заменяет правила редиректа ссылки новым упорядоченным набором
+func replaceRules(tx *sql.Tx, id int64, rules []storage.Rule) error {
+	if _, err := tx.Exec("DELETE FROM url_rule WHERE url_id = ?", id); err != nil {
+		return fmt.Errorf("delete rules: %w", err)
+	}
+
+	stmt, err := tx.Prepare("INSERT INTO url_rule(url_id, position, platform, language, country, target_url) VALUES (?, ?, ?, ?, ?, ?)")
+	if err != nil {
+		return fmt.Errorf("prepare statement: %w", err)
+	}
+	defer func() { _ = stmt.Close() }()
+
+	for i, rule := range rules {
+		if _, err := stmt.Exec(id, i, rule.Platform, rule.Language, rule.Country, rule.TargetURL); err != nil {
+			return fmt.Errorf("insert rule: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// queryer - общий интерфейс для *sql.DB и *sql.Tx
+type queryer interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+// rulesByURLID читает правила ссылки в порядке их применения
+func rulesByURLID(q queryer, id int64) ([]storage.Rule, error) {
+	rows, err := q.Query(
+		"SELECT platform, language, country, target_url FROM url_rule WHERE url_id = ? ORDER BY position", id,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("select rules: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var rules []storage.Rule
+	for rows.Next() {
+		var rule storage.Rule
+		if err := rows.Scan(&rule.Platform, &rule.Language, &rule.Country, &rule.TargetURL); err != nil {
+			return nil, fmt.Errorf("scan rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("select rules: %w", err)
+	}
+
+	return rules, nil
+}
+
+// targetsByURLID читает варианты A/B-теста ссылки
+func targetsByURLID(q queryer, id int64) ([]storage.Target, error) {
+	rows, err := q.Query(
+		"SELECT id, url, weight, clicks FROM url_target WHERE url_id = ? ORDER BY position", id,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("select targets: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var targets []storage.Target
+	for rows.Next() {
+		var target storage.Target
+		if err := rows.Scan(&target.ID, &target.URL, &target.Weight, &target.Clicks); err != nil {
+			return nil, fmt.Errorf("scan target: %w", err)
+		}
+		targets = append(targets, target)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("select targets: %w", err)
+	}
+
+	return targets, nil
+}
+
+// RecordClick - учесть переход по ссылке.
+// targetID - выбранный вариант A/B-теста, 0 если переход был не на вариант
+func (s *Storage) RecordClick(linkID int64, targetID int64) error {
+	const op = "storage.sqlite.RecordClick"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec("UPDATE url SET clicks = clicks + 1 WHERE id = ?", linkID); err != nil {
+		return fmt.Errorf("%s: update url: %w", op, err)
+	}
+
+	if targetID != 0 {
+		_, err := tx.Exec("UPDATE url_target SET clicks = clicks + 1 WHERE id = ? AND url_id = ?", targetID, linkID)
+		if err != nil {
+			return fmt.Errorf("%s: update target: %w", op, err)
+		}
+	}
+
+	// Ссылку могли окончательно удалить после редиректа - тогда событие не отправляется
+	link, err := scanLink(tx.QueryRow("SELECT "+linkColumns+" FROM url WHERE id = ?", linkID))
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		return fmt.Errorf("%s: read link: %w", op, err)
+	default:
+		if err := s.recordEvent(tx, storage.EventLinkClicked, link, &storage.EventClick{TargetID: targetID}); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+
+	return nil
+}
+
+// dsn - строка подключения к файлу БД.
+// Транзакции начинаются с BEGIN IMMEDIATE: они читают ссылку и затем изменяют ее, и при обычном
+// BEGIN (DEFERRED) параллельные транзакции не могут повысить блокировку до записи - SQLite сразу
+// возвращает "database is locked", не дожидаясь busy timeout. С IMMEDIATE блокировка на запись
+// берется в начале транзакции, а конкурирующие транзакции ждут ее до busyTimeout
+func dsn(storagePath string) string {
+	sep := "?"
+	if strings.Contains(storagePath, "?") {
+		sep = "&"
+	}
+
+	return fmt.Sprintf("%s%s_txlock=immediate&_busy_timeout=%d", storagePath, sep, busyTimeout)
+}
+
+// toUnix переводит необязательное время в значение для колонки INTEGER
+func toUnix(t *time.Time) sql.NullInt64 {
+	if t == nil {
+		return sql.NullInt64{}
+	}
+
+	return sql.NullInt64{Int64: t.Unix(), Valid: true}
+}
+
+// fromUnix - обратное преобразование к toUnix
+func fromUnix(v sql.NullInt64) *time.Time {
+	if !v.Valid {
+		return nil
+	}
+
+	t := time.Unix(v.Int64, 0).UTC()
+
+	return &t
+}
@@ -0,0 +1,364 @@
+package sqlite_test
+
+import (
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"url-shortener/internal/lib/aliaspolicy"
+	"url-shortener/internal/storage"
+	"url-shortener/internal/storage/sqlite"
+)
+
+// newStorage открывает хранилище во временном файле
+func newStorage(t *testing.T) *sqlite.Storage {
+	t.Helper()
+
+	store, err := sqlite.NewStorage(filepath.Join(t.TempDir(), "storage.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+
+	return store
+}
+
+// aliases - алиасы ссылок в порядке выдачи
+func aliases(links []storage.Link) []string {
+	res := make([]string, 0, len(links))
+	for _, l := range links {
+		res = append(res, l.Alias)
+	}
+	return res
+}
+
+func TestListLinks_MemberScope(t *testing.T) {
+	store := newStorage(t)
+
+	ws, err := store.CreateWorkspace("team", 1)
+	require.NoError(t, err)
+	require.NoError(t, store.SetMember(storage.Member{WorkspaceID: ws.ID, UID: 2, Role: storage.RoleViewer}))
+
+	for _, link := range []storage.Link{
+		{Alias: "first-own", URL: "https://example.com/1", OwnerUID: 1},
+		{Alias: "second-own", URL: "https://example.com/2", OwnerUID: 2},
+		{Alias: "team", URL: "https://example.com/team", OwnerUID: 1, WorkspaceID: ws.ID},
+		{Alias: "legacy", URL: "https://example.com/legacy"},
+	} {
+		_, err := store.SaveLink(link, storage.Actor{UID: link.OwnerUID})
+		require.NoError(t, err)
+	}
+
+	// Личные ссылки других пользователей не видны
+	links, err := store.ListLinks(storage.ListFilter{MemberUID: 2})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"second-own", "team"}, aliases(links))
+
+	links, err = store.ListLinks(storage.ListFilter{MemberUID: 3})
+	require.NoError(t, err)
+	require.Empty(t, links)
+
+	// Выгрузка ограничена так же, как список
+	var exported []storage.Link
+	require.NoError(t, store.ExportLinks(storage.ListFilter{MemberUID: 2}, func(link storage.Link) error {
+		exported = append(exported, link)
+		return nil
+	}))
+	require.ElementsMatch(t, []string{"second-own", "team"}, aliases(exported))
+
+	// Без фильтра по пользователю (администратор) видны все ссылки
+	links, err = store.ListLinks(storage.ListFilter{})
+	require.NoError(t, err)
+	require.Len(t, links, 4)
+}
+
+func TestSaveLink_AuditAndEvent(t *testing.T) {
+	store := newStorage(t)
+	store.SetOutboxEvents([]string{storage.EventLinkCreated})
+
+	actor := storage.Actor{UID: 7, Name: "user@example.com", RequestID: "req-1"}
+	_, err := store.SaveLink(storage.Link{Alias: "promo", URL: "https://example.com", OwnerUID: 7}, actor)
+	require.NoError(t, err)
+
+	entries, err := store.ListAudit(storage.AuditFilter{Alias: "promo"})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, storage.AuditLinkCreate, entries[0].Action)
+	require.Equal(t, int64(7), entries[0].ActorUID)
+	require.Equal(t, "req-1", entries[0].RequestID)
+	require.Empty(t, entries[0].Before)
+	require.Contains(t, string(entries[0].After), `"url":"https://example.com"`)
+
+	messages, err := store.PendingOutbox(10)
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	require.Equal(t, storage.EventLinkCreated, messages[0].EventType)
+
+	// Неудавшееся изменение не оставляет ни записи аудита, ни события
+	_, err = store.SaveLink(storage.Link{Alias: "promo", URL: "https://example.org"}, actor)
+	require.ErrorIs(t, err, storage.ErrURLExists)
+
+	entries, err = store.ListAudit(storage.AuditFilter{Alias: "promo"})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	messages, err = store.PendingOutbox(10)
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+}
+
+func TestNewStorage_MigrateFromScratch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "storage.db")
+
+	// БД первой версии сервиса: только таблица ссылок, user_version = 0
+	db, err := sql.Open("sqlite3", path)
+	require.NoError(t, err)
+	_, err = db.Exec(`CREATE TABLE url(
+		id INTEGER PRIMARY KEY,
+		alias TEXT NOT NULL UNIQUE,
+		url TEXT NOT NULL);
+	INSERT INTO url(alias, url) VALUES ('legacy', 'https://example.com/legacy');`)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	store, err := sqlite.NewStorage(path)
+	require.NoError(t, err)
+
+	got, err := store.GetURL("legacy")
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/legacy", got)
+	require.NoError(t, store.Close())
+
+	db, err = sql.Open("sqlite3", path)
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	var version int
+	require.NoError(t, db.QueryRow("PRAGMA user_version").Scan(&version))
+	require.Equal(t, sqlite.SchemaVersion(), version)
+
+	// Повторное открытие уже обновленной БД ничего не ломает
+	store, err = sqlite.NewStorage(path)
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+}
+
+func TestDeleteURL_RestoreAndPurge(t *testing.T) {
+	store := newStorage(t)
+
+	_, err := store.SaveLink(storage.Link{Alias: "promo", URL: "https://example.com/old"}, storage.Actor{})
+	require.NoError(t, err)
+
+	require.NoError(t, store.DeleteURL("", "promo", storage.Actor{}))
+	require.ErrorIs(t, store.DeleteURL("", "promo", storage.Actor{}), storage.ErrURLNotFound)
+
+	_, err = store.GetLink("", "promo")
+	require.ErrorIs(t, err, storage.ErrURLNotFound)
+
+	deleted, err := store.GetDeletedLink("", "promo")
+	require.NoError(t, err)
+	require.NotNil(t, deleted.DeletedAt)
+
+	// Удаленная ссылка еще занимает алиас
+	_, err = store.SaveLink(storage.Link{Alias: "promo", URL: "https://example.com/new"}, storage.Actor{})
+	require.ErrorIs(t, err, storage.ErrURLExists)
+
+	require.NoError(t, store.RestoreURL("", "promo", storage.Actor{}))
+	require.ErrorIs(t, store.RestoreURL("", "promo", storage.Actor{}), storage.ErrURLNotFound)
+
+	link, err := store.GetLink("", "promo")
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/old", link.URL)
+	require.Nil(t, link.DeletedAt)
+
+	// Очистка не трогает ссылки, удаленные позже срока
+	require.NoError(t, store.DeleteURL("", "promo", storage.Actor{}))
+
+	n, err := store.PurgeDeleted(time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	n, err = store.PurgeDeleted(time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+
+	require.ErrorIs(t, store.RestoreURL("", "promo", storage.Actor{}), storage.ErrURLNotFound)
+
+	// После очистки алиас свободен
+	_, err = store.SaveLink(storage.Link{Alias: "promo", URL: "https://example.com/new"}, storage.Actor{})
+	require.NoError(t, err)
+
+	entries, err := store.ListAudit(storage.AuditFilter{Alias: "promo", Action: storage.AuditLinkPurge})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
+
+func TestOutbox(t *testing.T) {
+	store := newStorage(t)
+
+	// Пока outbox выключен, события в него не пишутся
+	_, err := store.SaveLink(storage.Link{Alias: "before", URL: "https://example.com"}, storage.Actor{})
+	require.NoError(t, err)
+
+	messages, err := store.PendingOutbox(10)
+	require.NoError(t, err)
+	require.Empty(t, messages)
+
+	store.SetOutboxEvents([]string{storage.EventLinkCreated, storage.EventLinkDeleted})
+
+	_, err = store.SaveLink(storage.Link{Alias: "promo", URL: "https://example.com/promo"}, storage.Actor{})
+	require.NoError(t, err)
+	require.NoError(t, store.UpdateLink(storage.Link{Alias: "promo", URL: "https://example.com/new"}, storage.Actor{}))
+	require.NoError(t, store.DeleteURL("", "promo", storage.Actor{}))
+
+	// Обновление не входит в список событий outbox
+	messages, err = store.PendingOutbox(10)
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	require.Equal(t, storage.EventLinkCreated, messages[0].EventType)
+	require.Equal(t, storage.EventLinkDeleted, messages[1].EventType)
+	require.Equal(t, messages[0].LinkID, messages[1].LinkID)
+
+	var event storage.Event
+	require.NoError(t, json.Unmarshal(messages[0].Payload, &event))
+	require.Equal(t, messages[0].EventID, event.ID)
+	require.Equal(t, "promo", event.Link.Alias)
+	require.Equal(t, "https://example.com/promo", event.Link.URL)
+
+	require.NoError(t, store.RecordOutboxFailure(messages[0].ID, "broker unavailable"))
+	require.NoError(t, store.MarkOutboxPublished(messages[1].ID, time.Now()))
+
+	messages, err = store.PendingOutbox(10)
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	require.Equal(t, 1, messages[0].Attempts)
+	require.Equal(t, "broker unavailable", messages[0].LastError)
+
+	// Очищаются только опубликованные события
+	n, err := store.PurgeOutbox(time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+
+	messages, err = store.PendingOutbox(10)
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+}
+
+func TestReserveIdempotencyKey(t *testing.T) {
+	store := newStorage(t)
+
+	now := time.Now().Truncate(time.Second)
+	rec := storage.IdempotencyRecord{
+		Scope:       "uid:1",
+		Key:         "key-1",
+		Fingerprint: "hash-1",
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(time.Hour),
+	}
+
+	_, reserved, err := store.ReserveIdempotencyKey(rec, now.Add(-time.Minute))
+	require.NoError(t, err)
+	require.True(t, reserved)
+
+	// Повтор, пока первый запрос обрабатывается, получает незавершенную запись
+	retry := rec
+	retry.Fingerprint = "hash-2"
+	existing, reserved, err := store.ReserveIdempotencyKey(retry, now.Add(-time.Minute))
+	require.NoError(t, err)
+	require.False(t, reserved)
+	require.False(t, existing.Completed)
+	require.Equal(t, "hash-1", existing.Fingerprint)
+
+	// Тот же ключ другого клиента не пересекается с первым
+	other := rec
+	other.Scope = "uid:2"
+	_, reserved, err = store.ReserveIdempotencyKey(other, now.Add(-time.Minute))
+	require.NoError(t, err)
+	require.True(t, reserved)
+
+	// Незавершенная запись старше staleBefore (обработка прервалась) занимается заново
+	existing, reserved, err = store.ReserveIdempotencyKey(retry, now.Add(time.Second))
+	require.NoError(t, err)
+	require.True(t, reserved)
+	require.Equal(t, "hash-2", existing.Fingerprint)
+
+	// Завершенная запись возвращается с ответом и не занимается заново
+	require.NoError(t, store.CompleteIdempotencyKey(rec.Scope, rec.Key, 200, "application/json", []byte(`{"status":"OK"}`)))
+
+	existing, reserved, err = store.ReserveIdempotencyKey(rec, now.Add(time.Second))
+	require.NoError(t, err)
+	require.False(t, reserved)
+	require.True(t, existing.Completed)
+	require.Equal(t, 200, existing.StatusCode)
+	require.Equal(t, "application/json", existing.ContentType)
+	require.Equal(t, `{"status":"OK"}`, string(existing.Body))
+
+	// Освобождается только незавершенная запись
+	require.NoError(t, store.ReleaseIdempotencyKey(rec.Scope, rec.Key))
+	_, reserved, err = store.ReserveIdempotencyKey(rec, now.Add(-time.Minute))
+	require.NoError(t, err)
+	require.False(t, reserved)
+
+	require.NoError(t, store.ReleaseIdempotencyKey(other.Scope, other.Key))
+	_, reserved, err = store.ReserveIdempotencyKey(other, now.Add(-time.Minute))
+	require.NoError(t, err)
+	require.True(t, reserved)
+}
+
+func TestAliasMatch_KeyUniqueness(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "storage.db")
+
+	store, err := sqlite.NewStorage(path)
+	require.NoError(t, err)
+
+	// В точном режиме Promo и promo - разные ссылки
+	for _, alias := range []string{"Promo", "promo"} {
+		_, err := store.SaveLink(storage.Link{Alias: alias, URL: "https://example.com/" + alias}, storage.Actor{})
+		require.NoError(t, err)
+	}
+
+	// Переключение режима не проходит, пока ссылки совпадают, в том числе удаленные
+	require.ErrorIs(t, store.SetAliasMatch(aliaspolicy.MatchCaseInsensitive), storage.ErrAliasConflict)
+	require.NoError(t, store.DeleteURL("", "Promo", storage.Actor{}))
+	require.ErrorIs(t, store.SetAliasMatch(aliaspolicy.MatchCaseInsensitive), storage.ErrAliasConflict)
+
+	_, err = store.PurgeDeleted(time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.NoError(t, store.SetAliasMatch(aliaspolicy.MatchCaseInsensitive))
+
+	link, err := store.GetLink("", "PROMO")
+	require.NoError(t, err)
+	require.Equal(t, "promo", link.Alias)
+
+	_, err = store.SaveLink(storage.Link{Alias: "PROMO", URL: "https://example.com/other"}, storage.Actor{})
+	require.ErrorIs(t, err, storage.ErrURLExists)
+
+	// Ключи уникальны в пределах домена
+	_, err = store.SaveLink(storage.Link{Domain: "go.example.com", Alias: "PROMO", URL: "https://example.com/other"}, storage.Actor{})
+	require.NoError(t, err)
+
+	// В режиме похожих символов 0 и o совпадают
+	_, err = store.SaveLink(storage.Link{Alias: "c0de", URL: "https://example.com/code"}, storage.Actor{})
+	require.NoError(t, err)
+	require.NoError(t, store.SetAliasMatch(aliaspolicy.MatchConfusable))
+
+	_, err = store.SaveLink(storage.Link{Alias: "CODE", URL: "https://example.com/other"}, storage.Actor{})
+	require.ErrorIs(t, err, storage.ErrURLExists)
+
+	got, err := store.GetURL("code")
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/code", got)
+
+	// Режим сохраняется в БД вместе с ключами
+	require.NoError(t, store.Close())
+
+	store, err = sqlite.NewStorage(path)
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+
+	got, err = store.GetURL("CODE")
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/code", got)
+}
@@ -0,0 +1,174 @@
+// internal/storage/sqlite/threat.go
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"url-shortener/internal/storage"
+)
+
+// resetThreat - часть UPDATE url: решение администратора "ложное срабатывание" относится
+// к прежнему адресу, при смене адреса (параметр - новый адрес) оно сбрасывается.
+// Новый адрес к этому моменту уже проверен обработчиком (адрес из списка не сохраняется).
+// Отключенная ссылка остается отключенной до следующей проверки
+const resetThreat = `threat_status = CASE WHEN url = ? OR threat_status <> 'cleared' THEN threat_status ELSE '' END`
+
+// ScreeningBatch - очередная пачка ссылок для проверки по списку опасных адресов,
+// в порядке id начиная после afterID. Удаленные ссылки не проверяются
+func (s *Storage) ScreeningBatch(afterID int64, limit int) ([]storage.ScreenTask, error) {
+	const op = "storage.sqlite.ScreeningBatch"
+
+	rows, err := s.db.Query(
+		`SELECT id, url, fallback_url, threat_status FROM url
+		WHERE deleted_at IS NULL AND id > ? ORDER BY id LIMIT ?`,
+		afterID, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	var tasks []storage.ScreenTask
+	for rows.Next() {
+		var (
+			task     storage.ScreenTask
+			fallback string
+		)
+		if err := rows.Scan(&task.LinkID, &task.URL, &fallback, &task.Threat); err != nil {
+			_ = rows.Close()
+
+			return nil, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+
+		task.URLs = []string{task.URL}
+		if fallback != "" {
+			task.URLs = append(task.URLs, fallback)
+		}
+		tasks = append(tasks, task)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// Варианты A/B-теста и правила читаются после закрытия основного запроса
+	for i := range tasks {
+		targets, err := targetsByURLID(s.db, tasks[i].LinkID)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		for _, t := range targets {
+			tasks[i].URLs = append(tasks[i].URLs, t.URL)
+		}
+
+		rules, err := rulesByURLID(s.db, tasks[i].LinkID)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		for _, r := range rules {
+			tasks[i].URLs = append(tasks[i].URLs, r.TargetURL)
+		}
+	}
+
+	return tasks, nil
+}
+
+// SetThreat сохраняет результат проверки ссылки linkID с адресом url по списку опасных адресов.
+// Непустой threatType отключает ссылку (статус flagged), пустой - снимает отметку flagged,
+// если адрес ушел из списка. Решения администратора (confirmed, cleared) не меняются.
+// Если адрес перехода успел измениться, ничего не делает. Возвращает true, если статус изменился
+func (s *Storage) SetThreat(linkID int64, url string, threatType string) (bool, error) {
+	const op = "storage.sqlite.SetThreat"
+
+	var (
+		res sql.Result
+		err error
+	)
+	if threatType != "" {
+		res, err = s.db.Exec(
+			`UPDATE url SET threat_status = ?, threat_type = ?, threat_flagged_at = ?,
+				threat_reviewed_by = '', threat_reviewed_at = NULL
+			WHERE id = ? AND url = ? AND threat_status = ''`,
+			storage.ThreatFlagged, threatType, time.Now().Unix(), linkID, url,
+		)
+	} else {
+		res, err = s.db.Exec(
+			`UPDATE url SET threat_status = '', threat_type = '', threat_flagged_at = NULL
+			WHERE id = ? AND url = ? AND threat_status = ?`,
+			linkID, url, storage.ThreatFlagged,
+		)
+	}
+	if err != nil {
+		return false, fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return n > 0, nil
+}
+
+// ReviewThreat - решение администратора по отключенной ссылке: status storage.ThreatConfirmed
+// (ссылка остается отключенной) или storage.ThreatCleared (ложное срабатывание, ссылка снова работает).
+// Если ссылки нет - storage.ErrURLNotFound, если проверка ее не отмечала - storage.ErrNotFlagged.
+// Решение записывается в журнал аудита от имени actor
+func (s *Storage) ReviewThreat(domain string, alias string, status string, actor storage.Actor) (storage.Link, error) {
+	const op = "storage.sqlite.ReviewThreat"
+
+	if status != storage.ThreatConfirmed && status != storage.ThreatCleared {
+		return storage.Link{}, fmt.Errorf("%s: unknown review status %q", op, status)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return storage.Link{}, fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	before, err := linkSnapshot(tx, domain, s.match.Key(alias))
+	if errors.Is(err, sql.ErrNoRows) || err == nil && before.DeletedAt != nil {
+		return storage.Link{}, storage.ErrURLNotFound
+	}
+	if err != nil {
+		return storage.Link{}, fmt.Errorf("%s: read link: %w", op, err)
+	}
+	if before.Threat == nil {
+		return storage.Link{}, storage.ErrNotFlagged
+	}
+
+	_, err = tx.Exec(
+		"UPDATE url SET threat_status = ?, threat_reviewed_by = ?, threat_reviewed_at = ? WHERE id = ?",
+		status, reviewer(actor), time.Now().Unix(), before.ID,
+	)
+	if err != nil {
+		return storage.Link{}, fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	after, err := linkSnapshot(tx, domain, s.match.Key(alias))
+	if err != nil {
+		return storage.Link{}, fmt.Errorf("%s: read link: %w", op, err)
+	}
+	if err := writeAudit(tx, actor, storage.AuditLinkReview, domain, before.Alias, before, after); err != nil {
+		return storage.Link{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return storage.Link{}, fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+
+	return after, nil
+}
+
+// reviewer - кто принял решение: имя пользователя или, если его нет, UID
+func reviewer(actor storage.Actor) string {
+	if actor.Name != "" || actor.UID == 0 {
+		return actor.Name
+	}
+
+	return strconv.FormatInt(actor.UID, 10)
+}
@@ -0,0 +1,123 @@
+// internal/storage/sqlite/transfer.go
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"url-shortener/internal/storage"
+)
+
+// Сколько ссылок читать за один запрос при выгрузке
+const exportBatchSize = 500
+
+// ExportLinks передает в fn ссылки по фильтру (вместе с правилами и вариантами A/B-теста) в порядке id.
+// Ссылки читаются пачками, чтобы выгрузка не держала БД заблокированной и не загружала все в память.
+// Ошибка fn прерывает выгрузку
+func (s *Storage) ExportLinks(filter storage.ListFilter, fn func(storage.Link) error) error {
+	const op = "storage.sqlite.ExportLinks"
+
+	where, args, err := listWhere(filter)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	query := "SELECT " + linkColumns + " FROM url WHERE " + strings.Join(where, " AND ") +
+		" AND id > ? ORDER BY id LIMIT ?"
+
+	var lastID int64
+	for {
+		links, err := s.queryLinks(query, append(args, lastID, exportBatchSize)...)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		for _, link := range links {
+			if link.Rules, err = rulesByURLID(s.db, link.ID); err != nil {
+				return fmt.Errorf("%s: %w", op, err)
+			}
+			if link.Targets, err = targetsByURLID(s.db, link.ID); err != nil {
+				return fmt.Errorf("%s: %w", op, err)
+			}
+
+			if err := fn(link); err != nil {
+				return err
+			}
+		}
+
+		if len(links) < exportBatchSize {
+			return nil
+		}
+		lastID = links[len(links)-1].ID
+	}
+}
+
+// ReplaceLink - заменить все параметры существующей ссылки (поиск по домену и алиасу),
+// включая правила редиректа и варианты A/B-теста. Счетчики переходов ссылки сохраняются,
+// счетчики вариантов - нет, так как варианты создаются заново (и проверяются на доступность в ближайший проход).
+// Рабочее пространство и автор ссылки не меняются.
+// Замена записывается в журнал аудита от имени actor как изменение ссылки
+func (s *Storage) ReplaceLink(link storage.Link, actor storage.Actor) error {
+	const op = "storage.sqlite.ReplaceLink"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	key := s.match.Key(link.Alias)
+
+	before, err := linkSnapshot(tx, link.Domain, key)
+	if errors.Is(err, sql.ErrNoRows) || err == nil && before.DeletedAt != nil {
+		return storage.ErrURLNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("%s: read link: %w", op, err)
+	}
+
+	_, err = tx.Exec(
+		`UPDATE url SET url = ?, not_before = ?, not_after = ?, fallback_url = ?, sticky = ?,
+			utm_source = ?, utm_medium = ?, utm_campaign = ?, query_policy = ?,
+			redirect_code = ?, interstitial = ?, title = ?,
+			`+resetPreview+`, health_checked_at = NULL, `+resetThreat+`
+		WHERE id = ?`,
+		link.URL, toUnix(link.NotBefore), toUnix(link.NotAfter), link.FallbackURL, link.Sticky,
+		link.UTMSource, link.UTMMedium, link.UTMCampaign, link.QueryPolicy,
+		link.RedirectCode, link.Interstitial, link.Title,
+		link.URL, link.URL,
+		before.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	if err := replaceRules(tx, before.ID, link.Rules); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if _, err := tx.Exec("DELETE FROM url_target WHERE url_id = ?", before.ID); err != nil {
+		return fmt.Errorf("%s: delete targets: %w", op, err)
+	}
+	if err := insertTargets(tx, before.ID, link.Targets); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	after, err := linkSnapshot(tx, link.Domain, key)
+	if err != nil {
+		return fmt.Errorf("%s: read updated link: %w", op, err)
+	}
+	if err := writeAudit(tx, actor, storage.AuditLinkUpdate, link.Domain, before.Alias, before, after); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := s.recordEvent(tx, storage.EventLinkUpdated, after, nil); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,300 @@
+// internal/storage/sqlite/webhooks.go
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"url-shortener/internal/storage"
+)
+
+const webhookColumns = "id, uid, workspace_id, url, events, secret, created_at"
+
+func scanWebhook(row rowScanner) (storage.Webhook, error) {
+	var (
+		hook      storage.Webhook
+		events    string
+		createdAt int64
+	)
+
+	err := row.Scan(&hook.ID, &hook.UID, &hook.WorkspaceID, &hook.URL, &events, &hook.Secret, &createdAt)
+	if err != nil {
+		return storage.Webhook{}, err
+	}
+
+	// events хранятся одной строкой через пробел
+	hook.Events = strings.Fields(events)
+	hook.CreatedAt = time.Unix(createdAt, 0).UTC()
+
+	return hook, nil
+}
+
+// SaveWebhook - сохранить новый вебхук
+func (s *Storage) SaveWebhook(hook storage.Webhook) (int64, error) {
+	const op = "storage.sqlite.SaveWebhook"
+
+	res, err := s.db.Exec(
+		`INSERT INTO webhook(uid, workspace_id, url, events, secret, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		hook.UID, hook.WorkspaceID, hook.URL, strings.Join(hook.Events, " "), hook.Secret, hook.CreatedAt.Unix(),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("%s: failed to get last insert id: %w", op, err)
+	}
+
+	return id, nil
+}
+
+// ListWebhooks - вебхуки пользователя
+func (s *Storage) ListWebhooks(uid int64) ([]storage.Webhook, error) {
+	const op = "storage.sqlite.ListWebhooks"
+
+	rows, err := s.db.Query("SELECT "+webhookColumns+" FROM webhook WHERE uid = ? ORDER BY id", uid)
+	if err != nil {
+		return nil, fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	hooks := make([]storage.Webhook, 0)
+	for rows.Next() {
+		hook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		hooks = append(hooks, hook)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return hooks, nil
+}
+
+// GetWebhook - вебхук пользователя uid. Чужой вебхук не найден
+func (s *Storage) GetWebhook(uid int64, id int64) (storage.Webhook, error) {
+	const op = "storage.sqlite.GetWebhook"
+
+	hook, err := scanWebhook(s.db.QueryRow("SELECT "+webhookColumns+" FROM webhook WHERE id = ? AND uid = ?", id, uid))
+	if errors.Is(err, sql.ErrNoRows) {
+		return storage.Webhook{}, storage.ErrWebhookNotFound
+	}
+	if err != nil {
+		return storage.Webhook{}, fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	return hook, nil
+}
+
+// DeleteWebhook - удалить вебхук пользователя uid вместе с его доставками
+func (s *Storage) DeleteWebhook(uid int64, id int64) error {
+	const op = "storage.sqlite.DeleteWebhook"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.Exec("DELETE FROM webhook WHERE id = ? AND uid = ?", id, uid)
+	if err != nil {
+		return fmt.Errorf("%s: delete webhook: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if affected == 0 {
+		return storage.ErrWebhookNotFound
+	}
+
+	if _, err := tx.Exec("DELETE FROM webhook_delivery WHERE webhook_id = ?", id); err != nil {
+		return fmt.Errorf("%s: delete deliveries: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+
+	return nil
+}
+
+const deliveryColumns = `id, webhook_id, event_id, event_type, status, attempts, next_attempt_at,
+	last_status_code, last_error, created_at, delivered_at`
+
+func scanDelivery(row rowScanner, extra ...any) (storage.WebhookDelivery, error) {
+	var (
+		d                          storage.WebhookDelivery
+		createdAt                  int64
+		nextAttemptAt, deliveredAt sql.NullInt64
+	)
+
+	dest := append([]any{
+		&d.ID, &d.WebhookID, &d.EventID, &d.EventType, &d.Status, &d.Attempts, &nextAttemptAt,
+		&d.LastStatusCode, &d.LastError, &createdAt, &deliveredAt,
+	}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return storage.WebhookDelivery{}, err
+	}
+
+	d.NextAttemptAt = fromUnix(nextAttemptAt)
+	d.CreatedAt = time.Unix(createdAt, 0).UTC()
+	d.DeliveredAt = fromUnix(deliveredAt)
+
+	return d, nil
+}
+
+// ListDeliveries - журнал доставок вебхука пользователя uid, от новых к старым
+func (s *Storage) ListDeliveries(uid int64, webhookID int64, limit int) ([]storage.WebhookDelivery, error) {
+	const op = "storage.sqlite.ListDeliveries"
+
+	if _, err := s.GetWebhook(uid, webhookID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(
+		"SELECT "+deliveryColumns+" FROM webhook_delivery WHERE webhook_id = ? ORDER BY id DESC LIMIT ?",
+		webhookID, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	deliveries := make([]storage.WebhookDelivery, 0)
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return deliveries, nil
+}
+
+// DueDeliveries - доставки, время попытки которых наступило к now, в порядке очереди
+func (s *Storage) DueDeliveries(now time.Time, limit int) ([]storage.PendingDelivery, error) {
+	const op = "storage.sqlite.DueDeliveries"
+
+	rows, err := s.db.Query(
+		`SELECT d.id, d.webhook_id, d.event_id, d.event_type, d.status, d.attempts, d.next_attempt_at,
+			d.last_status_code, d.last_error, d.created_at, d.delivered_at, d.payload, w.url, w.secret
+		FROM webhook_delivery d JOIN webhook w ON w.id = d.webhook_id
+		WHERE d.status = ? AND d.next_attempt_at <= ?
+		ORDER BY d.next_attempt_at, d.id LIMIT ?`,
+		storage.DeliveryPending, now.Unix(), limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var due []storage.PendingDelivery
+	for rows.Next() {
+		var (
+			p       storage.PendingDelivery
+			payload string
+		)
+
+		p.WebhookDelivery, err = scanDelivery(rows, &payload, &p.URL, &p.Secret)
+		if err != nil {
+			return nil, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		p.Payload = []byte(payload)
+
+		due = append(due, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return due, nil
+}
+
+// RecordDeliveryAttempt - записать итог попытки доставки и запланировать следующую
+func (s *Storage) RecordDeliveryAttempt(id int64, attempt storage.DeliveryAttempt) error {
+	const op = "storage.sqlite.RecordDeliveryAttempt"
+
+	status := storage.DeliveryPending
+	var deliveredAt sql.NullInt64
+	switch {
+	case attempt.Delivered:
+		status = storage.DeliveryDelivered
+		deliveredAt = sql.NullInt64{Int64: attempt.At.Unix(), Valid: true}
+	case attempt.NextAttemptAt == nil:
+		status = storage.DeliveryFailed
+	}
+
+	_, err := s.db.Exec(
+		`UPDATE webhook_delivery SET status = ?, attempts = attempts + 1, next_attempt_at = ?,
+			last_status_code = ?, last_error = ?, delivered_at = ?
+		WHERE id = ?`,
+		status, toUnix(attempt.NextAttemptAt), attempt.StatusCode, attempt.Error, deliveredAt, id,
+	)
+	if err != nil {
+		return fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	return nil
+}
+
+// webhookSubscribers - вебхуки, подписанные на событие ссылки.
+// Вебхук без пространства получает события ссылок своего пользователя, вебхук пространства -
+// события ссылок пространства, пока пользователь состоит в нем
+func webhookSubscribers(tx *sql.Tx, eventType string, link storage.Link) ([]int64, error) {
+	rows, err := tx.Query(
+		`SELECT w.id FROM webhook w
+		WHERE (w.events = '' OR instr(' ' || w.events || ' ', ' ' || ? || ' ') > 0)
+		AND (
+			(w.workspace_id = 0 AND w.uid = ? AND w.uid != 0)
+			OR (w.workspace_id != 0 AND w.workspace_id = ? AND EXISTS (
+				SELECT 1 FROM workspace_member m WHERE m.workspace_id = w.workspace_id AND m.uid = w.uid))
+		)`,
+		eventType, link.OwnerUID, link.WorkspaceID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("webhooks: select subscribers: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("webhooks: scan subscriber: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("webhooks: select subscribers: %w", err)
+	}
+
+	return ids, nil
+}
+
+// enqueueDeliveries ставит событие в очередь доставки вебхуков webhookIDs
+func enqueueDeliveries(tx *sql.Tx, webhookIDs []int64, event storage.Event, payload []byte) error {
+	for _, id := range webhookIDs {
+		_, err := tx.Exec(
+			`INSERT INTO webhook_delivery(webhook_id, event_id, event_type, payload, status, next_attempt_at, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			id, event.ID, event.Type, string(payload), storage.DeliveryPending, event.CreatedAt.Unix(), event.CreatedAt.Unix(),
+		)
+		if err != nil {
+			return fmt.Errorf("webhooks: enqueue delivery: %w", err)
+		}
+	}
+
+	return nil
+}
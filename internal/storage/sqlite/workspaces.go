@@ -0,0 +1,238 @@
+// internal/storage/sqlite/workspaces.go
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"url-shortener/internal/storage"
+)
+
+// CreateWorkspace - создать рабочее пространство. Создатель становится его владельцем
+func (s *Storage) CreateWorkspace(name string, ownerUID int64) (storage.Workspace, error) {
+	const op = "storage.sqlite.CreateWorkspace"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return storage.Workspace{}, fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	now := time.Now().UTC().Truncate(time.Second)
+
+	res, err := tx.Exec("INSERT INTO workspace(name, created_at) VALUES (?, ?)", name, now.Unix())
+	if err != nil {
+		return storage.Workspace{}, fmt.Errorf("%s: insert workspace: %w", op, err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return storage.Workspace{}, fmt.Errorf("%s: failed to get last insert id: %w", op, err)
+	}
+
+	_, err = tx.Exec(
+		"INSERT INTO workspace_member(workspace_id, uid, role, created_at) VALUES (?, ?, ?, ?)",
+		id, ownerUID, storage.RoleOwner, now.Unix(),
+	)
+	if err != nil {
+		return storage.Workspace{}, fmt.Errorf("%s: insert owner: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return storage.Workspace{}, fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+
+	return storage.Workspace{ID: id, Name: name, CreatedAt: now, Role: storage.RoleOwner}, nil
+}
+
+// ListWorkspaces - рабочие пространства, в которых состоит пользователь, вместе с его ролью
+func (s *Storage) ListWorkspaces(uid int64) ([]storage.Workspace, error) {
+	const op = "storage.sqlite.ListWorkspaces"
+
+	rows, err := s.db.Query(`SELECT w.id, w.name, w.created_at, m.role
+		FROM workspace w JOIN workspace_member m ON m.workspace_id = w.id
+		WHERE m.uid = ? ORDER BY w.id`, uid)
+	if err != nil {
+		return nil, fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	workspaces := make([]storage.Workspace, 0)
+	for rows.Next() {
+		var (
+			w         storage.Workspace
+			createdAt int64
+		)
+		if err := rows.Scan(&w.ID, &w.Name, &createdAt, &w.Role); err != nil {
+			return nil, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		w.CreatedAt = time.Unix(createdAt, 0).UTC()
+		workspaces = append(workspaces, w)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return workspaces, nil
+}
+
+func scanMember(row rowScanner) (storage.Member, error) {
+	var (
+		m         storage.Member
+		createdAt int64
+	)
+
+	if err := row.Scan(&m.WorkspaceID, &m.UID, &m.Role, &createdAt); err != nil {
+		return storage.Member{}, err
+	}
+
+	m.CreatedAt = time.Unix(createdAt, 0).UTC()
+
+	return m, nil
+}
+
+// GetMember - получить участника рабочего пространства
+func (s *Storage) GetMember(workspaceID int64, uid int64) (storage.Member, error) {
+	const op = "storage.sqlite.GetMember"
+
+	m, err := scanMember(s.db.QueryRow(
+		"SELECT workspace_id, uid, role, created_at FROM workspace_member WHERE workspace_id = ? AND uid = ?",
+		workspaceID, uid,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return storage.Member{}, storage.ErrMemberNotFound
+	}
+	if err != nil {
+		return storage.Member{}, fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	return m, nil
+}
+
+// ListMembers - участники рабочего пространства
+func (s *Storage) ListMembers(workspaceID int64) ([]storage.Member, error) {
+	const op = "storage.sqlite.ListMembers"
+
+	rows, err := s.db.Query(
+		"SELECT workspace_id, uid, role, created_at FROM workspace_member WHERE workspace_id = ? ORDER BY created_at, uid",
+		workspaceID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	members := make([]storage.Member, 0)
+	for rows.Next() {
+		m, err := scanMember(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		members = append(members, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return members, nil
+}
+
+// SetMember - добавить участника или изменить его роль.
+// Нельзя понизить единственного владельца: пространство осталось бы без управления
+func (s *Storage) SetMember(member storage.Member) error {
+	const op = "storage.sqlite.SetMember"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var exists bool
+	err = tx.QueryRow("SELECT EXISTS(SELECT 1 FROM workspace WHERE id = ?)", member.WorkspaceID).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("%s: find workspace: %w", op, err)
+	}
+	if !exists {
+		return storage.ErrWorkspaceNotFound
+	}
+
+	if member.Role != storage.RoleOwner {
+		if err := checkNotLastOwner(tx, member.WorkspaceID, member.UID); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(`INSERT INTO workspace_member(workspace_id, uid, role, created_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT(workspace_id, uid) DO UPDATE SET role = excluded.role`,
+		member.WorkspaceID, member.UID, member.Role, time.Now().Unix(),
+	)
+	if err != nil {
+		return fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+
+	return nil
+}
+
+// RemoveMember - исключить участника из рабочего пространства.
+// Единственного владельца исключить нельзя
+func (s *Storage) RemoveMember(workspaceID int64, uid int64) error {
+	const op = "storage.sqlite.RemoveMember"
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := checkNotLastOwner(tx, workspaceID, uid); err != nil {
+		return err
+	}
+
+	res, err := tx.Exec("DELETE FROM workspace_member WHERE workspace_id = ? AND uid = ?", workspaceID, uid)
+	if err != nil {
+		return fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if affected == 0 {
+		return storage.ErrMemberNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+
+	return nil
+}
+
+// checkNotLastOwner возвращает storage.ErrLastOwner, если uid - единственный владелец пространства
+func checkNotLastOwner(tx *sql.Tx, workspaceID int64, uid int64) error {
+	var otherOwners, isOwner int
+	err := tx.QueryRow(`SELECT
+			COUNT(CASE WHEN uid != ? THEN 1 END),
+			COUNT(CASE WHEN uid = ? THEN 1 END)
+		FROM workspace_member WHERE workspace_id = ? AND role = ?`,
+		uid, uid, workspaceID, storage.RoleOwner,
+	).Scan(&otherOwners, &isOwner)
+	if err != nil {
+		return fmt.Errorf("count owners: %w", err)
+	}
+
+	if isOwner > 0 && otherOwners == 0 {
+		return storage.ErrLastOwner
+	}
+
+	return nil
+}
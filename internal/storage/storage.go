@@ -2,9 +2,490 @@
 
 package storage
 
-import "errors"
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
 
 var (
 	ErrURLNotFound = errors.New("url not found")
 	ErrURLExists   = errors.New("url exists")
+
+	ErrDomainNotFound = errors.New("domain not found")
+	ErrDomainExists   = errors.New("domain exists")
+
+	ErrWorkspaceNotFound = errors.New("workspace not found")
+	ErrMemberNotFound    = errors.New("member not found")
+	ErrLastOwner         = errors.New("workspace must have at least one owner")
+
+	ErrAPIKeyNotFound = errors.New("api key not found")
+
+	ErrAliasReserved       = errors.New("alias is reserved")
+	ErrReservationNotFound = errors.New("alias reservation not found")
+
+	ErrAliasConflict = errors.New("aliases conflict in the alias match mode")
+
+	ErrWebhookNotFound = errors.New("webhook not found")
+
+	ErrIdempotencyKeyNotFound = errors.New("idempotency key not found")
+
+	ErrNotFlagged = errors.New("link is not flagged by threat screening")
+)
+
+// Способы закрепления варианта A/B-теста за клиентом
+const (
+	StickyNone   = ""       // вариант выбирается заново на каждый переход
+	StickyCookie = "cookie" // выбранный вариант запоминается в cookie
+	StickyHash   = "hash"   // вариант определяется хешем IP и User-Agent клиента
+)
+
+// Состояния ссылки относительно окна активности
+const (
+	StateActive    = "active"    // ссылка работает
+	StateScheduled = "scheduled" // время запуска еще не наступило
+	StateEnded     = "ended"     // время действия закончилось
+	StateDeleted   = "deleted"   // ссылка удалена и ждет очистки, ее можно восстановить
+)
+
+// Link - сохраненная короткая ссылка со всеми параметрами
+type Link struct {
+	ID int64 `json:"id"`
+	// Короткий домен ссылки. Пустая строка - основной домен сервиса.
+	// Алиас уникален в пределах домена
+	Domain string `json:"domain,omitempty"`
+	Alias  string `json:"alias"`
+	URL    string `json:"url"`
+
+	// Рабочее пространство, которому принадлежит ссылка. 0 - общая ссылка без рабочего пространства
+	WorkspaceID int64 `json:"workspace_id,omitempty"`
+	// UID пользователя, создавшего ссылку. 0 - неизвестен (ссылка создана по basic auth)
+	OwnerUID int64 `json:"owner_uid,omitempty"`
+
+	// Окно активности ссылки. nil - граница не задана
+	NotBefore *time.Time `json:"not_before,omitempty"`
+	NotAfter  *time.Time `json:"not_after,omitempty"`
+	// Куда перенаправлять после окончания окна активности
+	FallbackURL string `json:"fallback_url,omitempty"`
+
+	// Упорядоченные правила умного редиректа. Первое подходящее правило
+	// определяет адрес перехода, если ни одно не подошло - используется URL
+	Rules []Rule `json:"rules,omitempty"`
+
+	// Варианты A/B-теста. Если заданы, трафик основного адреса
+	// распределяется между ними пропорционально весам
+	Targets []Target `json:"targets,omitempty"`
+	Sticky  string   `json:"sticky,omitempty"` // одно из Sticky*
+
+	// UTM-метки по умолчанию: добавляются к адресу перехода, если в нем нет своих
+	UTMSource   string `json:"utm_source,omitempty"`
+	UTMMedium   string `json:"utm_medium,omitempty"`
+	UTMCampaign string `json:"utm_campaign,omitempty"`
+	// Политика переноса параметров запроса короткой ссылки (одна из utm.Policy*).
+	// Пусто - политика по умолчанию из конфига
+	QueryPolicy string `json:"query_policy,omitempty"`
+
+	// HTTP-код редиректа: 301, 302, 307 или 308. 0 - по умолчанию (302)
+	RedirectCode int `json:"redirect_code,omitempty"`
+	// Показывать промежуточную страницу "вы переходите на ..." вместо мгновенного редиректа
+	Interstitial bool   `json:"interstitial,omitempty"`
+	Title        string `json:"title,omitempty"` // заголовок ссылки для страницы предпросмотра
+
+	Clicks int64 `json:"clicks"` // общее количество переходов
+
+	// Метаданные страницы перехода (заголовок, описание, картинка, иконка). Запрашиваются в фоне
+	Preview *LinkPreview `json:"preview,omitempty"`
+	// Результат последней проверки доступности страницы перехода. nil - еще не проверялась
+	Health *LinkHealth `json:"health,omitempty"`
+	// Адрес перехода найден в списке опасных адресов. nil - не найден
+	Threat *LinkThreat `json:"threat,omitempty"`
+
+	// Когда ссылка удалена. nil - не удалена. Удаленная ссылка не обслуживается,
+	// но до очистки по сроку хранения ее можно восстановить, а алиас остается занятым
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// Статусы получения метаданных страницы перехода
+const (
+	PreviewPending = "pending" // еще не запрошены (или адрес перехода изменился)
+	PreviewOK      = "ok"
+	PreviewFailed  = "failed" // страница недоступна или это не HTML-страница
+)
+
+// LinkPreview - метаданные страницы перехода
+type LinkPreview struct {
+	Status      string     `json:"status"` // одно из Preview*
+	Title       string     `json:"title,omitempty"`
+	Description string     `json:"description,omitempty"`
+	Image       string     `json:"image,omitempty"`   // og:image
+	Favicon     string     `json:"favicon,omitempty"` // иконка сайта
+	Error       string     `json:"error,omitempty"`   // почему не удалось получить метаданные
+	FetchedAt   *time.Time `json:"fetched_at,omitempty"`
+}
+
+// Статусы доступности страницы перехода
+const (
+	HealthOK      = "ok"
+	HealthFailing = "failing" // последние проверки неудачны, но их меньше порога
+	HealthBroken  = "broken"  // страница не открывается несколько проверок подряд
+)
+
+// LinkHealth - результат проверки доступности страницы перехода
+type LinkHealth struct {
+	Status     string     `json:"status"`                // одно из Health*
+	StatusCode int        `json:"status_code,omitempty"` // 0 - ответа не было
+	Error      string     `json:"error,omitempty"`
+	Failures   int        `json:"failures,omitempty"` // неудачных проверок подряд
+	CheckedAt  *time.Time `json:"checked_at,omitempty"`
+}
+
+// Статусы проверки адреса перехода по списку опасных адресов
+const (
+	ThreatFlagged   = "flagged"   // найден в списке, ссылка отключена до решения администратора
+	ThreatConfirmed = "confirmed" // администратор подтвердил угрозу: ссылка отключена, даже если адрес уйдет из списка
+	ThreatCleared   = "cleared"   // администратор признал срабатывание ложным: ссылка работает
+)
+
+// LinkThreat - срабатывание проверки адреса перехода по списку опасных адресов
+type LinkThreat struct {
+	Status     string     `json:"status"` // одно из Threat*
+	Type       string     `json:"type"`   // тип угрозы из списка: MALWARE, SOCIAL_ENGINEERING...
+	FlaggedAt  *time.Time `json:"flagged_at,omitempty"`
+	ReviewedBy string     `json:"reviewed_by,omitempty"`
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty"`
+}
+
+// Blocked - ссылка отключена: переход по ней не выполняется
+func (t *LinkThreat) Blocked() bool {
+	return t != nil && (t.Status == ThreatFlagged || t.Status == ThreatConfirmed)
+}
+
+// ScreenTask - ссылка для проверки по списку опасных адресов
+type ScreenTask struct {
+	LinkID int64
+	URL    string
+	// Все адреса перехода: основной, запасной, варианты A/B-теста и правила умного редиректа
+	URLs   []string
+	Threat string // текущий статус (одно из Threat*), пусто - не срабатывала
+}
+
+// HealthTask - ссылка, которую пора проверить
+type HealthTask struct {
+	LinkID int64
+	URL    string
+	// Остальные адреса перехода: запасной URL, варианты A/B-теста и адреса правил, без повторов
+	Targets  []string
+	Status   string // результат предыдущей проверки, пусто - не проверялась
+	Failures int
+}
+
+// PreviewTask - ссылка, для которой нужно получить метаданные страницы
+type PreviewTask struct {
+	LinkID int64
+	URL    string
+}
+
+// Target - вариант адреса перехода для A/B-теста
+type Target struct {
+	ID     int64  `json:"id"`
+	URL    string `json:"url"`
+	Weight int    `json:"weight"`
+	Clicks int64  `json:"clicks"`
+}
+
+// Rule - правило умного редиректа.
+// Пустое условие подходит для любого клиента, непустые условия должны выполняться все сразу
+type Rule struct {
+	Platform  string `json:"platform,omitempty"` // ios, android или desktop
+	Language  string `json:"language,omitempty"` // языковой тег: "de" подходит и для "de-AT"
+	Country   string `json:"country,omitempty"`  // код страны ISO 3166-1 alpha-2
+	TargetURL string `json:"target_url"`
+}
+
+// StateAt возвращает состояние ссылки на момент времени t
+func (l Link) StateAt(t time.Time) string {
+	if l.NotBefore != nil && t.Before(*l.NotBefore) {
+		return StateScheduled
+	}
+	if l.NotAfter != nil && !t.Before(*l.NotAfter) {
+		return StateEnded
+	}
+
+	return StateActive
+}
+
+// ListFilter - параметры выборки списка ссылок
+type ListFilter struct {
+	State  string // одно из State*, пустая строка - все неудаленные ссылки
+	Domain string // короткий домен, пустая строка - все домены
+	// рабочее пространство, 0 - все ссылки
+	WorkspaceID int64
+	// если не 0 - только личные ссылки пользователя и ссылки пространств, в которых он состоит
+	MemberUID int64
+	// доступность страницы перехода (одно из Health*), пустая строка - любая
+	Health string
+	// статус проверки по списку опасных адресов (одно из Threat*), пустая строка - любой
+	Threat string
+}
+
+// Domain - дополнительный короткий домен, обслуживаемый сервисом
+type Domain struct {
+	ID   int64  `json:"id"`
+	Host string `json:"host"` // имя хоста в нижнем регистре, без порта
+	// Токен, который владелец домена должен разместить в TXT-записи для подтверждения
+	VerifyToken string     `json:"verify_token"`
+	VerifiedAt  *time.Time `json:"verified_at,omitempty"`
+	// Куда перенаправлять запросы к неизвестным алиасам этого домена. Пусто - ответ "not found"
+	DefaultURL string    `json:"default_url,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ReservedAlias - алиас, зарезервированный администратором для будущего использования.
+// Создать ссылку с таким алиасом может только администратор
+type ReservedAlias struct {
+	Domain     string    `json:"domain,omitempty"` // пусто - основной домен
+	Alias      string    `json:"alias"`
+	Note       string    `json:"note,omitempty"` // для чего зарезервирован
+	ReservedBy string    `json:"reserved_by,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Verified - подтверждено ли владение доменом
+func (d Domain) Verified() bool {
+	return d.VerifiedAt != nil
+}
+
+// Роли участников рабочего пространства, от большей к меньшей
+const (
+	RoleOwner  = "owner"  // управляет участниками и ссылками
+	RoleEditor = "editor" // создает, изменяет и удаляет ссылки
+	RoleViewer = "viewer" // только просматривает ссылки и статистику
 )
+
+// roleRank - старшинство ролей для RoleAtLeast
+var roleRank = map[string]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleOwner:  3,
+}
+
+// RoleAtLeast - дает ли роль role права не меньше, чем роль min
+func RoleAtLeast(role string, min string) bool {
+	rank, ok := roleRank[role]
+
+	return ok && rank >= roleRank[min]
+}
+
+// Workspace - рабочее пространство команды с общими ссылками
+type Workspace struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	// Роль текущего пользователя (заполняется при выборке пространств пользователя)
+	Role string `json:"role,omitempty"`
+}
+
+// Member - участник рабочего пространства
+type Member struct {
+	WorkspaceID int64     `json:"workspace_id"`
+	UID         int64     `json:"uid"`
+	Role        string    `json:"role"` // одно из Role*
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// APIKey - ключ доступа к API для машинных клиентов.
+// Сам ключ показывается пользователю один раз при создании, в БД хранится только его хеш
+type APIKey struct {
+	ID     int64    `json:"id"`
+	UID    int64    `json:"uid"` // владелец ключа: запросы с ключом выполняются от его имени
+	Name   string   `json:"name"`
+	Prefix string   `json:"prefix"` // начало ключа, чтобы пользователь мог отличить ключи друг от друга
+	Scopes []string `json:"scopes"` // разрешенные действия, например "links:write"
+
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"` // nil - бессрочный
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// ActiveAt - действует ли ключ в момент времени t (не отозван и не истек)
+func (k APIKey) ActiveAt(t time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+
+	return k.ExpiresAt == nil || t.Before(*k.ExpiresAt)
+}
+
+// Действия, которые записываются в журнал аудита
+const (
+	AuditLinkCreate  = "link.create"
+	AuditLinkUpdate  = "link.update"
+	AuditLinkRules   = "link.rules" // замена правил умного редиректа
+	AuditLinkDelete  = "link.delete"
+	AuditLinkRestore = "link.restore"
+	AuditLinkPurge   = "link.purge"  // окончательное удаление по истечении срока хранения
+	AuditLinkReview  = "link.review" // решение администратора по срабатыванию списка опасных адресов
+)
+
+// Actor - кто выполняет изменение. Передается в методы хранилища, изменяющие ссылки,
+// и записывается в журнал аудита в той же транзакции, что и само изменение
+type Actor struct {
+	UID       int64  // пользователь SSO, 0 - нет (basic auth)
+	KeyID     int64  // API-ключ, 0 - запрос без ключа
+	Name      string // логин basic auth или email пользователя
+	RequestID string
+}
+
+// AuditEntry - запись журнала аудита. Журнал только пополняется: записи не изменяются и не удаляются
+type AuditEntry struct {
+	ID         int64     `json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	ActorUID   int64     `json:"actor_uid,omitempty"`
+	ActorKeyID int64     `json:"actor_key_id,omitempty"`
+	ActorName  string    `json:"actor_name,omitempty"`
+	RequestID  string    `json:"request_id,omitempty"`
+	Action     string    `json:"action"` // одно из Audit*
+	Domain     string    `json:"domain,omitempty"`
+	Alias      string    `json:"alias"`
+	// Состояние до и после изменения в JSON. Пусто при создании (до) и удалении (после)
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+// AuditFilter - параметры выборки журнала аудита. Пустые поля не ограничивают выборку
+type AuditFilter struct {
+	Domain   string
+	Alias    string
+	Action   string
+	ActorUID int64
+	Since    *time.Time
+	Until    *time.Time
+	// Курсор постраничной выборки: только записи с ID меньше заданного
+	BeforeID int64
+	Limit    int // 0 - без ограничения
+}
+
+// События жизненного цикла ссылки, на которые можно подписать вебхук
+const (
+	EventLinkCreated  = "link.created"
+	EventLinkUpdated  = "link.updated" // в т.ч. замена правил умного редиректа
+	EventLinkDeleted  = "link.deleted"
+	EventLinkRestored = "link.restored"
+	EventLinkClicked  = "link.clicked"
+	// Проверка доступности: страница перехода перестала открываться / снова открывается
+	EventLinkBroken    = "link.broken"
+	EventLinkRecovered = "link.recovered"
+)
+
+// EventTypes - все типы событий
+var EventTypes = []string{
+	EventLinkCreated, EventLinkUpdated, EventLinkDeleted, EventLinkRestored, EventLinkClicked,
+	EventLinkBroken, EventLinkRecovered,
+}
+
+// Event - событие ссылки, тело запроса вебхука
+type Event struct {
+	ID        string      `json:"id"` // одинаков при повторных доставках, по нему получатель отбрасывает дубли
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Link      EventLink   `json:"link"`
+	Click     *EventClick `json:"click,omitempty"` // только для link.clicked
+}
+
+// EventLink - ссылка в событии
+type EventLink struct {
+	ID          int64  `json:"id"`
+	Domain      string `json:"domain,omitempty"`
+	Alias       string `json:"alias"`
+	URL         string `json:"url"`
+	Title       string `json:"title,omitempty"`
+	WorkspaceID int64  `json:"workspace_id,omitempty"`
+	OwnerUID    int64  `json:"owner_uid,omitempty"`
+	Clicks      int64  `json:"clicks"`
+	// Результат последней проверки доступности. nil - ссылка еще не проверялась
+	Health *LinkHealth `json:"health,omitempty"`
+}
+
+// EventClick - переход по ссылке
+type EventClick struct {
+	TargetID int64 `json:"target_id,omitempty"` // вариант A/B-теста, 0 - основной URL или правило
+}
+
+// Webhook - подписка пользователя на события ссылок
+type Webhook struct {
+	ID  int64 `json:"id"`
+	UID int64 `json:"uid"`
+	// Пространство, о ссылках которого приходят события. 0 - ссылки, созданные пользователем
+	WorkspaceID int64    `json:"workspace_id,omitempty"`
+	URL         string   `json:"url"`
+	Events      []string `json:"events"` // пусто - все события
+	// Ключ подписи HMAC-SHA256 тела запроса. Показывается только при создании
+	Secret    string    `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Состояния доставки события вебхуку
+const (
+	DeliveryPending   = "pending"   // ждет первой или повторной попытки
+	DeliveryDelivered = "delivered" // получатель ответил 2xx
+	DeliveryFailed    = "failed"    // попытки исчерпаны
+)
+
+// WebhookDelivery - доставка события вебхуку (журнал доставок)
+type WebhookDelivery struct {
+	ID             int64      `json:"id"`
+	WebhookID      int64      `json:"webhook_id"`
+	EventID        string     `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	Status         string     `json:"status"` // одно из Delivery*
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty"`
+	LastStatusCode int        `json:"last_status_code,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+}
+
+// PendingDelivery - доставка, которую пора выполнить, вместе с адресом и ключом вебхука
+type PendingDelivery struct {
+	WebhookDelivery
+	URL     string
+	Secret  string
+	Payload []byte // тело запроса - Event в JSON
+}
+
+// DeliveryAttempt - итог попытки доставки
+type DeliveryAttempt struct {
+	At         time.Time
+	StatusCode int    // 0 - ответа не было
+	Error      string // пусто при успехе
+	Delivered  bool
+	// Время следующей попытки. nil при неуспешной попытке - попытки исчерпаны
+	NextAttemptAt *time.Time
+}
+
+// IdempotencyRecord - запрос с ключом идемпотентности и ответ на него
+type IdempotencyRecord struct {
+	Scope       string // кто отправил запрос
+	Key         string
+	Fingerprint string // хеш запроса: повтор с тем же ключом должен совпадать
+	Completed   bool   // false - первый запрос еще обрабатывается
+	StatusCode  int
+	ContentType string
+	Body        []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
+
+// OutboxMessage - событие из outbox, ожидающее публикации во внешнюю шину
+type OutboxMessage struct {
+	ID        int64
+	EventID   string // ключ идемпотентности: одинаков при повторных публикациях
+	EventType string
+	LinkID    int64
+	Payload   []byte // Event в JSON
+	CreatedAt time.Time
+	Attempts  int    // неудачных попыток публикации
+	LastError string // ошибка последней попытки
+}